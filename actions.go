@@ -8,6 +8,7 @@ package main
 // - Single source of truth for state transitions
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -116,6 +117,99 @@ func (m *model) toggleTrash() {
 	}
 }
 
+// cleanupTrashNow applies the trash retention policy immediately and reports the result.
+// Used by: menu (trash-cleanup), context menu (cleanup_trash in trash view).
+func (m *model) cleanupTrashNow() {
+	policy := m.config.trashPolicy()
+	removed, err := applyTrashPolicy(policy, time.Time{})
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Trash cleanup failed: %v", err), true)
+		return
+	}
+	if removed == 0 {
+		m.setStatusMessage(fmt.Sprintf("🧹 Trash already within policy (%s)", policy), false)
+	} else {
+		m.setStatusMessage(fmt.Sprintf("🧹 Removed %d items from trash (%s)", removed, policy), false)
+	}
+	if m.showTrashOnly {
+		m.loadFiles()
+		if m.cursor >= len(m.files) {
+			m.cursor = 0
+		}
+	}
+}
+
+// repairTrashNow rebuilds the trash index from the trash directory contents.
+// Used by: menu (trash-repair), context menu (repair_trash in trash view).
+func (m *model) repairTrashNow() {
+	recovered, dropped, err := repairTrashMetadata()
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Trash repair failed: %v", err), true)
+		return
+	}
+	m.setStatusMessage(fmt.Sprintf("🔧 Trash index rebuilt: %d recovered, %d stale entries dropped", recovered, dropped), false)
+	if m.showTrashOnly {
+		m.loadFiles()
+		if m.cursor >= len(m.files) {
+			m.cursor = 0
+		}
+	}
+}
+
+// restoreTrashedItem restores a trashed item (or a path inside a trashed directory)
+// to dest ("" = original location). On a name conflict without keepBoth it opens the
+// "Restore Conflict" dialog so the user can keep both copies.
+// Used by: context menu (restore, restore_as), dialogs (Restore Conflict, Restore As), file picker (restore to).
+func (m *model) restoreTrashedItem(path, dest string, keepBoth bool) {
+	restoredTo, err := restoreTrashPath(path, dest, keepBoth)
+	if errors.Is(err, errRestoreConflict) {
+		target := dest
+		if target == "" {
+			target, _ = trashOriginalPath(m.trashItems, path)
+		}
+		m.dialog = dialogModel{
+			dialogType: dialogConfirm,
+			title:      "Restore Conflict",
+			message:    fmt.Sprintf("'%s' already exists.\nKeep both and restore as '%s'?", target, filepath.Base(uniqueRestorePath(target))),
+		}
+		m.pendingRestorePath = path
+		m.pendingRestoreDest = dest
+		m.showDialog = true
+		return
+	}
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Failed to restore: %s", err), true)
+		return
+	}
+	m.setStatusMessage(fmt.Sprintf("♻ Restored to %s", restoredTo), false)
+	if m.showTrashOnly {
+		m.loadFiles() // Refresh trash view
+		if m.cursor >= len(m.files) {
+			m.cursor = 0
+		}
+	}
+}
+
+// startRestorePicker leaves the trash view and opens the file picker in startDir
+// so the user can choose where a trashed item should be restored.
+// Used by: context menu (restore_to).
+func (m *model) startRestorePicker(path, startDir string) {
+	if info, err := os.Stat(startDir); err != nil || !info.IsDir() {
+		startDir = m.trashRestorePath
+		if startDir == "" {
+			startDir, _ = os.UserHomeDir()
+		}
+	}
+	m.showTrashOnly = false
+	m.filePickerMode = true
+	m.filePickerRestoreSource = path
+	m.viewMode = viewSinglePane
+	m.currentPath = startDir
+	m.cursor = 0
+	m.loadFiles()
+	m.setStatusMessage(fmt.Sprintf("♻ Select destination for: %s (Enter = select folder, Esc = cancel)", filepath.Base(path)), false)
+}
+
 // togglePrompts toggles the prompts-only filter and auto-expands ~/.prompts.
 // Used by: menu (toggle-prompts, go-prompts), keyboard (F11).
 func (m *model) togglePrompts() {
@@ -164,7 +258,7 @@ func (m *model) toggleChangesMode() {
 			m.detailScrollX = 0
 			m.showDiffPreview = true
 			m.calculateLayout()
-			m.setStatusMessage(fmt.Sprintf("Git changes: %d files (d: toggle diff)", len(changed)), false)
+			m.setStatusMessage(fmt.Sprintf("Git changes: %d files (d: toggle diff, s/u: stage/unstage, [/]: hunks, c: commit, z/Z: stash)", len(changed)), false)
 		}
 	} else {
 		m.exitChangesMode()
@@ -0,0 +1,558 @@
+package main
+
+// Module: blame.go
+// Purpose: Git blame overlay for file previews
+// Responsibilities:
+// - Running `git blame --porcelain` in the background and parsing its output
+// - Prefixing each line with short commit, author and relative date, colored by age
+// - Keeping the prefix aligned with wrapped, syntax-highlighted lines
+// - Showing the selected line's commit (message and diff) with Enter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	blameHashWidth   = 7
+	blameAuthorWidth = 12
+	blameDateWidth   = 14
+	blameMinContent  = 30 // Narrower content drops the author column
+)
+
+// blameAgeColors go from the newest commits in the file to the oldest
+var blameAgeColors = []lipgloss.AdaptiveColor{
+	{Light: "#005f87", Dark: "#5fd7ff"},
+	{Light: "#00875f", Dark: "#5fd7af"},
+	{Light: "#5f8787", Dark: "#87afaf"},
+	{Light: "#767676", Dark: "#8a8a8a"},
+	{Light: "#9e9e9e", Dark: "#626262"},
+}
+
+// blameCommit is one commit referenced by the blame output
+type blameCommit struct {
+	hash    string
+	author  string
+	time    time.Time
+	summary string
+}
+
+// uncommitted reports whether the lines are local changes (git's all-zero hash)
+func (c *blameCommit) uncommitted() bool {
+	return strings.Trim(c.hash, "0") == ""
+}
+
+// blameRow is one displayed line: a file line, or a wrapped continuation of one
+type blameRow struct {
+	line  int // File line (0-based)
+	first bool
+	text  string
+}
+
+// blameState is the blame overlay for the previewed file
+type blameState struct {
+	path    string
+	loading bool
+	err     error
+	lines   []*blameCommit // Commit of each file line
+	oldest  time.Time
+	newest  time.Time
+	cursor  int // Selected file line
+
+	// Display rows for the content lines at rowsWidth (rebuilt when the width changes)
+	rows      []blameRow
+	rowsWidth int
+	rowsCount int // Content lines the rows were built from
+
+	detail *blameDetail // Commit opened with Enter
+}
+
+// blameDetail is the message and diff of one commit
+type blameDetail struct {
+	commit      *blameCommit
+	loading     bool
+	lines       []string
+	err         error
+	savedScroll int
+}
+
+// blameMsg delivers the parsed blame output
+type blameMsg struct {
+	blame *blameState
+	lines []*blameCommit
+	err   error
+}
+
+// blameCommitMsg delivers a commit's `git show` output
+type blameCommitMsg struct {
+	detail *blameDetail
+	lines  []string
+	err    error
+}
+
+// parseBlamePorcelain parses `git blame --porcelain` output into the commit of each line
+func parseBlamePorcelain(data []byte) ([]*blameCommit, error) {
+	commits := make(map[string]*blameCommit)
+	var lines []*blameCommit
+	var current *blameCommit
+	finalLine := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\t") {
+			// Line content: the header before it said which final line this is
+			if current == nil || finalLine < 1 {
+				return nil, fmt.Errorf("malformed blame output")
+			}
+			for len(lines) < finalLine {
+				lines = append(lines, nil)
+			}
+			lines[finalLine-1] = current
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		if len(key) == 40 && isHexString(key) {
+			// "<hash> <orig-line> <final-line> [<lines in group>]"
+			fields := strings.Fields(value)
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("malformed blame header: %q", line)
+			}
+			n, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("malformed blame header: %q", line)
+			}
+			finalLine = n
+			current = commits[key]
+			if current == nil {
+				current = &blameCommit{hash: key}
+				commits[key] = current
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch key {
+		case "author":
+			current.author = value
+		case "author-time":
+			if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.time = time.Unix(sec, 0)
+			}
+		case "summary":
+			current.summary = value
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// isHexString reports whether s is made of hex digits only
+func isHexString(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// blameCmd runs git blame for the file in the background
+func blameCmd(b *blameState) tea.Cmd {
+	path := b.path
+	return func() tea.Msg {
+		cmd := exec.Command("git", "-C", filepath.Dir(path), "blame", "--porcelain", "--", filepath.Base(path))
+		out, err := cmd.Output()
+		if err != nil {
+			return blameMsg{blame: b, err: gitErrorText(err)}
+		}
+		lines, err := parseBlamePorcelain(out)
+		return blameMsg{blame: b, lines: lines, err: err}
+	}
+}
+
+// blameCommitCmd loads a commit's message and diff in the background
+func blameCommitCmd(path string, d *blameDetail) tea.Cmd {
+	hash := d.commit.hash
+	return func() tea.Msg {
+		cmd := exec.Command("git", "-C", filepath.Dir(path), "show", "--no-color", "--stat", "--patch", hash)
+		out, err := cmd.Output()
+		if err != nil {
+			return blameCommitMsg{detail: d, err: err}
+		}
+		return blameCommitMsg{detail: d, lines: strings.Split(strings.TrimRight(string(out), "\n"), "\n")}
+	}
+}
+
+// blameAvailable reports whether the current preview shows the file's lines as they are
+// (so blame lines up with them)
+func (m model) blameAvailable() bool {
+	p := m.preview
+	return p.loaded && p.filePath != "" && !p.isBinary && !p.tooLarge && !p.isJSONL && !p.isPrompt &&
+		p.pager == nil && p.hex == nil && p.table == nil && p.db == nil && p.image == nil &&
+		p.dir == nil && p.tree == nil && p.follow == nil
+}
+
+// toggleBlame turns the blame overlay on or off for the previewed file
+func (m *model) toggleBlame() tea.Cmd {
+	if b := m.preview.blame; b != nil {
+		m.preview.blame = nil
+		m.preview.scrollPos = m.blameScrollToSource(b)
+		m.setStatusMessage("Blame off", false)
+		return statusTimeoutCmd()
+	}
+	if !m.blameAvailable() {
+		if m.preview.follow != nil {
+			m.setStatusMessage("Stop following (w) to show blame", true)
+		} else {
+			m.setStatusMessage("Blame is only available for text previews", true)
+		}
+		return statusTimeoutCmd()
+	}
+	if m.findGitRoot(filepath.Dir(m.preview.filePath)) == "" {
+		m.setStatusMessage("Not inside a git repository", true)
+		return statusTimeoutCmd()
+	}
+
+	b := &blameState{path: m.preview.filePath, loading: true}
+	// Start at the first line on screen
+	if !m.preview.isMarkdown && m.preview.cacheValid {
+		b.cursor = m.sourceLineAt(m.preview.scrollPos)
+	}
+	m.preview.blame = b
+	m.preview.scrollPos = m.blameRowOf(b.cursor)
+	m.setStatusMessage("Running git blame...", false)
+	return blameCmd(b)
+}
+
+// sourceLineAt returns the file line shown at wrapped line i of the regular text preview
+func (m model) sourceLineAt(i int) int {
+	width := m.preview.cachedWidth
+	row := 0
+	for line, text := range m.preview.content {
+		row += len(wrapLine(text, width))
+		if row > i {
+			return line
+		}
+	}
+	return max(len(m.preview.content)-1, 0)
+}
+
+// blameScrollToSource returns the regular preview's scroll position for the blame cursor
+func (m model) blameScrollToSource(b *blameState) int {
+	if m.preview.isMarkdown || !m.preview.cacheValid {
+		return 0
+	}
+	row := 0
+	for line := 0; line < b.cursor && line < len(m.preview.content); line++ {
+		row += len(wrapLine(m.preview.content[line], m.preview.cachedWidth))
+	}
+	return row
+}
+
+// applyBlameMsg shows finished blame output (results for a closed overlay are ignored)
+func (m *model) applyBlameMsg(msg blameMsg) tea.Cmd {
+	b := m.preview.blame
+	if b == nil || msg.blame != b {
+		return nil
+	}
+	b.loading = false
+	if msg.err != nil {
+		b.err = msg.err
+		m.preview.blame = nil
+		m.setStatusMessage(fmt.Sprintf("Blame failed: %v", msg.err), true)
+		return statusTimeoutCmd()
+	}
+	b.lines = msg.lines
+	for _, c := range b.lines {
+		if c == nil || c.uncommitted() || c.time.IsZero() {
+			continue
+		}
+		if b.oldest.IsZero() || c.time.Before(b.oldest) {
+			b.oldest = c.time
+		}
+		if c.time.After(b.newest) {
+			b.newest = c.time
+		}
+	}
+	m.setStatusMessage("Blame: j/k select line, Enter: show commit, b: hide", false)
+	return statusTimeoutCmd()
+}
+
+// applyBlameCommitMsg shows a loaded commit
+func (m *model) applyBlameCommitMsg(msg blameCommitMsg) {
+	b := m.preview.blame
+	if b == nil || b.detail != msg.detail {
+		return
+	}
+	d := b.detail
+	d.loading = false
+	d.lines, d.err = msg.lines, msg.err
+}
+
+// blameContentWidth returns the width left for file content next to the blame prefix
+func (m model) blameContentWidth() (width int, showAuthor bool) {
+	// Scrollbar (1) + space (1), then the prefix and its separator
+	width = m.previewBoxWidth() - 2 - blameHashWidth - 1 - blameDateWidth - 2
+	if width-blameAuthorWidth-1 >= blameMinContent {
+		return width - blameAuthorWidth - 1, true
+	}
+	return max(width, 10), false
+}
+
+// blameRows returns the display rows, wrapping the content lines to the width left by the prefix
+func (m model) blameRows() []blameRow {
+	b := m.preview.blame
+	width, _ := m.blameContentWidth()
+	if b.rows != nil && b.rowsWidth == width && b.rowsCount == len(m.preview.content) {
+		return b.rows
+	}
+	rows := make([]blameRow, 0, len(m.preview.content))
+	for line, text := range m.preview.content {
+		for k, w := range wrapLine(text, width) {
+			rows = append(rows, blameRow{line: line, first: k == 0, text: w})
+		}
+	}
+	b.rows, b.rowsWidth, b.rowsCount = rows, width, len(m.preview.content)
+	return rows
+}
+
+// blameLineCount returns the number of displayed lines (commit view or blame rows)
+func (m model) blameLineCount() int {
+	if d := m.preview.blame.detail; d != nil {
+		if d.loading || d.err != nil {
+			return 1
+		}
+		wrapped := m.diffDisplayLines(d.lines)
+		return len(wrapped)
+	}
+	return len(m.blameRows())
+}
+
+// blameDisplayLines returns the displayed content (without prefixes) for preview search
+func (m model) blameDisplayLines() []string {
+	if d := m.preview.blame.detail; d != nil {
+		wrapped := m.diffDisplayLines(d.lines)
+		return wrapped
+	}
+	rows := m.blameRows()
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = row.text
+	}
+	return lines
+}
+
+// moveBlameCursor moves the selected line and scrolls to keep it visible
+func (m *model) moveBlameCursor(delta int) {
+	b := m.preview.blame
+	b.cursor = max(0, min(b.cursor+delta, len(m.preview.content)-1))
+	m.blameKeepCursorVisible()
+}
+
+// blameRowOf returns the first display row of a file line
+func (m model) blameRowOf(line int) int {
+	rows := m.blameRows()
+	i := sort.Search(len(rows), func(i int) bool { return rows[i].line >= line })
+	return min(i, max(len(rows)-1, 0))
+}
+
+// blameKeepCursorVisible scrolls so all rows of the selected line are on screen
+func (m *model) blameKeepCursorVisible() {
+	b := m.preview.blame
+	rows := m.blameRows()
+	if len(rows) == 0 {
+		return
+	}
+	first := m.blameRowOf(b.cursor)
+	last := first
+	for last+1 < len(rows) && rows[last+1].line == b.cursor {
+		last++
+	}
+	visible := max(m.getPreviewVisibleLines(), 1)
+	if first < m.preview.scrollPos {
+		m.preview.scrollPos = first
+	} else if last >= m.preview.scrollPos+visible {
+		m.preview.scrollPos = min(first, last-visible+1)
+	}
+}
+
+// openBlameCommit shows the commit of the selected line
+func (m *model) openBlameCommit() tea.Cmd {
+	b := m.preview.blame
+	if b.loading || b.cursor >= len(b.lines) || b.lines[b.cursor] == nil {
+		return nil
+	}
+	c := b.lines[b.cursor]
+	if c.uncommitted() {
+		m.setStatusMessage("This line is not committed yet", true)
+		return statusTimeoutCmd()
+	}
+	b.detail = &blameDetail{commit: c, loading: true, savedScroll: m.preview.scrollPos}
+	m.preview.scrollPos = 0
+	return blameCommitCmd(b.path, b.detail)
+}
+
+// closeBlameCommit returns from the commit view to the blame rows
+func (m *model) closeBlameCommit() {
+	b := m.preview.blame
+	m.preview.scrollPos = b.detail.savedScroll
+	b.detail = nil
+}
+
+// ageColor picks the prefix color from the commit's age relative to the file's history
+func (b *blameState) ageColor(c *blameCommit) lipgloss.TerminalColor {
+	if c.uncommitted() {
+		return currentTheme.DiffAdded.adaptiveColor()
+	}
+	span := b.newest.Sub(b.oldest)
+	if span <= 0 {
+		return blameAgeColors[0]
+	}
+	step := int(float64(b.newest.Sub(c.time)) / float64(span) * float64(len(blameAgeColors)-1))
+	return blameAgeColors[max(0, min(step, len(blameAgeColors)-1))]
+}
+
+// blamePrefix formats the commit columns for a file line
+func blamePrefix(c *blameCommit, showAuthor bool) string {
+	var hash, author, date string
+	switch {
+	case c == nil:
+		// Line added after blame ran
+	case c.uncommitted():
+		hash, author, date = "·······", "You", "uncommitted"
+	default:
+		hash, author, date = c.hash[:blameHashWidth], c.author, formatLastCommitTime(c.time)
+	}
+	prefix := padToWidth(truncateToWidth(hash, blameHashWidth), blameHashWidth) + " "
+	if showAuthor {
+		prefix += padToWidth(truncateToWidth(author, blameAuthorWidth), blameAuthorWidth) + " "
+	}
+	return prefix + padToWidth(truncateToWidth(date, blameDateWidth), blameDateWidth)
+}
+
+// padToWidth pads s with spaces to width display columns
+func padToWidth(s string, width int) string {
+	if w := visualWidth(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// renderBlamePreview renders the blame rows, or the commit opened with Enter
+func (m model) renderBlamePreview(maxVisible int) string {
+	b := m.preview.blame
+	if d := b.detail; d != nil {
+		if d.loading || d.err != nil {
+			text := "Loading commit " + d.commit.hash[:blameHashWidth] + "..."
+			if d.err != nil {
+				text = fmt.Sprintf("Cannot show commit %s: %v", d.commit.hash[:blameHashWidth], d.err)
+			}
+			return lipgloss.NewStyle().Foreground(uiSubtleText()).Italic(true).Render(text) +
+				strings.Repeat("\n\033[0m", max(maxVisible-1, 0))
+		}
+		return m.renderDiffLines(d.lines, maxVisible, "commit "+d.commit.hash[:blameHashWidth])
+	}
+
+	var s strings.Builder
+	rows := m.blameRows()
+	_, showAuthor := m.blameContentWidth()
+	prefixWidth := visualWidth(blamePrefix(nil, showAuthor))
+
+	totalLines := max(len(rows), 1)
+	targetLines := maxVisible
+	if m.viewMode == viewDualPane {
+		targetLines = maxVisible - 1
+	}
+	start := max(0, min(m.preview.scrollPos, totalLines-targetLines))
+
+	cursorStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.SelectionFg.adaptiveColor()).
+		Background(currentTheme.SelectionBg.adaptiveColor())
+	separatorStyle := lipgloss.NewStyle().Foreground(uiSubtleText())
+
+	linesRendered := 0
+	writeLine := func(line string) {
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(line)
+		linesRendered++
+	}
+
+	for i := start; i < len(rows) && linesRendered < targetLines; i++ {
+		row := rows[i]
+		prefix := strings.Repeat(" ", prefixWidth)
+		var commit *blameCommit
+		if row.line < len(b.lines) {
+			commit = b.lines[row.line]
+		}
+		if row.first {
+			switch {
+			case b.loading:
+				prefix = padToWidth("…", prefixWidth)
+			case commit != nil:
+				prefix = blamePrefix(commit, showAuthor)
+			}
+		}
+		if row.line == b.cursor {
+			prefix = cursorStyle.Render(prefix)
+		} else if commit != nil && row.first {
+			prefix = lipgloss.NewStyle().Foreground(b.ageColor(commit)).Render(prefix)
+		}
+
+		line := m.renderScrollbar(i-start, maxVisible, totalLines) + " " + prefix + separatorStyle.Render("│") + " "
+		line += m.highlightSearchLine(row.text, i)
+		if visualWidth(line) > m.previewBoxWidth() {
+			line = truncateToWidth(line, m.previewBoxWidth())
+		}
+		writeLine(line + "\033[0m")
+	}
+
+	if m.viewMode == viewDualPane {
+		for linesRendered < targetLines {
+			writeLine("\033[0m")
+		}
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(lipgloss.NewStyle().Foreground(uiSubtleText()).Italic(true).Render(" " + m.blameStatusText() + " "))
+	} else {
+		for linesRendered < maxVisible {
+			writeLine("\033[0m")
+		}
+	}
+	return s.String()
+}
+
+// blameStatusText describes the selected line's commit for info lines
+func (m model) blameStatusText() string {
+	b := m.preview.blame
+	if b.loading {
+		return "Blame | running git blame..."
+	}
+	if d := b.detail; d != nil {
+		return fmt.Sprintf("Blame | commit %s %s", d.commit.hash[:blameHashWidth], d.commit.summary)
+	}
+	if b.cursor < len(b.lines) && b.lines[b.cursor] != nil {
+		c := b.lines[b.cursor]
+		if c.uncommitted() {
+			return fmt.Sprintf("Blame | Line %d: not committed yet", b.cursor+1)
+		}
+		return fmt.Sprintf("Blame | Line %d: %s %s, %s - %s", b.cursor+1, c.hash[:blameHashWidth], c.author,
+			formatLastCommitTime(c.time), c.summary)
+	}
+	return fmt.Sprintf("Blame | Line %d", b.cursor+1)
+}
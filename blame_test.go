@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initTestRepo creates a git repository with one committed file
+func initTestRepo(t *testing.T, name, content string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=Alice", "-c", "user.email=alice@example.com", "commit", "-q", "-m", "Initial commit"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestParseBlamePorcelain(t *testing.T) {
+	a := strings.Repeat("a", 40)
+	b := strings.Repeat("0", 40)
+	out := a + " 1 1 2\nauthor Alice\nauthor-time 1700000000\nsummary First\nfilename f.txt\n\tone\n" +
+		a + " 2 2\n\ttwo\n" +
+		b + " 3 3 1\nauthor Not Committed Yet\nauthor-time 1800000000\nsummary Version of f.txt from f.txt\nfilename f.txt\n\tthree\n"
+
+	lines, err := parseBlamePorcelain([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 3 || lines[0] != lines[1] || lines[0].author != "Alice" || lines[0].summary != "First" {
+		t.Fatalf("Unexpected commits: %+v", lines)
+	}
+	if lines[0].time.Unix() != 1700000000 || lines[0].uncommitted() || !lines[2].uncommitted() {
+		t.Errorf("Unexpected commit details: %+v / %+v", lines[0], lines[2])
+	}
+
+	if _, err := parseBlamePorcelain([]byte("\tcontent without header\n")); err == nil {
+		t.Error("Expected malformed output to fail")
+	}
+}
+
+func TestBlameOverlay(t *testing.T) {
+	long := strings.Repeat("word ", 40)
+	dir := initTestRepo(t, "notes.txt", "first\n"+long+"\nthird\n")
+	path := filepath.Join(dir, "notes.txt")
+	os.WriteFile(path, []byte("first\n"+long+"\nchanged\n"), 0644)
+
+	m := model{height: 30, width: 120, viewMode: viewFullPreview}
+	m.loadPreview(path)
+	m.populatePreviewCache()
+	cmd := m.toggleBlame()
+	b := m.preview.blame
+	if b == nil || !b.loading {
+		t.Fatal("Expected blame to start")
+	}
+
+	m.applyBlameMsg(cmd().(blameMsg))
+	if b.loading || len(b.lines) < 3 || b.lines[0].author != "Alice" || !b.lines[2].uncommitted() {
+		t.Fatalf("Unexpected blame: %+v", b.lines)
+	}
+
+	// The wrapped line's continuation rows have no prefix of their own
+	rows := m.blameRows()
+	if len(rows) <= len(m.preview.content) || !rows[1].first || rows[2].first || rows[2].line != 1 {
+		t.Fatalf("Expected line 2 to wrap, got %d rows", len(rows))
+	}
+	if m.getWrappedLineCount() != len(rows) {
+		t.Errorf("Expected the line count to follow the blame rows")
+	}
+	view := plainLines(strings.Split(m.renderBlamePreview(10), "\n"))
+	if !strings.Contains(view, "Alice") || !strings.Contains(view, "uncommitted") {
+		t.Errorf("Expected author and uncommitted marker in:\n%s", view)
+	}
+
+	// Enter on a committed line shows the commit
+	m.moveBlameCursor(-10)
+	detailCmd := m.openBlameCommit()
+	if detailCmd == nil || b.detail == nil {
+		t.Fatal("Expected the commit view to open")
+	}
+	m.applyBlameCommitMsg(detailCmd().(blameCommitMsg))
+	if text := strings.Join(b.detail.lines, "\n"); !strings.Contains(text, "Initial commit") || !strings.Contains(text, "+first") {
+		t.Errorf("Expected message and diff, got:\n%s", text)
+	}
+	m.closeBlameCommit()
+
+	// Uncommitted lines have no commit to show
+	m.moveBlameCursor(2)
+	if m.openBlameCommit(); b.detail != nil {
+		t.Error("Expected no commit view for an uncommitted line")
+	}
+
+	// Loading another file drops the overlay
+	m.loadPreview(path)
+	if m.preview.blame != nil {
+		t.Error("Expected the overlay to be cleared")
+	}
+}
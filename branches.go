@@ -0,0 +1,628 @@
+package main
+
+// Module: branches.go
+// Purpose: Branch switcher and manager (B, Git → Branches...)
+// Responsibilities:
+// - Listing local and remote branches with upstream, ahead/behind and last commit
+// - Checkout (remote branches get a local tracking branch), create, rename, delete and set upstream
+// - Keeping the git repositories list in sync after each change
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// gitBranchInfo is one local or remote branch
+type gitBranchInfo struct {
+	name     string // Short name: main, origin/main
+	remote   bool
+	current  bool
+	upstream string // Tracked branch (local branches only)
+	ahead    int    // Commits not on the upstream
+	behind   int    // Upstream commits not on the branch
+	gone     bool   // The upstream was deleted on the remote
+	time     time.Time
+	subject  string
+}
+
+// Branch manager prompts
+const (
+	branchPromptNone = iota
+	branchPromptCreate
+	branchPromptRename
+	branchPromptUpstream
+	branchPromptDelete
+	branchPromptForceDelete
+)
+
+// branchManager is the state of the branches dialog
+type branchManager struct {
+	root     string
+	branches []gitBranchInfo
+	cursor   int
+	offset   int // First visible row
+	prompt   int
+	input    string
+	err      string
+}
+
+// branchRefFormat is the for-each-ref format read by parseBranchRefs (fields split by \x1f)
+const branchRefFormat = "%(refname)%1f%(HEAD)%1f%(upstream:short)%1f%(upstream:track,nobracket)%1f%(committerdate:unix)%1f%(subject)"
+
+// parseBranchRefs parses git for-each-ref output; remote HEAD aliases are skipped
+func parseBranchRefs(out string) []gitBranchInfo {
+	var branches []gitBranchInfo
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) < 6 {
+			continue
+		}
+		b := gitBranchInfo{current: fields[1] == "*", upstream: fields[2], subject: fields[5]}
+		switch {
+		case strings.HasPrefix(fields[0], "refs/heads/"):
+			b.name = strings.TrimPrefix(fields[0], "refs/heads/")
+		case strings.HasPrefix(fields[0], "refs/remotes/"):
+			b.name = strings.TrimPrefix(fields[0], "refs/remotes/")
+			b.remote = true
+			if strings.HasSuffix(b.name, "/HEAD") {
+				continue
+			}
+		default:
+			continue
+		}
+		// Track is "ahead 1, behind 2", "ahead 1", "behind 2", "gone" or empty
+		for _, part := range strings.Split(fields[3], ", ") {
+			if part == "gone" {
+				b.gone = true
+			} else if n, ok := strings.CutPrefix(part, "ahead "); ok {
+				b.ahead, _ = strconv.Atoi(n)
+			} else if n, ok := strings.CutPrefix(part, "behind "); ok {
+				b.behind, _ = strconv.Atoi(n)
+			}
+		}
+		if unix, err := strconv.ParseInt(fields[4], 10, 64); err == nil {
+			b.time = time.Unix(unix, 0)
+		}
+		branches = append(branches, b)
+	}
+	return branches
+}
+
+// listBranches returns the repository's local branches followed by its remote branches
+func listBranches(root string) ([]gitBranchInfo, error) {
+	out, err := runGit(root, "", "for-each-ref", "--format="+branchRefFormat, "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	return parseBranchRefs(out), nil
+}
+
+// selectedRepoRoot returns the repository for the branch manager and stash browser: the selected repo in
+// git repositories mode, otherwise the current directory's
+func (m *model) selectedRepoRoot() string {
+	if file := m.getCurrentFile(); m.showGitReposOnly && file != nil && file.isGitRepo {
+		return file.path
+	}
+	return m.resolveGitRoot()
+}
+
+// openBranchManager opens the branches dialog for root
+func (m *model) openBranchManager(root string) {
+	if root == "" {
+		m.setStatusMessage("Not inside a git repository", true)
+		return
+	}
+	branches, err := listBranches(root)
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Branches failed: %v", err), true)
+		return
+	}
+	m.branches = &branchManager{root: root, branches: branches}
+	for i, b := range branches {
+		if b.current {
+			m.branches.cursor = i
+		}
+	}
+	m.dialog = dialogModel{dialogType: dialogBranches, title: "Branches"}
+	m.showDialog = true
+}
+
+// closeBranchManager closes the branches dialog
+func (m *model) closeBranchManager() {
+	m.branches = nil
+	m.showDialog = false
+	m.dialog = dialogModel{}
+}
+
+// selected returns the branch under the cursor
+func (bm *branchManager) selected() *gitBranchInfo {
+	if bm.cursor < 0 || bm.cursor >= len(bm.branches) {
+		return nil
+	}
+	return &bm.branches[bm.cursor]
+}
+
+// hasLocal reports whether a local branch is called name
+func (bm *branchManager) hasLocal(name string) bool {
+	for _, b := range bm.branches {
+		if !b.remote && b.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadBranches re-reads the branches after a change, keeping the cursor on name when it still exists
+func (m *model) reloadBranches(name string) {
+	bm := m.branches
+	branches, err := listBranches(bm.root)
+	if err != nil {
+		bm.err = err.Error()
+		return
+	}
+	bm.branches = branches
+	bm.cursor = min(bm.cursor, max(0, len(branches)-1))
+	for i, b := range branches {
+		if !b.remote && b.name == name {
+			bm.cursor = i
+		}
+	}
+	m.refreshGitRepoEntry(bm.root, branches)
+	m.invalidateGitStatus()
+	if m.showChangesOnly {
+		m.refreshChanges()
+	} else {
+		m.loadFiles()
+		m.preview.cacheValid = false
+	}
+}
+
+// refreshGitRepoEntry re-reads the branch and status of root in the git repositories list,
+// reusing branches when the caller has just listed them (nil = list them)
+func (m *model) refreshGitRepoEntry(root string, branches []gitBranchInfo) {
+	resolved := root
+	if r, err := filepath.EvalSymlinks(root); err == nil {
+		resolved = r
+	}
+	for i := range m.gitReposList {
+		repo := &m.gitReposList[i]
+		path := repo.path
+		if r, err := filepath.EvalSymlinks(path); err == nil {
+			path = r
+		}
+		if filepath.Clean(path) != filepath.Clean(resolved) {
+			continue
+		}
+		status := getGitStatusWithBranches(repo.path, branches)
+		repo.gitBranch = status.branch
+		repo.gitAhead = status.ahead
+		repo.gitBehind = status.behind
+		repo.gitDirty = status.dirty
+		repo.gitLastCommit = status.lastCommitTime
+	}
+}
+
+// branchGit runs a git command for the branch manager, showing a failure in the dialog
+func (m *model) branchGit(args ...string) bool {
+	if _, err := runGit(m.branches.root, "", args...); err != nil {
+		m.branches.err = err.Error()
+		return false
+	}
+	m.branches.err = ""
+	return true
+}
+
+// checkoutBranch switches to the selected branch; a remote branch is checked out
+// through a local branch tracking it
+func (m *model) checkoutBranch() {
+	bm := m.branches
+	b := bm.selected()
+	if b == nil {
+		return
+	}
+	if b.current {
+		m.setStatusMessage("Already on "+b.name, false)
+		return
+	}
+	name := b.name
+	args := []string{"switch", name}
+	if b.remote {
+		_, name, _ = strings.Cut(b.name, "/")
+		if !bm.hasLocal(name) {
+			args = []string{"switch", "-c", name, "--track", b.name}
+		} else {
+			args = []string{"switch", name}
+		}
+	}
+	if m.branchGit(args...) {
+		m.reloadBranches(name)
+		m.setStatusMessage("✓ Switched to "+name, false)
+	}
+}
+
+// startBranchPrompt opens one of the dialog's prompts for the selected branch
+func (m *model) startBranchPrompt(prompt int) {
+	bm := m.branches
+	b := bm.selected()
+	bm.err = ""
+	if prompt != branchPromptCreate {
+		if b == nil {
+			return
+		}
+		if b.remote {
+			bm.err = "Remote branches can only be checked out - pick a local branch"
+			return
+		}
+	}
+	bm.prompt = prompt
+	bm.input = ""
+	switch prompt {
+	case branchPromptRename:
+		bm.input = b.name
+	case branchPromptUpstream:
+		bm.input = b.upstream
+		if bm.input == "" {
+			bm.input = "origin/" + b.name
+		}
+	}
+}
+
+// submitBranchPrompt runs the action of the open prompt
+func (m *model) submitBranchPrompt() {
+	bm := m.branches
+	input := strings.TrimSpace(bm.input)
+	prompt := bm.prompt
+	bm.prompt = branchPromptNone
+	b := bm.selected()
+	switch prompt {
+	case branchPromptCreate:
+		if input == "" {
+			return
+		}
+		if m.branchGit("switch", "-c", input) {
+			m.reloadBranches(input)
+			m.setStatusMessage("✓ Created and switched to "+input, false)
+		}
+	case branchPromptRename:
+		if input == "" || b == nil || input == b.name {
+			return
+		}
+		if m.branchGit("branch", "-m", b.name, input) {
+			m.reloadBranches(input)
+			m.setStatusMessage(fmt.Sprintf("✓ Renamed %s to %s", b.name, input), false)
+		}
+	case branchPromptUpstream:
+		if b == nil {
+			return
+		}
+		name := b.name
+		if input == "" {
+			if b.upstream != "" && m.branchGit("branch", "--unset-upstream", name) {
+				m.reloadBranches(name)
+				m.setStatusMessage("✓ Removed the upstream of "+name, false)
+			}
+			return
+		}
+		if m.branchGit("branch", "--set-upstream-to="+input, name) {
+			m.reloadBranches(name)
+			m.setStatusMessage(fmt.Sprintf("✓ %s now tracks %s", name, input), false)
+		}
+	}
+}
+
+// deleteBranch deletes the selected local branch; an unmerged branch asks again before
+// it's force deleted
+func (m *model) deleteBranch(force bool) {
+	bm := m.branches
+	b := bm.selected()
+	if b == nil {
+		return
+	}
+	name := b.name
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	if _, err := runGit(bm.root, "", "branch", flag, name); err != nil {
+		if !force && strings.Contains(err.Error(), "not fully merged") {
+			bm.prompt = branchPromptForceDelete
+			return
+		}
+		bm.err = err.Error()
+		return
+	}
+	bm.err = ""
+	m.reloadBranches("")
+	m.setStatusMessage("✓ Deleted branch "+name, false)
+}
+
+// handleBranchKey handles keys while the branches dialog is open
+func (m model) handleBranchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	bm := m.branches
+	if bm == nil {
+		m.closeBranchManager()
+		return m, tea.ClearScreen
+	}
+	key := msg.String()
+
+	switch bm.prompt {
+	case branchPromptDelete, branchPromptForceDelete:
+		force := bm.prompt == branchPromptForceDelete
+		bm.prompt = branchPromptNone
+		if key == "y" || key == "Y" {
+			m.deleteBranch(force)
+		}
+		return m, nil
+	case branchPromptCreate, branchPromptRename, branchPromptUpstream:
+		switch key {
+		case "esc":
+			bm.prompt = branchPromptNone
+		case "enter":
+			m.submitBranchPrompt()
+		case "backspace":
+			if len(bm.input) > 0 {
+				_, size := utf8.DecodeLastRuneInString(bm.input)
+				bm.input = bm.input[:len(bm.input)-size]
+			}
+		default:
+			// Branch names can't contain spaces or control characters
+			for _, r := range msg.Runes {
+				if r <= 32 || r == 127 {
+					return m, nil
+				}
+			}
+			bm.input += string(msg.Runes)
+		}
+		return m, nil
+	}
+
+	switch key {
+	case "esc", "q", "B":
+		m.closeBranchManager()
+		return m, tea.ClearScreen
+	case "up", "k":
+		bm.cursor = max(0, bm.cursor-1)
+	case "down", "j":
+		bm.cursor = min(len(bm.branches)-1, bm.cursor+1)
+	case "home", "g":
+		bm.cursor = 0
+	case "end", "G":
+		bm.cursor = len(bm.branches) - 1
+	case "pgup":
+		bm.cursor = max(0, bm.cursor-m.branchVisibleRows())
+	case "pgdown":
+		bm.cursor = min(len(bm.branches)-1, bm.cursor+m.branchVisibleRows())
+	case "enter":
+		m.checkoutBranch()
+	case "n":
+		m.startBranchPrompt(branchPromptCreate)
+	case "r":
+		m.startBranchPrompt(branchPromptRename)
+	case "u":
+		m.startBranchPrompt(branchPromptUpstream)
+	case "x", "d", "delete":
+		if b := bm.selected(); b != nil && b.current {
+			bm.err = "Can't delete the checked out branch - switch to another one first"
+		} else {
+			m.startBranchPrompt(branchPromptDelete)
+		}
+	}
+	return m, nil
+}
+
+// branchDialogWidth is the branches dialog's content width
+func (m model) branchDialogWidth() int {
+	return max(40, min(100, m.width-8))
+}
+
+// branchVisibleRows is the number of list rows (branches and section headings) shown at once
+func (m model) branchVisibleRows() int {
+	rows := 3
+	if m.branches != nil && len(m.branches.branches) > 0 {
+		rows = len(m.branches.branches) + 1
+		if m.branches.branches[len(m.branches.branches)-1].remote {
+			rows++ // The remote heading
+		}
+	}
+	return max(3, min(min(rows, 16), m.height-14))
+}
+
+// branchDialogHeight estimates the dialog's rendered height for centering
+func (m model) branchDialogHeight() int {
+	return m.branchVisibleRows() + 10
+}
+
+// branchCounts formats how far a branch is ahead/behind its upstream
+func branchCounts(b gitBranchInfo) string {
+	switch {
+	case b.gone:
+		return "gone"
+	case b.ahead > 0 && b.behind > 0:
+		return fmt.Sprintf("↑%d↓%d", b.ahead, b.behind)
+	case b.ahead > 0:
+		return fmt.Sprintf("↑%d", b.ahead)
+	case b.behind > 0:
+		return fmt.Sprintf("↓%d", b.behind)
+	}
+	return ""
+}
+
+// branchTrack formats a branch's upstream with ahead/behind counts
+func branchTrack(b gitBranchInfo) string {
+	if b.remote || b.upstream == "" {
+		return ""
+	}
+	return strings.TrimSpace(b.upstream + " " + branchCounts(b))
+}
+
+// branchRows lays out the branch list: a heading row before the local and remote groups
+// (index -1) and one row per branch
+func (m model) branchRows(width int) (rows []string, index []int) {
+	bm := m.branches
+	nameWidth, upstreamWidth, countsWidth := 6, 0, 0
+	for _, b := range bm.branches {
+		nameWidth = max(nameWidth, visualWidth(b.name))
+		if !b.remote {
+			upstreamWidth = max(upstreamWidth, visualWidth(b.upstream))
+			countsWidth = max(countsWidth, visualWidth(branchCounts(b)))
+		}
+	}
+	nameWidth = min(nameWidth, width/3)
+	upstreamWidth = min(upstreamWidth, width/4)
+	const timeWidth = 14
+
+	heading := lipgloss.NewStyle().Bold(true).Foreground(uiMutedText())
+	aheadStyle := lipgloss.NewStyle().Foreground(currentTheme.DiffAdded.adaptiveColor())
+	for i, b := range bm.branches {
+		if i == 0 || b.remote != bm.branches[i-1].remote {
+			label := "Local"
+			if b.remote {
+				label = "Remote"
+			}
+			rows = append(rows, heading.Render(label))
+			index = append(index, -1)
+		}
+		marker := "  "
+		if b.current {
+			marker = "* "
+		}
+		row := marker + padToWidth(truncateToWidth(b.name, nameWidth), nameWidth)
+		if upstreamWidth > 0 {
+			upstream, counts := "", ""
+			if !b.remote {
+				upstream, counts = b.upstream, branchCounts(b)
+			}
+			row += "  " + padToWidth(truncateToWidth(upstream, upstreamWidth), upstreamWidth)
+			if countsWidth > 0 {
+				row += " " + padToWidth(counts, countsWidth)
+			}
+		}
+		row += "  " + padToWidth(formatLastCommitTime(b.time), timeWidth)
+		row += "  " + b.subject
+		row = padToWidth(truncateToWidth(row, width), width)
+		switch {
+		case i == bm.cursor:
+			row = selectedStyle.Render(row)
+		case b.current:
+			row = aheadStyle.Render(row)
+		}
+		rows = append(rows, row)
+		index = append(index, i)
+	}
+	return rows, index
+}
+
+// renderBranchDialog renders the branch manager
+func (m model) renderBranchDialog() string {
+	bm := m.branches
+	if bm == nil {
+		return ""
+	}
+	width := m.branchDialogWidth()
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(currentTheme.BorderFocused.adaptiveColor()).
+		Background(uiPanelBackground()).
+		Padding(1, 2).
+		Width(width)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(currentTheme.Title.adaptiveColor()).
+		Align(lipgloss.Center).
+		Width(width)
+
+	inputStyle := lipgloss.NewStyle().
+		Foreground(uiBodyText()).
+		Background(uiInputBackground()).
+		Padding(0, 1)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(uiMutedText())
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.DiffRemoved.adaptiveColor())
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(uiMutedText()).
+		Align(lipgloss.Center).
+		Width(width)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Branches - " + filepath.Base(bm.root)))
+	content.WriteString("\n\n")
+
+	rows, index := m.branchRows(width - 4)
+	visible := m.branchVisibleRows()
+	// Keep the cursor's row (and the heading above the first branch) in view
+	cursorRow := 0
+	for i, idx := range index {
+		if idx == bm.cursor {
+			cursorRow = i
+		}
+	}
+	offset := min(bm.offset, max(0, len(rows)-visible))
+	if cursorRow < offset {
+		offset = cursorRow
+		if offset > 0 && index[offset-1] == -1 {
+			offset--
+		}
+	}
+	if cursorRow >= offset+visible {
+		offset = cursorRow - visible + 1
+	}
+	bm.offset = offset
+	if len(rows) == 0 {
+		content.WriteString(mutedStyle.Render("No branches yet - make a first commit"))
+		content.WriteString("\n")
+	}
+	for i := offset; i < len(rows) && i < offset+visible; i++ {
+		content.WriteString(rows[i])
+		content.WriteString("\n")
+	}
+	for i := len(rows) - offset; i < visible; i++ {
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	b := bm.selected()
+	switch bm.prompt {
+	case branchPromptCreate:
+		current := "HEAD"
+		for _, br := range bm.branches {
+			if br.current {
+				current = br.name
+			}
+		}
+		content.WriteString("New branch from " + current + ": " + inputStyle.Render(bm.input+"█"))
+	case branchPromptRename:
+		content.WriteString("Rename " + b.name + " to: " + inputStyle.Render(bm.input+"█"))
+	case branchPromptUpstream:
+		content.WriteString("Upstream of " + b.name + " (empty to unset): " + inputStyle.Render(bm.input+"█"))
+	case branchPromptDelete:
+		content.WriteString(errorStyle.Render("Delete branch " + b.name + "? [y/N]"))
+	case branchPromptForceDelete:
+		content.WriteString(errorStyle.Render(b.name + " is not fully merged - delete it anyway? [y/N]"))
+	default:
+		if bm.err != "" {
+			content.WriteString(errorStyle.Render(truncateToWidth(bm.err, width-4)))
+		}
+	}
+	content.WriteString("\n\n")
+
+	hint := "Enter: checkout | n: new | r: rename | u: upstream | x: delete | Esc: close"
+	if bm.prompt != branchPromptNone {
+		hint = "Enter: confirm | Esc: cancel"
+	}
+	content.WriteString(hintStyle.Render(hint))
+
+	return borderStyle.Render(content.String())
+}
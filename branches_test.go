@@ -0,0 +1,144 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseBranchRefs(t *testing.T) {
+	out := "refs/heads/main\x1f*\x1forigin/main\x1fahead 2, behind 1\x1f1700000000\x1fLatest\n" +
+		"refs/heads/old\x1f \x1forigin/old\x1fgone\x1f1600000000\x1fOld work\n" +
+		"refs/remotes/origin/HEAD\x1f \x1f\x1f\x1f1700000000\x1fLatest\n" +
+		"refs/remotes/origin/main\x1f \x1f\x1f\x1f1690000000\x1fEarlier\n"
+	branches := parseBranchRefs(out)
+	if len(branches) != 3 {
+		t.Fatalf("Expected 3 branches without the remote HEAD, got %+v", branches)
+	}
+	main := branches[0]
+	if main.name != "main" || !main.current || main.remote || main.ahead != 2 || main.behind != 1 || main.time.Unix() != 1700000000 {
+		t.Errorf("Unexpected current branch: %+v", main)
+	}
+	if !branches[1].gone || branchTrack(branches[1]) != "origin/old gone" {
+		t.Errorf("Expected a gone upstream, got %+v", branches[1])
+	}
+	if b := branches[2]; b.name != "origin/main" || !b.remote || b.subject != "Earlier" {
+		t.Errorf("Unexpected remote branch: %+v", b)
+	}
+	if got := branchTrack(main); got != "origin/main ↑2↓1" {
+		t.Errorf("Expected the track with counts, got %q", got)
+	}
+}
+
+// selectBranch moves the branch manager's cursor to name
+func selectBranch(t *testing.T, m *model, name string) {
+	t.Helper()
+	for i, b := range m.branches.branches {
+		if b.name == name {
+			m.branches.cursor = i
+			return
+		}
+	}
+	t.Fatalf("No branch %s in %+v", name, m.branches.branches)
+}
+
+func TestBranchManager(t *testing.T) {
+	origin := initTestRepo(t, "f.txt", "one\n")
+	commitIdentity(t)
+	dir := filepath.Join(t.TempDir(), "clone")
+	gitOutput(t, origin, "clone", "-q", origin, dir)
+	base := getGitBranch(dir)
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte("two\n"), 0644)
+	gitCommitAll(t, dir, "Local work")
+
+	m := model{height: 40, width: 120, viewMode: viewSinglePane, currentPath: dir}
+	m.gitReposList = []fileItem{{name: "clone", path: dir, isDir: true, isGitRepo: true, gitBranch: base}}
+	m.loadFiles()
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")})
+	if m.branches == nil || m.dialog.dialogType != dialogBranches {
+		t.Fatal("Expected B to open the branch manager")
+	}
+	current := m.branches.selected()
+	if current == nil || current.name != base || current.upstream != "origin/"+base || current.ahead != 1 {
+		t.Fatalf("Expected the cursor on %s, 1 ahead of origin, got %+v", base, current)
+	}
+	if status := getGitStatus(dir); status.ahead != 1 || status.behind != 0 {
+		t.Errorf("Expected 1 ahead, 0 behind, got %d/%d", status.ahead, status.behind)
+	}
+	m.reloadBranches(base)
+	if repo := m.gitReposList[0]; repo.gitBranch != base || repo.gitAhead != 1 {
+		t.Errorf("Expected the repo row refreshed from the listed branches, got %s ↑%d", repo.gitBranch, repo.gitAhead)
+	}
+	if out := plainLines(strings.Split(m.renderBranchDialog(), "\n")); !strings.Contains(out, "origin/"+base+" ↑1") || !strings.Contains(out, "Remote") {
+		t.Errorf("Expected the upstream and the remote section:\n%s", out)
+	}
+
+	// Create a branch from the current one
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("topic")}, tea.KeyMsg{Type: tea.KeyEnter})
+	if got := getGitBranch(dir); got != "topic" || m.branches.selected().name != "topic" {
+		t.Fatalf("Expected topic checked out and selected, got %q (%s)", got, m.branches.err)
+	}
+	if m.gitReposList[0].gitBranch != "topic" {
+		t.Errorf("Expected the repos list refreshed, got %q", m.gitReposList[0].gitBranch)
+	}
+
+	// Rename it and make it track the remote branch
+	m.startBranchPrompt(branchPromptRename)
+	m.branches.input = "feature"
+	m.submitBranchPrompt()
+	m.startBranchPrompt(branchPromptUpstream)
+	if m.branches.input != "origin/feature" {
+		t.Errorf("Expected the upstream prefilled, got %q", m.branches.input)
+	}
+	m.branches.input = "origin/" + base
+	m.submitBranchPrompt()
+	if b := m.branches.selected(); b.name != "feature" || b.upstream != "origin/"+base || b.ahead != 1 {
+		t.Fatalf("Expected feature tracking origin/%s, got %+v (%s)", base, b, m.branches.err)
+	}
+
+	// The checked out branch can't be deleted; switch back first
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if m.branches.prompt != branchPromptNone || m.branches.err == "" {
+		t.Errorf("Expected deleting the current branch to be refused")
+	}
+	selectBranch(t, &m, base)
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if got := getGitBranch(dir); got != base {
+		t.Fatalf("Expected %s checked out, got %q", base, got)
+	}
+
+	// Delete after confirming; unmerged commits ask again before forcing
+	gitOutput(t, dir, "switch", "-q", "feature")
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte("three\n"), 0644)
+	gitCommitAll(t, dir, "Unmerged")
+	gitOutput(t, dir, "switch", "-q", base)
+	m.reloadBranches("")
+	selectBranch(t, &m, "feature")
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if m.branches.prompt != branchPromptForceDelete {
+		t.Fatalf("Expected a force delete prompt, got %d (%s)", m.branches.prompt, m.branches.err)
+	}
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if out := gitOutput(t, dir, "branch", "--list", "feature"); out != "" {
+		t.Errorf("Expected feature deleted, got %q", out)
+	}
+
+	// Checking out a remote branch creates a local tracking branch
+	gitOutput(t, origin, "branch", "remote-only")
+	gitOutput(t, dir, "fetch", "-q")
+	m.reloadBranches("")
+	selectBranch(t, &m, "origin/remote-only")
+	m.checkoutBranch()
+	if b := m.branches.selected(); b == nil || b.name != "remote-only" || !b.current || b.upstream != "origin/remote-only" {
+		t.Errorf("Expected a local remote-only branch tracking origin, got %+v (%s)", b, m.branches.err)
+	}
+
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.branches != nil || m.showDialog {
+		t.Error("Expected Esc to close the branch manager")
+	}
+}
@@ -0,0 +1,567 @@
+package main
+
+// Module: commit.go
+// Purpose: Commit composer for changes mode (c)
+// Responsibilities:
+// - Staged file list and a multi-line message editor in a dialog
+// - Amend and sign-off toggles
+// - Editing the message in the external editor
+// - Optional message generation: the staged diff is piped to a configured command
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	commitMaxFiles    = 6 // Staged files listed before "… and N more"
+	commitMessageRows = 8 // Message lines visible in the dialog
+)
+
+// commitComposer is the state of the commit dialog
+type commitComposer struct {
+	root       string
+	staged     []string // "M  path" lines for the staged files
+	message    []rune
+	cursor     int // Rune offset into message
+	amend      bool
+	signoff    bool
+	generating bool   // Message command running
+	err        string // Shown under the message until the next edit
+
+	messageFile string // Message handed to git commit -F while it runs
+}
+
+// commitMessageMsg delivers the output of the message command
+type commitMessageMsg struct {
+	composer *commitComposer
+	message  string
+	err      error
+}
+
+// commitDoneMsg is sent when git commit exits
+type commitDoneMsg struct {
+	composer *commitComposer
+	output   string // git's error output (hook messages), shown when the commit failed
+	err      error
+}
+
+// commitEditedMsg is sent when the external editor closes the message file
+type commitEditedMsg struct {
+	composer *commitComposer
+	path     string
+	err      error
+}
+
+// stagedFileList lists the index changes as "M  path" lines
+func stagedFileList(root string) ([]string, error) {
+	out, err := runGit(root, "", "diff", "--cached", "--name-status", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range parseNameStatus(out) {
+		path := f.path
+		if f.oldPath != "" {
+			path = f.oldPath + " → " + f.path
+		}
+		files = append(files, f.status+"  "+path)
+	}
+	return files, nil
+}
+
+// commitArgs builds the git commit command line; the message comes from the message file
+func (c *commitComposer) commitArgs() []string {
+	args := []string{"commit", "-F", c.messageFile}
+	if c.amend {
+		args = append(args, "--amend")
+	}
+	if c.signoff {
+		args = append(args, "--signoff")
+	}
+	return args
+}
+
+// openCommitComposer opens the commit dialog for the current repository
+func (m *model) openCommitComposer() {
+	root := m.resolveGitRoot()
+	if root == "" {
+		m.setStatusMessage("Not inside a git repository", true)
+		return
+	}
+	staged, err := stagedFileList(root)
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Commit failed: %v", err), true)
+		return
+	}
+	m.commit = &commitComposer{root: root, staged: staged}
+	m.dialog = dialogModel{dialogType: dialogCommit, title: "Commit"}
+	m.showDialog = true
+}
+
+// closeCommitComposer closes the commit dialog, dropping the message
+func (m *model) closeCommitComposer() {
+	m.commit = nil
+	m.showDialog = false
+	m.dialog = dialogModel{}
+}
+
+// setMessage replaces the message and puts the cursor at its end
+func (c *commitComposer) setMessage(text string) {
+	c.message = []rune(strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n \t"))
+	c.cursor = len(c.message)
+}
+
+// insert types text at the cursor
+func (c *commitComposer) insert(text string) {
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	runes := []rune(text)
+	c.message = append(c.message[:c.cursor], append(runes, c.message[c.cursor:]...)...)
+	c.cursor += len(runes)
+	c.err = ""
+}
+
+// backspace deletes the rune before the cursor
+func (c *commitComposer) backspace() {
+	if c.cursor == 0 {
+		return
+	}
+	c.message = append(c.message[:c.cursor-1], c.message[c.cursor:]...)
+	c.cursor--
+	c.err = ""
+}
+
+// lineBounds returns the start and end offsets of the line holding offset pos
+func (c *commitComposer) lineBounds(pos int) (start, end int) {
+	start, end = pos, pos
+	for start > 0 && c.message[start-1] != '\n' {
+		start--
+	}
+	for end < len(c.message) && c.message[end] != '\n' {
+		end++
+	}
+	return start, end
+}
+
+// moveLine moves the cursor to the previous (-1) or next (1) line, keeping the column
+func (c *commitComposer) moveLine(delta int) {
+	start, end := c.lineBounds(c.cursor)
+	col := c.cursor - start
+	if delta < 0 {
+		if start == 0 {
+			c.cursor = 0
+			return
+		}
+		prevStart, _ := c.lineBounds(start - 1)
+		c.cursor = min(prevStart+col, start-1)
+		return
+	}
+	if end == len(c.message) {
+		c.cursor = end
+		return
+	}
+	_, nextEnd := c.lineBounds(end + 1)
+	c.cursor = min(end+1+col, nextEnd)
+}
+
+// toggleCommitAmend switches amending; an empty message is filled with the last commit's
+func (m *model) toggleCommitAmend() {
+	c := m.commit
+	c.amend = !c.amend
+	if c.amend && strings.TrimSpace(string(c.message)) == "" {
+		if last, err := runGit(c.root, "", "log", "-1", "--format=%B"); err == nil {
+			c.setMessage(last)
+		}
+	}
+}
+
+// runCommit commits the index with the composed message. git runs on the terminal,
+// so slow hooks show their progress and GPG signing can ask for a passphrase.
+func (m *model) runCommit() tea.Cmd {
+	c := m.commit
+	message := strings.TrimSpace(string(c.message))
+	switch {
+	case c.generating:
+		c.err = "Wait for the message command to finish"
+		return nil
+	case message == "":
+		c.err = "Write a commit message first"
+		return nil
+	case len(c.staged) == 0 && !c.amend:
+		c.err = "Nothing staged - press s on a file to stage it"
+		return nil
+	}
+	f, err := os.CreateTemp("", "tfe-COMMIT_EDITMSG-*")
+	if err == nil {
+		_, err = f.WriteString(message + "\n")
+		f.Close()
+	}
+	if err != nil {
+		c.err = err.Error()
+		return nil
+	}
+	c.messageFile = f.Name()
+	cmd, output := c.gitCommitCmd()
+	return tea.Sequence(
+		tea.ClearScreen,
+		tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return commitDoneMsg{composer: c, output: output.String(), err: err}
+		}),
+	)
+}
+
+// gitCommitCmd returns the git commit command; its error output also goes to the
+// returned buffer, so a failing hook's message can be shown in the dialog
+func (c *commitComposer) gitCommitCmd() (*exec.Cmd, *bytes.Buffer) {
+	cmd := exec.Command("git", append([]string{"-C", c.root}, c.commitArgs()...)...)
+	output := &bytes.Buffer{}
+	cmd.Stderr = io.MultiWriter(os.Stderr, output)
+	return cmd, output
+}
+
+// applyCommitDoneMsg closes the dialog after a commit, or shows why it failed
+func (m *model) applyCommitDoneMsg(msg commitDoneMsg) tea.Cmd {
+	os.Remove(msg.composer.messageFile)
+	// Restore the terminal state like after the external editor
+	restore := tea.Batch(
+		tea.EnterAltScreen,
+		tea.ClearScreen,
+		tea.EnableMouseCellMotion,
+	)
+	c := m.commit
+	if c == nil || c != msg.composer {
+		return restore
+	}
+	c.messageFile = ""
+	if msg.err != nil {
+		c.err = "Commit failed: " + msg.err.Error()
+		// The last line of git's output is usually the reason (e.g. the hook's verdict)
+		if lines := strings.Split(strings.TrimSpace(msg.output), "\n"); lines[len(lines)-1] != "" {
+			c.err = "Commit failed: " + strings.TrimSpace(lines[len(lines)-1])
+		}
+		return restore
+	}
+
+	message := strings.TrimSpace(string(c.message))
+	summary := strings.SplitN(message, "\n", 2)[0]
+	if hash, err := runGit(c.root, "", "rev-parse", "--short", "HEAD"); err == nil {
+		summary = strings.TrimSpace(hash) + " " + summary
+	}
+	verb := "Committed"
+	if c.amend {
+		verb = "Amended"
+	}
+	m.closeCommitComposer()
+	m.refreshChanges()
+	m.setStatusMessage(fmt.Sprintf("✓ %s %s", verb, summary), false)
+	return tea.Batch(restore, statusTimeoutCmd())
+}
+
+// generateCommitMessage pipes the staged diff to the configured message command
+func (m *model) generateCommitMessage() tea.Cmd {
+	c := m.commit
+	command := strings.TrimSpace(m.config.CommitMessageCommand)
+	if command == "" {
+		c.err = "No message command - set Commit Message Command in Settings (Ctrl+,)"
+		return nil
+	}
+	if c.generating {
+		return nil
+	}
+	c.generating = true
+	c.err = ""
+	return commitMessageCmd(c, command)
+}
+
+// commitMessageCmd runs command through the shell (cmd on Windows) in the repo root with the staged diff on stdin
+func commitMessageCmd(c *commitComposer, command string) tea.Cmd {
+	root := c.root
+	return func() tea.Msg {
+		diff, err := runGit(root, "", "diff", "--cached", "--no-color", "--no-ext-diff")
+		if err != nil {
+			return commitMessageMsg{composer: c, err: err}
+		}
+		if diff == "" {
+			return commitMessageMsg{composer: c, err: fmt.Errorf("nothing staged to describe")}
+		}
+		cmd := exec.Command("sh", "-c", command)
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		}
+		cmd.Dir = root
+		cmd.Stdin = strings.NewReader(diff)
+		out, err := cmd.Output()
+		if err != nil {
+			return commitMessageMsg{composer: c, err: gitErrorText(err)}
+		}
+		if strings.TrimSpace(string(out)) == "" {
+			return commitMessageMsg{composer: c, err: fmt.Errorf("message command printed nothing")}
+		}
+		return commitMessageMsg{composer: c, message: string(out)}
+	}
+}
+
+// applyCommitMessageMsg fills in the generated message unless the dialog was closed meanwhile
+func (m *model) applyCommitMessageMsg(msg commitMessageMsg) {
+	c := m.commit
+	if c == nil || c != msg.composer {
+		return
+	}
+	c.generating = false
+	if msg.err != nil {
+		c.err = "Message command failed: " + msg.err.Error()
+		return
+	}
+	c.setMessage(msg.message)
+}
+
+// commitEditor returns the editor command for the message: the configured one, $VISUAL,
+// $EDITOR, then the first installed
+func (m model) commitEditor() []string {
+	for _, editor := range []string{m.config.Editor, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if fields := strings.Fields(editor); len(fields) > 0 {
+			return fields
+		}
+	}
+	if editor := getAvailableEditor(); editor != "" {
+		return []string{editor}
+	}
+	return nil
+}
+
+// editCommitMessage opens the message in the external editor; it's read back on return
+func (m *model) editCommitMessage() tea.Cmd {
+	c := m.commit
+	editor := m.commitEditor()
+	if editor == nil {
+		c.err = "No editor found - set one in Settings (Ctrl+,)"
+		return nil
+	}
+	f, err := os.CreateTemp("", "tfe-COMMIT_EDITMSG-*")
+	if err == nil {
+		_, err = f.WriteString(string(c.message) + "\n")
+		f.Close()
+	}
+	if err != nil {
+		c.err = err.Error()
+		return nil
+	}
+	path := f.Name()
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Dir = c.root
+	return tea.Sequence(
+		tea.ClearScreen,
+		tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return commitEditedMsg{composer: c, path: path, err: err}
+		}),
+	)
+}
+
+// applyCommitEditedMsg reads the edited message back and removes the temp file
+func (m *model) applyCommitEditedMsg(msg commitEditedMsg) tea.Cmd {
+	data, readErr := os.ReadFile(msg.path)
+	os.Remove(msg.path)
+	if c := m.commit; c != nil && c == msg.composer {
+		switch {
+		case msg.err != nil:
+			c.err = "Editor failed: " + msg.err.Error()
+		case readErr != nil:
+			c.err = readErr.Error()
+		default:
+			c.setMessage(string(data))
+		}
+	}
+	// Restore the terminal state like any other external editor
+	return tea.Batch(
+		tea.EnterAltScreen,
+		tea.ClearScreen,
+		tea.EnableMouseCellMotion,
+	)
+}
+
+// handleCommitKey handles keys while the commit dialog is open
+func (m model) handleCommitKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.commit
+	if c == nil {
+		m.closeCommitComposer()
+		return m, tea.ClearScreen
+	}
+	switch msg.String() {
+	case "esc":
+		m.closeCommitComposer()
+		m.setStatusMessage("Commit cancelled", false)
+		return m, tea.ClearScreen
+	case "ctrl+s":
+		return m, m.runCommit()
+	case "alt+a":
+		m.toggleCommitAmend()
+	case "alt+s":
+		c.signoff = !c.signoff
+	case "ctrl+e":
+		return m, m.editCommitMessage()
+	case "ctrl+g":
+		return m, m.generateCommitMessage()
+	case "enter":
+		c.insert("\n")
+	case "backspace":
+		c.backspace()
+	case "left":
+		c.cursor = max(0, c.cursor-1)
+	case "right":
+		c.cursor = min(len(c.message), c.cursor+1)
+	case "up":
+		c.moveLine(-1)
+	case "down":
+		c.moveLine(1)
+	case "home":
+		c.cursor, _ = c.lineBounds(c.cursor)
+	case "end":
+		_, c.cursor = c.lineBounds(c.cursor)
+	default:
+		// Printable text and pastes; newlines and tabs are kept
+		for _, r := range msg.Runes {
+			if (r < 32 && r != '\n' && r != '\r' && r != '\t') || r == 127 {
+				return m, nil
+			}
+		}
+		if len(msg.Runes) > 0 {
+			c.insert(string(msg.Runes))
+		}
+	}
+	return m, nil
+}
+
+// commitDialogWidth is the commit dialog's content width
+func (m model) commitDialogWidth() int {
+	return max(20, min(72, m.width-8))
+}
+
+// commitDialogHeight estimates the dialog's rendered height for centering
+func (m model) commitDialogHeight() int {
+	files := 1
+	if m.commit != nil {
+		files = max(1, min(len(m.commit.staged), commitMaxFiles+1))
+	}
+	return files + commitMessageRows + 14
+}
+
+// commitMessageLines returns the visible message lines with the cursor drawn in
+func (c *commitComposer) commitMessageLines() []string {
+	text := string(c.message[:c.cursor]) + "█" + string(c.message[c.cursor:])
+	lines := strings.Split(text, "\n")
+	cursorLine := strings.Count(string(c.message[:c.cursor]), "\n")
+	start := max(0, cursorLine-commitMessageRows+1)
+	end := min(len(lines), start+commitMessageRows)
+	lines = lines[start:end]
+	for len(lines) < commitMessageRows {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// renderCommitDialog renders the commit composer
+func (m model) renderCommitDialog() string {
+	c := m.commit
+	if c == nil {
+		return ""
+	}
+	width := m.commitDialogWidth()
+
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(currentTheme.BorderFocused.adaptiveColor()).
+		Background(uiPanelBackground()).
+		Padding(1, 2).
+		Width(width)
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(currentTheme.Title.adaptiveColor()).
+		Align(lipgloss.Center).
+		Width(width)
+
+	labelStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(uiBodyText())
+
+	inputStyle := lipgloss.NewStyle().
+		Foreground(uiBodyText()).
+		Background(uiInputBackground()).
+		Padding(0, 1).
+		Width(width - 4)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(uiMutedText())
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.DiffRemoved.adaptiveColor())
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(uiMutedText()).
+		Align(lipgloss.Center).
+		Width(width)
+
+	title := "Commit"
+	if c.amend {
+		title = "Amend Last Commit"
+	}
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(title))
+	content.WriteString("\n\n")
+	content.WriteString(labelStyle.Render(fmt.Sprintf("Staged (%s)", pluralize(len(c.staged), "file"))))
+	content.WriteString("\n")
+	if len(c.staged) == 0 {
+		content.WriteString(mutedStyle.Render("  Nothing staged"))
+		content.WriteString("\n")
+	}
+	for i, file := range c.staged {
+		if i == commitMaxFiles && len(c.staged) > commitMaxFiles+1 {
+			content.WriteString(mutedStyle.Render(fmt.Sprintf("  … and %d more", len(c.staged)-commitMaxFiles)))
+			content.WriteString("\n")
+			break
+		}
+		content.WriteString("  " + truncateToWidth(file, width-2))
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+	content.WriteString(labelStyle.Render("Message"))
+	content.WriteString("\n")
+	lines := c.commitMessageLines()
+	for i, line := range lines {
+		lines[i] = truncateToWidth(strings.ReplaceAll(line, "\t", "    "), width-6)
+	}
+	content.WriteString(inputStyle.Render(strings.Join(lines, "\n")))
+	content.WriteString("\n\n")
+
+	check := func(on bool) string {
+		if on {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	content.WriteString(fmt.Sprintf("%s Amend (Alt+A)   %s Sign-off (Alt+S)", check(c.amend), check(c.signoff)))
+	content.WriteString("\n")
+	switch {
+	case c.generating:
+		content.WriteString(mutedStyle.Render("Generating message..."))
+	case c.err != "":
+		content.WriteString(errorStyle.Render(truncateToWidth(c.err, width)))
+	}
+	content.WriteString("\n\n")
+	hint := "Ctrl+S: commit | Ctrl+E: editor | Esc: cancel"
+	if strings.TrimSpace(m.config.CommitMessageCommand) != "" {
+		hint = "Ctrl+S: commit | Ctrl+E: editor | Ctrl+G: generate | Esc: cancel"
+	}
+	content.WriteString(hintStyle.Render(hint))
+
+	return borderStyle.Render(content.String())
+}
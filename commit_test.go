@@ -0,0 +1,174 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commitIdentity sets the author and committer for commits made by TFE in tests
+func commitIdentity(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Carol")
+	t.Setenv("GIT_AUTHOR_EMAIL", "carol@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Carol")
+	t.Setenv("GIT_COMMITTER_EMAIL", "carol@example.com")
+}
+
+// typeKeys sends text to the model one key at a time
+func typeKeys(m model, keys ...tea.KeyMsg) model {
+	for _, key := range keys {
+		newM, _ := m.handleKeyEvent(key)
+		m = newM.(model)
+	}
+	return m
+}
+
+func TestCommitComposerEditing(t *testing.T) {
+	c := &commitComposer{}
+	c.insert("Fix it\nBody text")
+	c.moveLine(-1)
+	if c.cursor != 6 {
+		t.Errorf("Expected the cursor clamped to the end of the first line, got %d", c.cursor)
+	}
+	c.backspace()
+	c.insert("X")
+	if got := string(c.message); got != "Fix iX\nBody text" {
+		t.Errorf("Expected an edit at the end of the first line, got %q", got)
+	}
+	c.moveLine(1)
+	if c.cursor != 13 {
+		t.Errorf("Expected the cursor in the same column of the body, got %d", c.cursor)
+	}
+	c.moveLine(1)
+	if c.cursor != len(c.message) {
+		t.Errorf("Expected the cursor at the end on the last line, got %d", c.cursor)
+	}
+}
+
+func TestCommitStaged(t *testing.T) {
+	dir := initTestRepo(t, "f.txt", "one\n")
+	commitIdentity(t)
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte("two\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0644)
+	gitOutput(t, dir, "add", "f.txt")
+
+	m := changesModel(t, dir)
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if m.commit == nil || !m.showDialog || m.dialog.dialogType != dialogCommit {
+		t.Fatal("Expected c to open the commit dialog")
+	}
+	if strings.Join(m.commit.staged, "|") != "M  f.txt" {
+		t.Errorf("Expected only f.txt staged, got %q", m.commit.staged)
+	}
+
+	// An empty message is refused
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if m.commit == nil || m.commit.err == "" {
+		t.Fatal("Expected an error for an empty message")
+	}
+
+	m = typeKeys(m,
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Update f")},
+		tea.KeyMsg{Type: tea.KeyEnter},
+		tea.KeyMsg{Type: tea.KeyEnter},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Details")},
+		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s"), Alt: true},
+	)
+	if _, cmd := m.handleKeyEvent(tea.KeyMsg{Type: tea.KeyCtrlS}); cmd == nil {
+		t.Fatal("Expected Ctrl+S to start git commit")
+	}
+	m = commitNow(t, m)
+	if m.commit != nil || m.showDialog {
+		t.Fatalf("Expected the dialog closed after committing, got %+v", m.commit)
+	}
+	want := "Update f\n\nDetails\n\nSigned-off-by: Carol <carol@example.com>\n"
+	if got := gitOutput(t, dir, "log", "-1", "--format=%B"); strings.TrimSpace(got) != strings.TrimSpace(want) {
+		t.Errorf("Expected the signed-off message\n%s\ngot\n%s", want, got)
+	}
+	if len(m.changedFiles) != 1 {
+		t.Errorf("Expected only the untracked file left, got %d changes", len(m.changedFiles))
+	}
+
+	// Amending with nothing staged rewords the last commit, starting from its message
+	m.openCommitComposer()
+	m.toggleCommitAmend()
+	if !strings.HasPrefix(string(m.commit.message), "Update f") {
+		t.Errorf("Expected the last message prefilled, got %q", string(m.commit.message))
+	}
+	m.commit.setMessage("Reworded")
+	m = commitNow(t, m)
+	if got := gitOutput(t, dir, "log", "--format=%s"); got != "Reworded\nInitial commit\n" {
+		t.Errorf("Expected the last commit reworded, got %q", got)
+	}
+}
+
+// commitNow runs the git commit the composer starts, as the program would on the terminal
+func commitNow(t *testing.T, m model) model {
+	t.Helper()
+	if cmd := m.runCommit(); cmd == nil {
+		t.Fatalf("Expected git commit to start (%s)", m.commit.err)
+	}
+	c := m.commit
+	gitCmd, output := c.gitCommitCmd()
+	err := gitCmd.Run()
+	newM, _ := m.Update(commitDoneMsg{composer: c, output: output.String(), err: err})
+	return newM.(model)
+}
+
+func TestCommitHookFailure(t *testing.T) {
+	dir := initTestRepo(t, "f.txt", "one\n")
+	commitIdentity(t)
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte("two\n"), 0644)
+	gitOutput(t, dir, "add", "f.txt")
+	hook := filepath.Join(dir, ".git", "hooks", "pre-commit")
+	os.MkdirAll(filepath.Dir(hook), 0755)
+	os.WriteFile(hook, []byte("#!/bin/sh\necho 'checking...' >&2\necho 'lint: 2 problems' >&2\nexit 1\n"), 0755)
+
+	m := changesModel(t, dir)
+	m.openCommitComposer()
+	m.commit.setMessage("Update f")
+	m = commitNow(t, m)
+	if m.commit == nil || m.commit.err != "Commit failed: lint: 2 problems" {
+		t.Fatalf("Expected the hook's verdict in the open dialog, got %+v", m.commit)
+	}
+	if got := gitOutput(t, dir, "log", "--format=%s"); got != "Initial commit\n" {
+		t.Errorf("Expected no commit, got %q", got)
+	}
+}
+
+func TestCommitMessageCommand(t *testing.T) {
+	dir := initTestRepo(t, "f.txt", "one\n")
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte("two\n"), 0644)
+	gitOutput(t, dir, "add", "f.txt")
+
+	// The stub summarizes the diff it reads on stdin
+	script := filepath.Join(t.TempDir(), "summarize.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\necho \"Change $(grep -c '^[-+][^-+]') lines\"\n"), 0755)
+
+	m := changesModel(t, dir)
+	m.openCommitComposer()
+	if cmd := m.generateCommitMessage(); cmd != nil || m.commit.err == "" {
+		t.Fatal("Expected an error without a configured command")
+	}
+
+	m.config.CommitMessageCommand = script
+	cmd := m.generateCommitMessage()
+	if cmd == nil || !m.commit.generating {
+		t.Fatal("Expected the message command to start")
+	}
+	newM, _ := m.Update(cmd())
+	m = newM.(model)
+	if m.commit.generating || string(m.commit.message) != "Change 2 lines" {
+		t.Errorf("Expected the generated message, got %q (%s)", string(m.commit.message), m.commit.err)
+	}
+
+	m.config.CommitMessageCommand = "exit 3"
+	newM, _ = m.Update(m.generateCommitMessage()())
+	m = newM.(model)
+	if !strings.Contains(m.commit.err, "failed") || string(m.commit.message) != "Change 2 lines" {
+		t.Errorf("Expected a failing command to keep the message, got %q (%s)", string(m.commit.message), m.commit.err)
+	}
+}
@@ -0,0 +1,435 @@
+package main
+
+// Module: compare.go
+// Purpose: Comparing any two files with a built-in diff (no git or diff binary needed)
+// Responsibilities:
+// - Myers line diff and unified hunks with context lines
+// - Marking file A and comparing it with file B (key, context menu or file picker)
+// - Showing the result in the preview with hunk navigation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	compareMaxSize  = 8 * 1024 * 1024 // Larger files aren't compared
+	compareMaxEdits = 2000            // Beyond this many changed lines the rest is shown as replaced
+	compareContext  = 3               // Unchanged lines around each hunk
+	compareNoEOL    = "\x00"          // Marks a last line without a trailing newline
+)
+
+// compareState is a comparison of two files shown in the preview
+type compareState struct {
+	a, b  string   // Old and new file
+	lines []string // Unified diff (nil when the files are identical)
+	hunks int
+	err   error
+}
+
+// diffOp is one step of an edit script: ' ' keeps a[a] (= b[b]), '-' removes a[a], '+' inserts b[b]
+type diffOp struct {
+	kind byte
+	a, b int
+}
+
+// myersDiff returns the shortest edit script turning a into b (Myers' O(ND) algorithm).
+// Common leading and trailing lines are skipped first; when more than compareMaxEdits
+// lines differ, the remaining middle is reported as removed and re-added.
+func myersDiff(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{' ', i, i})
+	}
+	ops = append(ops, myersMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix)...)
+	for i := suffix; i > 0; i-- {
+		ops = append(ops, diffOp{' ', len(a) - i, len(b) - i})
+	}
+	return ops
+}
+
+// myersMiddle diffs the differing middle of two files; offset is the index of its first line
+func myersMiddle(a, b []string, offset int) []diffOp {
+	n, m := len(a), len(b)
+	replace := func() []diffOp {
+		ops := make([]diffOp, 0, n+m)
+		for i := 0; i < n; i++ {
+			ops = append(ops, diffOp{'-', offset + i, offset})
+		}
+		for j := 0; j < m; j++ {
+			ops = append(ops, diffOp{'+', offset + n, offset + j})
+		}
+		return ops
+	}
+	if n == 0 || m == 0 {
+		return replace()
+	}
+
+	// Compare line numbers instead of strings
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	x1, y1 := intern(a), intern(b)
+
+	// trace[d][k+d] = furthest x reached on diagonal k with d edits
+	var trace [][]int
+	found := false
+	for d := 0; d <= min(n+m, compareMaxEdits) && !found; d++ {
+		cur := make([]int, 2*d+1)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			switch {
+			case d == 0:
+				x = 0
+			case k == -d || (k != d && trace[d-1][k-1+d-1] < trace[d-1][k+1+d-1]):
+				x = trace[d-1][k+1+d-1] // Down: insert
+			default:
+				x = trace[d-1][k-1+d-1] + 1 // Right: remove
+			}
+			y := x - k
+			for x < n && y < m && x1[x] == y1[y] {
+				x, y = x+1, y+1
+			}
+			cur[k+d] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+		trace = append(trace, cur)
+	}
+	if !found {
+		return replace()
+	}
+
+	// Walk back from the end, collecting the script in reverse
+	var rev []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+			prevK = k + 1
+		}
+		prevX := prev[prevK+d-1]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			rev = append(rev, diffOp{' ', offset + x, offset + y})
+		}
+		if x == prevX {
+			y--
+			rev = append(rev, diffOp{'+', offset + x, offset + y})
+		} else {
+			x--
+			rev = append(rev, diffOp{'-', offset + x, offset + y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		rev = append(rev, diffOp{' ', offset + x, offset + y})
+	}
+
+	ops := make([]diffOp, len(rev))
+	for i, op := range rev {
+		ops[len(rev)-1-i] = op
+	}
+	return ops
+}
+
+// splitDiffLines splits file content into lines; a last line without a newline keeps
+// compareNoEOL so it differs from the same line with one
+func splitDiffLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.Split(content, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += compareNoEOL
+	return lines
+}
+
+// unifiedHunks formats an edit script as unified diff hunks ("@@ -a,b +c,d @@" + lines)
+func unifiedHunks(a, b []string, ops []diffOp, context int) (lines []string, hunks int) {
+	writeLine := func(marker byte, text string) {
+		if plain, ok := strings.CutSuffix(text, compareNoEOL); ok {
+			lines = append(lines, string(marker)+plain, `\ No newline at end of file`)
+			return
+		}
+		lines = append(lines, string(marker)+text)
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		// Extend the hunk while changes are close enough for their context to touch
+		start := max(0, i-context)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		end = min(len(ops), end+context)
+
+		oldStart, newStart := ops[start].a, ops[start].b
+		oldLen, newLen := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				oldLen++
+			}
+			if op.kind != '-' {
+				newLen++
+			}
+		}
+		// Like diff: an empty side names the line before it
+		if oldLen > 0 {
+			oldStart++
+		}
+		if newLen > 0 {
+			newStart++
+		}
+		lines = append(lines, fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldLen, newStart, newLen))
+		hunks++
+		for _, op := range ops[start:end] {
+			switch op.kind {
+			case ' ':
+				writeLine(' ', a[op.a])
+			case '-':
+				writeLine('-', a[op.a])
+			case '+':
+				writeLine('+', b[op.b])
+			}
+		}
+		i = end
+	}
+	return lines, hunks
+}
+
+// readCompareFile reads one side of a comparison, refusing folders, binaries and huge files
+func readCompareFile(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case info.IsDir():
+		return nil, fmt.Errorf("%s is a folder", filepath.Base(path))
+	case info.Size() > compareMaxSize:
+		return nil, fmt.Errorf("%s is too large to compare (%s)", filepath.Base(path), formatFileSize(info.Size()))
+	case isBinaryFile(path):
+		return nil, fmt.Errorf("%s is a binary file", filepath.Base(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return splitDiffLines(strings.ReplaceAll(string(data), "\r\n", "\n")), nil
+}
+
+// compareFiles diffs two files into a compareState
+func compareFiles(a, b string) *compareState {
+	c := &compareState{a: a, b: b}
+	oldLines, err := readCompareFile(a)
+	if err != nil {
+		c.err = err
+		return c
+	}
+	newLines, err := readCompareFile(b)
+	if err != nil {
+		c.err = err
+		return c
+	}
+	hunks, count := unifiedHunks(oldLines, newLines, myersDiff(oldLines, newLines), compareContext)
+	if count > 0 {
+		c.lines = append([]string{"--- " + a, "+++ " + b}, hunks...)
+		c.hunks = count
+	}
+	return c
+}
+
+// compareDisplayName shortens a compared path relative to the current folder
+func (m model) compareDisplayName(path string) string {
+	if rel, err := filepath.Rel(m.currentPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// markOrCompare is the compare key: the first file pressed on is marked, the next one
+// is compared with it (pressing it while a comparison is shown closes the comparison)
+func (m *model) markOrCompare(path string) tea.Cmd {
+	switch {
+	case m.preview.compare != nil:
+		m.closeCompare()
+	case m.compareMarked != "" && path != "" && path != m.compareMarked:
+		return m.openCompare(m.compareMarked, path)
+	default:
+		m.toggleCompareMark(path)
+	}
+	return nil
+}
+
+// toggleCompareMark marks a file as the old side of the next comparison, or clears the mark
+func (m *model) toggleCompareMark(path string) {
+	if m.compareMarked != "" && m.compareMarked == path {
+		m.compareMarked = ""
+		m.setStatusMessage("Compare mark cleared", false)
+		return
+	}
+	if info, err := os.Stat(path); path == "" || err != nil || info.IsDir() {
+		m.setStatusMessage("Select a file to compare", true)
+		return
+	}
+	m.compareMarked = path
+	m.setStatusMessage(fmt.Sprintf("⚖ Marked %s - press = on another file to compare", filepath.Base(path)), false)
+}
+
+// openCompare shows the diff of a (old) and b (new): in the right pane in dual-pane
+// mode, otherwise in the full-screen preview
+func (m *model) openCompare(a, b string) tea.Cmd {
+	if a == b {
+		m.setStatusMessage("Select a different file to compare with", true)
+		return nil
+	}
+	c := compareFiles(a, b)
+	if c.err != nil {
+		m.setStatusMessage(fmt.Sprintf("Cannot compare: %v", c.err), true)
+		return nil
+	}
+	m.compareMarked = ""
+	m.loadPreview(b)
+	m.preview.compare = c
+	m.preview.scrollPos = 0
+	if c.hunks == 0 {
+		m.setStatusMessage(fmt.Sprintf("Files are identical: %s and %s", m.compareDisplayName(a), m.compareDisplayName(b)), false)
+	} else {
+		m.setStatusMessage(fmt.Sprintf("⚖ %s → %s: %s", m.compareDisplayName(a), m.compareDisplayName(b), pluralize(c.hunks, "hunk")), false)
+	}
+
+	if m.viewMode == viewDualPane {
+		m.focusedPane = rightPane // Scroll and [ / ] act on the comparison
+		m.populatePreviewCache()
+		return nil
+	}
+	m.viewMode = viewFullPreview
+	m.searchMode = false
+	m.calculateLayout()
+	m.populatePreviewCache()
+	return tea.ClearScreen
+}
+
+// closeCompare goes back to the regular preview of the new file
+func (m *model) closeCompare() {
+	m.preview.compare = nil
+	m.preview.scrollPos = 0
+	m.preview.cacheValid = false
+	m.populatePreviewCache()
+	m.setStatusMessage("Compare closed", false)
+}
+
+// compareHunkStarts returns the display lines where hunks begin
+func (m model) compareHunkStarts() []int {
+	var starts []int
+	for i, line := range m.diffDisplayLines(m.preview.compare.lines) {
+		if plain, _ := ansiPlainText(line); strings.HasPrefix(plain, "@@") {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+// jumpCompareHunk scrolls to the next (dir > 0) or previous hunk
+func (m *model) jumpCompareHunk(dir int) {
+	starts := m.compareHunkStarts()
+	target := -1
+	for i, start := range starts {
+		if (dir > 0 && start > m.preview.scrollPos) || (dir < 0 && start < m.preview.scrollPos) {
+			target = i
+			if dir > 0 {
+				break
+			}
+		}
+	}
+	if target < 0 {
+		if dir > 0 {
+			m.setStatusMessage("No more hunks", false)
+		} else {
+			m.setStatusMessage("First hunk", false)
+		}
+		return
+	}
+	maxScroll := max(0, m.getWrappedLineCount()-m.getPreviewVisibleLines())
+	m.preview.scrollPos = min(starts[target], maxScroll)
+	m.setStatusMessage(fmt.Sprintf("Hunk %d/%d", target+1, len(starts)), false)
+}
+
+// compareLineCount returns the number of display lines of the comparison
+func (m model) compareLineCount() int {
+	if len(m.preview.compare.lines) == 0 {
+		return 1
+	}
+	return len(m.diffDisplayLines(m.preview.compare.lines))
+}
+
+// compareDisplayLines returns the displayed text for preview search
+func (m model) compareDisplayLines() []string {
+	return m.diffDisplayLines(m.preview.compare.lines)
+}
+
+// renderComparePreview renders the comparison like a git diff (unified or side by side)
+func (m model) renderComparePreview(maxVisible int) string {
+	c := m.preview.compare
+	if len(c.lines) == 0 {
+		text := fmt.Sprintf("Files are identical: %s and %s", m.compareDisplayName(c.a), m.compareDisplayName(c.b))
+		return diffMetaStyle.Render(text) + strings.Repeat("\n\033[0m", max(maxVisible-1, 0))
+	}
+	return m.renderDiffLines(c.lines, maxVisible, "compare")
+}
+
+// compareStatusText describes the comparison for the preview info line
+func (m model) compareStatusText() string {
+	c := m.preview.compare
+	return fmt.Sprintf("Compare %s → %s | %s", m.compareDisplayName(c.a), m.compareDisplayName(c.b), pluralize(c.hunks, "hunk"))
+}
+
+// startComparePicker opens the file picker to choose the file to compare path with.
+// Used by: context menu (compare_with).
+func (m *model) startComparePicker(path string) {
+	m.filePickerMode = true
+	m.filePickerCompareSource = path
+	m.viewMode = viewSinglePane
+	m.showPromptsOnly = false // Show all files
+	m.loadFiles()
+	m.setStatusMessage(fmt.Sprintf("⚖ Select a file to compare with %s (Enter = compare, Esc = cancel)", filepath.Base(path)), false)
+}
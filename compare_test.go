@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// applyDiffOps rebuilds both sides from an edit script
+func applyDiffOps(a, b []string, ops []diffOp) (oldLines, newLines []string, edits int) {
+	for _, op := range ops {
+		switch op.kind {
+		case ' ':
+			if a[op.a] != b[op.b] {
+				return nil, nil, -1
+			}
+			oldLines = append(oldLines, a[op.a])
+			newLines = append(newLines, b[op.b])
+		case '-':
+			oldLines = append(oldLines, a[op.a])
+			edits++
+		case '+':
+			newLines = append(newLines, b[op.b])
+			edits++
+		}
+	}
+	return oldLines, newLines, edits
+}
+
+func TestMyersDiff(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		edits int
+	}{
+		{"ABCABBA", "CBABAC", 5}, // The example from Myers' paper
+		{"", "ABC", 3},
+		{"ABC", "", 3},
+		{"ABC", "ABC", 0},
+		{"AXBYC", "ABC", 2},
+		{"ABCDEF", "ABXDEF", 2},
+	}
+	for _, tt := range tests {
+		a, b := strings.Split(tt.a, ""), strings.Split(tt.b, "")
+		oldLines, newLines, edits := applyDiffOps(a, b, myersDiff(a, b))
+		if strings.Join(oldLines, "") != tt.a || strings.Join(newLines, "") != tt.b {
+			t.Errorf("%s -> %s: script rebuilds %q -> %q", tt.a, tt.b, oldLines, newLines)
+		}
+		if edits != tt.edits {
+			t.Errorf("%s -> %s: expected %d edits, got %d", tt.a, tt.b, tt.edits, edits)
+		}
+	}
+}
+
+func TestUnifiedHunks(t *testing.T) {
+	var a []string
+	for i := 1; i <= 20; i++ {
+		a = append(a, fmt.Sprintf("line %d", i))
+	}
+	b := append([]string{}, a...)
+	b[1] = "changed 2"
+	b[4] = "changed 5" // Close to the first change: same hunk
+	b = append(b[:16], b[17:]...)
+
+	lines, hunks := unifiedHunks(a, b, myersDiff(a, b), 3)
+	if hunks != 2 {
+		t.Fatalf("Expected 2 hunks, got %d:\n%s", hunks, strings.Join(lines, "\n"))
+	}
+	if lines[0] != "@@ -1,8 +1,8 @@" || lines[1] != " line 1" || lines[2] != "-line 2" || lines[3] != "+changed 2" {
+		t.Errorf("Unexpected first hunk:\n%s", strings.Join(lines, "\n"))
+	}
+	want := "@@ -14,7 +14,6 @@\n line 14\n line 15\n line 16\n-line 17\n line 18\n line 19\n line 20"
+	if got := strings.Join(lines[len(lines)-8:], "\n"); got != want {
+		t.Errorf("Expected the second hunk\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestCompareFiles(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	os.WriteFile(a, []byte("one\ntwo\n"), 0644)
+	os.WriteFile(b, []byte("one\ntwo"), 0644)
+
+	// Only the missing trailing newline differs
+	c := compareFiles(a, b)
+	want := []string{"-two", "+two", `\ No newline at end of file`}
+	if c.err != nil || c.hunks != 1 || strings.Join(c.lines[len(c.lines)-3:], "|") != strings.Join(want, "|") {
+		t.Errorf("Expected the newline change, got %q (%v)", c.lines, c.err)
+	}
+
+	os.WriteFile(b, []byte("one\r\ntwo\r\n"), 0644)
+	if c := compareFiles(a, b); c.err != nil || c.hunks != 0 || c.lines != nil {
+		t.Errorf("Expected CRLF files to be identical, got %q (%v)", c.lines, c.err)
+	}
+
+	os.WriteFile(b, []byte{'x', 0, 'y'}, 0644)
+	if c := compareFiles(a, b); c.err == nil {
+		t.Error("Expected binary files to be refused")
+	}
+}
+
+func TestCompareView(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	var oldText, newText strings.Builder
+	for i := 1; i <= 60; i++ {
+		fmt.Fprintf(&oldText, "line %d\n", i)
+		if i == 5 || i == 50 {
+			fmt.Fprintf(&newText, "edited %d\n", i)
+		} else {
+			fmt.Fprintf(&newText, "line %d\n", i)
+		}
+	}
+	os.WriteFile(a, []byte(oldText.String()), 0644)
+	os.WriteFile(b, []byte(newText.String()), 0644)
+
+	m := model{height: 12, width: 100, viewMode: viewSinglePane, currentPath: dir}
+	m.markOrCompare(a)
+	if m.compareMarked != a {
+		t.Fatalf("Expected %s marked, got %q", a, m.compareMarked)
+	}
+	m.markOrCompare(b)
+	c := m.preview.compare
+	if c == nil || c.hunks != 2 || m.viewMode != viewFullPreview || m.compareMarked != "" {
+		t.Fatalf("Expected the comparison in the full preview, got %+v (view %v)", c, m.viewMode)
+	}
+	if out := plainLines(strings.Split(m.renderPreview(10), "\n")); !strings.Contains(out, "-line 5") || !strings.Contains(out, "+edited 5") {
+		t.Errorf("Expected the first hunk shown:\n%s", out)
+	}
+
+	// ] scrolls to the second hunk, [ back to the first
+	starts := m.compareHunkStarts()
+	m.jumpCompareHunk(1)
+	m.jumpCompareHunk(1)
+	if len(starts) != 2 || m.preview.scrollPos != starts[1] {
+		t.Errorf("Expected scroll at the second hunk %v, got %d", starts, m.preview.scrollPos)
+	}
+	m.jumpCompareHunk(-1)
+	if m.preview.scrollPos != starts[0] {
+		t.Errorf("Expected scroll back at the first hunk, got %d", m.preview.scrollPos)
+	}
+
+	// = closes the comparison, leaving the new file's preview
+	m.markOrCompare(b)
+	if m.preview.compare != nil || m.preview.filePath != b {
+		t.Errorf("Expected the comparison closed on %s, got %q", b, m.preview.filePath)
+	}
+}
@@ -22,8 +22,9 @@ type Config struct {
 	DarkMode bool `toml:"dark_mode"` // true = dark theme (default), false = light theme
 
 	// Behavior
-	AutoChanges        bool `toml:"auto_changes"`         // Auto-open changes mode when agent finishes (TFE_AUTO_CHANGES)
-	FileWatcherEnabled bool `toml:"file_watcher_enabled"` // Enable fsnotify file watcher for live refresh
+	AutoChanges          bool `toml:"auto_changes"`           // Auto-open changes mode when agent finishes (TFE_AUTO_CHANGES)
+	FileWatcherEnabled   bool `toml:"file_watcher_enabled"`   // Enable fsnotify file watcher for live refresh
+	GitStatusDecorations bool `toml:"git_status_decorations"` // Git status markers on file rows (runs git status per repo; turn off for huge repos)
 
 	// View defaults
 	DefaultViewMode string `toml:"default_view_mode"` // "tree", "list", or "detail"
@@ -33,9 +34,16 @@ type Config struct {
 	StartupDualPane   bool   `toml:"startup_dual_pane"`   // Open with preview pane visible
 	StartupFocus      string `toml:"startup_focus"`       // "files" or "preview" — which pane is focused on startup
 	FocusedPaneRatio  int    `toml:"focused_pane_ratio"`  // Focused pane width % in accordion layout (50-90, default 60)
+	HexBytesPerRow    int    `toml:"hex_bytes_per_row"`   // Bytes per row in the hex viewer (8, 16, 24 or 32)
 
 	// External tools
-	Editor string `toml:"editor"` // Preferred editor command (empty = use $EDITOR)
+	Editor               string `toml:"editor"`                 // Preferred editor command (empty = use $EDITOR)
+	CommitMessageCommand string `toml:"commit_message_command"` // Shell command that reads the staged diff on stdin and prints a commit message
+
+	// Trash retention (0 disables each limit)
+	TrashMaxAgeDays    int `toml:"trash_max_age_days"`    // Evict trashed items older than N days
+	TrashMaxSizeMB     int `toml:"trash_max_size_mb"`     // Evict oldest items while trash exceeds N MB (never the one just trashed)
+	TrashConfirmSizeMB int `toml:"trash_confirm_size_mb"` // Ask before trashing items larger than N MB (offers permanent delete)
 
 	// Profiles (launchable terminal sessions from the Profiles menu)
 	Profiles []Profile `toml:"profiles,omitempty"` // Custom profiles; nil/empty = use defaults
@@ -47,17 +55,22 @@ type Config struct {
 // defaultConfig returns the built-in configuration matching TFE's current hardcoded behavior
 func defaultConfig() Config {
 	return Config{
-		DarkMode:           true,
-		AutoChanges:        false,
-		FileWatcherEnabled: true,
-		DefaultViewMode:    "tree",
-		PanelLock:          false,
-		ShowHidden:         false,
-		SortOrder:          "name",
-		StartupDualPane:    true,
-		StartupFocus:       "files",
-		FocusedPaneRatio:   60,
-		Editor:             "",
+		DarkMode:             true,
+		AutoChanges:          false,
+		FileWatcherEnabled:   true,
+		GitStatusDecorations: true,
+		DefaultViewMode:      "tree",
+		PanelLock:            false,
+		ShowHidden:           false,
+		SortOrder:            "name",
+		StartupDualPane:      true,
+		StartupFocus:         "files",
+		FocusedPaneRatio:     60,
+		HexBytesPerRow:       16,
+		Editor:               "",
+		TrashMaxAgeDays:      0, // Trash limits are opt-in: 0 = off
+		TrashMaxSizeMB:       0,
+		TrashConfirmSizeMB:   0,
 		Profiles: []Profile{
 			{Name: "Shell Here", Command: "bash"},
 			{Name: "Claude Here", Command: "claude"},
@@ -45,8 +45,12 @@ func (m model) getContextMenuItems() []contextMenuItem {
 	// Special menu for trash view
 	if m.showTrashOnly {
 		items = append(items, contextMenuItem{"♻  Restore", "restore"})
+		items = append(items, contextMenuItem{"✏  Restore As...", "restore_as"})
+		items = append(items, contextMenuItem{"📂 Restore To...", "restore_to"})
 		items = append(items, contextMenuItem{"🗑  Delete Permanently", "permanent_delete"})
 		items = append(items, contextMenuItem{"─────────", "separator"})
+		items = append(items, contextMenuItem{"🧽 Clean Up Now", "cleanup_trash"})
+		items = append(items, contextMenuItem{"🔧 Repair Index", "repair_trash"})
 		items = append(items, contextMenuItem{"🧹 Empty Trash", "empty_trash"})
 		return items
 	}
@@ -87,6 +91,7 @@ func (m model) getContextMenuItems() []contextMenuItem {
 			items = append(items, contextMenuItem{"  ↑ Push", "git_push"})
 			items = append(items, contextMenuItem{"  🔄 Sync (Pull + Push)", "git_sync"})
 			items = append(items, contextMenuItem{"  🔍 Fetch", "git_fetch"})
+			items = append(items, contextMenuItem{"  🌿 Branches...", "git_branches"})
 		}
 
 		// Add separator and TUI tools if available (using cached availability - performance optimization)
@@ -177,6 +182,18 @@ func (m model) getContextMenuItems() []contextMenuItem {
 			items = append(items, contextMenuItem{"☆ Add Favorite", "togglefav"})
 		}
 
+		// Compare with another file (see compare.go)
+		items = append(items, contextMenuItem{"─────────", "separator"})
+		switch m.compareMarked {
+		case "":
+			items = append(items, contextMenuItem{"⚖  Mark for Compare", "compare_mark"})
+		case m.contextMenuFile.path:
+			items = append(items, contextMenuItem{"⚖  Unmark Compare", "compare_mark"})
+		default:
+			items = append(items, contextMenuItem{"⚖  Compare with " + filepath.Base(m.compareMarked), "compare_marked"})
+		}
+		items = append(items, contextMenuItem{"⚖  Compare with...", "compare_with"})
+
 		// Tmux file actions (split pane)
 		if m.inTmux {
 			items = append(items, contextMenuItem{"─────────", "separator"})
@@ -416,6 +433,13 @@ func (m model) executeContextMenuAction() (tea.Model, tea.Cmd) {
 		}
 		return m, tea.ClearScreen
 
+	case "git_branches":
+		// Open the branch manager for this repository
+		if m.contextMenuFile.isDir && isGitRepo(m.contextMenuFile.path) {
+			m.openBranchManager(m.contextMenuFile.path)
+		}
+		return m, tea.ClearScreen
+
 	case "newfolder":
 		// Create new folder in the selected directory
 		if m.contextMenuFile.isDir {
@@ -455,13 +479,34 @@ func (m model) executeContextMenuAction() (tea.Model, tea.Cmd) {
 		return m, tea.ClearScreen
 
 	case "restore":
-		// Restore item from trash
-		if err := restoreFromTrash(m.contextMenuFile.path); err != nil {
-			m.setStatusMessage(fmt.Sprintf("Failed to restore: %s", err), true)
-		} else {
-			m.setStatusMessage("Item restored successfully", false)
-			m.loadFiles() // Refresh trash view
+		// Restore item from trash (asks to keep both if the original location is taken)
+		m.restoreTrashedItem(m.contextMenuFile.path, "", false)
+		return m, tea.ClearScreen
+
+	case "restore_as":
+		// Restore under a new name next to the original location
+		original, ok := trashOriginalPath(m.trashItems, m.contextMenuFile.path)
+		if !ok {
+			m.setStatusMessage("Failed to restore: item not found in trash metadata", true)
+			return m, tea.ClearScreen
+		}
+		m.dialog = dialogModel{
+			dialogType: dialogInput,
+			title:      "Restore As",
+			message:    fmt.Sprintf("Restore into %s as:", filepath.Dir(original)),
+			input:      filepath.Base(original),
+		}
+		m.showDialog = true
+		return m, tea.ClearScreen
+
+	case "restore_to":
+		// Pick a destination directory with the file picker
+		original, ok := trashOriginalPath(m.trashItems, m.contextMenuFile.path)
+		if !ok {
+			m.setStatusMessage("Failed to restore: item not found in trash metadata", true)
+			return m, tea.ClearScreen
 		}
+		m.startRestorePicker(m.contextMenuFile.path, filepath.Dir(original))
 		return m, tea.ClearScreen
 
 	case "permanent_delete":
@@ -474,6 +519,16 @@ func (m model) executeContextMenuAction() (tea.Model, tea.Cmd) {
 		m.showDialog = true
 		return m, tea.ClearScreen
 
+	case "cleanup_trash":
+		// Apply retention policy (max age / size quota) right now
+		m.cleanupTrashNow()
+		return m, tea.ClearScreen
+
+	case "repair_trash":
+		// Rebuild trash.json from the trash directory (after a crash or corruption)
+		m.repairTrashNow()
+		return m, tea.ClearScreen
+
 	case "empty_trash":
 		// Empty entire trash
 		m.dialog = dialogModel{
@@ -521,6 +576,20 @@ func (m model) executeContextMenuAction() (tea.Model, tea.Cmd) {
 		m.setStatusMessage(fmt.Sprintf("📁 Select destination for: %s (Enter = select folder, Esc = cancel)", m.contextMenuFile.name), false)
 		return m, tea.ClearScreen
 
+	case "compare_mark":
+		// Mark the file for comparison (or clear the mark)
+		m.toggleCompareMark(m.contextMenuFile.path)
+		return m, nil
+
+	case "compare_marked":
+		// Compare the marked file with this one
+		return m, m.openCompare(m.compareMarked, m.contextMenuFile.path)
+
+	case "compare_with":
+		// Pick the file to compare with using the file picker
+		m.startComparePicker(m.contextMenuFile.path)
+		return m, tea.ClearScreen
+
 	case "rename":
 		// Rename the selected file or folder
 		m.dialog = dialogModel{
@@ -534,13 +603,12 @@ func (m model) executeContextMenuAction() (tea.Model, tea.Cmd) {
 
 	case "delete":
 		// Delete the selected file or folder (move to trash)
-		m.dialog = dialogModel{
+		cmd := m.openTrashDeleteDialog(*m.contextMenuFile, dialogModel{
 			dialogType: dialogConfirm,
 			title:      "Move to Trash",
 			message:    fmt.Sprintf("Move '%s' to trash?", m.contextMenuFile.name),
-		}
-		m.showDialog = true
-		return m, tea.ClearScreen
+		})
+		return m, tea.Batch(cmd, tea.ClearScreen)
 
 	// Tmux file actions
 	case "tmux_edit":
@@ -0,0 +1,987 @@
+package main
+
+// Module: csvtable.go
+// Purpose: Table preview for CSV/TSV files
+// Responsibilities:
+// - Delimiter detection and quote-aware record indexing (rows are paged from disk)
+// - Rendering an aligned table with a sticky header row and horizontal scroll
+// - Sorting by a column, filtering rows and per-column stats (run in the background)
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	csvIndexBudget = 16 * 1024 * 1024 // Bytes indexed per tick
+	csvMaxRows     = 10000000         // Rows indexed at most (8 bytes of index per row)
+	csvSampleRows  = 500              // Rows sampled to size the columns
+	csvMaxColWidth = 40
+	csvMinColWidth = 3
+	csvCacheRows   = 2000 // Parsed rows kept in memory
+	csvScrollStep  = 4    // Horizontal scroll step (same as detailScrollX)
+	csvMaxDistinct = 10000
+)
+
+// csvTableState is the table view for one CSV/TSV file
+type csvTableState struct {
+	path   string
+	size   int64
+	delim  rune
+	header []string
+	widths []int
+
+	// Record index: offsets[0] is the header, offsets[i] starts data row i-1
+	offsets   []int64
+	scanned   int64
+	inQuote   bool // Index scanner is inside a quoted field
+	pending   bool // A record starts at the next non-newline byte
+	indexed   bool
+	truncated bool // Indexing stopped at csvMaxRows
+	indexErr  error
+
+	cache map[int][]string // Parsed data rows by file row
+
+	// View
+	order     []int // File rows shown, after sort and filter (nil = file order)
+	sorted    []int // File rows in sort order (nil = file order)
+	sortCol   int   // -1 = file order
+	sortDesc  bool
+	matches   []bool // Rows passing the filter (nil = no filter)
+	matched   int
+	filter    string
+	col       int // Selected column
+	scrollX   int // Horizontal scroll offset in columns
+	showStats bool
+	stats     *csvColumnStats
+
+	busy string       // Running background task ("" = idle)
+	gen  atomic.Int64 // Bumped to cancel a running task
+}
+
+// csvColumnStats summarises one column
+type csvColumnStats struct {
+	column         int
+	count          int // Rows considered
+	empty          int
+	numeric        int
+	min, max, sum  float64
+	minText        string
+	maxText        string
+	distinct       int
+	distinctCapped bool
+	top            string
+	topCount       int
+}
+
+// csvTableMsg reports the result of a background sort, filter or stats scan
+type csvTableMsg struct {
+	path    string
+	gen     int64
+	kind    string // "sort", "filter" or "stats"
+	sorted  []int
+	matches []bool
+	matched int
+	filter  string
+	stats   *csvColumnStats
+	err     error
+}
+
+// detectCSVDelimiter picks the delimiter that splits the sample into the most
+// consistent number of fields (.tsv files prefer tabs)
+func detectCSVDelimiter(sample []byte, path string) rune {
+	best, bestScore := ',', -1
+	for _, delim := range []rune{',', '\t', ';', '|'} {
+		r := csv.NewReader(bytes.NewReader(sample))
+		r.Comma = delim
+		r.FieldsPerRecord = -1
+		r.LazyQuotes = true
+
+		fields, records, consistent := 0, 0, true
+		for records < 20 {
+			rec, err := r.Read()
+			if err != nil {
+				break // EOF, or a record cut off at the end of the sample
+			}
+			if records == 0 {
+				fields = len(rec)
+			} else if len(rec) != fields {
+				consistent = false
+			}
+			records++
+		}
+		if fields < 2 {
+			continue
+		}
+
+		score := fields
+		if consistent {
+			score += 1000
+		}
+		if delim == '\t' && strings.HasSuffix(strings.ToLower(path), ".tsv") {
+			score += 10000
+		}
+		if score > bestScore {
+			best, bestScore = delim, score
+		}
+	}
+	return best
+}
+
+// newCSVTableState opens path as a table, reading the header and indexing the first rows
+func newCSVTableState(path string, size int64) (*csvTableState, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sample := make([]byte, 64*1024)
+	n, err := f.Read(sample)
+	f.Close()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	sample = sample[:n]
+
+	t := &csvTableState{path: path, size: size, sortCol: -1, offsets: []int64{0}}
+	t.delim = detectCSVDelimiter(sample, path)
+
+	r := t.newReader(bytes.NewReader(sample))
+	header, err := r.Read()
+	if err != nil {
+		return nil, errors.New("no header row")
+	}
+	header = append([]string(nil), header...)
+	header[0] = strings.TrimPrefix(header[0], "\ufeff") // UTF-8 BOM
+	t.header = header
+
+	if err := t.indexStep(csvIndexBudget); err != nil {
+		return nil, err
+	}
+	t.computeWidths()
+	return t, nil
+}
+
+// newReader returns a lenient CSV reader for the table's delimiter
+func (t *csvTableState) newReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.Comma = t.delim
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	return reader
+}
+
+// indexStep records the start offsets of the next records, reading at most budget bytes.
+// Newlines inside quoted fields don't end a record; blank lines are skipped like csv.Reader does.
+func (t *csvTableState) indexStep(budget int64) error {
+	if t.indexed || t.indexErr != nil {
+		return t.indexErr
+	}
+
+	f, err := os.Open(t.path)
+	if err != nil {
+		t.indexErr = err
+		return err
+	}
+	defer f.Close()
+	if _, err := f.Seek(t.scanned, io.SeekStart); err != nil {
+		t.indexErr = err
+		return err
+	}
+
+	buf := make([]byte, pagerReadChunk)
+	var read int64
+	for read < budget && !t.indexed {
+		n, err := f.Read(buf)
+		if remaining := t.size - t.scanned; int64(n) > remaining {
+			n = int(remaining)
+		}
+
+		for i, b := range buf[:n] {
+			if t.pending && b != '\n' && b != '\r' {
+				t.pending = false
+				if len(t.offsets)-1 >= csvMaxRows {
+					t.truncated, t.indexed = true, true
+					return nil
+				}
+				t.offsets = append(t.offsets, t.scanned+int64(i))
+			}
+			switch {
+			case b == '"':
+				t.inQuote = !t.inQuote
+			case b == '\n' && !t.inQuote:
+				t.pending = true
+			}
+		}
+		t.scanned += int64(n)
+		read += int64(n)
+
+		if t.scanned >= t.size || err == io.EOF {
+			t.indexed = true
+		} else if err != nil {
+			t.indexErr = err
+			return err
+		}
+	}
+	return nil
+}
+
+// rowCount returns the number of data rows indexed so far
+func (t *csvTableState) rowCount() int {
+	return len(t.offsets) - 1
+}
+
+// viewCount returns the number of rows shown (after filtering)
+func (t *csvTableState) viewCount() int {
+	if t.order != nil {
+		return len(t.order)
+	}
+	return t.rowCount()
+}
+
+// fileRow maps a view position to a file row
+func (t *csvTableState) fileRow(i int) int {
+	if t.order != nil {
+		return t.order[i]
+	}
+	return i
+}
+
+// rows returns the parsed file rows, reading uncached ones from disk
+func (t *csvTableState) rows(fileRows []int) ([][]string, error) {
+	if t.cache == nil || len(t.cache) > csvCacheRows {
+		t.cache = make(map[int][]string)
+	}
+
+	var f *os.File
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	result := make([][]string, len(fileRows))
+	for i, row := range fileRows {
+		if rec, ok := t.cache[row]; ok {
+			result[i] = rec
+			continue
+		}
+		if f == nil {
+			var err error
+			if f, err = os.Open(t.path); err != nil {
+				return nil, err
+			}
+		}
+		start := t.offsets[row+1]
+		rec, err := t.newReader(io.NewSectionReader(f, start, t.size-start)).Read()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		t.cache[row] = rec
+		result[i] = rec
+	}
+	return result, nil
+}
+
+// computeWidths sizes the columns from the header and a sample of rows
+func (t *csvTableState) computeWidths() {
+	sample := make([]int, 0, csvSampleRows)
+	for i := 0; i < t.rowCount() && i < csvSampleRows; i++ {
+		sample = append(sample, i)
+	}
+	rows, _ := t.rows(sample)
+
+	// Rows with extra fields get unnamed columns
+	for _, rec := range rows {
+		for len(t.header) < len(rec) {
+			t.header = append(t.header, fmt.Sprintf("column %d", len(t.header)+1))
+		}
+	}
+
+	t.widths = make([]int, len(t.header))
+	for j, name := range t.header {
+		t.widths[j] = runewidth.StringWidth(csvCellText(name)) + 2 // Room for the sort arrow
+	}
+	for _, rec := range rows {
+		for j, cell := range rec {
+			t.widths[j] = max(t.widths[j], runewidth.StringWidth(csvCellText(cell)))
+		}
+	}
+	for j := range t.widths {
+		t.widths[j] = max(csvMinColWidth, min(csvMaxColWidth, t.widths[j]))
+	}
+}
+
+// csvCellText flattens a cell for display (embedded newlines and tabs become spaces)
+func csvCellText(cell string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ").Replace(cell)
+}
+
+// isCSVNumber reports whether a cell holds a number
+func isCSVNumber(cell string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+	return v, err == nil
+}
+
+// columnStart returns the offset of column j in a formatted row
+func (t *csvTableState) columnStart(j int) int {
+	start := 0
+	for i := 0; i < j; i++ {
+		start += t.widths[i] + 3 // " │ "
+	}
+	return start
+}
+
+// tableWidth returns the width of a formatted row
+func (t *csvTableState) tableWidth() int {
+	return t.columnStart(len(t.widths)) - 3
+}
+
+// formatRow lays out cells padded to the column widths. Numbers are right-aligned.
+func (t *csvTableState) formatRow(cells []string, style func(j int, text string) string) string {
+	return formatTableRow(cells, t.widths, func(j int) bool {
+		_, ok := isCSVNumber(cells[j])
+		return ok
+	}, style)
+}
+
+// formatTableRow lays out cells padded to widths, separated by " │ ".
+// Cells where numeric(j) is true are right-aligned.
+// Used by: CSV table preview, SQLite browser grid
+func formatTableRow(cells []string, widths []int, numeric func(j int) bool, style func(j int, text string) string) string {
+	sep := lipgloss.NewStyle().Foreground(uiMutedText()).Render(" │ ")
+	var b strings.Builder
+	for j, width := range widths {
+		if j > 0 {
+			b.WriteString(sep)
+		}
+		cell := ""
+		if j < len(cells) {
+			cell = csvCellText(cells[j])
+		}
+		text := runewidth.Truncate(cell, width, "…")
+		pad := strings.Repeat(" ", width-runewidth.StringWidth(text))
+		if j < len(cells) && numeric(j) {
+			text = pad + text
+		} else {
+			text += pad
+		}
+		if style != nil {
+			text = style(j, text)
+		}
+		b.WriteString(text)
+	}
+	return b.String()
+}
+
+// csvTableLayout returns the gutter width (scrollbar + row numbers) and the width left for cells
+func (m model) csvTableLayout() (numWidth, cellWidth int) {
+	t := m.preview.table
+	var boxContentWidth int
+	if m.viewMode == viewFullPreview {
+		boxContentWidth = m.width - 6
+	} else if m.displayMode == modeDetail || m.isNarrowTerminal() {
+		boxContentWidth = m.width - 6
+	} else {
+		boxContentWidth = m.rightWidth - 2
+	}
+	numWidth = max(3, len(strconv.Itoa(t.rowCount())))
+	cellWidth = max(10, boxContentWidth-numWidth-5) // scrollbar + space + number + " │ "
+	return numWidth, cellWidth
+}
+
+// csvScrollBy scrolls the table horizontally by delta columns of text
+func (m *model) csvScrollBy(delta int) {
+	t := m.preview.table
+	_, cellWidth := m.csvTableLayout()
+	maxScroll := max(0, t.tableWidth()-cellWidth)
+	t.scrollX = max(0, min(maxScroll, t.scrollX+delta))
+}
+
+// csvSelectColumn moves the column selection and scrolls it into view
+func (m *model) csvSelectColumn(delta int) tea.Cmd {
+	t := m.preview.table
+	t.col = max(0, min(len(t.widths)-1, t.col+delta))
+
+	_, cellWidth := m.csvTableLayout()
+	start := t.columnStart(t.col)
+	if start < t.scrollX {
+		t.scrollX = start
+	} else if end := start + t.widths[t.col]; end > t.scrollX+cellWidth {
+		t.scrollX = end - cellWidth
+	}
+
+	if t.showStats {
+		return m.csvStatsCmd()
+	}
+	return nil
+}
+
+// csvSortNext cycles the selected column through ascending, descending and file order
+func (m *model) csvSortNext() tea.Cmd {
+	t := m.preview.table
+	if !t.indexed {
+		m.setStatusMessage("Still reading rows - sort is available once the file is indexed", false)
+		return nil
+	}
+
+	switch {
+	case t.sortCol != t.col:
+		t.sortCol, t.sortDesc = t.col, false
+	case !t.sortDesc:
+		t.sortDesc = true
+	default:
+		t.sortCol, t.sorted = -1, nil
+		t.rebuildOrder()
+		m.setStatusMessage("Sort cleared (file order)", false)
+		return nil
+	}
+
+	t.busy = "sorting"
+	return csvScanCmd(t, "sort", t.sortCol, t.sortDesc, nil, "")
+}
+
+// csvFilterCmd starts filtering rows with expr ("" clears the filter)
+func (m *model) csvFilterCmd(expr string) tea.Cmd {
+	t := m.preview.table
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		t.matches, t.matched, t.filter = nil, 0, ""
+		t.rebuildOrder()
+		m.preview.scrollPos = 0
+		m.setStatusMessage("Filter cleared", false)
+		if t.showStats {
+			return m.csvStatsCmd()
+		}
+		return nil
+	}
+	if !t.indexed {
+		m.setStatusMessage("Still reading rows - filter is available once the file is indexed", false)
+		return nil
+	}
+
+	t.busy = "filtering"
+	return csvScanCmd(t, "filter", t.col, false, parseCSVFilter(expr, t.header), expr)
+}
+
+// csvStatsCmd starts computing stats for the selected column
+func (m *model) csvStatsCmd() tea.Cmd {
+	t := m.preview.table
+	if !t.indexed {
+		m.setStatusMessage("Still reading rows - stats are available once the file is indexed", false)
+		return nil
+	}
+	t.stats = nil
+	t.busy = "computing stats"
+	return csvScanCmd(t, "stats", t.col, false, nil, "")
+}
+
+// rebuildOrder combines the sort order and filter into the rows shown
+func (t *csvTableState) rebuildOrder() {
+	if t.matches == nil {
+		t.order = t.sorted
+		return
+	}
+	order := make([]int, 0, t.matched)
+	if t.sorted != nil {
+		for _, row := range t.sorted {
+			if t.matches[row] {
+				order = append(order, row)
+			}
+		}
+	} else {
+		for row, ok := range t.matches {
+			if ok {
+				order = append(order, row)
+			}
+		}
+	}
+	t.order = order
+}
+
+// csvScanCmd streams every row of the file in the background for a sort, filter or stats task.
+// Starting a new task cancels the previous one.
+func csvScanCmd(t *csvTableState, kind string, col int, desc bool, keep func([]string) bool, filter string) tea.Cmd {
+	gen := t.gen.Add(1)
+	path, delim, rows := t.path, t.delim, t.rowCount()
+	matches := t.matches
+
+	return func() tea.Msg {
+		msg := csvTableMsg{path: path, gen: gen, kind: kind, filter: filter}
+		cancelled := func() bool { return t.gen.Load() != gen }
+
+		f, err := os.Open(path)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		defer f.Close()
+		r := csv.NewReader(f)
+		r.Comma, r.FieldsPerRecord, r.LazyQuotes, r.ReuseRecord = delim, -1, true, true
+		if _, err := r.Read(); err != nil { // Header
+			msg.err = err
+			return msg
+		}
+
+		var values []string
+		stats := &csvColumnStats{column: col}
+		counts := make(map[string]int)
+		if kind == "filter" {
+			msg.matches = make([]bool, rows)
+		}
+
+		for row := 0; row < rows; row++ {
+			if row%4096 == 0 && cancelled() {
+				return nil
+			}
+			rec, err := r.Read()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				msg.err = err
+				return msg
+			}
+			cell := ""
+			if col < len(rec) {
+				cell = rec[col]
+			}
+
+			switch kind {
+			case "sort":
+				values = append(values, strings.Clone(cell))
+			case "filter":
+				if keep(rec) {
+					msg.matches[row] = true
+					msg.matched++
+				}
+			case "stats":
+				if matches == nil || (row < len(matches) && matches[row]) {
+					stats.add(cell, counts)
+				}
+			}
+		}
+
+		switch kind {
+		case "sort":
+			msg.sorted = sortCSVRows(values, desc)
+		case "stats":
+			stats.finish(counts)
+			msg.stats = stats
+		}
+		return msg
+	}
+}
+
+// sortCSVRows returns row numbers ordered by value. Columns where every non-empty
+// value is a number sort numerically; empty cells always sort last.
+func sortCSVRows(values []string, desc bool) []int {
+	numbers := make([]float64, len(values))
+	numeric := true
+	for i, v := range values {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		n, ok := isCSVNumber(v)
+		if !ok {
+			numeric = false
+			break
+		}
+		numbers[i] = n
+	}
+
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		va, vb := values[order[a]], values[order[b]]
+		emptyA, emptyB := strings.TrimSpace(va) == "", strings.TrimSpace(vb) == ""
+		if emptyA || emptyB {
+			return !emptyA && emptyB
+		}
+		var cmp int
+		if numeric {
+			na, nb := numbers[order[a]], numbers[order[b]]
+			switch {
+			case na < nb:
+				cmp = -1
+			case na > nb:
+				cmp = 1
+			}
+		} else {
+			cmp = strings.Compare(strings.ToLower(va), strings.ToLower(vb))
+		}
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	return order
+}
+
+// add accounts for one cell
+func (s *csvColumnStats) add(cell string, counts map[string]int) {
+	s.count++
+	if strings.TrimSpace(cell) == "" {
+		s.empty++
+		return
+	}
+
+	if n, ok := isCSVNumber(cell); ok {
+		if s.numeric == 0 || n < s.min {
+			s.min = n
+		}
+		if s.numeric == 0 || n > s.max {
+			s.max = n
+		}
+		s.sum += n
+		s.numeric++
+	}
+	if s.count-s.empty == 1 || cell < s.minText {
+		s.minText = strings.Clone(cell)
+	}
+	if cell > s.maxText {
+		s.maxText = strings.Clone(cell)
+	}
+
+	if _, seen := counts[cell]; seen || len(counts) < csvMaxDistinct {
+		counts[strings.Clone(cell)]++
+	} else {
+		s.distinctCapped = true
+	}
+}
+
+// finish computes the distinct count and most common value
+func (s *csvColumnStats) finish(counts map[string]int) {
+	s.distinct = len(counts)
+	for value, count := range counts {
+		if count > s.topCount || (count == s.topCount && value < s.top) {
+			s.top, s.topCount = value, count
+		}
+	}
+}
+
+// text summarises the stats on one line
+func (s *csvColumnStats) text(name string) string {
+	distinct := strconv.Itoa(s.distinct)
+	if s.distinctCapped {
+		distinct += "+"
+	}
+	text := fmt.Sprintf("%s: %d rows, %d empty, %s distinct", name, s.count, s.empty, distinct)
+
+	filled := s.count - s.empty
+	if filled > 0 && s.numeric == filled {
+		text += fmt.Sprintf(" | min %s, max %s, mean %s, sum %s",
+			formatCSVNumber(s.min), formatCSVNumber(s.max),
+			formatCSVNumber(s.sum/float64(s.numeric)), formatCSVNumber(s.sum))
+	} else if filled > 0 {
+		text += fmt.Sprintf(" | min %q, max %q", runewidth.Truncate(s.minText, 20, "…"), runewidth.Truncate(s.maxText, 20, "…"))
+	}
+	if s.topCount > 1 {
+		text += fmt.Sprintf(" | top %q ×%d", runewidth.Truncate(csvCellText(s.top), 20, "…"), s.topCount)
+	}
+	return text
+}
+
+// formatCSVNumber formats stats numbers compactly
+func formatCSVNumber(n float64) string {
+	return strconv.FormatFloat(n, 'g', 10, 64)
+}
+
+// csvColumnIndex resolves a column by header name (case-insensitive) or "#N" (1-based)
+func csvColumnIndex(name string, header []string) int {
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, "#") {
+		if n, err := strconv.Atoi(name[1:]); err == nil && n >= 1 && n <= len(header) {
+			return n - 1
+		}
+		return -1
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseCSVFilter compiles a row filter:
+//
+//	text        any cell contains text (case-insensitive)
+//	col=value   cell equals value (also !=)
+//	col~text    cell contains text
+//	col>n       numeric comparison (also <, >=, <=; text columns compare alphabetically)
+//
+// col is a header name or #N. Expressions whose left side isn't a column are free text.
+func parseCSVFilter(expr string, header []string) func([]string) bool {
+	for _, op := range []string{"!=", ">=", "<=", "=", "~", ">", "<"} {
+		idx := strings.Index(expr, op)
+		if idx <= 0 {
+			continue
+		}
+		col := csvColumnIndex(expr[:idx], header)
+		if col < 0 {
+			continue
+		}
+		want := strings.TrimSpace(expr[idx+len(op):])
+		wantLower := strings.ToLower(want)
+		wantNum, wantIsNum := isCSVNumber(want)
+
+		return func(rec []string) bool {
+			cell := ""
+			if col < len(rec) {
+				cell = strings.TrimSpace(rec[col])
+			}
+			switch op {
+			case "=":
+				return strings.EqualFold(cell, want)
+			case "!=":
+				return !strings.EqualFold(cell, want)
+			case "~":
+				return strings.Contains(strings.ToLower(cell), wantLower)
+			}
+
+			var cmp int
+			if n, ok := isCSVNumber(cell); ok && wantIsNum {
+				switch {
+				case n < wantNum:
+					cmp = -1
+				case n > wantNum:
+					cmp = 1
+				}
+			} else if cell == "" {
+				return false
+			} else {
+				cmp = strings.Compare(strings.ToLower(cell), wantLower)
+			}
+			switch op {
+			case ">":
+				return cmp > 0
+			case "<":
+				return cmp < 0
+			case ">=":
+				return cmp >= 0
+			default:
+				return cmp <= 0
+			}
+		}
+	}
+
+	needle := strings.ToLower(strings.TrimSpace(expr))
+	return func(rec []string) bool {
+		for _, cell := range rec {
+			if strings.Contains(strings.ToLower(cell), needle) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// advanceCSVIndex indexes more rows of a large table on each tick
+func (m *model) advanceCSVIndex() {
+	t := m.preview.table
+	if t == nil || t.indexed || t.indexErr != nil {
+		return
+	}
+	if err := t.indexStep(csvIndexBudget); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Table: indexing stopped: %v", err), true)
+	}
+}
+
+// applyCSVTableMsg applies a finished sort, filter or stats scan (stale results are ignored)
+func (m *model) applyCSVTableMsg(msg csvTableMsg) tea.Cmd {
+	t := m.preview.table
+	if t == nil || t.path != msg.path || t.gen.Load() != msg.gen {
+		return nil
+	}
+	t.busy = ""
+
+	if msg.err != nil {
+		m.setStatusMessage(fmt.Sprintf("Table %s failed: %v", msg.kind, msg.err), true)
+		return nil
+	}
+
+	switch msg.kind {
+	case "sort":
+		t.sorted = msg.sorted
+		t.rebuildOrder()
+		direction := "ascending"
+		if t.sortDesc {
+			direction = "descending"
+		}
+		m.setStatusMessage(fmt.Sprintf("Sorted by %s (%s) - s: next order", t.header[t.sortCol], direction), false)
+	case "filter":
+		t.matches, t.matched, t.filter = msg.matches, msg.matched, msg.filter
+		t.rebuildOrder()
+		m.preview.scrollPos = 0
+		m.setStatusMessage(fmt.Sprintf("Filter %s: %d of %d rows (. then Enter on empty: clear)", msg.filter, msg.matched, t.rowCount()), false)
+		if t.showStats {
+			return m.csvStatsCmd()
+		}
+	case "stats":
+		t.stats = msg.stats
+	}
+	return nil
+}
+
+// csvTableStatusText returns the table's position summary for info lines
+func (m model) csvTableStatusText(lastVisibleRow int) string {
+	t := m.preview.table
+	delim := map[rune]string{',': "comma", '\t': "tab", ';': "semicolon", '|': "pipe"}[t.delim]
+	total := strconv.Itoa(t.rowCount())
+	if !t.indexed {
+		total += fmt.Sprintf("+ (reading %d%%)", t.scanned*100/max64(t.size, 1))
+	} else if t.truncated {
+		total += " (row limit reached)"
+	}
+
+	text := fmt.Sprintf("Table (%s) | %d columns | Row %d/%s", delim, len(t.header), lastVisibleRow, total)
+	if t.filter != "" {
+		text += fmt.Sprintf(" | Filter: %s (%d rows)", t.filter, t.matched)
+	}
+	if t.busy != "" {
+		text += " | " + t.busy + "..."
+	}
+	return text
+}
+
+// max64 returns the larger of two int64 values
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// renderCSVTablePreview renders the header, the visible rows and the optional stats line
+func (m model) renderCSVTablePreview(maxVisible int) string {
+	var s strings.Builder
+	t := m.preview.table
+	numWidth, cellWidth := m.csvTableLayout()
+
+	targetLines := maxVisible
+	if m.viewMode == viewDualPane {
+		targetLines = maxVisible - 1
+	}
+	statsLines := 0
+	if t.showStats {
+		statsLines = 1
+	}
+	dataLines := max(1, targetLines-2-statsLines) // Header + separator stay on screen
+
+	total := t.viewCount()
+	start := max(0, min(m.preview.scrollPos, total-dataLines))
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Folder.adaptiveColor())
+	selectedStyle := lipgloss.NewStyle().Bold(true).
+		Foreground(currentTheme.SelectionFg.adaptiveColor()).
+		Background(currentTheme.SelectionBg.adaptiveColor())
+	gutterStyle := lipgloss.NewStyle().Foreground(uiSubtleText())
+	mutedStyle := lipgloss.NewStyle().Foreground(uiMutedText())
+
+	linesRendered := 0
+	writeLine := func(line string) {
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(line)
+		linesRendered++
+	}
+	gutter := func(text string) string {
+		return gutterStyle.Render(fmt.Sprintf("%*s", numWidth, text)) + mutedStyle.Render(" │ ")
+	}
+
+	// Sticky header
+	header := make([]string, len(t.header))
+	for j, name := range t.header {
+		header[j] = name
+		if j == t.sortCol {
+			header[j] = runewidth.Truncate(name, t.widths[j]-2, "…") + map[bool]string{false: " ▲", true: " ▼"}[t.sortDesc]
+		}
+	}
+	headerLine := t.formatRow(header, func(j int, text string) string {
+		if j == t.col {
+			return selectedStyle.Render(text)
+		}
+		return headerStyle.Render(text)
+	})
+	writeLine("  " + gutter("#") + m.extractVisibleColumns(headerLine, t.scrollX, cellWidth))
+
+	var sep strings.Builder
+	for j, width := range t.widths {
+		if j > 0 {
+			sep.WriteString("─┼─")
+		}
+		sep.WriteString(strings.Repeat("─", width))
+	}
+	writeLine("  " + mutedStyle.Render(strings.Repeat("─", numWidth)+"─┼─") +
+		m.extractVisibleColumns(mutedStyle.Render(sep.String()), t.scrollX, cellWidth))
+
+	// Rows
+	fileRows := make([]int, 0, dataLines)
+	for i := start; i < total && len(fileRows) < dataLines; i++ {
+		fileRows = append(fileRows, t.fileRow(i))
+	}
+	rows, err := t.rows(fileRows)
+	if err != nil {
+		writeLine(fmt.Sprintf("Error reading file: %v", err))
+	} else if total == 0 {
+		if t.filter != "" {
+			writeLine(mutedStyle.Render("  (no rows match the filter)"))
+		} else {
+			writeLine(mutedStyle.Render("  (no rows)"))
+		}
+	}
+	for i, rec := range rows {
+		line := m.renderScrollbar(i, dataLines, total) + " " + gutter(strconv.Itoa(fileRows[i]+1))
+		line += m.extractVisibleColumns(t.formatRow(rec, nil), t.scrollX, cellWidth)
+		writeLine(line + "\033[0m")
+	}
+
+	for linesRendered < targetLines-statsLines {
+		writeLine("\033[0m")
+	}
+	if t.showStats {
+		statsText := "Computing stats..."
+		if t.stats != nil && t.stats.column < len(t.header) {
+			statsText = t.stats.text(t.header[t.stats.column])
+		}
+		writeLine(mutedStyle.Render(truncateToWidth("  Σ "+statsText, numWidth+cellWidth+3)))
+	}
+
+	if m.viewMode == viewDualPane {
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		scrollStyle := lipgloss.NewStyle().
+			Foreground(uiSubtleText()).
+			Italic(true)
+		s.WriteString(scrollStyle.Render(fmt.Sprintf(" row %d/%d [table] ", min(start+1, total), total)))
+	}
+
+	return s.String()
+}
+
+// openCSVTable shows path as a table, falling back to text if it has no header row
+func (m *model) openCSVTable(path string, size int64) bool {
+	t, err := newCSVTableState(path, size)
+	if err != nil {
+		return false
+	}
+	m.preview.table = t
+	m.preview.content = nil
+	m.preview.loaded = true
+	return true
+}
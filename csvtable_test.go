@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCSVTestFile writes content to name in a temp dir and returns the path
+func writeCSVTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	return path
+}
+
+// newTestCSVTable opens path as a table and indexes it completely
+func newTestCSVTable(t *testing.T, path string) *csvTableState {
+	t.Helper()
+	info, _ := os.Stat(path)
+	table, err := newCSVTableState(path, info.Size())
+	if err != nil {
+		t.Fatalf("newCSVTableState failed: %v", err)
+	}
+	for !table.indexed {
+		if err := table.indexStep(csvIndexBudget); err != nil {
+			t.Fatalf("indexStep failed: %v", err)
+		}
+	}
+	return table
+}
+
+// runCSVScan runs a background scan synchronously and applies its result
+func runCSVScan(t *testing.T, m *model, cmd func() interface{}) {
+	t.Helper()
+	msg, ok := cmd().(csvTableMsg)
+	if !ok {
+		t.Fatal("Expected csvTableMsg")
+	}
+	m.applyCSVTableMsg(msg)
+}
+
+// TestDetectCSVDelimiter tests delimiter detection
+func TestDetectCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		sample string
+		path   string
+		want   rune
+	}{
+		{"a,b,c\n1,2,3\n", "x.csv", ','},
+		{"a;b;c\n1;2,5;3\n", "x.csv", ';'},
+		{"a\tb\n1\t2\n", "x.tsv", '\t'},
+		{"a|b|c\n1|2|3\n", "x.txt", '|'},
+		{"name,note\n\"x\",\"a;b;c;d\"\n", "x.csv", ','},
+	}
+	for _, tt := range tests {
+		if got := detectCSVDelimiter([]byte(tt.sample), tt.path); got != tt.want {
+			t.Errorf("detectCSVDelimiter(%q) = %q, want %q", tt.sample, got, tt.want)
+		}
+	}
+}
+
+// TestCSVTableIndex tests record indexing with quoted newlines and blank lines
+func TestCSVTableIndex(t *testing.T) {
+	content := "\ufeffname,note,qty\n" +
+		"apple,\"multi\nline\",3\n" +
+		"\n" +
+		"pear,\"has \"\"quotes\"\", commas\",10\r\n" +
+		"fig,,2"
+	table := newTestCSVTable(t, writeCSVTestFile(t, "fruit.csv", content))
+
+	if strings.Join(table.header, "|") != "name|note|qty" {
+		t.Errorf("Unexpected header %q", table.header)
+	}
+	if table.rowCount() != 3 {
+		t.Fatalf("Expected 3 rows, got %d", table.rowCount())
+	}
+
+	rows, err := table.rows([]int{2, 0, 1})
+	if err != nil {
+		t.Fatalf("rows failed: %v", err)
+	}
+	if rows[0][0] != "fig" || rows[1][1] != "multi\nline" || rows[2][1] != `has "quotes", commas` {
+		t.Errorf("Unexpected rows %q", rows)
+	}
+}
+
+// TestCSVTableSortFilterStats tests sorting, filtering and stats
+func TestCSVTableSortFilterStats(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("id,city,score\n")
+	cities := []string{"Oslo", "berlin", "Austin", "Oslo", "Cairo"}
+	scores := []string{"10", "9.5", "", "100", "-3"}
+	for i := range cities {
+		fmt.Fprintf(&b, "%d,%s,%s\n", i+1, cities[i], scores[i])
+	}
+	table := newTestCSVTable(t, writeCSVTestFile(t, "cities.csv", b.String()))
+
+	m := &model{height: 40, width: 120, viewMode: viewFullPreview}
+	m.preview.loaded = true
+	m.preview.table = table
+
+	// Numeric sort with empty cells last
+	table.col = 2
+	runCSVScan(t, m, func() interface{} { return m.csvSortNext()() })
+	if got := fmt.Sprint(table.order); got != "[4 1 0 3 2]" {
+		t.Errorf("Ascending numeric sort = %s", got)
+	}
+	runCSVScan(t, m, func() interface{} { return m.csvSortNext()() })
+	if got := fmt.Sprint(table.order); got != "[3 0 1 4 2]" {
+		t.Errorf("Descending numeric sort = %s", got)
+	}
+
+	// Text sort is case-insensitive
+	table.col = 1
+	runCSVScan(t, m, func() interface{} { return m.csvSortNext()() })
+	if got := fmt.Sprint(table.order); got != "[2 1 4 0 3]" {
+		t.Errorf("Text sort = %s", got)
+	}
+
+	// Filter keeps the sort order
+	runCSVScan(t, m, func() interface{} { return m.csvFilterCmd("city=oslo")() })
+	if got := fmt.Sprint(table.order); got != "[0 3]" || table.matched != 2 {
+		t.Errorf("Filtered order = %s (%d matched)", got, table.matched)
+	}
+
+	// Stats cover the filtered rows
+	table.col = 2
+	runCSVScan(t, m, func() interface{} { return m.csvStatsCmd()() })
+	if s := table.stats; s == nil || s.count != 2 || s.min != 10 || s.max != 100 {
+		t.Errorf("Unexpected stats %+v", table.stats)
+	}
+
+	m.csvFilterCmd("")
+	if table.viewCount() != 5 || table.filter != "" {
+		t.Error("Expected filter to be cleared")
+	}
+	runCSVScan(t, m, func() interface{} { return m.csvStatsCmd()() })
+	if s := table.stats; s.count != 5 || s.empty != 1 || s.distinct != 4 || !strings.Contains(s.text("score"), "mean 29.125") {
+		t.Errorf("Unexpected stats %+v: %s", s, s.text("score"))
+	}
+}
+
+// TestParseCSVFilter tests the row filter syntax
+func TestParseCSVFilter(t *testing.T) {
+	header := []string{"Name", "Age", "Team"}
+	row := []string{"Ada Lovelace", "36", "Analytical"}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"lovelace", true},
+		{"babbage", false},
+		{"name=ada lovelace", true},
+		{"Name!=Ada Lovelace", false},
+		{"team~lyt", true},
+		{"age>30", true},
+		{"age<=35", false},
+		{"#2>=36", true},
+		{"#3=analytical", true},
+		{"unknown=1", false}, // Not a column - searched as text
+	}
+	for _, tt := range tests {
+		if got := parseCSVFilter(tt.expr, header)(row); got != tt.want {
+			t.Errorf("filter %q = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// TestCSVTableScroll tests horizontal scrolling and column selection
+func TestCSVTableScroll(t *testing.T) {
+	header := make([]string, 20)
+	cells := make([]string, 20)
+	for i := range header {
+		header[i] = fmt.Sprintf("column_%02d", i)
+		cells[i] = strings.Repeat("x", 12)
+	}
+	content := strings.Join(header, ",") + "\n" + strings.Join(cells, ",") + "\n"
+	path := writeCSVTestFile(t, "wide.csv", content)
+
+	m := &model{height: 30, width: 80, viewMode: viewFullPreview, previewMouseEnabled: true}
+	m.loadPreview(path)
+	table := m.preview.table
+	if table == nil {
+		t.Fatal("Expected CSV file to open in the table view")
+	}
+
+	m.csvScrollBy(csvScrollStep)
+	if table.scrollX != csvScrollStep {
+		t.Errorf("Expected scrollX %d, got %d", csvScrollStep, table.scrollX)
+	}
+	m.csvScrollBy(-100)
+	if table.scrollX != 0 {
+		t.Errorf("Expected scrollX clamped to 0, got %d", table.scrollX)
+	}
+	m.csvScrollBy(100000)
+	_, cellWidth := m.csvTableLayout()
+	if table.scrollX != table.tableWidth()-cellWidth {
+		t.Errorf("Expected scrollX clamped to %d, got %d", table.tableWidth()-cellWidth, table.scrollX)
+	}
+
+	// Selecting the first column scrolls back to it
+	table.col = 1
+	m.csvSelectColumn(-1)
+	if table.scrollX != 0 {
+		t.Errorf("Expected first column scrolled into view, got scrollX %d", table.scrollX)
+	}
+
+	out := m.renderCSVTablePreview(10)
+	if !strings.Contains(out, "column_00") || strings.Contains(out, "column_19") {
+		t.Error("Expected only the leftmost columns to be rendered")
+	}
+}
@@ -0,0 +1,1066 @@
+package main
+
+// Module: datatree.go
+// Purpose: Collapsible tree view for JSON, YAML and TOML files
+// Responsibilities:
+// - Parsing JSON/YAML/TOML into an ordered node tree (document key order is kept)
+// - Folding objects/arrays and flattening the visible rows
+// - Showing and copying the path (.servers[2].port) or value of the node under the cursor
+// - A small jq-like filter language (.a.b, [n], [], .., keys, length, select(...))
+// - Pretty-printing minified JSON for the text view
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	dataTreeMaxSize     = 16 * 1024 * 1024 // Structured files up to this size get the tree view (bigger ones use the pager)
+	dataTreeExpandLimit = 5000             // Documents with more nodes start folded below the second level
+	dataTreeMaxNodes    = 2000000          // Cap on nodes created while expanding YAML aliases
+)
+
+// dataNodeKind is the JSON type of a tree node
+type dataNodeKind int
+
+const (
+	dataObject dataNodeKind = iota
+	dataArray
+	dataString
+	dataNumber
+	dataBool
+	dataNull
+)
+
+// dataNode is one value in a structured document
+type dataNode struct {
+	key       string // Member name (objects)
+	index     int    // Position in the parent array, -1 otherwise
+	kind      dataNodeKind
+	value     string // Scalar value (strings unquoted)
+	children  []*dataNode
+	parent    *dataNode
+	collapsed bool
+}
+
+// dataTreeRow is one visible row of the tree
+type dataTreeRow struct {
+	node  *dataNode
+	depth int
+}
+
+// dataTreeState is the tree view for one structured file
+type dataTreeState struct {
+	format   string    // "JSON", "YAML" or "TOML"
+	root     *dataNode // Parsed document
+	view     *dataNode // Node shown at the top of the tree (filter result or root)
+	filter   string    // Active filter expression ("" = none)
+	rows     []dataTreeRow
+	cursor   int
+	textMode bool // Showing the regular text preview instead of the tree
+}
+
+// structuredDataFormat returns "JSON", "YAML" or "TOML" for files the tree view supports
+func structuredDataFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".geojson", ".har":
+		return "JSON"
+	case ".yaml", ".yml":
+		return "YAML"
+	case ".toml":
+		return "TOML"
+	}
+	return ""
+}
+
+// newDataNode creates a detached node
+func newDataNode(kind dataNodeKind, value string) *dataNode {
+	return &dataNode{kind: kind, value: value, index: -1}
+}
+
+// add appends child as a member (objects) or element (arrays)
+func (n *dataNode) add(key string, child *dataNode) {
+	child.parent = n
+	if n.kind == dataArray {
+		child.index = len(n.children)
+	} else {
+		child.key = key
+	}
+	n.children = append(n.children, child)
+}
+
+// isContainer reports whether the node is an object or array
+func (n *dataNode) isContainer() bool {
+	return n.kind == dataObject || n.kind == dataArray
+}
+
+// path returns the jq-style path of the node (".servers[2].port")
+func (n *dataNode) path() string {
+	var parts []string
+	for c := n; c.parent != nil; c = c.parent {
+		switch {
+		case c.parent.kind == dataArray:
+			parts = append(parts, fmt.Sprintf("[%d]", c.index))
+		case isDataIdent(c.key):
+			parts = append(parts, "."+c.key)
+		default:
+			parts = append(parts, "["+quoteJSON(c.key)+"]")
+		}
+	}
+	if len(parts) == 0 {
+		return "."
+	}
+	var b strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		b.WriteString(parts[i])
+	}
+	return b.String()
+}
+
+// isDataIdent reports whether key can be written as .key in a path
+func isDataIdent(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		isLetter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isLetter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteJSON returns s as a JSON string literal
+func quoteJSON(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// countNodes returns the number of nodes in the subtree
+func (n *dataNode) countNodes() int {
+	count := 1
+	for _, c := range n.children {
+		count += c.countNodes()
+	}
+	return count
+}
+
+// setCollapsed folds or unfolds every container below n (n itself stays open)
+func (n *dataNode) setCollapsed(collapsed bool, fromDepth, depth int) {
+	if n.isContainer() && depth >= fromDepth {
+		n.collapsed = collapsed
+	}
+	for _, c := range n.children {
+		c.setCollapsed(collapsed, fromDepth, depth+1)
+	}
+}
+
+// jsonText returns the subtree as indented JSON
+func (n *dataNode) jsonText() string {
+	var b strings.Builder
+	writeDataJSON(&b, n, 0)
+	return b.String()
+}
+
+// writeDataJSON writes n as JSON, indenting nested containers by two spaces per level
+func writeDataJSON(b *strings.Builder, n *dataNode, depth int) {
+	switch n.kind {
+	case dataObject, dataArray:
+		open, close := "{", "}"
+		if n.kind == dataArray {
+			open, close = "[", "]"
+		}
+		if len(n.children) == 0 {
+			b.WriteString(open + close)
+			return
+		}
+		b.WriteString(open + "\n")
+		for i, c := range n.children {
+			b.WriteString(strings.Repeat("  ", depth+1))
+			if n.kind == dataObject {
+				b.WriteString(quoteJSON(c.key) + ": ")
+			}
+			writeDataJSON(b, c, depth+1)
+			if i < len(n.children)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(strings.Repeat("  ", depth) + close)
+	case dataString:
+		b.WriteString(quoteJSON(n.value))
+	default:
+		b.WriteString(n.value)
+	}
+}
+
+// parseDataTree parses content in the given format ("JSON", "YAML" or "TOML")
+func parseDataTree(content []byte, format string) (*dataNode, error) {
+	switch format {
+	case "JSON":
+		return parseJSONTree(content)
+	case "YAML":
+		return parseYAMLTree(content)
+	case "TOML":
+		return parseTOMLTree(content)
+	}
+	return nil, fmt.Errorf("unsupported format: %s", format)
+}
+
+// parseJSONTree parses JSON keeping object members in document order
+func parseJSONTree(content []byte) (*dataNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.UseNumber()
+	root, err := parseJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the top-level JSON value")
+	}
+	return root, nil
+}
+
+// parseJSONValue reads one value from the token stream
+func parseJSONValue(dec *json.Decoder) (*dataNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		n := newDataNode(dataObject, "")
+		if v == '[' {
+			n.kind = dataArray
+		}
+		for dec.More() {
+			key := ""
+			if n.kind == dataObject {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ = keyTok.(string)
+			}
+			child, err := parseJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.add(key, child)
+		}
+		// Closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return newDataNode(dataString, v), nil
+	case json.Number:
+		return newDataNode(dataNumber, v.String()), nil
+	case bool:
+		return newDataNode(dataBool, strconv.FormatBool(v)), nil
+	default:
+		return newDataNode(dataNull, "null"), nil
+	}
+}
+
+// parseYAMLTree parses YAML (multi-document files become an array of documents)
+func parseYAMLTree(content []byte) (*dataNode, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	budget := dataTreeMaxNodes
+
+	var docs []*dataNode
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, yamlToDataNode(&doc, &budget))
+	}
+
+	switch len(docs) {
+	case 0:
+		return newDataNode(dataNull, "null"), nil
+	case 1:
+		return docs[0], nil
+	}
+	root := newDataNode(dataArray, "")
+	for _, doc := range docs {
+		root.add("", doc)
+	}
+	return root, nil
+}
+
+// yamlToDataNode converts a yaml.v3 node. Aliases are expanded until budget runs
+// out, after which they are shown as "*anchor" (guards against alias bombs).
+func yamlToDataNode(y *yaml.Node, budget *int) *dataNode {
+	*budget--
+	switch y.Kind {
+	case yaml.DocumentNode:
+		if len(y.Content) == 0 {
+			return newDataNode(dataNull, "null")
+		}
+		return yamlToDataNode(y.Content[0], budget)
+	case yaml.AliasNode:
+		if *budget <= 0 || y.Alias == nil {
+			return newDataNode(dataString, "*"+y.Value)
+		}
+		return yamlToDataNode(y.Alias, budget)
+	case yaml.MappingNode:
+		n := newDataNode(dataObject, "")
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			n.add(y.Content[i].Value, yamlToDataNode(y.Content[i+1], budget))
+		}
+		return n
+	case yaml.SequenceNode:
+		n := newDataNode(dataArray, "")
+		for _, c := range y.Content {
+			n.add("", yamlToDataNode(c, budget))
+		}
+		return n
+	}
+
+	switch y.ShortTag() {
+	case "!!null":
+		return newDataNode(dataNull, "null")
+	case "!!bool":
+		var b bool
+		if y.Decode(&b) == nil {
+			return newDataNode(dataBool, strconv.FormatBool(b))
+		}
+	case "!!int", "!!float":
+		return newDataNode(dataNumber, y.Value)
+	}
+	return newDataNode(dataString, y.Value)
+}
+
+// parseTOMLTree parses TOML, ordering keys as they appear in the document
+func parseTOMLTree(content []byte) (*dataNode, error) {
+	var data map[string]interface{}
+	md, err := toml.Decode(string(content), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys are matched by their path without array indices ("servers\x00name")
+	order := make(map[string]int)
+	for i, key := range md.Keys() {
+		joined := strings.Join(key, "\x00")
+		if _, ok := order[joined]; !ok {
+			order[joined] = i
+		}
+	}
+	return tomlToDataNode(data, "", order), nil
+}
+
+// tomlToDataNode converts a decoded TOML value
+func tomlToDataNode(v interface{}, path string, order map[string]int) *dataNode {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		n := newDataNode(dataObject, "")
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		childPath := func(k string) string {
+			if path == "" {
+				return k
+			}
+			return path + "\x00" + k
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			oi, iok := order[childPath(keys[i])]
+			oj, jok := order[childPath(keys[j])]
+			if iok != jok {
+				return iok
+			}
+			if iok && oi != oj {
+				return oi < oj
+			}
+			return keys[i] < keys[j]
+		})
+		for _, k := range keys {
+			n.add(k, tomlToDataNode(val[k], childPath(k), order))
+		}
+		return n
+	case []map[string]interface{}:
+		n := newDataNode(dataArray, "")
+		for _, item := range val {
+			n.add("", tomlToDataNode(item, path, order))
+		}
+		return n
+	case []interface{}:
+		n := newDataNode(dataArray, "")
+		for _, item := range val {
+			n.add("", tomlToDataNode(item, path, order))
+		}
+		return n
+	case string:
+		return newDataNode(dataString, val)
+	case int64:
+		return newDataNode(dataNumber, strconv.FormatInt(val, 10))
+	case float64:
+		return newDataNode(dataNumber, strconv.FormatFloat(val, 'g', -1, 64))
+	case bool:
+		return newDataNode(dataBool, strconv.FormatBool(val))
+	}
+	// Dates and times
+	return newDataNode(dataString, fmt.Sprint(v))
+}
+
+// prettyPrintJSON indents minified JSON (long lines, few line breaks).
+// Returns content unchanged if it already looks formatted or doesn't parse.
+func prettyPrintJSON(content []byte) []byte {
+	lines := bytes.Count(content, []byte("\n")) + 1
+	if len(content)/lines < 200 {
+		return content
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, content, "", "  "); err != nil {
+		return content
+	}
+	return buf.Bytes()
+}
+
+// newDataTreeState creates the tree view for a parsed document
+func newDataTreeState(root *dataNode, format string) *dataTreeState {
+	t := &dataTreeState{format: format, root: root, view: root}
+	if root.countNodes() > dataTreeExpandLimit {
+		root.setCollapsed(true, 2, 0)
+	}
+	t.rebuild()
+	return t
+}
+
+// rebuild recomputes the visible rows after folding or filtering
+func (t *dataTreeState) rebuild() {
+	t.rows = t.rows[:0]
+	var walk func(n *dataNode, depth int)
+	walk = func(n *dataNode, depth int) {
+		t.rows = append(t.rows, dataTreeRow{node: n, depth: depth})
+		if !n.collapsed {
+			for _, c := range n.children {
+				walk(c, depth+1)
+			}
+		}
+	}
+	walk(t.view, 0)
+	if t.cursor >= len(t.rows) {
+		t.cursor = len(t.rows) - 1
+	}
+	if t.cursor < 0 {
+		t.cursor = 0
+	}
+}
+
+// current returns the node under the cursor
+func (t *dataTreeState) current() *dataNode {
+	if t.cursor < len(t.rows) {
+		return t.rows[t.cursor].node
+	}
+	return t.view
+}
+
+// nodePath returns the path shown for a node. Values computed by a filter
+// (keys, length) have no place in the document, so the filter is shown instead.
+func (t *dataTreeState) nodePath(n *dataNode) string {
+	if n.parent == nil && n != t.root {
+		return t.filter
+	}
+	return n.path()
+}
+
+// isResultList reports whether the view is a list of several filter results
+func (t *dataTreeState) isResultList() bool {
+	return t.view != t.root && t.view.parent == nil && t.view.kind == dataArray && t.filter != ""
+}
+
+// dataTreeActive reports whether the tree view is showing
+func (m model) dataTreeActive() bool {
+	return m.preview.tree != nil && !m.preview.tree.textMode
+}
+
+// dataTreeMoveCursor moves the cursor by delta rows and keeps it on screen
+func (m *model) dataTreeMoveCursor(delta int) {
+	t := m.preview.tree
+	t.cursor = max(0, min(len(t.rows)-1, t.cursor+delta))
+
+	visible := m.getPreviewVisibleLines()
+	if m.viewMode == viewDualPane {
+		visible-- // Position indicator line
+	}
+	if t.cursor < m.preview.scrollPos {
+		m.preview.scrollPos = t.cursor
+	} else if visible > 0 && t.cursor >= m.preview.scrollPos+visible {
+		m.preview.scrollPos = t.cursor - visible + 1
+	}
+}
+
+// dataTreeToggle folds or unfolds the node under the cursor
+func (m *model) dataTreeToggle(collapsed bool) {
+	t := m.preview.tree
+	n := t.current()
+	if !n.isContainer() || len(n.children) == 0 {
+		return
+	}
+	n.collapsed = collapsed
+	t.rebuild()
+}
+
+// dataTreeCollapseOrParent folds the current node, or moves to its parent if already folded
+func (m *model) dataTreeCollapseOrParent() {
+	t := m.preview.tree
+	n := t.current()
+	if n.isContainer() && !n.collapsed && len(n.children) > 0 {
+		m.dataTreeToggle(true)
+		return
+	}
+	depth := t.rows[t.cursor].depth
+	for i := t.cursor - 1; i >= 0; i-- {
+		if t.rows[i].depth < depth {
+			m.dataTreeMoveCursor(i - t.cursor)
+			return
+		}
+	}
+}
+
+// dataTreeSetAll folds (below the top level) or unfolds the whole view
+func (m *model) dataTreeSetAll(collapsed bool) {
+	t := m.preview.tree
+	t.view.setCollapsed(collapsed, 1, 0)
+	t.rebuild()
+	m.dataTreeMoveCursor(0)
+}
+
+// copyDataTreePath copies the path of the node under the cursor
+// Used by: keyboard ("y" in tree view)
+func (m *model) copyDataTreePath() {
+	path := m.preview.tree.nodePath(m.preview.tree.current())
+	if err := copyToClipboard(path); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Failed to copy path: %s", err), true)
+		return
+	}
+	m.setStatusMessage(fmt.Sprintf("✓ Copied path: %s", path), false)
+}
+
+// copyDataTreeValue copies the value under the cursor (scalars raw, containers as JSON)
+// Used by: keyboard ("Y" in tree view)
+func (m *model) copyDataTreeValue() {
+	n := m.preview.tree.current()
+	value := n.value
+	if n.isContainer() {
+		value = n.jsonText()
+	}
+	if err := copyToClipboard(value); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Failed to copy value: %s", err), true)
+		return
+	}
+	m.setStatusMessage(fmt.Sprintf("✓ Copied value of %s (%d chars)", m.preview.tree.nodePath(n), len(value)), false)
+}
+
+// toggleDataTreeText switches between the tree view and the regular text preview
+func (m *model) toggleDataTreeText() {
+	t := m.preview.tree
+	t.textMode = !t.textMode
+	m.preview.scrollPos = 0
+	if t.textMode {
+		m.setStatusMessage("Text view (t: back to tree)", false)
+	} else {
+		m.dataTreeMoveCursor(0)
+		m.setStatusMessage("Tree view (t: text view)", false)
+	}
+}
+
+// applyDataTreeFilter shows the results of a jq-like filter ("" or "." resets)
+func (m *model) applyDataTreeFilter(expr string) {
+	t := m.preview.tree
+	expr = strings.TrimSpace(expr)
+	if expr == "" || expr == "." {
+		t.view, t.filter = t.root, ""
+		t.cursor = 0
+		t.rebuild()
+		m.preview.scrollPos = 0
+		m.setStatusMessage("Filter cleared", false)
+		return
+	}
+
+	results, err := runDataFilter(t.root, expr)
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Filter error: %s", err), true)
+		return
+	}
+	if len(results) == 0 {
+		m.setStatusMessage(fmt.Sprintf("No results for %s", expr), false)
+		return
+	}
+
+	if len(results) == 1 {
+		t.view = results[0]
+	} else {
+		// Results keep their real parents so their document paths stay correct
+		t.view = newDataNode(dataArray, "")
+		t.view.children = results
+	}
+	t.view.collapsed = false
+	t.filter = expr
+	t.cursor = 0
+	t.rebuild()
+	m.preview.scrollPos = 0
+	m.setStatusMessage(fmt.Sprintf("Filter %s: %d result(s) (. then Enter: clear)", expr, len(results)), false)
+}
+
+// dataPathOp is one step of a filter path
+type dataPathOp struct {
+	kind  byte // 'f' field, 'i' index, 'e' each ([]), 'r' recurse (..)
+	field string
+	index int
+}
+
+// runDataFilter evaluates a jq-like filter against root. Supported:
+// paths (.a.b, ."key", ["key"], [2], [-1], [], ..), pipes (|), keys, length
+// and select(path), select(path op literal) with ==, !=, <, <=, >, >=.
+func runDataFilter(root *dataNode, expr string) ([]*dataNode, error) {
+	nodes := []*dataNode{root}
+	for _, stage := range splitDataFilter(expr, '|') {
+		stage = strings.TrimSpace(stage)
+		var out []*dataNode
+		switch {
+		case stage == "keys":
+			for _, n := range nodes {
+				if !n.isContainer() {
+					return nil, fmt.Errorf("%s has no keys", n.path())
+				}
+				list := newDataNode(dataArray, "")
+				for _, c := range n.children {
+					if n.kind == dataObject {
+						list.add("", newDataNode(dataString, c.key))
+					} else {
+						list.add("", newDataNode(dataNumber, strconv.Itoa(c.index)))
+					}
+				}
+				out = append(out, list)
+			}
+		case stage == "length":
+			for _, n := range nodes {
+				length := len(n.children)
+				switch n.kind {
+				case dataString:
+					length = len([]rune(n.value))
+				case dataNull:
+					length = 0
+				case dataNumber, dataBool:
+					return nil, fmt.Errorf("%s has no length", n.path())
+				}
+				out = append(out, newDataNode(dataNumber, strconv.Itoa(length)))
+			}
+		case strings.HasPrefix(stage, "select(") && strings.HasSuffix(stage, ")"):
+			cond, err := parseDataCondition(stage[len("select(") : len(stage)-1])
+			if err != nil {
+				return nil, err
+			}
+			for _, n := range nodes {
+				if cond(n) {
+					out = append(out, n)
+				}
+			}
+		default:
+			ops, err := parseDataPath(stage)
+			if err != nil {
+				return nil, err
+			}
+			for _, n := range nodes {
+				out = append(out, applyDataPath(n, ops)...)
+			}
+		}
+		nodes = out
+	}
+	return nodes, nil
+}
+
+// splitDataFilter splits s on sep outside quotes and parentheses
+func splitDataFilter(s string, sep byte) []string {
+	var parts []string
+	depth, start, inQuote := 0, 0, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && inQuote:
+			i++
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseDataPath parses a path expression such as .servers[2].port or .items[].name
+func parseDataPath(s string) ([]dataPathOp, error) {
+	if s == "" || (s[0] != '.' && s[0] != '[') {
+		return nil, fmt.Errorf("expected a path starting with '.': %s", s)
+	}
+
+	var ops []dataPathOp
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == ' ':
+			i++
+		case strings.HasPrefix(s[i:], ".."):
+			ops = append(ops, dataPathOp{kind: 'r'})
+			i += 2
+			if i < len(s) && s[i] != '.' && s[i] != '[' && s[i] != ' ' {
+				i-- // "..name" - let the name be parsed as a field
+			}
+		case s[i] == '.':
+			i++
+			if i < len(s) && s[i] == '"' {
+				quoted, err := strconv.QuotedPrefix(s[i:])
+				if err != nil {
+					return nil, fmt.Errorf("unterminated string in %s", s)
+				}
+				name, _ := strconv.Unquote(quoted)
+				ops = append(ops, dataPathOp{kind: 'f', field: name})
+				i += len(quoted)
+				continue
+			}
+			start := i
+			for i < len(s) && (s[i] == '_' || s[i] == '-' || s[i] == '$' || (s[i] >= 'a' && s[i] <= 'z') ||
+				(s[i] >= 'A' && s[i] <= 'Z') || (s[i] >= '0' && s[i] <= '9')) {
+				i++
+			}
+			if i > start {
+				ops = append(ops, dataPathOp{kind: 'f', field: s[start:i]})
+			}
+		case s[i] == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing ']' in %s", s)
+			}
+			inner := strings.TrimSpace(s[i+1 : i+end])
+			if strings.HasPrefix(inner, `"`) {
+				name, err := strconv.Unquote(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid key [%s]", inner)
+				}
+				ops = append(ops, dataPathOp{kind: 'f', field: name})
+				i += end + 1
+				continue
+			}
+			switch inner {
+			case "":
+				ops = append(ops, dataPathOp{kind: 'e'})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index [%s]", inner)
+				}
+				ops = append(ops, dataPathOp{kind: 'i', index: index})
+			}
+			i += end + 1
+		default:
+			return nil, fmt.Errorf("unexpected %q in %s", s[i], s)
+		}
+	}
+	return ops, nil
+}
+
+// applyDataPath returns the nodes a path selects from n (missing members select nothing)
+func applyDataPath(n *dataNode, ops []dataPathOp) []*dataNode {
+	nodes := []*dataNode{n}
+	for _, op := range ops {
+		var out []*dataNode
+		for _, cur := range nodes {
+			switch op.kind {
+			case 'f':
+				if cur.kind == dataObject {
+					for _, c := range cur.children {
+						if c.key == op.field {
+							out = append(out, c)
+							break
+						}
+					}
+				}
+			case 'i':
+				if cur.kind == dataArray {
+					index := op.index
+					if index < 0 {
+						index += len(cur.children)
+					}
+					if index >= 0 && index < len(cur.children) {
+						out = append(out, cur.children[index])
+					}
+				}
+			case 'e':
+				out = append(out, cur.children...)
+			case 'r':
+				var walk func(d *dataNode)
+				walk = func(d *dataNode) {
+					out = append(out, d)
+					for _, c := range d.children {
+						walk(c)
+					}
+				}
+				walk(cur)
+			}
+		}
+		nodes = out
+	}
+	return nodes
+}
+
+// parseDataCondition compiles the inside of select(...)
+func parseDataCondition(s string) (func(*dataNode) bool, error) {
+	s = strings.TrimSpace(s)
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		idx := indexOutsideQuotes(s, op)
+		if idx < 0 {
+			continue
+		}
+		ops, err := parseDataPath(strings.TrimSpace(s[:idx]))
+		if err != nil {
+			return nil, err
+		}
+		var literal interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(s[idx+len(op):])), &literal); err != nil {
+			return nil, fmt.Errorf("invalid literal in select: %s", strings.TrimSpace(s[idx+len(op):]))
+		}
+		return func(n *dataNode) bool {
+			for _, v := range applyDataPath(n, ops) {
+				if compareDataValue(v, op, literal) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	// No operator: keep nodes where the path exists and is not false/null
+	ops, err := parseDataPath(s)
+	if err != nil {
+		return nil, err
+	}
+	return func(n *dataNode) bool {
+		for _, v := range applyDataPath(n, ops) {
+			if v.kind != dataNull && !(v.kind == dataBool && v.value == "false") {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// indexOutsideQuotes finds sub in s, skipping quoted strings
+func indexOutsideQuotes(s, sub string) int {
+	inQuote := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && inQuote:
+			i++
+		case s[i] == '"':
+			inQuote = !inQuote
+		case !inQuote && strings.HasPrefix(s[i:], sub):
+			return i
+		}
+	}
+	return -1
+}
+
+// compareDataValue compares a node with a JSON literal
+func compareDataValue(n *dataNode, op string, literal interface{}) bool {
+	cmp := 0
+	switch lit := literal.(type) {
+	case string:
+		if n.kind != dataString {
+			return op == "!="
+		}
+		cmp = strings.Compare(n.value, lit)
+	case float64:
+		num, err := strconv.ParseFloat(n.value, 64)
+		if n.kind != dataNumber || err != nil {
+			return op == "!="
+		}
+		switch {
+		case num < lit:
+			cmp = -1
+		case num > lit:
+			cmp = 1
+		}
+	case bool:
+		if n.kind != dataBool {
+			return op == "!="
+		}
+		if n.value != strconv.FormatBool(lit) {
+			cmp = 1
+		}
+	case nil:
+		if n.kind != dataNull {
+			cmp = 1
+		}
+	default:
+		return false
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// dataTreeStatusText returns the tree's cursor path and position for info lines
+func (m model) dataTreeStatusText() string {
+	t := m.preview.tree
+	text := fmt.Sprintf("%s tree | %s | Row %d/%d", t.format, t.nodePath(t.current()), t.cursor+1, len(t.rows))
+	if t.filter != "" {
+		text += " | Filter: " + t.filter
+	}
+	return text
+}
+
+// renderDataTreePreview renders the visible rows of the tree
+func (m model) renderDataTreePreview(maxVisible int) string {
+	var s strings.Builder
+	t := m.preview.tree
+
+	var boxContentWidth int
+	if m.viewMode == viewFullPreview {
+		boxContentWidth = m.width - 6
+	} else if m.displayMode == modeDetail || m.isNarrowTerminal() {
+		boxContentWidth = m.width - 6
+	} else {
+		boxContentWidth = m.rightWidth - 2
+	}
+
+	totalRows := max(len(t.rows), 1)
+	targetLines := maxVisible
+	if m.viewMode == viewDualPane {
+		targetLines = maxVisible - 1
+	}
+	start := max(0, min(m.preview.scrollPos, totalRows-targetLines))
+
+	keyStyle := lipgloss.NewStyle().Foreground(currentTheme.Folder.adaptiveColor())
+	stringStyle := lipgloss.NewStyle().Foreground(currentTheme.DiffAdded.adaptiveColor())
+	numberStyle := lipgloss.NewStyle().Foreground(currentTheme.DiffHunkHeader.adaptiveColor())
+	literalStyle := lipgloss.NewStyle().Foreground(currentTheme.Agents.adaptiveColor())
+	summaryStyle := lipgloss.NewStyle().Foreground(uiMutedText())
+	cursorStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.SelectionFg.adaptiveColor()).
+		Background(currentTheme.SelectionBg.adaptiveColor())
+
+	linesRendered := 0
+	writeLine := func(line string) {
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(line)
+		linesRendered++
+	}
+
+	for i := start; i < len(t.rows) && linesRendered < targetLines; i++ {
+		row := t.rows[i]
+		n := row.node
+
+		// Label: member name, array index, or the full path for filter results
+		var label string
+		switch {
+		case row.depth == 0:
+			label = "."
+			if t.filter != "" {
+				label = t.filter
+			}
+		case row.depth == 1 && t.isResultList():
+			label = t.nodePath(n)
+		case n.parent != nil && n.parent.kind == dataArray:
+			label = fmt.Sprintf("[%d]", n.index)
+		default:
+			label = n.key
+		}
+
+		marker := "  "
+		if n.isContainer() && len(n.children) > 0 {
+			marker = "▾ "
+			if n.collapsed {
+				marker = "▸ "
+			}
+		}
+
+		var value string
+		switch n.kind {
+		case dataObject:
+			value = summaryStyle.Render(fmt.Sprintf("{%d}", len(n.children)))
+		case dataArray:
+			value = summaryStyle.Render(fmt.Sprintf("[%d]", len(n.children)))
+		case dataString:
+			value = stringStyle.Render(quoteJSON(n.value))
+		case dataNumber:
+			value = numberStyle.Render(n.value)
+		default:
+			value = literalStyle.Render(n.value)
+		}
+
+		labelText := marker + label
+		if i == t.cursor {
+			labelText = cursorStyle.Render(labelText)
+		} else {
+			labelText = marker + keyStyle.Render(label)
+		}
+
+		line := m.renderScrollbar(i-start, maxVisible, totalRows) + " "
+		line += strings.Repeat("  ", row.depth) + labelText + summaryStyle.Render(": ") + value
+		if visualWidth(line) > boxContentWidth {
+			line = truncateToWidth(line, boxContentWidth)
+		}
+		writeLine(line + "\033[0m")
+	}
+
+	if m.viewMode == viewDualPane {
+		scrollStyle := lipgloss.NewStyle().
+			Foreground(uiSubtleText()).
+			Italic(true)
+		for linesRendered < targetLines {
+			writeLine("\033[0m")
+		}
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(scrollStyle.Render(fmt.Sprintf(" %s [tree] ", t.nodePath(t.current()))))
+	} else {
+		for linesRendered < maxVisible {
+			writeLine("\033[0m")
+		}
+	}
+
+	return s.String()
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const dataTreeTestJSON = `{"name":"api","servers":[{"host":"a","port":80},{"host":"b","port":81},{"host":"c","port":8080,"tags":["x y"]}],"my key":null,"enabled":true}`
+
+// childKeys returns the member names (or indices) of n in order
+func childKeys(n *dataNode) string {
+	var keys []string
+	for _, c := range n.children {
+		if n.kind == dataArray {
+			keys = append(keys, "["+c.value+"]")
+		} else {
+			keys = append(keys, c.key)
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+// TestParseJSONTree tests that JSON parses in document order with correct kinds
+func TestParseJSONTree(t *testing.T) {
+	root, err := parseJSONTree([]byte(dataTreeTestJSON))
+	if err != nil {
+		t.Fatalf("parseJSONTree failed: %v", err)
+	}
+	if got := childKeys(root); got != "name,servers,my key,enabled" {
+		t.Errorf("Expected document key order, got %s", got)
+	}
+
+	servers := root.children[1]
+	if servers.kind != dataArray || len(servers.children) != 3 {
+		t.Fatalf("Expected servers array of 3, got kind %d with %d children", servers.kind, len(servers.children))
+	}
+	port := servers.children[2].children[1]
+	if port.kind != dataNumber || port.value != "8080" {
+		t.Errorf("Expected number 8080, got kind %d value %q", port.kind, port.value)
+	}
+	if root.children[2].kind != dataNull || root.children[3].kind != dataBool {
+		t.Error("Expected null and bool kinds")
+	}
+
+	if _, err := parseJSONTree([]byte(`{"a":1} {"b":2}`)); err == nil {
+		t.Error("Expected error for trailing data")
+	}
+	if _, err := parseJSONTree([]byte(`{"a":`)); err == nil {
+		t.Error("Expected error for truncated JSON")
+	}
+}
+
+// TestDataNodePath tests jq-style path rendering
+func TestDataNodePath(t *testing.T) {
+	root, _ := parseJSONTree([]byte(dataTreeTestJSON))
+
+	tests := []struct {
+		node *dataNode
+		want string
+	}{
+		{root, "."},
+		{root.children[0], ".name"},
+		{root.children[1].children[2].children[1], ".servers[2].port"},
+		{root.children[1].children[2].children[2].children[0], ".servers[2].tags[0]"},
+		{root.children[2], `["my key"]`},
+	}
+	for _, tt := range tests {
+		if got := tt.node.path(); got != tt.want {
+			t.Errorf("path() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+// TestParseYAMLTree tests YAML parsing, scalar typing, aliases and multiple documents
+func TestParseYAMLTree(t *testing.T) {
+	content := `defaults: &defaults
+  retries: 3
+  verbose: false
+services:
+  web:
+    <<: *defaults
+    image: nginx
+  db: *defaults
+empty: ~
+`
+	root, err := parseYAMLTree([]byte(content))
+	if err != nil {
+		t.Fatalf("parseYAMLTree failed: %v", err)
+	}
+	if got := childKeys(root); got != "defaults,services,empty" {
+		t.Errorf("Unexpected key order: %s", got)
+	}
+	retries := root.children[0].children[0]
+	if retries.kind != dataNumber || retries.value != "3" {
+		t.Errorf("Expected number 3, got %+v", retries)
+	}
+	if root.children[0].children[1].kind != dataBool || root.children[2].kind != dataNull {
+		t.Error("Expected bool and null kinds")
+	}
+	db := root.children[1].children[1]
+	if db.kind != dataObject || childKeys(db) != "retries,verbose" {
+		t.Errorf("Expected alias to expand, got %s", childKeys(db))
+	}
+
+	docs, err := parseYAMLTree([]byte("a: 1\n---\nb: 2\n"))
+	if err != nil {
+		t.Fatalf("parseYAMLTree failed: %v", err)
+	}
+	if docs.kind != dataArray || len(docs.children) != 2 {
+		t.Errorf("Expected 2 documents, got %d", len(docs.children))
+	}
+}
+
+// TestParseTOMLTree tests that TOML keys keep document order
+func TestParseTOMLTree(t *testing.T) {
+	content := `title = "demo"
+zeta = 1
+
+[owner]
+name = "me"
+age = 42
+
+[[servers]]
+port = 80
+host = "a"
+
+[[servers]]
+port = 81
+host = "b"
+`
+	root, err := parseTOMLTree([]byte(content))
+	if err != nil {
+		t.Fatalf("parseTOMLTree failed: %v", err)
+	}
+	if got := childKeys(root); got != "title,zeta,owner,servers" {
+		t.Errorf("Unexpected key order: %s", got)
+	}
+	if got := childKeys(root.children[2]); got != "name,age" {
+		t.Errorf("Unexpected owner key order: %s", got)
+	}
+	servers := root.children[3]
+	if servers.kind != dataArray || len(servers.children) != 2 {
+		t.Fatalf("Expected 2 servers, got %+v", servers)
+	}
+	if got := childKeys(servers.children[1]); got != "port,host" {
+		t.Errorf("Unexpected server key order: %s", got)
+	}
+	if port := servers.children[1].children[0]; port.path() != ".servers[1].port" || port.value != "81" {
+		t.Errorf("Unexpected port node %s = %s", port.path(), port.value)
+	}
+}
+
+// TestRunDataFilter tests the jq-like filter language
+func TestRunDataFilter(t *testing.T) {
+	root, _ := parseJSONTree([]byte(dataTreeTestJSON))
+
+	tests := []struct {
+		expr  string
+		paths []string // Paths (or values for computed results) of the results
+	}{
+		{".name", []string{".name"}},
+		{".servers[1].host", []string{".servers[1].host"}},
+		{".servers[-1].port", []string{".servers[2].port"}},
+		{".servers[].port", []string{".servers[0].port", ".servers[1].port", ".servers[2].port"}},
+		{`.["my key"]`, []string{`["my key"]`}},
+		{`."my key"`, []string{`["my key"]`}},
+		{".servers[] | select(.port > 80) | .host", []string{".servers[1].host", ".servers[2].host"}},
+		{`.servers[] | select(.host == "c")`, []string{".servers[2]"}},
+		{".servers[] | select(.tags)", []string{".servers[2]"}},
+		{"..port", []string{".servers[0].port", ".servers[1].port", ".servers[2].port"}},
+		{".missing", nil},
+		{".servers | length", []string{"3"}},
+	}
+
+	for _, tt := range tests {
+		results, err := runDataFilter(root, tt.expr)
+		if err != nil {
+			t.Errorf("runDataFilter(%q) error: %v", tt.expr, err)
+			continue
+		}
+		var got []string
+		for _, n := range results {
+			if n.parent == nil && n != root {
+				got = append(got, n.value)
+			} else {
+				got = append(got, n.path())
+			}
+		}
+		if strings.Join(got, " ") != strings.Join(tt.paths, " ") {
+			t.Errorf("runDataFilter(%q) = %v, want %v", tt.expr, got, tt.paths)
+		}
+	}
+
+	keys, err := runDataFilter(root, ".servers[0] | keys")
+	if err != nil || len(keys) != 1 || keys[0].jsonText() != "[\n  \"host\",\n  \"port\"\n]" {
+		t.Errorf("keys returned %v, %v", keys, err)
+	}
+
+	for _, bad := range []string{"servers", ".servers[x]", ".name | keys", "select(.a == nope)"} {
+		if _, err := runDataFilter(root, bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+// TestDataTreeRows tests folding, cursor movement and filter views
+func TestDataTreeRows(t *testing.T) {
+	root, _ := parseJSONTree([]byte(dataTreeTestJSON))
+	m := model{height: 40, width: 120, viewMode: viewFullPreview}
+	m.preview.loaded = true
+	m.preview.tree = newDataTreeState(root, "JSON")
+	tree := m.preview.tree
+
+	total := len(tree.rows)
+	if total != root.countNodes() {
+		t.Fatalf("Expected all %d nodes visible, got %d rows", root.countNodes(), total)
+	}
+
+	// Fold "servers" from its first child: left moves to the parent, then folds it
+	m.dataTreeMoveCursor(3) // .servers[0]
+	if path := tree.nodePath(tree.current()); path != ".servers[0]" {
+		t.Fatalf("Expected cursor on .servers[0], got %s", path)
+	}
+	m.dataTreeToggle(true)
+	m.dataTreeCollapseOrParent()
+	if path := tree.nodePath(tree.current()); path != ".servers" {
+		t.Fatalf("Expected cursor on .servers, got %s", path)
+	}
+	m.dataTreeCollapseOrParent()
+	if len(tree.rows) != 5 {
+		t.Errorf("Expected 5 rows with servers folded, got %d", len(tree.rows))
+	}
+
+	m.dataTreeSetAll(false)
+	if len(tree.rows) != total {
+		t.Errorf("Expected %d rows after expanding all, got %d", total, len(tree.rows))
+	}
+
+	// Filter with several results: rows show the results with their document paths
+	m.applyDataTreeFilter(".servers[].host")
+	if !tree.isResultList() || len(tree.rows) != 4 {
+		t.Fatalf("Expected a result list of 3, got %d rows", len(tree.rows))
+	}
+	if path := tree.nodePath(tree.rows[2].node); path != ".servers[1].host" {
+		t.Errorf("Expected result path .servers[1].host, got %s", path)
+	}
+	if tree.rows[2].node.value != "b" {
+		t.Errorf("Expected value b, got %s", tree.rows[2].node.value)
+	}
+
+	m.applyDataTreeFilter(".")
+	if tree.view != root || tree.filter != "" {
+		t.Error("Expected filter to be cleared")
+	}
+}
+
+// TestPrettyPrintJSON tests that only minified JSON is reformatted
+func TestPrettyPrintJSON(t *testing.T) {
+	minified := `{"items":[` + strings.Repeat(`{"id":1,"name":"item"},`, 20) + `{"id":2}]}`
+	pretty := string(prettyPrintJSON([]byte(minified)))
+	if !strings.Contains(pretty, "\n  \"items\": [") {
+		t.Errorf("Expected indented output, got %q", pretty[:40])
+	}
+
+	formatted := "{\n  \"a\": 1\n}\n"
+	if got := string(prettyPrintJSON([]byte(formatted))); got != formatted {
+		t.Error("Expected formatted JSON to be left alone")
+	}
+	invalid := strings.Repeat("x", 500)
+	if got := string(prettyPrintJSON([]byte(invalid))); got != invalid {
+		t.Error("Expected invalid JSON to be left alone")
+	}
+}
+
+// TestLoadPreviewDataTree tests that structured files open in the tree view
+func TestLoadPreviewDataTree(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "data.json")
+	os.WriteFile(jsonPath, []byte(dataTreeTestJSON), 0644)
+
+	m := &model{height: 40, width: 120, viewMode: viewFullPreview}
+	m.loadPreview(jsonPath)
+	if !m.dataTreeActive() || m.preview.tree.format != "JSON" {
+		t.Fatal("Expected JSON file to open in the tree view")
+	}
+	if m.getWrappedLineCount() != len(m.preview.tree.rows) {
+		t.Error("Expected line count to follow tree rows")
+	}
+
+	// Text view shows the file too, and isn't tree-rendered
+	m.toggleDataTreeText()
+	if m.dataTreeActive() || len(m.preview.content) == 0 {
+		t.Error("Expected text view after toggling")
+	}
+
+	// Invalid documents fall back to the text preview
+	badPath := filepath.Join(dir, "bad.yaml")
+	os.WriteFile(badPath, []byte("a: [1, 2\n"), 0644)
+	m.loadPreview(badPath)
+	if m.preview.tree != nil || len(m.preview.content) == 0 {
+		t.Error("Expected invalid YAML to use the text preview")
+	}
+}
@@ -0,0 +1,630 @@
+package main
+
+// Module: dbbrowser.go
+// Purpose: SQLite database browser in the preview pane
+// Responsibilities:
+// - Listing tables, views, indexes and triggers with their schemas
+// - Browsing rows in a paged grid (rowid tables read only the visible rows)
+// - Running read-only SQL queries in the background and showing the results
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	dbSampleRows  = 200 // Rows sampled to size the grid columns
+	dbCacheRows   = 500 // Paged rows cached around the viewport
+	dbMaxColWidth = 40
+	dbMinColWidth = 4
+	dbScrollStep  = 4
+)
+
+// dbBrowserState is the database preview: the schema list, or a grid of rows
+type dbBrowserState struct {
+	db          *sqliteDB
+	cursor      int // Selected schema object
+	savedScroll int // Schema list scroll position, restored when leaving a grid
+	grid        *dbGrid
+	lastQuery   string
+	busy        string // Task running in the background ("" = idle)
+	gen         atomic.Int64
+}
+
+// dbGrid is a table's rows or a query result
+type dbGrid struct {
+	title   string // Object name or the query
+	sql     string // Query the grid shows (pre-fills the SQL prompt)
+	columns []string
+	widths  []int
+	scrollX int
+
+	// Rowid tables are paged from their leaf pages (the rowid is shown in the gutter)
+	obj        *sqliteObject
+	leaves     []sqliteLeaf
+	total      int
+	cacheStart int
+	cache      [][]sqlValue // Rows with the rowid appended
+
+	// Query results, views, indexes and WITHOUT ROWID tables are held in memory
+	rows      [][]sqlValue
+	truncated bool
+}
+
+// dbSchemaLine is one line of the schema list
+type dbSchemaLine struct {
+	obj    int // Index into the schema
+	text   string
+	header bool
+}
+
+// dbBrowserMsg delivers an opened table or a query result
+type dbBrowserMsg struct {
+	path string
+	gen  int64
+	grid *dbGrid
+	err  error
+}
+
+// openDBBrowser shows path in the database browser. Returns false if it can't be read.
+func (m *model) openDBBrowser(path string) bool {
+	db, err := openSQLiteDB(path)
+	if err != nil {
+		return false
+	}
+	m.preview.db = &dbBrowserState{db: db}
+	m.preview.content = nil
+	m.preview.loaded = true
+	return true
+}
+
+// paged reports whether the grid reads rows from a table's leaf pages
+func (g *dbGrid) paged() bool {
+	return g.obj != nil
+}
+
+// count returns the number of rows in the grid
+func (g *dbGrid) count() int {
+	if g.paged() {
+		return g.total
+	}
+	return len(g.rows)
+}
+
+// window returns up to n rows starting at start. Paged rows carry the rowid last.
+func (g *dbGrid) window(db *sqliteDB, start, n int) ([][]sqlValue, error) {
+	if !g.paged() {
+		end := min(len(g.rows), start+n)
+		if start >= end {
+			return nil, nil
+		}
+		return g.rows[start:end], nil
+	}
+
+	end := min(g.total, start+n)
+	if start >= end {
+		return nil, nil
+	}
+	if start < g.cacheStart || end > g.cacheStart+len(g.cache) {
+		// Read a block around the viewport so scrolling rarely touches the disk
+		from := max(0, start-dbCacheRows/2)
+		c, err := db.connect()
+		if err != nil {
+			return nil, err
+		}
+		defer c.Close()
+		var rows [][]sqlValue
+		err = c.tableRowsAt(g.leaves, from, max(dbCacheRows, end-from), func(rowid int64, rec []sqlValue) {
+			rows = append(rows, append(g.obj.tableRow(rowid, rec), rowid))
+		})
+		if err != nil {
+			return nil, err
+		}
+		g.cacheStart, g.cache = from, rows
+	}
+	lo := start - g.cacheStart
+	hi := min(len(g.cache), end-g.cacheStart)
+	if lo >= hi {
+		return nil, nil
+	}
+	return g.cache[lo:hi], nil
+}
+
+// computeWidths sizes the columns from the header and the first rows
+func (g *dbGrid) computeWidths(db *sqliteDB) {
+	rows, _ := g.window(db, 0, dbSampleRows)
+	g.widths = make([]int, len(g.columns))
+	for j, name := range g.columns {
+		g.widths[j] = runewidth.StringWidth(csvCellText(name))
+	}
+	for _, row := range rows {
+		for j := range g.widths {
+			if j < len(row) {
+				g.widths[j] = max(g.widths[j], runewidth.StringWidth(csvCellText(formatSQLValue(row[j]))))
+			}
+		}
+	}
+	for j := range g.widths {
+		g.widths[j] = max(dbMinColWidth, min(dbMaxColWidth, g.widths[j]))
+	}
+}
+
+// tableWidth returns the width of a formatted grid row
+func (g *dbGrid) tableWidth() int {
+	width := 0
+	for _, w := range g.widths {
+		width += w + 3 // " │ "
+	}
+	return max(0, width-3)
+}
+
+// dbOpenObjectCmd opens the selected table, view or index as a grid in the background
+func (m *model) dbOpenObjectCmd() tea.Cmd {
+	b := m.preview.db
+	if b.cursor >= len(b.db.schema) {
+		return nil
+	}
+	obj := b.db.schema[b.cursor]
+	switch obj.kind {
+	case "table", "view", "index":
+	default:
+		m.setStatusMessage(fmt.Sprintf("%s %s has no rows to browse", strings.ToUpper(obj.kind[:1])+obj.kind[1:], obj.name), false)
+		return nil
+	}
+
+	gen := b.gen.Add(1)
+	b.busy = "Opening " + obj.name
+	db := b.db
+	cancelled := func() bool { return b.gen.Load() != gen }
+	sql := fmt.Sprintf("SELECT * FROM %s", sqlQuoteIdent(obj.name))
+
+	return func() tea.Msg {
+		msg := dbBrowserMsg{path: db.path, gen: gen}
+		if obj.kind == "table" && !obj.withoutRowid && !obj.virtual && obj.root != 0 && len(obj.columns) > 0 {
+			// Rowid table: list its leaf pages so any row can be read directly
+			c, err := db.connect()
+			if err != nil {
+				msg.err = err
+				return msg
+			}
+			defer c.Close()
+			g := &dbGrid{title: obj.name, sql: sql, obj: obj, columns: obj.columnNames()}
+			if g.leaves, g.total, msg.err = c.tableLeaves(obj.root, cancelled); msg.err != nil {
+				return msg
+			}
+			g.computeWidths(db)
+			msg.grid = g
+			return msg
+		}
+
+		res, err := db.query(sql, sqlMaxResultRows, cancelled)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		msg.grid = &dbGrid{title: obj.name, sql: sql, columns: res.columns, rows: res.rows, truncated: res.truncated}
+		msg.grid.computeWidths(db)
+		return msg
+	}
+}
+
+// dbQueryCmd runs a SQL query in the background
+func (m *model) dbQueryCmd(sql string) tea.Cmd {
+	b := m.preview.db
+	sql = strings.TrimSpace(sql)
+	if sql == "" {
+		return nil
+	}
+	b.lastQuery = sql
+	gen := b.gen.Add(1)
+	b.busy = "Running query"
+	db := b.db
+	cancelled := func() bool { return b.gen.Load() != gen }
+
+	return func() tea.Msg {
+		msg := dbBrowserMsg{path: db.path, gen: gen}
+		res, err := db.query(sql, sqlMaxResultRows, cancelled)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		msg.grid = &dbGrid{title: sql, sql: sql, columns: res.columns, rows: res.rows, truncated: res.truncated}
+		msg.grid.computeWidths(db)
+		return msg
+	}
+}
+
+// sqlQuoteIdent quotes a table name for a generated query
+func sqlQuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// applyDBBrowserMsg shows an opened table or query result (stale results are ignored)
+func (m *model) applyDBBrowserMsg(msg dbBrowserMsg) tea.Cmd {
+	b := m.preview.db
+	if b == nil || b.db.path != msg.path || b.gen.Load() != msg.gen {
+		return nil
+	}
+	b.busy = ""
+	if msg.err != nil {
+		m.setStatusMessage(fmt.Sprintf("SQLite: %v", msg.err), true)
+		return nil
+	}
+	if b.grid == nil {
+		b.savedScroll = m.preview.scrollPos
+	}
+	b.grid = msg.grid
+	m.preview.scrollPos = 0
+	if msg.grid.truncated {
+		m.setStatusMessage(fmt.Sprintf("Showing the first %d rows - add a WHERE or LIMIT to narrow the query", sqlMaxResultRows), false)
+	}
+	return nil
+}
+
+// dbBack leaves the grid and returns to the schema list
+func (m *model) dbBack() {
+	b := m.preview.db
+	b.gen.Add(1) // Cancel anything still running
+	b.busy = ""
+	b.grid = nil
+	m.preview.scrollPos = b.savedScroll
+}
+
+// dbOpenQueryPrompt opens the SQL prompt, pre-filled with the current query
+func (m *model) dbOpenQueryPrompt() {
+	b := m.preview.db
+	m.preview.gotoActive = true
+	switch {
+	case b.grid != nil:
+		m.preview.gotoInput = b.grid.sql
+	case b.lastQuery != "":
+		m.preview.gotoInput = b.lastQuery
+	case b.cursor < len(b.db.schema):
+		m.preview.gotoInput = fmt.Sprintf("SELECT * FROM %s LIMIT 100", sqlQuoteIdent(b.db.schema[b.cursor].name))
+	default:
+		m.preview.gotoInput = "SELECT * FROM sqlite_schema"
+	}
+}
+
+// copyDBSchema copies the CREATE statement of the selected object
+// Used by: keyboard ("y" in the database browser)
+func (m *model) copyDBSchema() {
+	b := m.preview.db
+	if b.cursor >= len(b.db.schema) {
+		return
+	}
+	obj := b.db.schema[b.cursor]
+	if err := copyToClipboard(obj.sql); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Failed to copy schema: %s", err), true)
+		return
+	}
+	m.setStatusMessage(fmt.Sprintf("✓ Copied schema of %s", obj.name), false)
+}
+
+// dbSchemaLines lists the schema objects, each followed by its SQL (wrapped to the pane)
+func (m model) dbSchemaLines() []dbSchemaLine {
+	b := m.preview.db
+	width := max(20, m.previewBoxWidth()-6)
+	var lines []dbSchemaLine
+	for i, obj := range b.db.schema {
+		lines = append(lines, dbSchemaLine{obj: i, header: true})
+		sql := obj.sql
+		if sql == "" {
+			sql = "(created automatically for a UNIQUE or PRIMARY KEY constraint)"
+		}
+		for _, line := range strings.Split(strings.ReplaceAll(sql, "\t", "    "), "\n") {
+			line = strings.TrimRight(line, " \r")
+			indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+			if len(indent) > width/2 {
+				indent = indent[:width/2]
+			}
+			for _, part := range wrapLine(strings.TrimLeft(line, " "), width-len(indent)) {
+				lines = append(lines, dbSchemaLine{obj: i, text: "    " + indent + part})
+			}
+		}
+	}
+	return lines
+}
+
+// dbMoveCursor selects another schema object and scrolls its schema into view
+func (m *model) dbMoveCursor(delta int) {
+	b := m.preview.db
+	if len(b.db.schema) == 0 {
+		return
+	}
+	b.cursor = max(0, min(len(b.db.schema)-1, b.cursor+delta))
+
+	lines := m.dbSchemaLines()
+	first, last := -1, -1
+	for i, line := range lines {
+		if line.obj == b.cursor {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	visible := m.getPreviewVisibleLines()
+	if m.viewMode == viewDualPane {
+		visible-- // Position indicator line
+	}
+	if first < m.preview.scrollPos {
+		m.preview.scrollPos = first
+	} else if visible > 0 && last >= m.preview.scrollPos+visible {
+		m.preview.scrollPos = max(0, min(first, last-visible+1))
+	}
+}
+
+// dbGridLayout returns the gutter width (scrollbar + row numbers) and the width left for cells
+func (m model) dbGridLayout() (numWidth, cellWidth int) {
+	g := m.preview.db.grid
+	numWidth = 3
+	if g.paged() {
+		numWidth = len("rowid")
+	}
+	if g.paged() && len(g.cache) > 0 {
+		// Rowids can be far larger than the row count
+		numWidth = max(numWidth, len(formatSQLValue(g.cache[len(g.cache)-1][len(g.columns)])))
+	}
+	numWidth = max(numWidth, len(strconv.Itoa(g.count())))
+	cellWidth = max(10, m.previewBoxWidth()-numWidth-5) // scrollbar + space + number + " │ "
+	return numWidth, cellWidth
+}
+
+// dbScrollBy scrolls the grid horizontally
+func (m *model) dbScrollBy(delta int) {
+	g := m.preview.db.grid
+	_, cellWidth := m.dbGridLayout()
+	g.scrollX = max(0, min(max(0, g.tableWidth()-cellWidth), g.scrollX+delta))
+}
+
+// dbBrowserLineCount returns the number of lines the browser renders (for scrolling)
+func (m model) dbBrowserLineCount() int {
+	b := m.preview.db
+	if b.grid != nil {
+		return b.grid.count() + 2 // Header + separator
+	}
+	return len(m.dbSchemaLines())
+}
+
+// dbBrowserStatusText returns the browser's summary for info lines
+func (m model) dbBrowserStatusText(lastVisibleLine int) string {
+	b := m.preview.db
+	var text string
+	if g := b.grid; g != nil {
+		total := strconv.Itoa(g.count())
+		if g.truncated {
+			total += "+"
+		}
+		text = fmt.Sprintf("SQLite | %s | %d columns | Row %d/%s",
+			truncateToWidth(strings.Join(strings.Fields(g.title), " "), 40), len(g.columns),
+			max(0, min(lastVisibleLine-2, g.count())), total)
+	} else {
+		counts := make(map[string]int)
+		for _, obj := range b.db.schema {
+			counts[obj.kind]++
+		}
+		var parts []string
+		for _, kind := range []string{"table", "view", "index", "trigger"} {
+			if n := counts[kind]; n > 0 {
+				label := kind + "s"
+				if kind == "index" {
+					label = "indexes"
+				}
+				if n == 1 {
+					label = kind
+				}
+				parts = append(parts, fmt.Sprintf("%d %s", n, label))
+			}
+		}
+		if len(parts) == 0 {
+			parts = append(parts, "empty")
+		}
+		text = fmt.Sprintf("SQLite | %s | %d pages of %s", strings.Join(parts, ", "),
+			b.db.pageCount, formatFileSize(int64(b.db.pageSize)))
+		if b.db.walFrames > 0 {
+			text += fmt.Sprintf(" | WAL: %d frames", b.db.walFrames)
+		}
+	}
+	if b.busy != "" {
+		text += " | " + b.busy + "..."
+	}
+	return text
+}
+
+// renderDBBrowserPreview renders the schema list or the grid
+func (m model) renderDBBrowserPreview(maxVisible int) string {
+	if m.preview.db.grid != nil {
+		return m.renderDBGrid(maxVisible)
+	}
+
+	var s strings.Builder
+	b := m.preview.db
+	lines := m.dbSchemaLines()
+	width := m.previewBoxWidth()
+
+	targetLines := maxVisible
+	if m.viewMode == viewDualPane {
+		targetLines = maxVisible - 1
+	}
+	start := max(0, min(m.preview.scrollPos, len(lines)-targetLines))
+
+	nameStyle := lipgloss.NewStyle().Bold(true)
+	kindStyles := map[string]lipgloss.Style{
+		"table":   lipgloss.NewStyle().Foreground(currentTheme.Folder.adaptiveColor()),
+		"view":    lipgloss.NewStyle().Foreground(currentTheme.DiffAdded.adaptiveColor()),
+		"index":   lipgloss.NewStyle().Foreground(currentTheme.DiffHunkHeader.adaptiveColor()),
+		"trigger": lipgloss.NewStyle().Foreground(currentTheme.Agents.adaptiveColor()),
+	}
+	mutedStyle := lipgloss.NewStyle().Foreground(uiMutedText())
+	sqlStyle := lipgloss.NewStyle().Foreground(uiSubtleText())
+	cursorStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.SelectionFg.adaptiveColor()).
+		Background(currentTheme.SelectionBg.adaptiveColor())
+
+	linesRendered := 0
+	writeLine := func(line string) {
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(line)
+		linesRendered++
+	}
+
+	if len(lines) == 0 {
+		writeLine(mutedStyle.Render("  (empty database - no tables)"))
+	}
+	for i := start; i < len(lines) && linesRendered < targetLines; i++ {
+		line := lines[i]
+		if !line.header {
+			writeLine(sqlStyle.Render(line.text))
+			continue
+		}
+
+		obj := b.db.schema[line.obj]
+		var detail string
+		switch obj.kind {
+		case "table":
+			detail = fmt.Sprintf("%d columns", len(obj.columns))
+			if obj.withoutRowid {
+				detail += ", without rowid"
+			}
+			if obj.virtual {
+				detail = "virtual table"
+			}
+		case "index", "trigger":
+			detail = "on " + obj.table
+		}
+
+		if line.obj == b.cursor {
+			text := fmt.Sprintf("▸ %-8s %s  %s", obj.kind, obj.name, detail)
+			text = truncateToWidth(text, width-2)
+			writeLine(" " + cursorStyle.Render(text+strings.Repeat(" ", max(0, width-2-visualWidth(text)))))
+			continue
+		}
+		kindStyle, ok := kindStyles[obj.kind]
+		if !ok {
+			kindStyle = mutedStyle
+		}
+		text := "  " + kindStyle.Render(fmt.Sprintf("%-8s", obj.kind)) + " " + nameStyle.Render(obj.name) + "  " + mutedStyle.Render(detail)
+		writeLine(" " + truncateToWidth(text, width-2))
+	}
+
+	for linesRendered < targetLines {
+		writeLine("")
+	}
+
+	if m.viewMode == viewDualPane {
+		scrollStyle := lipgloss.NewStyle().
+			Foreground(uiSubtleText()).
+			Italic(true)
+		s.WriteString("\n")
+		s.WriteString(scrollStyle.Render(fmt.Sprintf(" object %d/%d [sqlite] ", min(b.cursor+1, len(b.db.schema)), len(b.db.schema))))
+	}
+	return s.String()
+}
+
+// renderDBGrid renders the column header and the visible rows of the grid
+func (m model) renderDBGrid(maxVisible int) string {
+	var s strings.Builder
+	b := m.preview.db
+	g := b.grid
+
+	targetLines := maxVisible
+	if m.viewMode == viewDualPane {
+		targetLines = maxVisible - 1
+	}
+	dataLines := max(1, targetLines-2) // Header + separator stay on screen
+	total := g.count()
+	start := max(0, min(m.preview.scrollPos, total-dataLines))
+	rows, err := g.window(b.db, start, dataLines)
+	numWidth, cellWidth := m.dbGridLayout()
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Folder.adaptiveColor())
+	gutterStyle := lipgloss.NewStyle().Foreground(uiSubtleText())
+	mutedStyle := lipgloss.NewStyle().Foreground(uiMutedText())
+
+	linesRendered := 0
+	writeLine := func(line string) {
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(line)
+		linesRendered++
+	}
+	gutter := func(text string) string {
+		return gutterStyle.Render(fmt.Sprintf("%*s", numWidth, text)) + mutedStyle.Render(" │ ")
+	}
+
+	gutterTitle := "#"
+	if g.paged() {
+		gutterTitle = "rowid"
+	}
+	headerLine := formatTableRow(g.columns, g.widths, func(int) bool { return false }, func(_ int, text string) string {
+		return headerStyle.Render(text)
+	})
+	writeLine("  " + gutter(runewidth.Truncate(gutterTitle, numWidth, "")) + m.extractVisibleColumns(headerLine, g.scrollX, cellWidth))
+
+	var sep strings.Builder
+	for j, width := range g.widths {
+		if j > 0 {
+			sep.WriteString("─┼─")
+		}
+		sep.WriteString(strings.Repeat("─", width))
+	}
+	writeLine("  " + mutedStyle.Render(strings.Repeat("─", numWidth)+"─┼─") +
+		m.extractVisibleColumns(mutedStyle.Render(sep.String()), g.scrollX, cellWidth))
+
+	if err != nil {
+		writeLine(fmt.Sprintf("Error reading database: %v", err))
+	} else if total == 0 {
+		writeLine(mutedStyle.Render("  (no rows)"))
+	}
+	for i, row := range rows {
+		cells := make([]string, len(g.columns))
+		for j := range cells {
+			if j < len(row) {
+				cells[j] = formatSQLValue(row[j])
+			}
+		}
+		numeric := func(j int) bool {
+			switch row[j].(type) {
+			case int64, float64:
+				return true
+			}
+			return false
+		}
+		styled := formatTableRow(cells, g.widths, numeric, func(j int, text string) string {
+			if j < len(row) && row[j] == nil {
+				return mutedStyle.Render(text)
+			}
+			return text
+		})
+
+		label := strconv.Itoa(start + i + 1)
+		if g.paged() {
+			label = formatSQLValue(row[len(g.columns)])
+		}
+		line := m.renderScrollbar(i, dataLines, total) + " " + gutter(label)
+		writeLine(line + m.extractVisibleColumns(styled, g.scrollX, cellWidth) + "\033[0m")
+	}
+
+	for linesRendered < targetLines {
+		writeLine("\033[0m")
+	}
+
+	if m.viewMode == viewDualPane {
+		scrollStyle := lipgloss.NewStyle().
+			Foreground(uiSubtleText()).
+			Italic(true)
+		s.WriteString("\n")
+		s.WriteString(scrollStyle.Render(fmt.Sprintf(" row %d/%d [sqlite] ", min(start+1, total), total)))
+	}
+	return s.String()
+}
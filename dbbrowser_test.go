@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runDBCmd runs a browser command synchronously and applies its result
+func runDBCmd(t *testing.T, m *model, cmd tea.Cmd) {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("Expected a command")
+	}
+	msg, ok := cmd().(dbBrowserMsg)
+	if !ok {
+		t.Fatal("Expected dbBrowserMsg")
+	}
+	m.applyDBBrowserMsg(msg)
+}
+
+// TestDBBrowserSchemaAndGrid tests the schema list, table paging and going back
+func TestDBBrowserSchemaAndGrid(t *testing.T) {
+	path := createTestSQLiteDB(t, sqliteTestSchema)
+	m := &model{height: 30, width: 100, viewMode: viewFullPreview, previewMouseEnabled: true}
+	m.loadPreview(path)
+	b := m.preview.db
+	if b == nil {
+		t.Fatal("Expected the database to open in the browser")
+	}
+
+	out := m.renderPreview(20)
+	for _, want := range []string{"users", "CREATE INDEX idx_users_age", "without rowid", "adults"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected schema list to contain %q", want)
+		}
+	}
+	if !strings.Contains(m.dbBrowserStatusText(0), "2 tables, 1 view, 1 index") {
+		t.Errorf("Unexpected status %q", m.dbBrowserStatusText(0))
+	}
+
+	// Rowid tables page from disk, with the rowid in the gutter
+	m.preview.scrollPos = 5
+	runDBCmd(t, m, m.dbOpenObjectCmd())
+	g := b.grid
+	if g == nil || !g.paged() || g.count() != 2000 {
+		t.Fatalf("Expected a paged grid of 2000 rows, got %+v", g)
+	}
+	if m.getWrappedLineCount() != 2002 {
+		t.Errorf("Expected 2002 lines, got %d", m.getWrappedLineCount())
+	}
+	m.preview.scrollPos = 1990
+	out = m.renderPreview(12)
+	if !strings.Contains(out, "user2000") || strings.Contains(out, "user1980") {
+		t.Error("Expected the last rows to be rendered")
+	}
+
+	m.dbBack()
+	if b.grid != nil || m.preview.scrollPos != 5 {
+		t.Errorf("Expected schema list at the saved scroll position, got %d", m.preview.scrollPos)
+	}
+
+	// Views are run and held in memory
+	m.dbMoveCursor(3)
+	runDBCmd(t, m, m.dbOpenObjectCmd())
+	if g := b.grid; g == nil || g.paged() || g.count() != 1587 || strings.Join(g.columns, ",") != "name,age" {
+		t.Errorf("Unexpected view grid %+v", b.grid)
+	}
+}
+
+// TestDBBrowserQueryPrompt tests typing and running a query from the SQL prompt
+func TestDBBrowserQueryPrompt(t *testing.T) {
+	path := createTestSQLiteDB(t, sqliteTestSchema)
+	var tm tea.Model = model{height: 30, width: 100, viewMode: viewFullPreview}
+	m := tm.(model)
+	m.loadPreview(path)
+
+	tm, _ = m.handleKeyEvent(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".")})
+	m = tm.(model)
+	if !m.preview.gotoActive || m.preview.gotoInput != `SELECT * FROM "users" LIMIT 100` {
+		t.Fatalf("Expected SQL prompt pre-filled for the selected table, got %q", m.preview.gotoInput)
+	}
+
+	m.preview.gotoInput = "SELECT name, age FROM kv"
+	tm, cmd := m.handleKeyEvent(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(model)
+	msg := cmd().(dbBrowserMsg)
+	m.applyDBBrowserMsg(msg)
+	if msg.err == nil || m.preview.db.grid != nil {
+		t.Fatal("Expected an error for a missing column")
+	}
+
+	// Spaces are typed into the prompt
+	m.preview.gotoActive = true
+	m.preview.gotoInput = ""
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("SELECT")},
+		{Type: tea.KeySpace, Runes: []rune(" ")},
+		{Type: tea.KeyRunes, Runes: []rune("k")},
+		{Type: tea.KeySpace, Runes: []rune(" ")},
+		{Type: tea.KeyRunes, Runes: []rune("FROM kv WHERE v > 2")},
+	} {
+		tm, _ = m.handleKeyEvent(key)
+		m = tm.(model)
+	}
+	tm, cmd = m.handleKeyEvent(tea.KeyMsg{Type: tea.KeyEnter})
+	m = tm.(model)
+	runDBCmd(t, &m, cmd)
+	g := m.preview.db.grid
+	// Text sorts after numbers, so 'one' > 2 as in SQLite
+	if g == nil || g.count() != 2 || g.rows[0][0] != "a" || g.rows[1][0] != "c" {
+		t.Fatalf("Expected two result rows, got %+v", g)
+	}
+	if !strings.Contains(m.renderPreview(10), "c") {
+		t.Error("Expected result to be rendered")
+	}
+}
+
+// TestDBBrowserNotSQLite tests that other .db files keep the regular preview
+func TestDBBrowserNotSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	os.WriteFile(path, []byte("\x00\x01not sqlite"), 0644)
+
+	m := &model{height: 30, width: 100, viewMode: viewFullPreview}
+	m.loadPreview(path)
+	if m.preview.db != nil || !m.preview.isBinary {
+		t.Error("Expected a non-SQLite .db file to use the binary preview")
+	}
+}
@@ -15,6 +15,10 @@ func (m model) renderDialog() string {
 		return m.renderConfirmDialog()
 	case dialogSettings:
 		return m.renderSettingsPanel()
+	case dialogCommit:
+		return m.renderCommitDialog()
+	case dialogBranches:
+		return m.renderBranchDialog()
 	default:
 		return ""
 	}
@@ -105,7 +109,11 @@ func (m model) renderConfirmDialog() string {
 	content.WriteString("\n\n")
 	content.WriteString(messageStyle.Render(m.dialog.message))
 	content.WriteString("\n\n")
-	content.WriteString(hintStyle.Render("[Y]es / [N]o / [Esc]"))
+	hint := m.dialog.hint
+	if hint == "" {
+		hint = "[Y]es / [N]o / [Esc]"
+	}
+	content.WriteString(hintStyle.Render(hint))
 
 	return borderStyle.Render(content.String())
 }
@@ -125,6 +133,14 @@ func (m model) getDialogPosition() (int, int) {
 		items := settingsByCategory(m.settingsCategory)
 		dialogHeight = len(items) + 10 // items + title + tabs + separator + hints + padding
 	}
+	if m.dialog.dialogType == dialogCommit {
+		dialogWidth = m.commitDialogWidth() + 2
+		dialogHeight = m.commitDialogHeight()
+	}
+	if m.dialog.dialogType == dialogBranches {
+		dialogWidth = m.branchDialogWidth() + 2
+		dialogHeight = m.branchDialogHeight()
+	}
 
 	x := (m.width - dialogWidth) / 2
 	y := (m.height - dialogHeight) / 2
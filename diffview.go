@@ -0,0 +1,344 @@
+package main
+
+// Module: diffview.go
+// Purpose: Layout of git diffs in the preview
+// Responsibilities:
+// - Word-level highlighting of the changes between paired removed/added lines
+// - Side-by-side layout with old and new columns aligned by hunk
+// - Falling back to the unified layout when the pane is too narrow
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	diffSideBySideMinWidth = 90  // Narrower panes show the unified diff
+	diffWordMaxTokens      = 400 // Longer lines skip word highlighting
+	diffLineNumWidth       = 5   // Line number gutter in side-by-side columns
+)
+
+// diffUseSideBySide reports whether diffs are laid out side by side at the given width
+func (m model) diffUseSideBySide(width int) bool {
+	return m.diffSideBySide && width >= diffSideBySideMinWidth
+}
+
+// toggleDiffSideBySide switches diffs between the unified and side-by-side layouts
+func (m *model) toggleDiffSideBySide() {
+	m.diffSideBySide = !m.diffSideBySide
+	m.preview.scrollPos = 0 // Line counts differ between the layouts
+	switch {
+	case !m.diffSideBySide:
+		m.setStatusMessage("Unified diff", false)
+	case !m.diffUseSideBySide(m.diffWrapWidth()):
+		m.setStatusMessage("Side-by-side diff (pane too narrow, showing unified)", false)
+	default:
+		m.setStatusMessage("Side-by-side diff", false)
+	}
+}
+
+// diffDisplayLines lays out diff lines (git diff or git show output) for the preview:
+// one styled string per screen line, unified or side by side
+func (m model) diffDisplayLines(rawLines []string) []string {
+	width := m.diffWrapWidth()
+	if m.diffUseSideBySide(width) {
+		return sideBySideDiffLines(rawLines, width)
+	}
+	return unifiedDiffLines(rawLines, width)
+}
+
+// diffLineStyle returns the style of a diff line category (classifyDiffLine)
+func diffLineStyle(kind int) (lipgloss.Style, bool) {
+	switch kind {
+	case 1:
+		return diffAddedStyle, true
+	case 2:
+		return diffRemovedStyle, true
+	case 3:
+		return diffHunkHeaderStyle, true
+	case 4:
+		return diffMetaStyle, true
+	}
+	return lipgloss.Style{}, false
+}
+
+// styleWrapped wraps a line to width and styles every piece with its category's style
+func styleWrapped(line string, kind, width int) []string {
+	wrapped := wrapLine(line, width)
+	if style, ok := diffLineStyle(kind); ok {
+		for i, w := range wrapped {
+			wrapped[i] = style.Render(w)
+		}
+	}
+	return wrapped
+}
+
+// pairChangedLines pairs each run of removed lines with the run of added lines right
+// after it (first with first, ...); partner[i] is the paired line's index or -1
+func pairChangedLines(rawLines []string) []int {
+	partner := make([]int, len(rawLines))
+	for i := range partner {
+		partner[i] = -1
+	}
+	for i := 0; i < len(rawLines); {
+		if classifyDiffLine(rawLines[i]) != 2 {
+			i++
+			continue
+		}
+		start := i
+		for i < len(rawLines) && classifyDiffLine(rawLines[i]) == 2 {
+			i++
+		}
+		removed := i - start
+		for k := 0; i < len(rawLines) && classifyDiffLine(rawLines[i]) == 1; k, i = k+1, i+1 {
+			if k < removed {
+				partner[start+k], partner[i] = i, start+k
+			}
+		}
+	}
+	return partner
+}
+
+// diffTokens splits a line into words, runs of spaces and single other characters
+func diffTokens(s string) []string {
+	var tokens []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch {
+		case isWordRune(runes[i]):
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+		case unicode.IsSpace(runes[i]):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+		}
+		tokens = append(tokens, string(runes[i:j]))
+		i = j
+	}
+	return tokens
+}
+
+// wordDiff marks the tokens of two lines that aren't part of their longest common
+// subsequence. ok is false when the lines have too little in common to be worth it
+// (or are too long), in which case the whole lines count as changed.
+func wordDiff(oldTokens, newTokens []string) (oldChanged, newChanged []bool, ok bool) {
+	n, k := len(oldTokens), len(newTokens)
+	if n == 0 || k == 0 || n > diffWordMaxTokens || k > diffWordMaxTokens {
+		return nil, nil, false
+	}
+	// lcs[i][j] = common tokens of oldTokens[i:] and newTokens[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, k+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := k - 1; j >= 0; j-- {
+			if oldTokens[i] == newTokens[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	oldChanged, newChanged = make([]bool, n), make([]bool, k)
+	common := 0
+	for i, j := 0, 0; i < n || j < k; {
+		switch {
+		case i < n && j < k && oldTokens[i] == newTokens[j]:
+			if strings.TrimSpace(oldTokens[i]) != "" {
+				common++
+			}
+			i, j = i+1, j+1
+		case j >= k || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			oldChanged[i] = true
+			i++
+		default:
+			newChanged[j] = true
+			j++
+		}
+	}
+	// Mostly rewritten lines read better without highlights
+	if common*3 < min(n, k) {
+		return nil, nil, false
+	}
+	return oldChanged, newChanged, true
+}
+
+// styleWords renders tokens in the line's style, with changed tokens emphasized.
+// Every token is styled on its own so the styling survives wrapping.
+func styleWords(tokens []string, changed []bool, base, emphasis lipgloss.Style) string {
+	var s strings.Builder
+	for i, token := range tokens {
+		switch {
+		case changed[i]:
+			s.WriteString(emphasis.Render(token))
+		case strings.TrimSpace(token) == "":
+			s.WriteString(token)
+		default:
+			s.WriteString(base.Render(token))
+		}
+	}
+	return s.String()
+}
+
+// wordHighlights returns the content (without the +/- marker) of paired changed lines
+// with their differences emphasized; ok is false when they aren't highlighted
+func wordHighlights(removed, added string) (oldStyled, newStyled string, ok bool) {
+	oldTokens, newTokens := diffTokens(removed), diffTokens(added)
+	oldChanged, newChanged, ok := wordDiff(oldTokens, newTokens)
+	if !ok {
+		return "", "", false
+	}
+	return styleWords(oldTokens, oldChanged, diffRemovedStyle, diffRemovedWordStyle),
+		styleWords(newTokens, newChanged, diffAddedStyle, diffAddedWordStyle), true
+}
+
+// unifiedDiffLines wraps a unified diff to width, highlighting changed words in paired lines
+func unifiedDiffLines(rawLines []string, width int) []string {
+	partner := pairChangedLines(rawLines)
+	var lines []string
+	for i, line := range rawLines {
+		kind := classifyDiffLine(line)
+		if p := partner[i]; p >= 0 {
+			removed, added := line, rawLines[p]
+			if kind == 1 {
+				removed, added = added, removed
+			}
+			if oldStyled, newStyled, ok := wordHighlights(removed[1:], added[1:]); ok {
+				styled := diffAddedStyle.Render("+") + newStyled
+				if kind == 2 {
+					styled = diffRemovedStyle.Render("-") + oldStyled
+				}
+				lines = append(lines, wrapLine(styled, width)...)
+				continue
+			}
+		}
+		lines = append(lines, styleWrapped(line, kind, width)...)
+	}
+	return lines
+}
+
+// diffSide is one column of a side-by-side row
+type diffSide struct {
+	num    int    // Line number (0 = no line on this side)
+	text   string // Content without the +/- marker
+	styled string // Content with word highlights ("" = style the whole text by kind)
+	kind   int
+}
+
+// parseHunkStart returns the first old and new line numbers of a "@@ -a,b +c,d @@" header
+func parseHunkStart(line string) (oldStart, newStart int) {
+	fields := strings.Fields(line)
+	if len(fields) >= 3 {
+		fmt.Sscanf(fields[1], "-%d", &oldStart)
+		fmt.Sscanf(fields[2], "+%d", &newStart)
+	}
+	return oldStart, newStart
+}
+
+// sideBySideDiffLines lays out a diff in two columns: removed lines on the left, added
+// lines on the right, paired up within each change and aligned on context lines.
+// Headers, hunk headers and anything outside hunks (commit messages) span both columns.
+func sideBySideDiffLines(rawLines []string, width int) []string {
+	colWidth := (width - 3) / 2 // " │ " between the columns
+	textWidth := colWidth - diffLineNumWidth
+	separator := lipgloss.NewStyle().Foreground(uiSubtleText()).Render(" │ ")
+	numStyle := lipgloss.NewStyle().Foreground(uiSubtleText())
+
+	var lines []string
+	column := func(side diffSide) []string {
+		if side.num == 0 {
+			return []string{strings.Repeat(" ", colWidth)}
+		}
+		var wrapped []string
+		if side.styled != "" {
+			wrapped = wrapLine(side.styled, textWidth)
+		} else {
+			wrapped = styleWrapped(side.text, side.kind, textWidth)
+		}
+		for k, w := range wrapped {
+			gutter := strings.Repeat(" ", diffLineNumWidth)
+			if k == 0 {
+				gutter = numStyle.Render(fmt.Sprintf("%*d ", diffLineNumWidth-1, side.num))
+			}
+			wrapped[k] = gutter + padToWidth(truncateToWidth(w, textWidth), textWidth)
+		}
+		return wrapped
+	}
+	addRow := func(left, right diffSide) {
+		l, r := column(left), column(right)
+		for k := 0; k < max(len(l), len(r)); k++ {
+			cellL, cellR := strings.Repeat(" ", colWidth), ""
+			if k < len(l) {
+				cellL = l[k]
+			}
+			if k < len(r) {
+				cellR = r[k]
+			}
+			lines = append(lines, cellL+"\033[0m"+separator+cellR)
+		}
+	}
+
+	inHunk := false
+	oldNum, newNum := 0, 0
+	for i := 0; i < len(rawLines); i++ {
+		line := rawLines[i]
+		kind := classifyDiffLine(line)
+		switch {
+		case kind == 3:
+			inHunk = true
+			oldNum, newNum = parseHunkStart(line)
+			lines = append(lines, styleWrapped(line, kind, width)...)
+			continue
+		case kind == 4 || !inHunk || strings.HasPrefix(line, "\\"):
+			if strings.HasPrefix(line, "diff ") {
+				inHunk = false
+			}
+			lines = append(lines, styleWrapped(line, kind, width)...)
+			continue
+		case kind == 0:
+			text := strings.TrimPrefix(line, " ")
+			addRow(diffSide{num: oldNum, text: text}, diffSide{num: newNum, text: text})
+			oldNum++
+			newNum++
+			continue
+		}
+
+		// A change: the removed run on the left next to the added run after it
+		a := i
+		for a < len(rawLines) && classifyDiffLine(rawLines[a]) == 2 {
+			a++
+		}
+		b := a
+		for b < len(rawLines) && classifyDiffLine(rawLines[b]) == 1 {
+			b++
+		}
+		removed, added := rawLines[i:a], rawLines[a:b]
+		for k := 0; k < max(len(removed), len(added)); k++ {
+			var left, right diffSide
+			if k < len(removed) {
+				left = diffSide{num: oldNum + k, text: removed[k][1:], kind: 2}
+			}
+			if k < len(added) {
+				right = diffSide{num: newNum + k, text: added[k][1:], kind: 1}
+			}
+			if left.num > 0 && right.num > 0 {
+				if oldStyled, newStyled, ok := wordHighlights(left.text, right.text); ok {
+					left.styled, right.styled = oldStyled, newStyled
+				}
+			}
+			addRow(left, right)
+		}
+		oldNum += len(removed)
+		newNum += len(added)
+		i = b - 1
+	}
+	return lines
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+var testDiff = []string{
+	"diff --git a/main.go b/main.go",
+	"--- a/main.go",
+	"+++ b/main.go",
+	"@@ -10,4 +10,5 @@ func main() {",
+	" \tx := 1",
+	"-\tfmt.Println(\"hello world\", x)",
+	"-\treturn",
+	"+\tfmt.Println(\"hello there\", x)",
+	" \ty := 2",
+	"+\tz := 3",
+	"+\tw := 4",
+}
+
+func TestWordDiff(t *testing.T) {
+	oldTokens := diffTokens(`fmt.Println("hello world", x)`)
+	newTokens := diffTokens(`fmt.Println("hello there", x)`)
+	oldChanged, newChanged, ok := wordDiff(oldTokens, newTokens)
+	if !ok {
+		t.Fatal("Expected similar lines to be highlighted")
+	}
+	var removed, added []string
+	for i, token := range oldTokens {
+		if oldChanged[i] {
+			removed = append(removed, token)
+		}
+	}
+	for i, token := range newTokens {
+		if newChanged[i] {
+			added = append(added, token)
+		}
+	}
+	if strings.Join(removed, "|") != "world" || strings.Join(added, "|") != "there" {
+		t.Errorf("Expected only the changed word marked, got %q and %q", removed, added)
+	}
+
+	// Rewritten lines aren't highlighted word by word
+	if _, _, ok := wordDiff(diffTokens("alpha beta gamma"), diffTokens("one two three")); ok {
+		t.Error("Expected no highlights for unrelated lines")
+	}
+}
+
+func TestPairChangedLines(t *testing.T) {
+	partner := pairChangedLines(testDiff)
+	if partner[5] != 7 || partner[7] != 5 {
+		t.Errorf("Expected the first removed and added lines paired, got %v", partner)
+	}
+	if partner[6] != -1 || partner[9] != -1 {
+		t.Errorf("Expected unmatched lines unpaired, got %v", partner)
+	}
+}
+
+func TestUnifiedDiffLines(t *testing.T) {
+	lines := unifiedDiffLines(testDiff, 80)
+	if len(lines) != len(testDiff) {
+		t.Fatalf("Expected one line per diff line, got %d", len(lines))
+	}
+	plain := plainLines(lines)
+	if !strings.Contains(plain, `+ fmt.Println("hello there", x)`) {
+		t.Errorf("Expected the added line's text intact:\n%s", plain)
+	}
+	// The changed word is emphasized on its own
+	if !strings.Contains(lines[7], diffAddedWordStyle.Render("there")) {
+		t.Errorf("Expected the changed word emphasized: %q", lines[7])
+	}
+}
+
+func TestSideBySideDiffLines(t *testing.T) {
+	lines := sideBySideDiffLines(testDiff, 100)
+	plain := strings.Split(plainLines(lines), "\n")
+	if len(plain) != 4+6 {
+		t.Fatalf("Expected 4 header lines and 6 rows, got %d:\n%s", len(plain), strings.Join(plain, "\n"))
+	}
+	colWidth := (100 - 3) / 2
+	for i, line := range plain[4:] {
+		left, right, ok := strings.Cut(line, " │ ")
+		if !ok || visualWidth(left) != colWidth {
+			t.Fatalf("Row %d not aligned: %q", i, line)
+		}
+		plain[4+i] = strings.TrimSpace(strings.TrimSpace(left) + " | " + strings.TrimSpace(right))
+	}
+	want := []string{
+		"10 x := 1 | 10 x := 1",
+		`11 fmt.Println("hello world", x) | 11 fmt.Println("hello there", x)`,
+		"12 return |",
+		"13 y := 2 | 12 y := 2",
+		"| 13 z := 3",
+		"| 14 w := 4",
+	}
+	for i, w := range want {
+		if plain[4+i] != w {
+			t.Errorf("Row %d: expected %q, got %q", i, w, plain[4+i])
+		}
+	}
+
+	// Narrow panes fall back to the unified layout
+	m := model{width: 80, viewMode: viewFullPreview, diffSideBySide: true}
+	if m.diffUseSideBySide(m.diffWrapWidth()) {
+		t.Error("Expected unified diff in a narrow pane")
+	}
+	m.width = 160
+	if !m.diffUseSideBySide(m.diffWrapWidth()) {
+		t.Error("Expected side-by-side diff in a wide pane")
+	}
+}
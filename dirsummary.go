@@ -0,0 +1,393 @@
+package main
+
+// Module: dirsummary.go
+// Purpose: Preview for a selected directory
+// Responsibilities:
+// - Counting the directory's items by type and finding its newest files
+// - Showing the start of its README
+// - Adding up the size of everything below it in the background (cancellable)
+// - Branch, dirty and ahead/behind info for git repositories (getGitStatus)
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	dirSummaryTypes       = 6  // File types listed by count
+	dirSummaryNewest      = 5  // Newest files listed
+	dirSummaryReadmeLines = 40 // README lines shown
+	dirSummaryReadmeBytes = 64 * 1024
+)
+
+// dirSummaryState is the preview of one directory
+type dirSummaryState struct {
+	path        string
+	modTime     time.Time
+	folders     int
+	files       int
+	hidden      int
+	links       int
+	types       []dirTypeCount // Files per type, most common first
+	newest      []fileItem     // Most recently modified files
+	readme      string         // README file name ("" = none)
+	readmeLines []string       // First lines of the README
+	readErr     error
+
+	// Total size, added up by a background walk
+	scheduled bool // Walk has been started
+	sizeDone  bool
+	size      int64
+	sizeFiles int64
+	sizeErrs  int64 // Entries that couldn't be read
+	walkBytes atomic.Int64
+	walkFiles atomic.Int64
+	shown     int64        // walkFiles when the content was last rebuilt
+	gen       atomic.Int64 // Bumped to cancel the walk
+
+	// Git status when the directory is a repository root (fetched with the walk)
+	isRepo  bool
+	gitDone bool
+	git     gitStatus
+}
+
+// dirTypeCount is the number of files of one type (getFileType)
+type dirTypeCount struct {
+	name  string
+	count int
+}
+
+// dirSizeMsg reports the total size of a directory
+type dirSizeMsg struct {
+	dir   *dirSummaryState
+	gen   int64
+	size  int64
+	files int64
+	errs  int64
+}
+
+// dirGitMsg reports the git status of a directory that is a repository root
+type dirGitMsg struct {
+	dir    *dirSummaryState
+	status gitStatus
+}
+
+// isReadmeName reports whether a file name is a README (README, README.md, readme.txt...)
+func isReadmeName(name string) bool {
+	base := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+	return base == "readme"
+}
+
+// newDirSummary reads the directory's entries (not its subdirectories)
+func newDirSummary(path string, showHidden bool) (*dirSummaryState, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	d := &dirSummaryState{path: path, isRepo: isGitRepo(path)}
+	if info, err := os.Stat(path); err == nil {
+		d.modTime = info.ModTime()
+	}
+
+	counts := make(map[string]int)
+	var files []fileItem
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			d.hidden++
+			if !showHidden {
+				continue
+			}
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed while reading
+		}
+		item := fileItem{name: name, path: filepath.Join(path, name), size: info.Size(), modTime: info.ModTime(), mode: info.Mode()}
+		if info.Mode()&os.ModeSymlink != 0 {
+			d.links++
+			if target, err := os.Stat(item.path); err == nil && target.IsDir() {
+				d.folders++
+				continue
+			}
+		}
+		if info.IsDir() {
+			d.folders++
+			continue
+		}
+		d.files++
+		counts[getFileType(item)]++
+		files = append(files, item)
+		if d.readme == "" && isReadmeName(name) {
+			d.readme = name
+		}
+	}
+
+	for name, count := range counts {
+		d.types = append(d.types, dirTypeCount{name: name, count: count})
+	}
+	sort.Slice(d.types, func(i, j int) bool {
+		if d.types[i].count != d.types[j].count {
+			return d.types[i].count > d.types[j].count
+		}
+		return d.types[i].name < d.types[j].name
+	})
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.After(files[j].modTime) })
+	if len(files) > dirSummaryNewest {
+		files = files[:dirSummaryNewest]
+	}
+	d.newest = files
+
+	if d.readme != "" {
+		d.readmeLines, d.readErr = readFirstLines(filepath.Join(path, d.readme), dirSummaryReadmeLines, dirSummaryReadmeBytes)
+	}
+	return d, nil
+}
+
+// readFirstLines reads up to maxLines lines from the start of a file
+func readFirstLines(path string, maxLines int, maxBytes int64) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	buf := make([]byte, maxBytes)
+	n, _ := f.Read(buf)
+	text := strings.ReplaceAll(string(buf[:n]), "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+	return lines, nil
+}
+
+// walkSize adds up the sizes of all files below the directory. Symlinks aren't
+// followed; it returns early (cancelled = true) when the generation changes.
+func (d *dirSummaryState) walkSize(gen int64) (size, files, errs int64, cancelled bool) {
+	filepath.WalkDir(d.path, func(path string, entry fs.DirEntry, err error) error {
+		if d.gen.Load() != gen {
+			cancelled = true
+			return filepath.SkipAll
+		}
+		if err != nil {
+			errs++
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			errs++
+			return nil
+		}
+		size += info.Size()
+		files++
+		if files%256 == 0 {
+			d.walkBytes.Store(size) // Progress shown while walking
+			d.walkFiles.Store(files)
+		}
+		return nil
+	})
+	return size, files, errs, cancelled
+}
+
+// openDirSummary shows a summary of the directory instead of file content
+func (m *model) openDirSummary(path string) {
+	m.preview.loaded = true
+	m.preview.fileSize = 0
+	d, err := newDirSummary(path, m.showHidden)
+	if err != nil {
+		m.preview.content = []string{
+			"📁 " + filepath.Base(path),
+			"",
+			fmt.Sprintf("❌ Cannot read folder: %v", err),
+		}
+		return
+	}
+	m.preview.dir = d
+	m.preview.content = d.lines()
+}
+
+// dirSummaryCmd starts the size walk and git status lookup for the directory
+// preview. It is called from the global tick so loadPreview needn't return a
+// command; a new preview bumps the generation and the walk stops.
+func (m *model) dirSummaryCmd() tea.Cmd {
+	d := m.preview.dir
+	if d == nil || d.scheduled {
+		return nil
+	}
+	d.scheduled = true
+	gen := d.gen.Load()
+
+	cmds := []tea.Cmd{func() tea.Msg {
+		size, files, errs, cancelled := d.walkSize(gen)
+		if cancelled {
+			return nil
+		}
+		return dirSizeMsg{dir: d, gen: gen, size: size, files: files, errs: errs}
+	}}
+	if d.isRepo {
+		path := d.path
+		cmds = append(cmds, func() tea.Msg {
+			return dirGitMsg{dir: d, status: getGitStatus(path)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// cancel stops the background size walk
+func (d *dirSummaryState) cancel() {
+	d.gen.Add(1)
+}
+
+// advanceDirSummary refreshes the walk's progress while it runs
+func (m *model) advanceDirSummary() {
+	d := m.preview.dir
+	if d == nil || d.sizeDone || !d.scheduled {
+		return
+	}
+	if files := d.walkFiles.Load(); files != d.shown {
+		d.shown = files
+		m.refreshDirSummary()
+	}
+}
+
+// applyDirSizeMsg shows a finished size walk (stale results are ignored)
+func (m *model) applyDirSizeMsg(msg dirSizeMsg) {
+	d := m.preview.dir
+	if d == nil || msg.dir != d || d.gen.Load() != msg.gen {
+		return
+	}
+	d.sizeDone = true
+	d.size, d.sizeFiles, d.sizeErrs = msg.size, msg.files, msg.errs
+	m.refreshDirSummary()
+}
+
+// applyDirGitMsg shows the repository's git status
+func (m *model) applyDirGitMsg(msg dirGitMsg) {
+	d := m.preview.dir
+	if d == nil || msg.dir != d {
+		return
+	}
+	d.gitDone = true
+	d.git = msg.status
+	m.refreshDirSummary()
+}
+
+// refreshDirSummary rebuilds the preview lines, keeping the scroll position
+func (m *model) refreshDirSummary() {
+	m.preview.content = m.preview.dir.lines()
+	m.preview.cacheValid = false
+	m.populatePreviewCache()
+	if maxScroll := m.getWrappedLineCount() - m.getPreviewVisibleLines(); m.preview.scrollPos > maxScroll {
+		m.preview.scrollPos = max(maxScroll, 0)
+	}
+}
+
+// lines renders the summary as preview lines
+func (d *dirSummaryState) lines() []string {
+	lines := []string{
+		"📁 " + filepath.Base(d.path),
+		"",
+		"Path: " + d.path,
+	}
+	if !d.modTime.IsZero() {
+		lines = append(lines, "Modified: "+formatModTime(d.modTime))
+	}
+
+	// Contents
+	lines = append(lines, "", fmt.Sprintf("Contents: %s, %s", pluralize(d.folders, "folder"), pluralize(d.files, "file")))
+	var extras []string
+	if d.hidden > 0 {
+		extras = append(extras, fmt.Sprintf("%d hidden", d.hidden))
+	}
+	if d.links > 0 {
+		extras = append(extras, pluralize(d.links, "symlink"))
+	}
+	if len(extras) > 0 {
+		lines[len(lines)-1] += " (" + strings.Join(extras, ", ") + ")"
+	}
+	for i, t := range d.types {
+		if i == dirSummaryTypes {
+			rest := 0
+			for _, other := range d.types[i:] {
+				rest += other.count
+			}
+			lines = append(lines, fmt.Sprintf("  %-20s %d", "Other", rest))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %-20s %d", t.name, t.count))
+	}
+
+	// Total size (everything below the folder)
+	switch {
+	case d.sizeDone:
+		total := fmt.Sprintf("Total size: %s in %s", formatFileSize(d.size), pluralize(int(d.sizeFiles), "file"))
+		if d.sizeErrs > 0 {
+			total += fmt.Sprintf(" (%d unreadable)", d.sizeErrs)
+		}
+		lines = append(lines, "", total)
+	case d.walkFiles.Load() > 0:
+		lines = append(lines, "", fmt.Sprintf("Total size: calculating... %s so far (%s)",
+			formatFileSize(d.walkBytes.Load()), pluralize(int(d.walkFiles.Load()), "file")))
+	default:
+		lines = append(lines, "", "Total size: calculating...")
+	}
+
+	// Git repository
+	if d.isRepo {
+		lines = append(lines, "", "🔀 Git repository")
+		if !d.gitDone {
+			lines = append(lines, "  Checking status...")
+		} else {
+			lines = append(lines, "  Branch: "+d.git.branch, "  Status: "+formatGitStatus(d.git))
+			if d.git.ahead > 0 || d.git.behind > 0 {
+				lines = append(lines, fmt.Sprintf("  Ahead/behind origin: ↑%d ↓%d", d.git.ahead, d.git.behind))
+			}
+			lines = append(lines, "  Last commit: "+formatLastCommitTime(d.git.lastCommitTime))
+		}
+	}
+
+	// Newest files
+	if len(d.newest) > 0 {
+		lines = append(lines, "", "Newest files:")
+		for _, f := range d.newest {
+			lines = append(lines, fmt.Sprintf("  %-10s %8s  %s", formatModTime(f.modTime), formatFileSize(f.size), f.name))
+		}
+	}
+
+	// README
+	if d.readme != "" {
+		lines = append(lines, "", "📖 "+d.readme, strings.Repeat("─", 40))
+		if d.readErr != nil {
+			lines = append(lines, fmt.Sprintf("Cannot read: %v", d.readErr))
+		}
+		lines = append(lines, d.readmeLines...)
+	}
+
+	if len(d.newest) == 0 && d.folders == 0 {
+		lines = append(lines, "", "(empty folder)")
+	}
+	return lines
+}
+
+// pluralize formats a count with a noun ("1 file", "2 files")
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDirSummary(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "src"), 0755)
+	os.Mkdir(filepath.Join(dir, ".cache"), 0755)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Project\r\n\r\nHello\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "util.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "src", "deep.txt"), []byte(strings.Repeat("x", 1000)), 0644)
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(dir, "util.go"), old, old)
+
+	d, err := newDirSummary(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.folders != 1 || d.files != 3 || d.hidden != 1 {
+		t.Errorf("Expected 1 folder, 3 files, 1 hidden, got %d, %d, %d", d.folders, d.files, d.hidden)
+	}
+	if len(d.types) == 0 || d.types[0].name != "Go Source" || d.types[0].count != 2 {
+		t.Errorf("Expected Go Source first with 2 files, got %+v", d.types)
+	}
+	if d.readme != "README.md" || strings.Join(d.readmeLines, "|") != "# Project||Hello" {
+		t.Errorf("Unexpected README %q: %q", d.readme, d.readmeLines)
+	}
+	if d.newest[len(d.newest)-1].name != "util.go" {
+		t.Errorf("Expected the oldest file last, got %s", d.newest[len(d.newest)-1].name)
+	}
+
+	// Hidden entries are counted when they're shown
+	if d, _ := newDirSummary(dir, true); d.folders != 2 {
+		t.Errorf("Expected the hidden folder counted, got %d folders", d.folders)
+	}
+
+	size, files, errs, cancelled := d.walkSize(d.gen.Load())
+	if cancelled || errs != 0 || files != 4 || size != 1000+int64(len("# Project\r\n\r\nHello\n"))+26 {
+		t.Errorf("Unexpected walk: %d bytes in %d files (%d errors, cancelled %v)", size, files, errs, cancelled)
+	}
+
+	// A new generation stops the walk
+	gen := d.gen.Load()
+	d.cancel()
+	if _, _, _, cancelled := d.walkSize(gen); !cancelled {
+		t.Error("Expected the walk to be cancelled")
+	}
+}
+
+func TestDirSummaryPreview(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hi\n"), 0644)
+
+	m := model{height: 30, width: 100, viewMode: viewFullPreview}
+	m.loadPreview(dir)
+	m.populatePreviewCache()
+	d := m.preview.dir
+	if d == nil || !m.preview.loaded {
+		t.Fatal("Expected a folder summary")
+	}
+	text := strings.Join(m.preview.content, "\n")
+	if !strings.Contains(text, "1 file") || !strings.Contains(text, "calculating") || !strings.Contains(text, "notes.txt") {
+		t.Errorf("Unexpected summary:\n%s", text)
+	}
+
+	// The walk is started once and its result fills in the total
+	cmd := m.dirSummaryCmd()
+	if cmd == nil || m.dirSummaryCmd() != nil {
+		t.Fatal("Expected the size walk to start once")
+	}
+	msg, ok := cmd().(dirSizeMsg)
+	if !ok {
+		t.Fatal("Expected a size result")
+	}
+	m.applyDirSizeMsg(msg)
+	if text := strings.Join(m.preview.content, "\n"); !strings.Contains(text, "Total size: 3B in 1 file") {
+		t.Errorf("Expected the total size, got:\n%s", text)
+	}
+
+	// Results for a folder that is no longer previewed are ignored
+	m.loadPreview(filepath.Join(dir, "notes.txt"))
+	if m.preview.dir != nil || d.gen.Load() == msg.gen {
+		t.Error("Expected the folder summary cancelled")
+	}
+	m.applyDirSizeMsg(msg)
+	if !strings.HasPrefix(plainLines(m.preview.content), "hi") {
+		t.Errorf("Expected the stale result ignored, got %q", plainLines(m.preview.content))
+	}
+}
@@ -457,7 +457,7 @@ type browserOpenedMsg struct {
 	err     error
 }
 
-// openInBrowser opens a file in the default browser
+// openInBrowser opens a file (or URL) in the default browser
 func openInBrowser(path string) tea.Cmd {
 	browser := getAvailableBrowser()
 	if browser == "" {
@@ -473,8 +473,8 @@ func openInBrowser(path string) tea.Cmd {
 		var c *exec.Cmd
 		browserPath := path
 
-		// In WSL, convert Linux paths to Windows paths for better compatibility
-		if isWSL() && browser != "wslview" {
+		// In WSL, convert Linux paths to Windows paths for better compatibility (URLs are passed as-is)
+		if isWSL() && browser != "wslview" && !strings.Contains(path, "://") && !strings.HasPrefix(path, "mailto:") {
 			// Use wslpath to convert WSL path to Windows path
 			cmd := exec.Command("wslpath", "-w", path)
 			output, err := cmd.Output()
@@ -0,0 +1,403 @@
+package main
+
+// Module: exif.go
+// Purpose: Reading EXIF metadata from image files
+// Responsibilities:
+// - Locating the EXIF block in JPEG (APP1), PNG (eXIf) and WebP (EXIF chunk) files
+// - Parsing the TIFF structure (IFD0, Exif and GPS sub-IFDs)
+// - Formatting camera, exposure, date and location fields for display
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+const exifMaxSize = 1 << 20 // Larger EXIF blocks are ignored
+
+// exifData is the EXIF metadata of an image
+type exifData struct {
+	fields      []exifField // Display fields in a fixed order
+	orientation int         // 1-8 (1 = stored upright, 0 = not set)
+}
+
+// exifField is one labelled EXIF value
+type exifField struct {
+	label string
+	value string
+}
+
+// EXIF tag numbers
+const (
+	exifTagMake             = 0x010F
+	exifTagModel            = 0x0110
+	exifTagOrientation      = 0x0112
+	exifTagSoftware         = 0x0131
+	exifTagDateTime         = 0x0132
+	exifTagArtist           = 0x013B
+	exifTagCopyright        = 0x8298
+	exifTagExposureTime     = 0x829A
+	exifTagFNumber          = 0x829D
+	exifTagExifIFD          = 0x8769
+	exifTagGPSIFD           = 0x8825
+	exifTagISO              = 0x8827
+	exifTagDateTimeOriginal = 0x9003
+	exifTagFocalLength      = 0x920A
+	exifTagLensModel        = 0xA434
+	exifTagGPSLatitudeRef   = 0x0001
+	exifTagGPSLatitude      = 0x0002
+	exifTagGPSLongitudeRef  = 0x0003
+	exifTagGPSLongitude     = 0x0004
+	exifTagGPSAltitude      = 0x0006
+)
+
+// readImageEXIF reads the EXIF metadata of a JPEG, PNG or WebP file.
+// Returns nil if the file has none (or it can't be parsed).
+func readImageEXIF(path string) *exifData {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var magic [12]byte
+	if _, err := io.ReadFull(f, magic[:]); err != nil {
+		return nil
+	}
+	var tiff []byte
+	switch {
+	case magic[0] == 0xFF && magic[1] == 0xD8:
+		tiff = findJPEGExif(f)
+	case bytes.HasPrefix(magic[:], []byte("\x89PNG\r\n\x1a\n")):
+		tiff = findPNGExif(f)
+	case string(magic[0:4]) == "RIFF" && string(magic[8:12]) == "WEBP":
+		tiff = findWebPExif(f)
+	}
+	if tiff == nil {
+		return nil
+	}
+	return parseEXIF(tiff)
+}
+
+// findJPEGExif walks the JPEG segments up to the image data looking for an Exif APP1 segment
+func findJPEGExif(r io.ReadSeeker) []byte {
+	if _, err := r.Seek(2, io.SeekStart); err != nil {
+		return nil
+	}
+	var hdr [4]byte
+	for {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil || hdr[0] != 0xFF {
+			return nil
+		}
+		marker := hdr[1]
+		if marker == 0xDA || marker == 0xD9 { // Start of scan / end of image
+			return nil
+		}
+		size := int(binary.BigEndian.Uint16(hdr[2:])) - 2
+		if size < 0 {
+			return nil
+		}
+		if marker == 0xE1 && size > 6 && size <= exifMaxSize {
+			data := make([]byte, size)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil
+			}
+			if bytes.HasPrefix(data, []byte("Exif\x00\x00")) {
+				return data[6:]
+			}
+			continue // XMP also lives in APP1
+		}
+		if _, err := r.Seek(int64(size), io.SeekCurrent); err != nil {
+			return nil
+		}
+	}
+}
+
+// findPNGExif walks the PNG chunks looking for an eXIf chunk
+func findPNGExif(r io.ReadSeeker) []byte {
+	if _, err := r.Seek(8, io.SeekStart); err != nil {
+		return nil
+	}
+	var hdr [8]byte
+	for {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil
+		}
+		size := int64(binary.BigEndian.Uint32(hdr[:4]))
+		switch string(hdr[4:]) {
+		case "eXIf":
+			if size > exifMaxSize {
+				return nil
+			}
+			data := make([]byte, size)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil
+			}
+			return data
+		case "IEND":
+			return nil
+		}
+		if _, err := r.Seek(size+4, io.SeekCurrent); err != nil { // Data + CRC
+			return nil
+		}
+	}
+}
+
+// findWebPExif walks the RIFF chunks of a WebP file looking for an EXIF chunk
+func findWebPExif(r io.ReadSeeker) []byte {
+	var hdr [8]byte
+	for {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return nil
+		}
+		size := int64(binary.LittleEndian.Uint32(hdr[4:]))
+		if string(hdr[:4]) == "EXIF" {
+			if size > exifMaxSize {
+				return nil
+			}
+			data := make([]byte, size)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil
+			}
+			// Some writers keep the JPEG-style prefix
+			return bytes.TrimPrefix(data, []byte("Exif\x00\x00"))
+		}
+		if _, err := r.Seek(size+size%2, io.SeekCurrent); err != nil { // Chunks are padded to even sizes
+			return nil
+		}
+	}
+}
+
+// exifReader reads IFD entries from a TIFF block
+type exifReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// exifEntry is a raw IFD entry
+type exifEntry struct {
+	typ   uint16
+	count uint32
+	value []byte // The entry's data (inline or at its offset)
+}
+
+// exifTypeSizes is the size in bytes of each TIFF field type
+var exifTypeSizes = map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// ifd reads the entries of the IFD at offset, keyed by tag
+func (e exifReader) ifd(offset uint32) map[uint16]exifEntry {
+	entries := make(map[uint16]exifEntry)
+	if int64(offset)+2 > int64(len(e.data)) {
+		return entries
+	}
+	n := int(e.order.Uint16(e.data[offset:]))
+	pos := int(offset) + 2
+	for i := 0; i < n && pos+12 <= len(e.data); i, pos = i+1, pos+12 {
+		tag := e.order.Uint16(e.data[pos:])
+		typ := e.order.Uint16(e.data[pos+2:])
+		count := e.order.Uint32(e.data[pos+4:])
+		size, ok := exifTypeSizes[typ]
+		if !ok || count > exifMaxSize {
+			continue
+		}
+		total := size * int(count)
+		var value []byte
+		if total <= 4 {
+			value = e.data[pos+8 : pos+8+total]
+		} else {
+			off := int64(e.order.Uint32(e.data[pos+8:]))
+			if off+int64(total) > int64(len(e.data)) {
+				continue
+			}
+			value = e.data[off : off+int64(total)]
+		}
+		entries[tag] = exifEntry{typ: typ, count: count, value: value}
+	}
+	return entries
+}
+
+// integer returns the i'th value of a BYTE/SHORT/LONG entry
+func (e exifReader) integer(entry exifEntry, i int) (uint32, bool) {
+	switch entry.typ {
+	case 1, 7:
+		if i < len(entry.value) {
+			return uint32(entry.value[i]), true
+		}
+	case 3:
+		if 2*i+2 <= len(entry.value) {
+			return uint32(e.order.Uint16(entry.value[2*i:])), true
+		}
+	case 4:
+		if 4*i+4 <= len(entry.value) {
+			return e.order.Uint32(entry.value[4*i:]), true
+		}
+	}
+	return 0, false
+}
+
+// rational returns the i'th value of a RATIONAL/SRATIONAL entry as numerator and denominator
+func (e exifReader) rational(entry exifEntry, i int) (num, den float64, ok bool) {
+	if (entry.typ != 5 && entry.typ != 10) || 8*i+8 > len(entry.value) {
+		return 0, 0, false
+	}
+	n, d := e.order.Uint32(entry.value[8*i:]), e.order.Uint32(entry.value[8*i+4:])
+	if entry.typ == 10 {
+		return float64(int32(n)), float64(int32(d)), d != 0
+	}
+	return float64(n), float64(d), d != 0
+}
+
+// exifText returns an ASCII entry without its NUL terminator and padding
+func exifText(entry exifEntry) string {
+	if entry.typ != 2 {
+		return ""
+	}
+	s := string(entry.value)
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// parseEXIF parses a TIFF-structured EXIF block
+func parseEXIF(data []byte) *exifData {
+	if len(data) < 8 {
+		return nil
+	}
+	var e exifReader
+	switch string(data[:2]) {
+	case "II":
+		e.order = binary.LittleEndian
+	case "MM":
+		e.order = binary.BigEndian
+	default:
+		return nil
+	}
+	if e.order.Uint16(data[2:]) != 42 {
+		return nil
+	}
+	e.data = data
+
+	ifd0 := e.ifd(e.order.Uint32(data[4:]))
+	sub := map[uint16]exifEntry{}
+	if entry, ok := ifd0[exifTagExifIFD]; ok {
+		if off, ok := e.integer(entry, 0); ok {
+			sub = e.ifd(off)
+		}
+	}
+	gps := map[uint16]exifEntry{}
+	if entry, ok := ifd0[exifTagGPSIFD]; ok {
+		if off, ok := e.integer(entry, 0); ok {
+			gps = e.ifd(off)
+		}
+	}
+
+	x := &exifData{}
+	add := func(label, value string) {
+		if value != "" {
+			x.fields = append(x.fields, exifField{label, value})
+		}
+	}
+
+	// Camera: the model usually repeats the make ("Canon" + "Canon EOS R5")
+	camMake, model := exifText(ifd0[exifTagMake]), exifText(ifd0[exifTagModel])
+	camera := model
+	if camMake != "" && !strings.HasPrefix(strings.ToLower(model), strings.ToLower(strings.Fields(camMake)[0])) {
+		camera = strings.TrimSpace(camMake + " " + model)
+	}
+	add("Camera", camera)
+	add("Lens", exifText(sub[exifTagLensModel]))
+
+	taken := exifText(sub[exifTagDateTimeOriginal])
+	if taken == "" {
+		taken = exifText(ifd0[exifTagDateTime])
+	}
+	add("Taken", formatEXIFDate(taken))
+
+	if num, den, ok := e.rational(sub[exifTagExposureTime], 0); ok && num > 0 {
+		if num/den < 1 {
+			add("Exposure", fmt.Sprintf("1/%.0fs", den/num))
+		} else {
+			add("Exposure", strings.TrimSuffix(fmt.Sprintf("%.1f", num/den), ".0")+"s")
+		}
+	}
+	if num, den, ok := e.rational(sub[exifTagFNumber], 0); ok && num > 0 {
+		add("Aperture", "f/"+strings.TrimSuffix(fmt.Sprintf("%.1f", num/den), ".0"))
+	}
+	if iso, ok := e.integer(sub[exifTagISO], 0); ok && iso > 0 {
+		add("ISO", fmt.Sprint(iso))
+	}
+	if num, den, ok := e.rational(sub[exifTagFocalLength], 0); ok && num > 0 {
+		add("Focal length", strings.TrimSuffix(fmt.Sprintf("%.1f", num/den), ".0")+"mm")
+	}
+
+	if o, ok := e.integer(ifd0[exifTagOrientation], 0); ok && o >= 1 && o <= 8 {
+		x.orientation = int(o)
+		if o != 1 {
+			add("Orientation", exifOrientationNames[o])
+		}
+	}
+
+	lat, latOK := e.gpsCoordinate(gps[exifTagGPSLatitude], exifText(gps[exifTagGPSLatitudeRef]) == "S")
+	lon, lonOK := e.gpsCoordinate(gps[exifTagGPSLongitude], exifText(gps[exifTagGPSLongitudeRef]) == "W")
+	if latOK && lonOK {
+		location := fmt.Sprintf("%.5f, %.5f", lat, lon)
+		if num, den, ok := e.rational(gps[exifTagGPSAltitude], 0); ok {
+			location += fmt.Sprintf(" (%.0fm)", num/den)
+		}
+		add("Location", location)
+	}
+
+	add("Software", exifText(ifd0[exifTagSoftware]))
+	add("Artist", exifText(ifd0[exifTagArtist]))
+	add("Copyright", exifText(ifd0[exifTagCopyright]))
+
+	if len(x.fields) == 0 && x.orientation == 0 {
+		return nil
+	}
+	return x
+}
+
+// gpsCoordinate converts a degrees/minutes/seconds GPS entry to decimal degrees
+func (e exifReader) gpsCoordinate(entry exifEntry, negative bool) (float64, bool) {
+	var parts [3]float64
+	for i := range parts {
+		num, den, ok := e.rational(entry, i)
+		if !ok {
+			return 0, false
+		}
+		parts[i] = num / den
+	}
+	deg := parts[0] + parts[1]/60 + parts[2]/3600
+	if negative {
+		deg = -deg
+	}
+	if math.IsNaN(deg) || math.Abs(deg) > 180 {
+		return 0, false
+	}
+	return deg, true
+}
+
+// formatEXIFDate turns "2024:06:01 14:03:22" into "2024-06-01 14:03:22"
+func formatEXIFDate(s string) string {
+	if len(s) >= 10 && s[4] == ':' && s[7] == ':' {
+		return s[:4] + "-" + s[5:7] + "-" + s[8:]
+	}
+	return s
+}
+
+// exifOrientationNames describes the orientation tag values
+var exifOrientationNames = map[uint32]string{
+	1: "Normal",
+	2: "Mirrored",
+	3: "Rotated 180°",
+	4: "Flipped",
+	5: "Mirrored, rotated 90° CCW",
+	6: "Rotated 90° CW",
+	7: "Mirrored, rotated 90° CW",
+	8: "Rotated 90° CCW",
+}
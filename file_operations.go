@@ -264,6 +264,9 @@ func (m *model) loadFiles() {
 	// Update file watcher to track the current directory
 	m.switchWatchPath(m.currentPath)
 
+	// Git status markers for the rows (cached per repository)
+	m.loadGitStatus()
+
 	// Auto-exit agent view if user navigated outside the .claude/projects/ directory
 	if m.showAgentView {
 		homeDir, _ := os.UserHomeDir()
@@ -287,14 +290,22 @@ func (m *model) loadFiles() {
 		trashItems, err := getTrashItems()
 		if err != nil {
 			m.files = []fileItem{}
-			m.setStatusMessage(fmt.Sprintf("Error loading trash: %v", err), true)
+			m.setStatusMessage(fmt.Sprintf("Error loading trash: %v (Go → Repair Trash Index)", err), true)
 			return
 		}
 
-		// Convert trash items to file items for display
-		m.files = convertTrashItemsToFileItems(trashItems)
 		m.trashItems = trashItems // Cache for later use
-		return
+		if size, err := getTrashSize(); err == nil {
+			m.trashTotalSize = size
+		}
+
+		// Browsing inside a trashed directory (partial restore): fall through to
+		// the normal directory listing. Otherwise show the top-level trash items.
+		trashDir, err := getTrashDir()
+		if err != nil || !strings.HasPrefix(m.currentPath, trashDir+string(filepath.Separator)) {
+			m.files = convertTrashItemsToFileItems(trashItems)
+			return
+		}
 	}
 
 	// SECURITY: Validate and clean the path to prevent directory traversal attacks
@@ -661,8 +672,17 @@ func (m *model) sortFiles() {
 
 // loadPreview loads the content of a file for preview
 func (m *model) loadPreview(path string) {
+	if m.preview.follow != nil && m.preview.follow.path != filepath.Clean(path) {
+		m.stopFollow() // Following ends when another file is previewed
+	}
 	m.preview.filePath = path
 	m.preview.fileName = filepath.Base(path)
+	// Trashed files carry a timestamp prefix - show the original name instead
+	if m.showTrashOnly {
+		if originalPath, ok := trashOriginalPath(m.trashItems, path); ok {
+			m.preview.fileName = filepath.Base(originalPath) + " (in trash)"
+		}
+	}
 	m.preview.scrollPos = 0
 	m.preview.loaded = false
 	m.preview.isBinary = false
@@ -678,6 +698,34 @@ func (m *model) loadPreview(path string) {
 	m.preview.promptTemplate = nil
 	m.preview.isJSONL = false
 	m.preview.cachedJSONLMessages = nil
+	if m.preview.pager != nil {
+		m.preview.pager.searchGen.Add(1) // Cancel any in-flight pager search
+	}
+	m.preview.pager = nil
+	if m.preview.hex != nil {
+		m.preview.hex.searchGen.Add(1) // Cancel any in-flight hex search
+	}
+	m.preview.hex = nil
+	m.preview.tree = nil
+	if m.preview.table != nil {
+		m.preview.table.gen.Add(1) // Cancel any running sort/filter/stats scan
+	}
+	m.preview.table = nil
+	if m.preview.db != nil {
+		m.preview.db.gen.Add(1) // Cancel any running query
+	}
+	m.preview.db = nil
+	m.preview.image = nil
+	m.preview.anim = nil // Pending frame ticks see a different animation and stop
+	m.preview.markdown = nil
+	if m.preview.dir != nil {
+		m.preview.dir.cancel() // Stop adding up the previous folder's size
+	}
+	m.preview.dir = nil
+	m.preview.blame = nil // A running blame sees a different overlay and is dropped
+	m.preview.history = nil
+	m.preview.compare = nil
+	m.preview.gotoActive = false
 	// Invalidate cache when loading new file
 	m.preview.cacheValid = false
 	m.preview.cachedWrappedLines = nil
@@ -756,6 +804,12 @@ func (m *model) loadPreview(path string) {
 		return
 	}
 
+	// Directories get a summary of their contents
+	if info.IsDir() {
+		m.openDirSummary(path)
+		return
+	}
+
 	m.preview.fileSize = info.Size()
 
 	// JSONL conversation files: read last N lines (tail) regardless of size
@@ -764,9 +818,26 @@ func (m *model) loadPreview(path string) {
 		return
 	}
 
-	// Check if file is too large (>1MB)
-	const maxSize = 1024 * 1024 // 1MB
-	if info.Size() > maxSize {
+	// CSV/TSV files render as a table (rows are paged from disk, so any size works)
+	if isCSVFile(path) && !isBinaryFile(path) && m.openCSVTable(path, info.Size()) {
+		return
+	}
+
+	// SQLite databases open in the schema browser (pages are read on demand, so any size works)
+	if isSQLiteFile(path) && m.openDBBrowser(path) {
+		return
+	}
+
+	// Large text files stream from disk in the pager instead of being loaded
+	// (structured data up to dataTreeMaxSize is loaded for the tree view)
+	if info.Size() > pagerThreshold && !isBinaryFile(path) &&
+		(structuredDataFormat(path) == "" || info.Size() > dataTreeMaxSize) {
+		m.openPager(path, info.Size())
+		return
+	}
+
+	// Large binary files can't be previewed
+	if info.Size() > pagerThreshold {
 		m.preview.tooLarge = true
 		m.preview.content = []string{
 			"File too large to preview",
@@ -775,49 +846,8 @@ func (m *model) loadPreview(path string) {
 			"Press 'E' to edit in external editor",
 		}
 		m.preview.loaded = true
-		return
-	}
-
-	// Check for CSV files (text-based, but better viewed with specialized tools)
-	if isCSVFile(path) {
-		// Still read as text, but add a helpful hint
-		content, err := os.ReadFile(path)
-		if err != nil {
-			m.preview.content = []string{
-				fmt.Sprintf("Error reading file: %v", err),
-			}
-			m.preview.loaded = true
-			return
-		}
-
-		lines := strings.Split(string(content), "\n")
-		// Add header with hint based on tool availability
-		var hintLines []string
-		if getAvailableCSVViewer() != "" {
-			hintLines = []string{
-				"📈 CSV/Spreadsheet file",
-				fmt.Sprintf("Size: %s", formatFileSize(info.Size())),
-				"",
-				"✨ Press F4 to open in VisiData (interactive spreadsheet viewer)",
-				"",
-				"─────────────────────────────────────",
-				"",
-			}
-		} else {
-			hintLines = []string{
-				"📈 CSV/Spreadsheet file",
-				fmt.Sprintf("Size: %s", formatFileSize(info.Size())),
-				"",
-				"💡 Tip: Install VisiData for better viewing:",
-				"   sudo apt install visidata",
-				"   or: pipx install visidata",
-				"",
-				"─────────────────────────────────────",
-				"",
-			}
-		}
-		m.preview.content = append(hintLines, lines...)
-		m.preview.loaded = true
+		// Show the bytes instead (x toggles back to this notice)
+		m.preview.hex = newHexViewState(path, info.Size(), m.config.HexBytesPerRow)
 		return
 	}
 
@@ -939,12 +969,16 @@ func (m *model) loadPreview(path string) {
 			maxWidth := 80  // Will be refined based on preview pane width
 			maxHeight := 30 // Will be refined based on preview pane height
 
-			hdImageData, success := renderImageWithProtocol(path, maxWidth, maxHeight)
+			// Animated GIFs play frame by frame (see imageanim.go)
+			anim := loadImageAnimation(path)
+			var hdImageData string
+			success := false
+			if anim == nil {
+				hdImageData, success = renderImageWithProtocol(path, maxWidth, maxHeight)
+			} else {
+				success = detectTerminalProtocol() != ProtocolNone
+			}
 			if success {
-				// HD image rendering succeeded - store the rendered data
-				// Split into lines for preview rendering
-				imageLines := strings.Split(strings.TrimRight(hdImageData, "\n"), "\n")
-
 				// Add header info with fallback options
 				protocolName := getProtocolName()
 				header := []string{
@@ -952,7 +986,6 @@ func (m *model) loadPreview(path string) {
 					fmt.Sprintf("Size: %s", formatFileSize(info.Size())),
 					"",
 				}
-				content = append(header, imageLines...)
 
 				// Add footer with fallback viewing options
 				// Useful if protocol doesn't work (e.g., WezTerm in WSL) or user prefers external viewer
@@ -962,8 +995,17 @@ func (m *model) loadPreview(path string) {
 					footer = append(footer, fmt.Sprintf("💡 Alternative: Press V to view in %s", imageViewer))
 				}
 				footer = append(footer, "   Press F3 to open in browser")
-				content = append(content, footer...)
 
+				if anim != nil {
+					content, success = m.startProtocolAnimation(anim, header, footer)
+				} else {
+					// HD image rendering succeeded - split the rendered data into lines for preview rendering
+					imageLines := strings.Split(strings.TrimRight(hdImageData, "\n"), "\n")
+					content = append(header, imageLines...)
+					content = append(content, footer...)
+				}
+			}
+			if success {
 				// Set flag to prevent wrapping of graphics protocol escape sequences
 				m.preview.hasGraphicsProtocol = true
 			} else {
@@ -999,6 +1041,10 @@ func (m *model) loadPreview(path string) {
 						"💡 For HD inline previews, use WezTerm or Kitty terminal",
 					}
 				}
+				// Draw the image with Unicode blocks instead (tmux, SSH, plain xterm)
+				if m.openImagePreview(path, info.Size()) && anim != nil {
+					m.startImageAnimation(anim)
+				}
 			}
 		} else {
 			// Generic binary file
@@ -1023,6 +1069,8 @@ func (m *model) loadPreview(path string) {
 					"   or: sudo apt install hexyl",
 				}
 			}
+			// Generic binaries open in the built-in hex view (x toggles back to this notice)
+			m.preview.hex = newHexViewState(path, info.Size(), m.config.HexBytesPerRow)
 		}
 
 		m.preview.content = content
@@ -1091,6 +1139,7 @@ func (m *model) loadPreview(path string) {
 		lines := strings.Split(string(content), "\n")
 		m.preview.content = lines
 		m.preview.loaded = true
+		m.preview.markdown = parseMarkdownNav(lines) // Outline and links (see markdownnav.go)
 
 		// DON'T populate cache here - let caller do it after setting view mode
 		// This prevents rendering with wrong width (e.g., m.rightWidth=0 in single-pane)
@@ -1098,6 +1147,28 @@ func (m *model) loadPreview(path string) {
 		return
 	}
 
+	// JSON/YAML/TOML: tree view (t toggles the text view, where minified JSON is pretty-printed)
+	if format := structuredDataFormat(path); format != "" {
+		if root, err := parseDataTree(content, format); err == nil {
+			m.preview.tree = newDataTreeState(root, format)
+		}
+		if format == "JSON" {
+			content = prettyPrintJSON(content)
+		}
+		if len(content) > pagerThreshold {
+			if m.preview.tree == nil {
+				// Didn't parse - fall back to paging the raw file
+				m.openPager(path, info.Size())
+				return
+			}
+			// Too big to highlight - keep the text view plain
+			m.preview.content = strings.Split(string(content), "\n")
+			m.preview.loaded = true
+			m.populatePreviewCache()
+			return
+		}
+	}
+
 	// Try syntax highlighting for code files
 	highlighted, ok := highlightCode(string(content), path)
 	var lines []string
@@ -1112,10 +1183,11 @@ func (m *model) loadPreview(path string) {
 		m.preview.isSyntaxHighlighted = false
 	}
 
-	// Limit number of lines
-	if len(lines) > m.preview.maxPreview {
-		lines = lines[:m.preview.maxPreview]
-		lines = append(lines, "", fmt.Sprintf("... (truncated after %d lines)", m.preview.maxPreview))
+	// Too many lines to keep wrapped in memory - page from disk instead of truncating
+	if m.preview.maxPreview > 0 && len(lines) > m.preview.maxPreview && m.preview.tree == nil {
+		m.preview.isSyntaxHighlighted = false
+		m.openPager(path, info.Size())
+		return
 	}
 
 	m.preview.content = lines
@@ -1125,6 +1197,21 @@ func (m *model) loadPreview(path string) {
 	m.populatePreviewCache()
 }
 
+// openPager shows path in the streaming pager
+func (m *model) openPager(path string, size int64) {
+	pager, err := newPagerState(path, size)
+	if err != nil {
+		m.preview.content = []string{
+			fmt.Sprintf("Error reading file: %v", err),
+		}
+		m.preview.loaded = true
+		return
+	}
+	m.preview.pager = pager
+	m.preview.content = nil
+	m.preview.loaded = true
+}
+
 // populatePreviewCache pre-computes and caches wrapped/rendered content for better scroll performance
 func (m *model) populatePreviewCache() {
 	if !m.preview.loaded {
@@ -1332,22 +1419,111 @@ func (m *model) createDirectory(name string) error {
 	return nil
 }
 
-// deleteFileOrDir deletes a file or directory
-func (m *model) deleteFileOrDir(path string, isDir bool) error {
-	// Check if exists
-	if _, err := os.Stat(path); err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("file not found")
+// trashedMsg reports the result of a background move to trash
+type trashedMsg struct {
+	file fileItem
+	done string // Status message on success
+	err  error
+}
+
+// trashFileCmd moves file to trash in the background: moving a folder across
+// filesystems and measuring it for the metadata can take a while.
+// done is the status message shown on success.
+func (m model) trashFileCmd(file fileItem, done string) tea.Cmd {
+	policy := m.config.trashPolicy()
+	return func() tea.Msg {
+		if _, err := os.Stat(file.path); err != nil {
+			if os.IsNotExist(err) {
+				err = fmt.Errorf("file not found")
+			}
+			return trashedMsg{file: file, err: err}
 		}
-		return err
+		// Move to trash instead of permanent delete for safety
+		if err := trashWithPolicy(file.path, policy); err != nil {
+			return trashedMsg{file: file, err: fmt.Errorf("failed to move to trash: %w", err)}
+		}
+		return trashedMsg{file: file, done: done}
 	}
+}
 
-	// Move to trash instead of permanent delete for safety
-	if err := moveToTrash(path); err != nil {
-		return fmt.Errorf("failed to move to trash: %w", err)
+// applyTrashedMsg reports a finished move to trash and reloads the list
+func (m *model) applyTrashedMsg(msg trashedMsg) {
+	if msg.err != nil {
+		m.setStatusMessage(fmt.Sprintf("Error: %s", msg.err), true)
+		return
+	}
+	m.setStatusMessage(msg.done, false)
+	m.loadFiles()
+	// Adjust cursor if needed
+	if m.cursor >= len(m.files) {
+		m.cursor = len(m.files) - 1
+		if m.cursor < 0 {
+			m.cursor = 0
+		}
 	}
+}
 
-	return nil
+// trashSizeMsg carries a folder's size, measured for the delete confirmation
+type trashSizeMsg struct {
+	file          fileItem
+	size          int64
+	defaultDialog dialogModel
+}
+
+// openTrashDeleteDialog shows the confirmation dialog for trashing file.
+// Folders are only measured when a confirm size or quota is set, and then in the
+// background: the dialog opens once the walk finishes (see applyTrashSizeMsg).
+func (m *model) openTrashDeleteDialog(file fileItem, defaultDialog dialogModel) tea.Cmd {
+	if policy := m.config.trashPolicy(); !file.isDir || (policy.ConfirmSize <= 0 && policy.MaxSize <= 0) {
+		m.dialog = m.trashDeleteDialog(file, file.size, defaultDialog)
+		m.showDialog = true
+		return nil
+	}
+	m.setStatusMessage(fmt.Sprintf("Measuring %s...", file.name), false)
+	return func() tea.Msg {
+		return trashSizeMsg{file: file, size: dirSize(file.path), defaultDialog: defaultDialog}
+	}
+}
+
+// applyTrashSizeMsg opens the delete confirmation for a measured folder, unless
+// the user has moved on (another dialog, or a different target selected)
+func (m *model) applyTrashSizeMsg(msg trashSizeMsg) {
+	target := m.contextMenuFile
+	if target == nil {
+		target = m.getCurrentFile()
+	}
+	if m.showDialog || target == nil || target.path != msg.file.path {
+		return
+	}
+	m.statusMessage = ""
+	m.dialog = m.trashDeleteDialog(msg.file, msg.size, msg.defaultDialog)
+	m.showDialog = true
+}
+
+// trashDeleteDialog returns the confirmation dialog for trashing file of the given size.
+// Items above the configured confirm size or the trash quota get a "Large Item" dialog
+// that also offers permanent deletion; everything else uses the given default dialog.
+func (m model) trashDeleteDialog(file fileItem, size int64, defaultDialog dialogModel) dialogModel {
+	policy := m.config.trashPolicy()
+	if !policy.needsConfirm(size) {
+		return defaultDialog
+	}
+
+	message := fmt.Sprintf("'%s' is %s.", file.name, formatFileSize(size))
+	if policy.ConfirmSize > 0 && size > policy.ConfirmSize {
+		message = fmt.Sprintf("'%s' is %s (confirm limit: %s).", file.name, formatFileSize(size), formatFileSize(policy.ConfirmSize))
+	}
+	if policy.MaxSize > 0 && size > policy.MaxSize {
+		message += fmt.Sprintf("\nIt is larger than the trash quota of %s: it will be kept, but every other item\nin the trash will be permanently deleted.", formatFileSize(policy.MaxSize))
+	}
+	message += "\n\nMove to trash anyway, or delete permanently?"
+
+	return dialogModel{
+		dialogType: dialogConfirm,
+		title:      "Large Item",
+		message:    message,
+		hint:       "[Y] Trash / [P]ermanent / [N]o",
+	}
 }
 
 // permanentDeleteFileOrDir permanently deletes a file without moving to trash
@@ -833,7 +833,7 @@ func TestLoadPreviewLargeFile(t *testing.T) {
 	tmpDir, cleanup := setupTestDir(t)
 	defer cleanup()
 
-	// Create a file larger than 1MB
+	// Create a text file larger than 1MB - opens in the streaming pager
 	largeFile := filepath.Join(tmpDir, "large.txt")
 	largeContent := make([]byte, 1024*1024+1) // 1MB + 1 byte
 	for i := range largeContent {
@@ -851,6 +851,15 @@ func TestLoadPreviewLargeFile(t *testing.T) {
 		t.Error("Expected preview to be loaded")
 	}
 
+	if m.preview.tooLarge || m.preview.pager == nil {
+		t.Error("Expected large text file to open in the pager")
+	}
+
+	// Large binary files still can't be previewed
+	largeContent[0] = 0
+	createTestFileWithContent(t, largeFile, largeContent)
+	m.loadPreview(largeFile)
+
 	if !m.preview.tooLarge {
 		t.Error("Expected tooLarge flag to be set")
 	}
@@ -0,0 +1,413 @@
+package main
+
+// Module: follow.go
+// Purpose: Follow mode (like tail -f / less +F) for files that grow while previewed
+// Responsibilities:
+// - Watching the previewed file with its own fsnotify watcher (file and parent directory)
+// - Reading only the bytes appended since the last update
+// - Appending lines to text previews, parsed messages to JSONL conversations and
+//   growing the streaming pager's index
+// - Reloading when the file is truncated or replaced (log rotation)
+// - Keeping the view at the end unless the user has scrolled up
+//
+// The directory watcher (file_watcher.go) batches events over 500ms and reloads the
+// whole preview; while following, the preview is updated from here instead.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	followPollInterval = time.Second // Fallback when fsnotify is unavailable
+	followMaxLineBytes = 8 << 20     // Longest incomplete line carried over between updates
+)
+
+// followState follows the previewed file as it grows
+type followState struct {
+	path    string
+	info    os.FileInfo // Identity of the followed file (a different file at path = rotated)
+	offset  int64       // Bytes of the file shown so far
+	partial string      // Bytes after the last newline (the line still being written)
+	// Text previews: content lines for complete file lines; entries after them show partial
+	complete int
+	missing  bool // File was removed and hasn't come back yet
+
+	watcher *fsnotify.Watcher // nil = poll instead
+	events  chan struct{}     // Coalesced change notifications (closed when the watcher stops)
+}
+
+// followMsg reports that the followed file may have changed
+type followMsg struct {
+	follow *followState
+}
+
+// followable reports whether the current preview can be followed
+func (m model) followable() bool {
+	p := m.preview
+	if !p.loaded || p.filePath == "" || p.isBinary || p.tooLarge || p.isPrompt ||
+		p.table != nil || p.db != nil || p.image != nil || p.tree != nil {
+		return false
+	}
+	// Symlink previews describe the link instead of showing the file
+	info, err := os.Lstat(p.filePath)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// toggleFollow turns follow mode on or off for the previewed file
+func (m *model) toggleFollow() tea.Cmd {
+	if m.preview.follow != nil {
+		m.stopFollow()
+		m.setStatusMessage("Follow mode off", false)
+		return statusTimeoutCmd()
+	}
+	if err := m.startFollow(); err != nil {
+		m.setStatusMessage(err.Error(), true)
+		return statusTimeoutCmd()
+	}
+	m.setStatusMessage(fmt.Sprintf("👁 Following %s - scroll up to pause, G to resume, w to stop", m.preview.fileName), false)
+	return tea.Batch(m.preview.follow.wait(), statusTimeoutCmd())
+}
+
+// startFollow starts following the previewed file and scrolls to its end
+func (m *model) startFollow() error {
+	if !m.followable() {
+		return errors.New("Follow mode works with text files and JSONL conversations")
+	}
+
+	f := &followState{path: filepath.Clean(m.preview.filePath), events: make(chan struct{}, 1)}
+	// Watch the directory too: rotation replaces the file, which ends a watch on the file itself
+	if w, err := fsnotify.NewWatcher(); err == nil {
+		if err := w.Add(filepath.Dir(f.path)); err == nil {
+			w.Add(f.path) // Needed for write events where directory watches don't report them (kqueue)
+			f.watcher = w
+			go f.watch()
+		} else {
+			w.Close()
+		}
+	}
+
+	m.preview.follow = f
+	m.syncFollow()
+	m.followScrollToEnd()
+	return nil
+}
+
+// stopFollow turns follow mode off
+func (m *model) stopFollow() {
+	f := m.preview.follow
+	if f == nil {
+		return
+	}
+	if f.watcher != nil {
+		f.watcher.Close() // Ends watch(), which closes events and releases the waiting command
+	}
+	m.preview.follow = nil
+}
+
+// watch forwards changes to the followed file to the events channel
+func (f *followState) watch() {
+	defer close(f.events)
+	for {
+		select {
+		case event, ok := <-f.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != f.path || event.Op == fsnotify.Chmod {
+				continue
+			}
+			select {
+			case f.events <- struct{}{}:
+			default:
+				// An update is already pending - it reads everything appended so far
+			}
+		case _, ok := <-f.watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// wait returns a command that delivers the next change (or polls without fsnotify)
+func (f *followState) wait() tea.Cmd {
+	if f.watcher == nil {
+		return tea.Tick(followPollInterval, func(time.Time) tea.Msg {
+			return followMsg{follow: f}
+		})
+	}
+	return func() tea.Msg {
+		if _, ok := <-f.events; !ok {
+			return nil
+		}
+		return followMsg{follow: f}
+	}
+}
+
+// handleFollowMsg updates the preview from the followed file and keeps listening
+func (m *model) handleFollowMsg(msg followMsg) tea.Cmd {
+	f := m.preview.follow
+	if f == nil || msg.follow != f {
+		return nil // Follow mode was turned off or moved to another file
+	}
+	m.updateFollow()
+	return f.wait()
+}
+
+// updateFollow brings the preview up to date with the followed file
+func (m *model) updateFollow() {
+	f := m.preview.follow
+	info, err := os.Stat(f.path)
+	if err != nil {
+		// Removed: rotation usually creates the new file right away
+		if !f.missing {
+			f.missing = true
+			m.setStatusMessage(fmt.Sprintf("👁 %s was removed - waiting for it to come back", m.preview.fileName), false)
+		}
+		return
+	}
+
+	switch {
+	case f.missing || !os.SameFile(info, f.info):
+		m.reloadFollow(fmt.Sprintf("👁 %s was replaced - reloaded", m.preview.fileName))
+	case info.Size() < f.offset:
+		m.reloadFollow(fmt.Sprintf("👁 %s was truncated - reloaded", m.preview.fileName))
+	case info.Size() > f.offset:
+		m.appendFollow(info.Size())
+	}
+}
+
+// reloadFollow reloads the followed file from the start (after truncation or rotation)
+func (m *model) reloadFollow(status string) {
+	f := m.preview.follow
+	atEnd := m.followAtEnd()
+
+	m.loadPreview(f.path) // Same path: follow mode stays on
+	m.populatePreviewCache()
+	m.syncFollow()
+	if f.watcher != nil {
+		f.watcher.Add(f.path) // The watch on the old file ended with it
+	}
+	m.refreshSearchMatches()
+	if atEnd {
+		m.followScrollToEnd()
+	}
+	if status != "" {
+		m.setStatusMessage(status, false)
+	}
+}
+
+// syncFollow records how much of the file the freshly loaded preview shows
+func (m *model) syncFollow() {
+	f := m.preview.follow
+	f.missing = false
+	f.partial, f.complete = "", 0
+	if info, err := os.Stat(f.path); err == nil {
+		f.info = info
+	}
+
+	switch {
+	case m.preview.pager != nil:
+		f.offset = m.preview.pager.size
+	case m.preview.isJSONL:
+		f.offset = m.preview.fileSize
+		f.partial = readPartialLine(f.path, f.offset)
+	default:
+		// Text previews hold the whole file: count its lines so appends can
+		// replace the display of the unfinished last line
+		f.offset = m.preview.fileSize
+		data, err := readFileRange(f.path, 0, f.offset)
+		if err != nil {
+			return
+		}
+		f.complete = min(bytes.Count(data, []byte{'\n'}), len(m.preview.content))
+		f.partial = string(data[bytes.LastIndexByte(data, '\n')+1:])
+	}
+}
+
+// appendFollow shows the bytes appended to the followed file up to size
+func (m *model) appendFollow(size int64) {
+	f := m.preview.follow
+	atEnd := m.followAtEnd()
+
+	// Large bursts go through a regular load (which picks the pager or a tail read)
+	if m.preview.pager == nil && size-f.offset > pagerThreshold {
+		m.reloadFollow("")
+		return
+	}
+
+	data, err := readFileRange(f.path, f.offset, size)
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Follow: %v", err), true)
+		return
+	}
+	f.offset = size
+	m.preview.fileSize = size
+
+	switch {
+	case m.preview.pager != nil:
+		m.preview.pager.grow(size)
+	case m.preview.isJSONL:
+		lines := strings.Split(f.partial+string(data), "\n")
+		f.partial = lines[len(lines)-1]
+		m.preview.cachedJSONLMessages = append(m.preview.cachedJSONLMessages, parseJSONLMessages(lines[:len(lines)-1])...)
+	default:
+		m.appendFollowText(string(data))
+		if m.preview.maxPreview > 0 && len(m.preview.content) > m.preview.maxPreview {
+			m.reloadFollow("") // Too many lines to keep in memory - continue in the pager
+		}
+	}
+
+	m.refreshSearchMatches()
+	if atEnd {
+		m.followScrollToEnd()
+	}
+}
+
+// appendFollowText appends text to a text or markdown preview, re-wrapping only the new lines
+func (m *model) appendFollowText(data string) {
+	f := m.preview.follow
+	text := f.partial + data
+	lines := strings.Split(text, "\n")
+	f.partial = lines[len(lines)-1]
+
+	display := lines
+	if m.preview.isSyntaxHighlighted {
+		if highlighted, ok := highlightCode(text, f.path); ok {
+			if hl := strings.Split(highlighted, "\n"); len(hl) >= len(lines) {
+				display = hl[:len(lines)]
+			}
+		}
+	}
+
+	// The entries after the complete lines showed the unfinished line - replace them
+	complete := min(f.complete, len(m.preview.content))
+	replaced := 0
+	if m.preview.cacheValid && !m.preview.isMarkdown {
+		for _, line := range m.preview.content[complete:] {
+			replaced += len(wrapLine(line, m.preview.cachedWidth))
+		}
+	}
+	m.preview.content = append(m.preview.content[:complete], display...)
+	f.complete = complete + len(lines) - 1
+
+	if m.preview.isMarkdown {
+		// Glamour renders the document as a whole
+		m.preview.markdown = parseMarkdownNav(m.preview.content)
+		m.preview.cacheValid = false
+		m.populatePreviewCache()
+		return
+	}
+	if !m.preview.cacheValid || replaced > len(m.preview.cachedWrappedLines) {
+		m.preview.cacheValid = false
+		m.populatePreviewCache()
+		return
+	}
+	wrapped := m.preview.cachedWrappedLines[:len(m.preview.cachedWrappedLines)-replaced]
+	for _, line := range display {
+		wrapped = append(wrapped, wrapLine(line, m.preview.cachedWidth)...)
+	}
+	m.preview.cachedWrappedLines = wrapped
+	m.preview.cachedLineCount = len(wrapped)
+}
+
+// followMaxScroll returns the scroll position that shows the end of the preview
+func (m model) followMaxScroll() int {
+	if m.preview.pager != nil {
+		return m.pagerMaxScroll()
+	}
+	return max(0, m.getWrappedLineCount()-m.getPreviewVisibleLines())
+}
+
+// followAtEnd reports whether the view is at the end (new lines scroll into view)
+func (m model) followAtEnd() bool {
+	if p := m.preview.pager; p != nil && p.pendingEnd {
+		return true
+	}
+	return m.preview.scrollPos >= m.followMaxScroll()
+}
+
+// followScrollToEnd scrolls to the end (the pager jumps once indexing gets there)
+func (m *model) followScrollToEnd() {
+	if p := m.preview.pager; p != nil && !p.indexed {
+		p.pendingEnd = true
+		return
+	}
+	m.preview.scrollPos = m.followMaxScroll()
+}
+
+// followBadge returns the title bar marker for follow mode
+func (m model) followBadge() string {
+	if m.preview.follow == nil {
+		return ""
+	}
+	if !m.followAtEnd() {
+		return " [Following: paused, G to resume]"
+	}
+	return " [Following]"
+}
+
+// readFileRange reads bytes [from, to) of path
+func readFileRange(path string, from, to int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data := make([]byte, to-from)
+	n, err := io.ReadFull(io.NewSectionReader(f, from, to-from), data)
+	if err == io.ErrUnexpectedEOF {
+		err = nil // Truncated meanwhile - the next update reloads
+	}
+	return data[:n], err
+}
+
+// readPartialLine returns the bytes between the last newline before offset and offset
+func readPartialLine(path string, offset int64) string {
+	const chunk = 64 * 1024
+	var tail []byte
+	for end := offset; end > 0 && int64(len(tail)) < followMaxLineBytes; end -= chunk {
+		data, err := readFileRange(path, max64(0, end-chunk), end)
+		if err != nil {
+			return ""
+		}
+		if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+			return string(append(data[i+1:], tail...))
+		}
+		tail = append(data, tail...)
+	}
+	if int64(len(tail)) >= followMaxLineBytes {
+		return "" // Too long to carry over (it won't parse as a message anyway)
+	}
+	return string(tail)
+}
+
+// parseJSONLMessages parses conversation lines, skipping blank and malformed ones
+// Used by: loadJSONLPreview, appendFollow
+func parseJSONLMessages(rawLines []string) []jsonlMessage {
+	messages := make([]jsonlMessage, 0, len(rawLines))
+	for _, line := range rawLines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var msg jsonlMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
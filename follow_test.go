@@ -0,0 +1,225 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func appendToFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// plainLines strips styling so incremental and full loads can be compared
+func plainLines(lines []string) string {
+	var plain []string
+	for _, line := range lines {
+		text, _ := ansiPlainText(line)
+		plain = append(plain, text)
+	}
+	return strings.Join(plain, "\n")
+}
+
+func TestFollowText(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	os.WriteFile(path, []byte("one\ntwo\npart"), 0644)
+
+	m := model{height: 20, width: 100, viewMode: viewFullPreview}
+	m.loadPreview(path)
+	m.populatePreviewCache()
+	if err := m.startFollow(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.stopFollow()
+
+	var lines []string
+	for i := 0; i < 40; i++ {
+		lines = append(lines, strings.Repeat("x", i*5)) // Some lines wrap
+	}
+	appendToFile(t, path, "ial\n"+strings.Join(lines, "\n")+"\nnext")
+	m.updateFollow()
+
+	// Same lines and wrapping as loading the file from scratch
+	fresh := model{height: 20, width: 100, viewMode: viewFullPreview}
+	fresh.loadPreview(path)
+	fresh.populatePreviewCache()
+	if plainLines(m.preview.content) != plainLines(fresh.preview.content) {
+		t.Fatalf("Appended content differs from a full load:\n%s", plainLines(m.preview.content))
+	}
+	if plainLines(m.preview.cachedWrappedLines) != plainLines(fresh.preview.cachedWrappedLines) {
+		t.Errorf("Incremental wrapping differs from a full load")
+	}
+	if m.preview.content[2] != "partial" || m.preview.fileSize != fresh.preview.fileSize {
+		t.Errorf("Expected the unfinished line completed, got %q", m.preview.content[2])
+	}
+	if m.preview.scrollPos != m.followMaxScroll() || m.preview.scrollPos == 0 {
+		t.Errorf("Expected to stay at the end, at %d of %d", m.preview.scrollPos, m.followMaxScroll())
+	}
+
+	// Scrolled up: new lines don't move the view
+	m.preview.scrollPos = 3
+	appendToFile(t, path, " line\nmore\n")
+	m.updateFollow()
+	if m.preview.scrollPos != 3 || !strings.Contains(m.followBadge(), "paused") {
+		t.Errorf("Expected the view to stay put while paused, at %d", m.preview.scrollPos)
+	}
+	if got := m.preview.content[len(m.preview.content)-3]; got != "next line" {
+		t.Errorf("Expected the partial line completed, got %q", got)
+	}
+
+	// Truncated (logrotate copytruncate): reloaded from the start
+	m.preview.scrollPos = m.followMaxScroll()
+	os.WriteFile(path, []byte("fresh\n"), 0644)
+	m.updateFollow()
+	if m.preview.follow == nil || plainLines(m.preview.content) != "fresh\n" {
+		t.Fatalf("Expected a reload after truncation, got %q", m.preview.content)
+	}
+
+	// Rotated: removed, then a new file appears at the path
+	os.Rename(path, path+".1")
+	m.updateFollow()
+	if !m.preview.follow.missing {
+		t.Error("Expected to wait for the file to come back")
+	}
+	os.WriteFile(path, []byte("rotated\n"), 0644)
+	m.updateFollow()
+	if plainLines(m.preview.content) != "rotated\n" || m.preview.follow.missing {
+		t.Errorf("Expected the new file after rotation, got %q", m.preview.content)
+	}
+
+	// Previewing another file ends follow mode
+	m.loadPreview(path + ".1")
+	if m.preview.follow != nil {
+		t.Error("Expected follow mode to stop on another file")
+	}
+}
+
+func TestFollowJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	os.WriteFile(path, []byte(`{"type":"user","message":{"role":"user","content":"first"}}`+"\n"), 0644)
+
+	m := model{height: 20, width: 100, viewMode: viewFullPreview}
+	m.loadPreview(path)
+	if err := m.startFollow(); err != nil {
+		t.Fatal(err)
+	}
+	defer m.stopFollow()
+
+	// A message written in two parts is only parsed once complete
+	appendToFile(t, path, `{"type":"user","message":{"role":"user",`)
+	m.updateFollow()
+	if len(m.preview.cachedJSONLMessages) != 1 {
+		t.Fatalf("Expected the incomplete message to wait, got %d messages", len(m.preview.cachedJSONLMessages))
+	}
+	appendToFile(t, path, `"content":"second"}}`+"\nnot json\n")
+	m.updateFollow()
+	if len(m.preview.cachedJSONLMessages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(m.preview.cachedJSONLMessages))
+	}
+	if out := m.renderPreview(18); !strings.Contains(out, "second") {
+		t.Errorf("Expected the new message in the preview:\n%s", out)
+	}
+}
+
+func TestPagerGrow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.log")
+	os.WriteFile(path, []byte("a\nb\n"), 0644)
+	p, err := newPagerState(path, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.lineCount() != 2 {
+		t.Fatalf("Expected 2 lines, got %d", p.lineCount())
+	}
+	p.visibleLines(0, 10)
+
+	appendToFile(t, path, "c\nd")
+	p.grow(7)
+	for !p.indexed {
+		p.indexStep(pagerIndexBudget)
+	}
+	lines, _ := p.visibleLines(0, 10)
+	if p.lineCount() != 4 || strings.Join(lines, ",") != "a,b,c,d" {
+		t.Errorf("Expected the appended lines, got %d: %v", p.lineCount(), lines)
+	}
+
+	// Continuing the unfinished last line doesn't add a line
+	appendToFile(t, path, "d\n")
+	p.grow(9)
+	for !p.indexed {
+		p.indexStep(pagerIndexBudget)
+	}
+	lines, _ = p.visibleLines(0, 10)
+	if p.lineCount() != 4 || lines[3] != "dd" {
+		t.Errorf("Expected the last line extended, got %d: %v", p.lineCount(), lines)
+	}
+}
+
+func TestFollowWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agent.log")
+	os.WriteFile(path, []byte("start\n"), 0644)
+
+	m := model{height: 20, width: 100, viewMode: viewFullPreview}
+	m.loadPreview(path)
+	m.populatePreviewCache()
+	cmd := m.toggleFollow()
+	f := m.preview.follow
+	if f == nil || cmd == nil {
+		t.Fatal("Expected follow mode on")
+	}
+	if f.watcher == nil {
+		t.Skip("fsnotify unavailable")
+	}
+
+	got := make(chan any, 1)
+	go func() { got <- f.wait()() }()
+	appendToFile(t, path, "written\n")
+	select {
+	case msg := <-got:
+		fm, ok := msg.(followMsg)
+		if !ok {
+			t.Fatalf("Expected a followMsg, got %T", msg)
+		}
+		m.handleFollowMsg(fm)
+	case <-time.After(5 * time.Second):
+		t.Fatal("No change reported for the followed file")
+	}
+	if plainLines(m.preview.content) != "start\nwritten\n" {
+		t.Errorf("Unexpected content %q", m.preview.content)
+	}
+
+	// Turning it off releases the waiting command (a change still queued is ignored)
+	m.toggleFollow()
+	go func() {
+		for {
+			msg := f.wait()()
+			if msg == nil {
+				got <- nil
+				return
+			}
+			if m.handleFollowMsg(msg.(followMsg)) != nil {
+				got <- msg
+				return
+			}
+		}
+	}()
+	select {
+	case msg := <-got:
+		if msg != nil {
+			t.Errorf("Expected no more updates after stopping, got %T", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Waiting command not released")
+	}
+}
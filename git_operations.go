@@ -224,6 +224,13 @@ type gitStatus struct {
 // getGitStatus returns comprehensive git status for a repository
 // Returns empty gitStatus if not a git repo or error occurs
 func getGitStatus(repoPath string) gitStatus {
+	return getGitStatusWithBranches(repoPath, nil)
+}
+
+// getGitStatusWithBranches is getGitStatus reusing already listed branches (nil = list them).
+// Ahead/behind come from the branches' upstream tracking info, so a repo costs one
+// git status and at most one for-each-ref covering all its branches.
+func getGitStatusWithBranches(repoPath string, branches []gitBranchInfo) gitStatus {
 	status := gitStatus{}
 
 	// Check if it's a git repo
@@ -237,10 +244,18 @@ func getGitStatus(repoPath string) gitStatus {
 	// Check for uncommitted changes
 	status.dirty = hasUncommittedChanges(repoPath)
 
-	// Get ahead/behind counts by reading refs
-	ahead, behind := getAheadBehindCounts(repoPath, status.branch)
-	status.ahead = ahead
-	status.behind = behind
+	// Get ahead/behind counts of the checked out branch
+	if branches == nil {
+		if out, err := runGit(repoPath, "", "for-each-ref", "--format="+branchRefFormat, "refs/heads"); err == nil {
+			branches = parseBranchRefs(out)
+		}
+	}
+	for _, b := range branches {
+		if b.current && !b.remote {
+			status.ahead = b.ahead
+			status.behind = b.behind
+		}
+	}
 
 	// Get last commit info
 	commitMsg, commitTime := getLastCommitInfo(repoPath)
@@ -250,62 +265,6 @@ func getGitStatus(repoPath string) gitStatus {
 	return status
 }
 
-// getAheadBehindCounts returns how many commits ahead/behind the remote
-// Returns (0, 0) if no remote or error occurs
-func getAheadBehindCounts(repoPath, branch string) (int, int) {
-	if branch == "" {
-		return 0, 0
-	}
-
-	// Read local branch ref
-	localRef := filepath.Join(repoPath, ".git", "refs", "heads", branch)
-	localHash, err := os.ReadFile(localRef)
-	if err != nil {
-		return 0, 0
-	}
-	localCommit := strings.TrimSpace(string(localHash))
-
-	// Read remote branch ref (assuming origin)
-	remoteRef := filepath.Join(repoPath, ".git", "refs", "remotes", "origin", branch)
-	remoteHash, err := os.ReadFile(remoteRef)
-	if err != nil {
-		// No remote tracking branch
-		return 0, 0
-	}
-	remoteCommit := strings.TrimSpace(string(remoteHash))
-
-	// If commits are the same, we're in sync
-	if localCommit == remoteCommit {
-		return 0, 0
-	}
-
-	// Count commits ahead and behind using git log
-	// This is a simplified check - real implementation would parse git objects
-	// For now, we'll use a heuristic based on commit hash comparison
-	// If local != remote, we're either ahead or behind (or diverged)
-
-	// Try to determine ahead/behind by checking packed-refs as fallback
-	ahead, behind := checkPackedRefs(repoPath, branch, localCommit, remoteCommit)
-
-	return ahead, behind
-}
-
-// checkPackedRefs checks packed-refs file for commit history
-// This is a simplified heuristic - not 100% accurate
-func checkPackedRefs(repoPath, branch, localCommit, remoteCommit string) (int, int) {
-	// For now, if commits differ, assume we're ahead by 1
-	// A proper implementation would parse the git object database
-	// This is a placeholder for the real git log parsing
-
-	// Simple heuristic: if local and remote differ, mark as diverged (1 ahead, 1 behind)
-	// Real implementation would use: git rev-list --count local..remote
-	if localCommit != remoteCommit {
-		return 1, 0 // Assume ahead for now
-	}
-
-	return 0, 0
-}
-
 // getLastCommitInfo returns the last commit message and time
 // Returns empty string and zero time if error occurs
 func getLastCommitInfo(repoPath string) (string, time.Time) {
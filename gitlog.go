@@ -0,0 +1,968 @@
+package main
+
+// Module: gitlog.go
+// Purpose: Commit log browser for the current repository (L, Git → Log)
+// Responsibilities:
+// - Listing commits with a compact ASCII branch graph, author and relative date
+// - Reading the log a page at a time from one running git log as the cursor nears the end,
+//   so huge repositories open instantly and scrolling never re-walks the history
+// - Filtering by author, path and message text
+// - Showing a commit's files and full diff, and the diff of one file at that commit
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	gitLogPageSize      = 200 // Commits read from git log per page
+	gitLogGraphMaxWidth = 24  // Graph columns shown before the graph is cut off
+)
+
+// Log filter prompts
+const (
+	gitLogPromptNone = iota
+	gitLogPromptAuthor
+	gitLogPromptPath
+	gitLogPromptMessage
+)
+
+// gitLogCommit is one commit in the log
+type gitLogCommit struct {
+	hash    string
+	parents []string // Rewritten by git to the listed commits when filtering by path
+	author  string
+	time    time.Time
+	subject string
+	graph   string // Graph cells left of the commit
+}
+
+// gitLogFilter limits the commits listed
+type gitLogFilter struct {
+	author  string // --author pattern
+	path    string // Pathspec, relative to the repository root
+	message string // --grep pattern (case-insensitive)
+}
+
+// gitLogGraph lays out the branch graph one commit at a time: each lane holds the
+// hash of the commit it leads to, so later pages continue the graph where it stopped
+type gitLogGraph struct {
+	lanes []string
+}
+
+// gitLogState is the log browser for a repository
+type gitLogState struct {
+	root       string
+	filter     gitLogFilter
+	commits    []gitLogCommit
+	graph      *gitLogGraph // nil while filtering by author or message (parents aren't listed)
+	graphWidth int          // Widest graph so far, to keep the columns aligned
+	stream     *gitLogStream
+	loading    bool
+	done       bool // Every commit has been read
+	err        error
+	cursor     int
+	returnMode viewMode // View to go back to on close
+
+	prompt int    // Filter being edited
+	input  string // Text typed into the prompt
+
+	commit *gitLogCommitView // Files and diff of the opened commit
+}
+
+// gitLogStream is the git log the pages are read from. It keeps running between
+// pages, so each page continues where the last one stopped.
+type gitLogStream struct {
+	cmd      *exec.Cmd
+	out      *bufio.Reader
+	stderr   bytes.Buffer
+	waitOnce sync.Once
+	waitErr  error
+}
+
+// gitChangedPath is a file changed by a commit (git diff --name-status)
+type gitChangedPath struct {
+	status  string // A, M, D, R (renamed), C (copied), T (type change)
+	path    string
+	oldPath string // Path before a rename or copy
+}
+
+// gitLogCommitView is a commit opened from the log: header, file list and full diff
+type gitLogCommitView struct {
+	commit      gitLogCommit
+	base        string // Revision the commit is compared with; empty for a root commit
+	loading     bool
+	header      []string
+	files       []gitChangedPath
+	lines       []string // Full diff
+	err         error
+	cursor      int // Selected file
+	savedScroll int
+
+	file *gitLogFileView // Diff of one file opened with Enter
+}
+
+// gitLogFileView is the diff of one file at the opened commit
+type gitLogFileView struct {
+	file        gitChangedPath
+	loading     bool
+	lines       []string
+	err         error
+	savedScroll int
+}
+
+// gitLogPageMsg delivers a page of commits
+type gitLogPageMsg struct {
+	log     *gitLogState
+	commits []gitLogCommit
+	done    bool // git log has listed every commit
+	err     error
+}
+
+// gitLogCommitMsg delivers an opened commit's header, files and diff
+type gitLogCommitMsg struct {
+	view   *gitLogCommitView
+	base   string
+	header []string
+	files  []gitChangedPath
+	lines  []string
+	err    error
+}
+
+// gitLogFileMsg delivers the diff of one file
+type gitLogFileMsg struct {
+	view  *gitLogFileView
+	lines []string
+	err   error
+}
+
+// gitLogFormat is the git log format read by parseGitLog (fields split by \x1f)
+const gitLogFormat = "--format=%H%x1f%P%x1f%an%x1f%at%x1f%s"
+
+// parseGitLog parses git log output in the gitLogFormat
+func parseGitLog(out string) []gitLogCommit {
+	var commits []gitLogCommit
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x1f", 5)
+		if len(fields) < 5 {
+			continue
+		}
+		c := gitLogCommit{hash: fields[0], parents: strings.Fields(fields[1]), author: fields[2], subject: fields[4]}
+		if unix, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			c.time = time.Unix(unix, 0)
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+// gitLogArgs builds the git log command line.
+// --parents makes git rewrite parents to listed commits when limiting to a path;
+// --topo-order lists children before their parents even when commit dates are skewed,
+// which the graph relies on.
+func gitLogArgs(filter gitLogFilter) []string {
+	args := []string{"log", "--parents", "--topo-order", "--no-color", gitLogFormat}
+	if filter.author != "" {
+		args = append(args, "--author="+filter.author)
+	}
+	if filter.message != "" {
+		args = append(args, "--regexp-ignore-case", "--grep="+filter.message)
+	}
+	args = append(args, "--")
+	if filter.path != "" {
+		args = append(args, filter.path)
+	}
+	return args
+}
+
+// startGitLogStream starts git log for the repository at root
+func startGitLogStream(root string, filter gitLogFilter) (*gitLogStream, error) {
+	st := &gitLogStream{cmd: exec.Command("git", append([]string{"-C", root}, gitLogArgs(filter)...)...)}
+	st.cmd.Stderr = &st.stderr
+	out, err := st.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := st.cmd.Start(); err != nil {
+		return nil, err
+	}
+	st.out = bufio.NewReader(out)
+	return st, nil
+}
+
+// readPage reads the next page of commits; done is set once git log has finished
+func (st *gitLogStream) readPage() (commits []gitLogCommit, done bool, err error) {
+	var page strings.Builder
+	for n := 0; n < gitLogPageSize; n++ {
+		line, err := st.out.ReadString('\n')
+		page.WriteString(line)
+		if err == io.EOF {
+			return parseGitLog(page.String()), true, st.wait()
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return parseGitLog(page.String()), false, nil
+}
+
+// wait reaps git log, reporting its error output if it failed
+func (st *gitLogStream) wait() error {
+	st.waitOnce.Do(func() {
+		if err := st.cmd.Wait(); err != nil {
+			st.waitErr = err
+			if text := strings.TrimSpace(st.stderr.String()); text != "" {
+				st.waitErr = fmt.Errorf("%s", text)
+			}
+		}
+	})
+	return st.waitErr
+}
+
+// close stops git log when the log is closed or reloaded before reaching the end
+func (st *gitLogStream) close() {
+	if st.cmd.Process != nil {
+		st.cmd.Process.Kill()
+	}
+	go st.wait()
+}
+
+// gitLogPageCmd reads the next page of commits in the background
+func gitLogPageCmd(s *gitLogState) tea.Cmd {
+	st := s.stream
+	return func() tea.Msg {
+		commits, done, err := st.readPage()
+		return gitLogPageMsg{log: s, commits: commits, done: done, err: err}
+	}
+}
+
+// next places a commit in the graph and returns its row: * for the commit, | for
+// lanes passing by, / or \ for lanes ending in the commit and lanes its merge opens
+func (g *gitLogGraph) next(hash string, parents []string) string {
+	col := g.lane(hash, 0)
+	if col < 0 {
+		col = g.lane("", 0)
+		g.lanes[col] = hash
+	}
+	cells := make([]byte, len(g.lanes))
+	for i, h := range g.lanes {
+		switch {
+		case i == col:
+			cells[i] = '*'
+		case h == hash:
+			// Another branch comes together in this commit (col is the first lane leading here)
+			cells[i] = '/'
+			g.lanes[i] = ""
+		case h != "":
+			cells[i] = '|'
+		default:
+			cells[i] = ' '
+		}
+	}
+
+	g.lanes[col] = ""
+	if len(parents) > 0 {
+		g.lanes[col] = parents[0]
+	}
+	for _, parent := range parents[min(len(parents), 1):] {
+		if g.lane(parent, 0) >= 0 {
+			continue // Already on its way there
+		}
+		// A merged branch opens a lane to the right
+		i := g.lane("", col+1)
+		g.lanes[i] = parent
+		for len(cells) <= i {
+			cells = append(cells, ' ')
+		}
+		cells[i] = '\\'
+	}
+	for len(g.lanes) > 0 && g.lanes[len(g.lanes)-1] == "" {
+		g.lanes = g.lanes[:len(g.lanes)-1]
+	}
+
+	row := make([]string, len(cells))
+	for i, c := range cells {
+		row[i] = string(c)
+	}
+	return strings.TrimRight(strings.Join(row, " "), " ")
+}
+
+// lane returns the first lane from start on leading to hash; for an empty hash
+// a free lane is returned, added at the end when there is none
+func (g *gitLogGraph) lane(hash string, start int) int {
+	for i := start; i < len(g.lanes); i++ {
+		if g.lanes[i] == hash {
+			return i
+		}
+	}
+	if hash != "" {
+		return -1
+	}
+	g.lanes = append(g.lanes, "")
+	return len(g.lanes) - 1
+}
+
+// parseNameStatus parses `git diff --name-status -z` output
+func parseNameStatus(out string) []gitChangedPath {
+	fields := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	var files []gitChangedPath
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] == "" {
+			continue
+		}
+		f := gitChangedPath{status: fields[i][:1], path: fields[i+1]}
+		// Renames and copies carry the old and the new path
+		if (f.status == "R" || f.status == "C") && i+2 < len(fields) {
+			f.oldPath, f.path = f.path, fields[i+2]
+			i++
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// gitLogDiffArgs builds a diff-tree command line comparing a commit with base
+// (a root commit is compared with the empty tree)
+func gitLogDiffArgs(base, hash string, extra ...string) []string {
+	args := append([]string{"diff-tree", "-r", "-M", "--no-color", "--no-commit-id"}, extra...)
+	if base == "" {
+		return append(args, "--root", hash)
+	}
+	return append(args, base, hash)
+}
+
+// gitLogCommitCmd loads a commit's header, changed files and diff against its first parent
+func gitLogCommitCmd(root string, v *gitLogCommitView) tea.Cmd {
+	hash := v.commit.hash
+	return func() tea.Msg {
+		header, err := runGit(root, "", "show", "-s", "--no-color", hash)
+		if err != nil {
+			return gitLogCommitMsg{view: v, err: err}
+		}
+		// The real parent, not the one rewritten for a path filter
+		base := ""
+		if _, err := runGit(root, "", "rev-parse", "--verify", "-q", hash+"^"); err == nil {
+			base = hash + "^"
+		}
+		names, err := runGit(root, "", gitLogDiffArgs(base, hash, "--name-status", "-z")...)
+		if err != nil {
+			return gitLogCommitMsg{view: v, err: err}
+		}
+		diff, err := runGit(root, "", gitLogDiffArgs(base, hash, "-p")...)
+		if err != nil {
+			return gitLogCommitMsg{view: v, err: err}
+		}
+		msg := gitLogCommitMsg{view: v, base: base, files: parseNameStatus(names),
+			header: strings.Split(strings.TrimRight(header, "\n"), "\n")}
+		if diff = strings.TrimRight(diff, "\n"); diff != "" {
+			msg.lines = strings.Split(diff, "\n")
+		}
+		return msg
+	}
+}
+
+// gitLogFileCmd loads the diff of one file at the opened commit
+func gitLogFileCmd(root string, c *gitLogCommitView, v *gitLogFileView) tea.Cmd {
+	args := append(gitLogDiffArgs(c.base, c.commit.hash, "-p"), "--", v.file.path)
+	if v.file.oldPath != "" {
+		args = append(args, v.file.oldPath) // Both sides, so the rename is detected
+	}
+	return func() tea.Msg {
+		out, err := runGit(root, "", args...)
+		if err != nil {
+			return gitLogFileMsg{view: v, err: err}
+		}
+		return gitLogFileMsg{view: v, lines: strings.Split(strings.TrimRight(out, "\n"), "\n")}
+	}
+}
+
+// openGitLog lists the repository's commits (the selected one in git repos mode) in the full-screen preview
+func (m *model) openGitLog() tea.Cmd {
+	root := m.selectedRepoRoot()
+	if root == "" {
+		m.setStatusMessage("Not inside a git repository", true)
+		return statusTimeoutCmd()
+	}
+	m.gitLog = &gitLogState{root: root, returnMode: m.viewMode}
+	m.preview.scrollPos = 0
+	m.viewMode = viewFullPreview
+	m.searchMode = false
+	m.calculateLayout()
+	return m.reloadGitLog()
+}
+
+// closeGitLog goes back to the view the log was opened from
+func (m *model) closeGitLog() {
+	if st := m.gitLog.stream; st != nil {
+		st.close()
+	}
+	m.viewMode = m.gitLog.returnMode
+	m.gitLog = nil
+	m.preview.scrollPos = 0
+	m.calculateLayout()
+	m.populatePreviewCache()
+}
+
+// reloadGitLog starts over from the first page, after opening or a filter change
+func (m *model) reloadGitLog() tea.Cmd {
+	old := m.gitLog
+	s := &gitLogState{root: old.root, filter: old.filter, returnMode: old.returnMode, loading: true}
+	// Author and message filters leave gaps between commits and their parents
+	if s.filter.author == "" && s.filter.message == "" {
+		s.graph = &gitLogGraph{}
+	}
+	if old.stream != nil {
+		old.stream.close()
+	}
+	m.gitLog = s
+	m.preview.scrollPos = 0
+
+	stream, err := startGitLogStream(s.root, s.filter)
+	if err != nil {
+		s.loading = false
+		s.err = err
+		m.setStatusMessage(fmt.Sprintf("Log failed: %v", err), true)
+		return statusTimeoutCmd()
+	}
+	s.stream = stream
+	m.setStatusMessage("Loading log...", false)
+	return gitLogPageCmd(s)
+}
+
+// applyGitLogPageMsg appends a loaded page (results for a closed or reloaded log are ignored)
+func (m *model) applyGitLogPageMsg(msg gitLogPageMsg) tea.Cmd {
+	s := m.gitLog
+	if s == nil || msg.log != s {
+		return nil
+	}
+	s.loading = false
+	if msg.err != nil {
+		s.err = msg.err
+		m.setStatusMessage(fmt.Sprintf("Log failed: %v", msg.err), true)
+		return statusTimeoutCmd()
+	}
+	first := len(s.commits) == 0
+	for _, c := range msg.commits {
+		if s.graph != nil {
+			c.graph = s.graph.next(c.hash, c.parents)
+			s.graphWidth = max(s.graphWidth, min(len(c.graph), gitLogGraphMaxWidth))
+		}
+		s.commits = append(s.commits, c)
+	}
+	s.done = msg.done
+	if first {
+		if len(s.commits) == 0 {
+			m.setStatusMessage("No matching commits", false)
+		} else {
+			m.setStatusMessage(fmt.Sprintf("%s | Enter: files and diff, a/p/t: filter by author/path/text", m.gitLogCount()), false)
+		}
+		return statusTimeoutCmd()
+	}
+	// Keep going while the cursor is still near the end (G pressed before the page arrived)
+	return m.loadMoreGitLog()
+}
+
+// loadMoreGitLog reads the next page once the cursor gets within a screen of the last loaded commit
+func (m *model) loadMoreGitLog() tea.Cmd {
+	s := m.gitLog
+	if s.loading || s.done || s.err != nil {
+		return nil
+	}
+	if s.cursor+2*max(m.getPreviewVisibleLines(), 1) < len(s.commits) {
+		return nil
+	}
+	s.loading = true
+	return gitLogPageCmd(s)
+}
+
+// gitLogCount formats the number of loaded commits ("200+" while more are left)
+func (m model) gitLogCount() string {
+	s := m.gitLog
+	count := pluralize(len(s.commits), "commit")
+	if !s.done {
+		count = fmt.Sprintf("%d+ commits", len(s.commits))
+	}
+	return count
+}
+
+// applyGitLogCommitMsg shows a loaded commit unless another one was opened meanwhile
+func (m *model) applyGitLogCommitMsg(msg gitLogCommitMsg) {
+	s := m.gitLog
+	if s == nil || s.commit != msg.view {
+		return
+	}
+	v := s.commit
+	v.loading = false
+	v.base, v.header, v.files, v.lines, v.err = msg.base, msg.header, msg.files, msg.lines, msg.err
+}
+
+// applyGitLogFileMsg shows a loaded file diff unless another one was opened meanwhile
+func (m *model) applyGitLogFileMsg(msg gitLogFileMsg) {
+	s := m.gitLog
+	if s == nil || s.commit == nil || s.commit.file != msg.view {
+		return
+	}
+	v := s.commit.file
+	v.loading = false
+	v.lines, v.err = msg.lines, msg.err
+}
+
+// selected returns the commit under the cursor
+func (s *gitLogState) selected() *gitLogCommit {
+	if s.cursor < len(s.commits) {
+		return &s.commits[s.cursor]
+	}
+	return nil
+}
+
+// moveGitLogCursor moves the selected commit, scrolls to keep it visible and loads more when near the end
+func (m *model) moveGitLogCursor(delta int) tea.Cmd {
+	s := m.gitLog
+	s.cursor = max(0, min(s.cursor+delta, len(s.commits)-1))
+	visible := max(m.gitLogVisibleRows(), 1)
+	if s.cursor < m.preview.scrollPos {
+		m.preview.scrollPos = s.cursor
+	} else if s.cursor >= m.preview.scrollPos+visible {
+		m.preview.scrollPos = s.cursor - visible + 1
+	}
+	return m.loadMoreGitLog()
+}
+
+// openGitLogCommit shows the selected commit's files and diff
+func (m *model) openGitLogCommit() tea.Cmd {
+	s := m.gitLog
+	c := s.selected()
+	if c == nil {
+		return nil
+	}
+	s.commit = &gitLogCommitView{commit: *c, loading: true, savedScroll: m.preview.scrollPos}
+	m.preview.scrollPos = 0
+	return gitLogCommitCmd(s.root, s.commit)
+}
+
+// closeGitLogCommit returns to the commit list
+func (m *model) closeGitLogCommit() {
+	s := m.gitLog
+	m.preview.scrollPos = s.commit.savedScroll
+	s.commit = nil
+}
+
+// openGitLogFile shows the diff of the selected file at the opened commit
+func (m *model) openGitLogFile() tea.Cmd {
+	c := m.gitLog.commit
+	if c.loading || c.cursor >= len(c.files) {
+		return nil
+	}
+	c.file = &gitLogFileView{file: c.files[c.cursor], loading: true, savedScroll: m.preview.scrollPos}
+	m.preview.scrollPos = 0
+	return gitLogFileCmd(m.gitLog.root, c, c.file)
+}
+
+// closeGitLogFile returns to the commit's files and diff
+func (m *model) closeGitLogFile() {
+	c := m.gitLog.commit
+	m.preview.scrollPos = c.file.savedScroll
+	c.file = nil
+}
+
+// moveGitLogFileCursor moves the selected file of the opened commit and scrolls to keep it visible
+func (m *model) moveGitLogFileCursor(delta int) {
+	c := m.gitLog.commit
+	if len(c.files) == 0 {
+		return
+	}
+	c.cursor = max(0, min(c.cursor+delta, len(c.files)-1))
+	row := m.gitLogFileStart() + c.cursor
+	visible := max(m.getPreviewVisibleLines(), 1)
+	if row < m.preview.scrollPos {
+		m.preview.scrollPos = row
+	} else if row >= m.preview.scrollPos+visible {
+		m.preview.scrollPos = row - visible + 1
+	}
+}
+
+// startGitLogPrompt starts editing one of the filters, prefilled with its value
+func (m *model) startGitLogPrompt(prompt int) {
+	s := m.gitLog
+	s.prompt = prompt
+	switch prompt {
+	case gitLogPromptAuthor:
+		s.input = s.filter.author
+	case gitLogPromptPath:
+		s.input = s.filter.path
+	case gitLogPromptMessage:
+		s.input = s.filter.message
+	}
+}
+
+// submitGitLogPrompt applies the edited filter and reloads the log
+func (m *model) submitGitLogPrompt() tea.Cmd {
+	s := m.gitLog
+	input := strings.TrimSpace(s.input)
+	switch s.prompt {
+	case gitLogPromptAuthor:
+		s.filter.author = input
+	case gitLogPromptPath:
+		// Paths are typed relative to the repository root
+		s.filter.path = filepath.ToSlash(strings.TrimPrefix(input, "/"))
+	case gitLogPromptMessage:
+		s.filter.message = input
+	}
+	s.prompt = gitLogPromptNone
+	s.input = ""
+	return m.reloadGitLog()
+}
+
+// handleGitLogKey handles the log browser's keys; false lets quit, help and search through
+func (m *model) handleGitLogKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	s := m.gitLog
+	if s == nil {
+		return false, nil
+	}
+	key := msg.String()
+
+	if s.prompt != gitLogPromptNone {
+		switch key {
+		case "esc":
+			s.prompt = gitLogPromptNone
+			s.input = ""
+		case "enter":
+			return true, m.submitGitLogPrompt()
+		case "backspace":
+			if len(s.input) > 0 {
+				_, size := utf8.DecodeLastRuneInString(s.input)
+				s.input = s.input[:len(s.input)-size]
+			}
+		default:
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				s.input += string(msg.Runes)
+			}
+		}
+		return true, nil
+	}
+
+	switch key {
+	case "q", "ctrl+c", "f10", "f1", "ctrl+f", "/", "m", "M":
+		return false, nil
+	case "L":
+		m.closeGitLog()
+		return true, tea.ClearScreen
+	}
+
+	if c := s.commit; c != nil {
+		visible := m.getPreviewVisibleLines()
+		maxScroll := max(m.gitLogLineCount()-visible, 0)
+		switch key {
+		case "pageup", "pgup":
+			m.preview.scrollPos = max(m.preview.scrollPos-visible, 0)
+		case "pagedown", "pgdn", "pgdown", " ":
+			m.preview.scrollPos = min(m.preview.scrollPos+visible, maxScroll)
+		case "home", "g":
+			m.preview.scrollPos = 0
+		case "end", "G":
+			m.preview.scrollPos = maxScroll
+		case "D":
+			m.toggleDiffSideBySide()
+		}
+		if c.file != nil {
+			switch key {
+			case "esc", "backspace", "enter":
+				m.closeGitLogFile()
+			case "up", "k":
+				m.preview.scrollPos = max(m.preview.scrollPos-1, 0)
+			case "down", "j":
+				m.preview.scrollPos = min(m.preview.scrollPos+1, maxScroll)
+			}
+			return true, nil
+		}
+		switch key {
+		case "esc", "backspace":
+			m.closeGitLogCommit()
+		case "up", "k":
+			m.moveGitLogFileCursor(-1)
+		case "down", "j":
+			m.moveGitLogFileCursor(1)
+		case "enter":
+			return true, m.openGitLogFile()
+		}
+		return true, nil
+	}
+
+	switch key {
+	case "up", "k":
+		return true, m.moveGitLogCursor(-1)
+	case "down", "j":
+		return true, m.moveGitLogCursor(1)
+	case "pageup", "pgup":
+		return true, m.moveGitLogCursor(-m.gitLogVisibleRows())
+	case "pagedown", "pgdn", "pgdown":
+		return true, m.moveGitLogCursor(m.gitLogVisibleRows())
+	case "home", "g":
+		return true, m.moveGitLogCursor(-len(s.commits))
+	case "end", "G":
+		return true, m.moveGitLogCursor(len(s.commits))
+	case "enter":
+		return true, m.openGitLogCommit()
+	case "a":
+		m.startGitLogPrompt(gitLogPromptAuthor)
+	case "p":
+		m.startGitLogPrompt(gitLogPromptPath)
+	case "t":
+		m.startGitLogPrompt(gitLogPromptMessage)
+	case "c":
+		if s.filter != (gitLogFilter{}) {
+			s.filter = gitLogFilter{}
+			return true, m.reloadGitLog()
+		}
+	case "esc":
+		m.closeGitLog()
+		return true, tea.ClearScreen
+	}
+	return true, nil
+}
+
+// gitLogVisibleRows returns the commit rows that fit (the filter prompt takes the first line)
+func (m model) gitLogVisibleRows() int {
+	rows := m.getPreviewVisibleLines()
+	if m.gitLog.prompt != gitLogPromptNone {
+		rows--
+	}
+	return rows
+}
+
+// gitLogRow formats a commit for the list: graph, short hash, date, author and subject
+func gitLogRow(c *gitLogCommit, graphWidth int) string {
+	graph := ""
+	if graphWidth > 0 {
+		graph = padToWidth(truncateToWidth(c.graph, graphWidth), graphWidth) + " "
+	}
+	return fmt.Sprintf("%s%s  %s %s %s", graph, c.hash[:blameHashWidth],
+		padToWidth(formatLastCommitTime(c.time), blameDateWidth),
+		padToWidth(truncateToWidth(c.author, blameAuthorWidth), blameAuthorWidth),
+		c.subject)
+}
+
+// gitLogFileRow formats a changed file: status and path (with the old path of a rename)
+func gitLogFileRow(f gitChangedPath) string {
+	if f.oldPath != "" {
+		return fmt.Sprintf("  %s  %s → %s", f.status, f.oldPath, f.path)
+	}
+	return fmt.Sprintf("  %s  %s", f.status, f.path)
+}
+
+// gitLogFileStart returns the line of the opened commit's first file (after the header)
+func (m model) gitLogFileStart() int {
+	width := m.diffWrapWidth()
+	n := 0
+	for _, line := range m.gitLog.commit.header {
+		n += len(wrapLine(line, width))
+	}
+	return n + 1
+}
+
+// gitLogCommitLines lays out the opened commit: header, file list (the selected file
+// highlighted) and full diff
+func (m model) gitLogCommitLines() []string {
+	c := m.gitLog.commit
+	width := m.diffWrapWidth()
+	var lines []string
+	for _, line := range c.header {
+		lines = append(lines, wrapLine(line, width)...)
+	}
+	lines = append(lines, "")
+
+	cursorStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.SelectionFg.adaptiveColor()).
+		Background(currentTheme.SelectionBg.adaptiveColor())
+	for i, f := range c.files {
+		row := truncateToWidth(gitLogFileRow(f), width)
+		switch {
+		case i == c.cursor:
+			row = cursorStyle.Render(padToWidth(row, width))
+		case f.status == "A":
+			row = diffAddedStyle.Render(row)
+		case f.status == "D":
+			row = diffRemovedStyle.Render(row)
+		}
+		lines = append(lines, row)
+	}
+	if len(c.files) == 0 {
+		lines = append(lines, "  (no file changes)")
+	}
+	lines = append(lines, "")
+	return append(lines, m.diffDisplayLines(c.lines)...)
+}
+
+// gitLogLineCount returns the number of displayed lines (opened file diff, commit or log)
+func (m model) gitLogLineCount() int {
+	s := m.gitLog
+	if c := s.commit; c != nil {
+		if v := c.file; v != nil {
+			if v.loading || v.err != nil {
+				return 1
+			}
+			return len(m.diffDisplayLines(v.lines))
+		}
+		if c.loading || c.err != nil {
+			return 1
+		}
+		return len(m.gitLogCommitLines())
+	}
+	return max(len(s.commits), 1)
+}
+
+// gitLogDisplayLines returns the displayed text for preview search
+func (m model) gitLogDisplayLines() []string {
+	s := m.gitLog
+	if c := s.commit; c != nil {
+		if c.file != nil {
+			return m.diffDisplayLines(c.file.lines)
+		}
+		return m.gitLogCommitLines()
+	}
+	lines := make([]string, len(s.commits))
+	for i := range s.commits {
+		lines[i] = gitLogRow(&s.commits[i], s.graphWidth)
+	}
+	return lines
+}
+
+// renderGitLogPreview renders the log, or the commit or file diff opened from it
+func (m model) renderGitLogPreview(maxVisible int) string {
+	st := m.gitLog
+	subtle := lipgloss.NewStyle().Foreground(uiSubtleText()).Italic(true)
+	message := func(text string) string {
+		return subtle.Render(text) + strings.Repeat("\n\033[0m", max(maxVisible-1, 0))
+	}
+
+	if c := st.commit; c != nil {
+		short := c.commit.hash[:blameHashWidth]
+		if v := c.file; v != nil {
+			switch {
+			case v.loading:
+				return message("Loading " + v.file.path + "...")
+			case v.err != nil:
+				return message(fmt.Sprintf("Cannot show %s: %v", v.file.path, v.err))
+			}
+			return m.renderDiffLines(v.lines, maxVisible, v.file.path+" @ "+short)
+		}
+		switch {
+		case c.loading:
+			return message("Loading " + short + "...")
+		case c.err != nil:
+			return message(fmt.Sprintf("Cannot show %s: %v", short, c.err))
+		}
+		return m.renderDiffDisplayLines(m.gitLogCommitLines(), maxVisible, "commit "+short)
+	}
+
+	var s strings.Builder
+	linesRendered := 0
+	writeLine := func(line string) {
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(line)
+		linesRendered++
+	}
+	if st.prompt != gitLogPromptNone {
+		inputStyle := lipgloss.NewStyle().Foreground(currentTheme.SelectionBg.adaptiveColor()).Bold(true)
+		label := map[int]string{gitLogPromptAuthor: "Author", gitLogPromptPath: "Path", gitLogPromptMessage: "Message text"}[st.prompt]
+		writeLine(" " + label + " (Enter: apply, empty: any): " + inputStyle.Render(st.input+"█") + "\033[0m")
+	}
+
+	switch {
+	case st.err != nil:
+		writeLine(subtle.Render(fmt.Sprintf("Cannot load the log: %v", st.err)))
+	case st.loading && len(st.commits) == 0:
+		writeLine(subtle.Render("Loading log of " + filepath.Base(st.root) + "..."))
+	case len(st.commits) == 0:
+		writeLine(subtle.Render("No matching commits (c: clear filters)"))
+	}
+
+	totalLines := len(st.commits)
+	visible := maxVisible - linesRendered
+	start := max(0, min(m.preview.scrollPos, totalLines-visible))
+	width := m.previewBoxWidth() - 2
+
+	cursorStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.SelectionFg.adaptiveColor()).
+		Background(currentTheme.SelectionBg.adaptiveColor())
+	graphStyle := lipgloss.NewStyle().Foreground(uiSubtleText())
+	hashStyle := lipgloss.NewStyle().Foreground(blameAgeColors[0])
+	graphWidth := 0
+	if st.graphWidth > 0 {
+		graphWidth = st.graphWidth + 1
+	}
+
+	for i := start; i < totalLines && linesRendered < maxVisible; i++ {
+		row := truncateToWidth(gitLogRow(&st.commits[i], st.graphWidth), width)
+		if i == st.cursor {
+			row = cursorStyle.Render(padToWidth(row, width))
+		} else if len(row) >= graphWidth+blameHashWidth {
+			row = graphStyle.Render(row[:graphWidth]) + hashStyle.Render(row[graphWidth:graphWidth+blameHashWidth]) +
+				m.highlightSearchLine(row[graphWidth+blameHashWidth:], i)
+		}
+		writeLine(m.renderScrollbar(i-start, maxVisible, totalLines) + " " + row + "\033[0m")
+	}
+	for linesRendered < maxVisible {
+		writeLine("\033[0m")
+	}
+	return s.String()
+}
+
+// filterText describes the active filters ("" when there are none)
+func (s *gitLogState) filterText() string {
+	var parts []string
+	if s.filter.author != "" {
+		parts = append(parts, "author: "+s.filter.author)
+	}
+	if s.filter.path != "" {
+		parts = append(parts, "path: "+s.filter.path)
+	}
+	if s.filter.message != "" {
+		parts = append(parts, "text: "+s.filter.message)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// gitLogStatusText describes the selection for info lines
+func (m model) gitLogStatusText() string {
+	s := m.gitLog
+	filters := ""
+	if text := s.filterText(); text != "" {
+		filters = " | " + text
+	}
+	switch {
+	case s.commit != nil && s.commit.file != nil:
+		return fmt.Sprintf("Log | %s at %s", s.commit.file.file.path, s.commit.commit.hash[:blameHashWidth])
+	case s.commit != nil:
+		return fmt.Sprintf("Log | commit %s %s | %s", s.commit.commit.hash[:blameHashWidth], s.commit.commit.subject,
+			pluralize(len(s.commit.files), "file"))
+	case s.loading && len(s.commits) == 0:
+		return "Log | loading..." + filters
+	case len(s.commits) == 0:
+		return "Log | no commits" + filters
+	}
+	return fmt.Sprintf("Log | %d of %s%s", s.cursor+1, m.gitLogCount(), filters)
+}
+
+// gitLogTitle is the full-screen preview title while the log is open
+func (m model) gitLogTitle() string {
+	title := "Log: " + filepath.Base(m.gitLog.root)
+	if text := m.gitLog.filterText(); text != "" {
+		title += " (" + text + ")"
+	}
+	return title
+}
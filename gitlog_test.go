@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestGitLogGraph(t *testing.T) {
+	// The history of a merged topic branch, newest first:
+	// e edits after the merge m of b (topic) into c, both based on a
+	g := &gitLogGraph{}
+	rows := []string{
+		g.next("e", []string{"m"}),
+		g.next("m", []string{"c", "b"}),
+		g.next("b", []string{"a"}),
+		g.next("c", []string{"a"}),
+		g.next("a", nil),
+	}
+	want := []string{"*", "* \\", "| *", "* |", "* /"}
+	if strings.Join(rows, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected the graph\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(rows, "\n"))
+	}
+	if len(g.lanes) != 0 {
+		t.Errorf("Expected every lane closed after the root, got %q", g.lanes)
+	}
+
+	// A second root gets its own lane only while the first one is still open
+	g.next("x", []string{"y"})
+	if got := g.next("z", nil); got != "| *" {
+		t.Errorf("Expected an unrelated tip next to the open lane, got %q", got)
+	}
+}
+
+func TestParseNameStatus(t *testing.T) {
+	files := parseNameStatus("M\x00a.go\x00R097\x00old.go\x00new.go\x00A\x00dir/b.txt\x00")
+	if len(files) != 3 {
+		t.Fatalf("Expected 3 files, got %+v", files)
+	}
+	if f := files[1]; f.status != "R" || f.oldPath != "old.go" || f.path != "new.go" {
+		t.Errorf("Unexpected rename %+v", f)
+	}
+	if gitLogFileRow(files[1]) != "  R  old.go → new.go" || gitLogFileRow(files[2]) != "  A  dir/b.txt" {
+		t.Errorf("Unexpected rows %q, %q", gitLogFileRow(files[1]), gitLogFileRow(files[2]))
+	}
+}
+
+// runLogCmd runs a log command and feeds its message back to the model
+func runLogCmd(t *testing.T, m model, cmd tea.Cmd) model {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("Expected a command")
+	}
+	newM, _ := m.Update(cmd())
+	return newM.(model)
+}
+
+func TestGitLogBrowser(t *testing.T) {
+	dir := initTestRepo(t, "f.txt", "one\n")
+	base := getGitBranch(dir)
+	gitOutput(t, dir, "switch", "-q", "-c", "topic")
+	os.WriteFile(filepath.Join(dir, "topic.txt"), []byte("topic\n"), 0644)
+	gitCommitAll(t, dir, "Add topic")
+	gitOutput(t, dir, "switch", "-q", base)
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte("two\n"), 0644)
+	gitCommitAll(t, dir, "Edit f")
+	gitOutput(t, dir, "-c", "user.name=Alice", "-c", "user.email=alice@example.com", "merge", "-q", "--no-edit", "topic")
+
+	m := model{height: 30, width: 120, viewMode: viewSinglePane, currentPath: dir}
+	m.loadFiles()
+	m = runLogCmd(t, m, m.openGitLog())
+	s := m.gitLog
+	if s == nil || m.viewMode != viewFullPreview || len(s.commits) != 4 || !s.done {
+		t.Fatalf("Expected 4 commits in the full preview, got %+v", s)
+	}
+	if s.commits[0].subject != "Merge branch 'topic'" || s.commits[0].graph != "* \\" {
+		t.Errorf("Expected the merge with a branch opening, got %+v", s.commits[0])
+	}
+	if out := plainLines(m.gitLogDisplayLines()); !strings.Contains(out, "Bob") || !strings.Contains(out, "Add topic") {
+		t.Errorf("Expected authors and subjects in the log:\n%s", out)
+	}
+
+	// Enter shows the commit's files and diff, Enter on a file its diff alone
+	selectLogCommit(t, &m, "Edit f")
+	_, cmd := m.handleGitLogKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = runLogCmd(t, m, cmd)
+	c := m.gitLog.commit
+	if c == nil || len(c.files) != 1 || c.files[0].path != "f.txt" || c.files[0].status != "M" {
+		t.Fatalf("Expected f.txt modified, got %+v", c)
+	}
+	if out := plainLines(m.gitLogCommitLines()); !strings.Contains(out, "Edit f") || !strings.Contains(out, "+two") {
+		t.Errorf("Expected the message and the diff:\n%s", out)
+	}
+	_, cmd = m.handleGitLogKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = runLogCmd(t, m, cmd)
+	if v := m.gitLog.commit.file; v == nil || !strings.Contains(strings.Join(v.lines, "\n"), "-one") {
+		t.Fatalf("Expected the diff of f.txt, got %+v", v)
+	}
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyEsc}, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.gitLog == nil || m.gitLog.commit != nil {
+		t.Fatal("Expected Esc twice to go back to the log")
+	}
+
+	// The root commit is compared with the empty tree
+	selectLogCommit(t, &m, "Initial commit")
+	m = runLogCmd(t, m, m.openGitLogCommit())
+	if c := m.gitLog.commit; len(c.files) != 1 || c.files[0].status != "A" || c.base != "" {
+		t.Errorf("Expected f.txt added by the root commit, got %+v", c)
+	}
+	m.closeGitLogCommit()
+
+	// Filters: author hides the graph, path keeps it with rewritten parents
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Bob")})
+	_, cmd = m.handleGitLogKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = runLogCmd(t, m, cmd)
+	if s := m.gitLog; len(s.commits) != 2 || s.graph != nil || s.graphWidth != 0 {
+		t.Errorf("Expected Bob's 2 commits without a graph, got %d", len(s.commits))
+	}
+	m.gitLog.filter = gitLogFilter{path: "topic.txt"}
+	m = runLogCmd(t, m, m.reloadGitLog())
+	if s := m.gitLog; len(s.commits) != 1 || s.commits[0].subject != "Add topic" || s.commits[0].graph != "*" {
+		t.Errorf("Expected only the topic commit, got %+v", s.commits)
+	}
+	m.gitLog.filter = gitLogFilter{message: "EDIT"}
+	m = runLogCmd(t, m, m.reloadGitLog())
+	if s := m.gitLog; len(s.commits) != 1 || s.commits[0].subject != "Edit f" {
+		t.Errorf("Expected a case-insensitive message match, got %+v", s.commits)
+	}
+	if !strings.Contains(m.gitLogTitle(), "text: EDIT") {
+		t.Errorf("Expected the filter in the title, got %q", m.gitLogTitle())
+	}
+	_, cmd = m.handleGitLogKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	m = runLogCmd(t, m, cmd)
+	if len(m.gitLog.commits) != 4 {
+		t.Errorf("Expected c to clear the filters, got %d commits", len(m.gitLog.commits))
+	}
+
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.gitLog != nil || m.viewMode != viewSinglePane {
+		t.Errorf("Expected Esc to close the log, got view mode %d", m.viewMode)
+	}
+}
+
+// selectLogCommit moves the log's cursor to the commit with subject
+func selectLogCommit(t *testing.T, m *model, subject string) {
+	t.Helper()
+	for i, c := range m.gitLog.commits {
+		if c.subject == subject {
+			m.gitLog.cursor = i
+			return
+		}
+	}
+	t.Fatalf("No commit %q", subject)
+}
+
+func TestGitLogPaging(t *testing.T) {
+	dir := initTestRepo(t, "f.txt", "one\n")
+	// A page and a half of empty commits on top of the first one
+	var stream strings.Builder
+	fmt.Fprintf(&stream, "reset refs/heads/%s\nfrom %s\n", getGitBranch(dir), strings.TrimSpace(gitOutput(t, dir, "rev-parse", "HEAD")))
+	for i := 1; i < gitLogPageSize*3/2; i++ {
+		msg := fmt.Sprintf("Commit %d", i)
+		fmt.Fprintf(&stream, "commit refs/heads/%s\ncommitter Dave <dave@example.com> %d +0000\ndata %d\n%s\n",
+			getGitBranch(dir), 1700000000+i, len(msg), msg)
+	}
+	importCmd := exec.Command("git", "-C", dir, "fast-import", "--quiet")
+	importCmd.Stdin = strings.NewReader(stream.String())
+	if out, err := importCmd.CombinedOutput(); err != nil {
+		t.Fatalf("fast-import: %v\n%s", err, out)
+	}
+
+	m := model{height: 30, width: 120, viewMode: viewSinglePane, currentPath: dir}
+	m = runLogCmd(t, m, m.openGitLog())
+	if s := m.gitLog; len(s.commits) != gitLogPageSize || s.done {
+		t.Fatalf("Expected one page loaded, got %d", len(s.commits))
+	}
+	if cmd := m.moveGitLogCursor(10); cmd != nil {
+		t.Error("Expected no paging far from the end")
+	}
+	if !strings.Contains(m.gitLogStatusText(), "11 of 200+ commits") {
+		t.Errorf("Unexpected status %q", m.gitLogStatusText())
+	}
+
+	// G goes to the last loaded commit, which reads the next page
+	_, cmd := m.handleGitLogKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if !m.gitLog.loading {
+		t.Fatal("Expected the next page to load")
+	}
+	m = runLogCmd(t, m, cmd)
+	s := m.gitLog
+	if len(s.commits) != gitLogPageSize*3/2 || !s.done || s.commits[len(s.commits)-1].subject != "Initial commit" {
+		t.Errorf("Expected all %d commits, got %d (done %v)", gitLogPageSize*3/2, len(s.commits), s.done)
+	}
+	if s.commits[gitLogPageSize].graph != "*" {
+		t.Errorf("Expected the graph to continue on the next page, got %q", s.commits[gitLogPageSize].graph)
+	}
+}
+
+func TestGitLogSkewedDates(t *testing.T) {
+	dir := initTestRepo(t, "f.txt", "one\n")
+	branch, base := getGitBranch(dir), strings.TrimSpace(gitOutput(t, dir, "rev-parse", "HEAD"))
+	now := time.Now().Unix()
+	// A merged side commit dated long before its parent, the initial commit
+	commits := []struct {
+		ref, msg, extra string
+		at              int64
+	}{
+		{branch, "Topic", "from " + base, now + 100},
+		{"side", "Skewed", "from " + base, 1000000000},
+		{branch, "Merge", "merge refs/heads/side", now + 200},
+	}
+	var stream strings.Builder
+	for _, c := range commits {
+		fmt.Fprintf(&stream, "commit refs/heads/%s\ncommitter Dave <dave@example.com> %d +0000\ndata %d\n%s\n%s\n",
+			c.ref, c.at, len(c.msg), c.msg, c.extra)
+	}
+	importCmd := exec.Command("git", "-C", dir, "fast-import", "--quiet")
+	importCmd.Stdin = strings.NewReader(stream.String())
+	if out, err := importCmd.CombinedOutput(); err != nil {
+		t.Fatalf("fast-import: %v\n%s", err, out)
+	}
+
+	m := model{height: 30, width: 120, viewMode: viewSinglePane, currentPath: dir}
+	m = runLogCmd(t, m, m.openGitLog())
+	var subjects []string
+	for _, c := range m.gitLog.commits {
+		subjects = append(subjects, c.subject)
+	}
+	if len(subjects) != 4 || subjects[0] != "Merge" || subjects[3] != "Initial commit" {
+		t.Errorf("Expected children before parents despite the dates, got %v", subjects)
+	}
+	if m.gitLog.graph != nil && len(m.gitLog.graph.lanes) != 0 {
+		t.Errorf("Expected every lane closed at the root, got %q", m.gitLog.graph.lanes)
+	}
+	m.closeGitLog()
+}
@@ -0,0 +1,196 @@
+package main
+
+// Module: gitstatus.go
+// Purpose: Git status markers in the list, detail and tree views
+// Responsibilities:
+// - Caching one `git status --porcelain -z` per repository, refreshed in the background
+//   (debounced) after file watcher events and git actions
+// - Mapping status codes to short markers (M, A, D, R, U, ??)
+// - Marking folders that contain changes (●), all the way up to the repository root
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	gitStatusDirMarker = "●"                    // Folder containing changes
+	gitStatusDebounce  = 300 * time.Millisecond // Bursts of file changes are refreshed once
+)
+
+// gitStatusCache is the parsed git status of a repository
+type gitStatusCache struct {
+	root  string
+	files map[string]string // Path relative to root → porcelain XY code (untracked folders without the slash)
+	dirs  map[string]bool   // Folders containing changes, relative to root
+}
+
+// gitStatusState is the git status behind the row markers. The markers shown stay
+// until a refresh finishes, so writes in a huge repository never block the UI.
+type gitStatusState struct {
+	cache   *gitStatusCache // Markers shown (nil = none)
+	root    string          // Repository of the current directory
+	stale   time.Time       // When the cache went out of date (zero = up to date)
+	loading bool            // git status running
+}
+
+// gitStatusMsg delivers a background git status run
+type gitStatusMsg struct {
+	cache   *gitStatusCache
+	started time.Time
+}
+
+// parseGitStatusZ parses `git status --porcelain -z` output
+func parseGitStatusZ(root, out string) *gitStatusCache {
+	s := &gitStatusCache{root: root, files: make(map[string]string), dirs: make(map[string]bool)}
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
+		}
+		code, rel := entry[:2], strings.TrimSuffix(entry[3:], "/")
+		// Renames and copies are followed by the original path
+		if code[0] == 'R' || code[0] == 'C' || code[1] == 'R' || code[1] == 'C' {
+			i++
+		}
+		s.files[rel] = code
+		for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+			s.dirs[dir] = true
+		}
+	}
+	return s
+}
+
+// loadGitStatus follows the current directory's repository: entering another one
+// drops the markers and reads its status on the next tick (see gitStatusCmd)
+func (m *model) loadGitStatus() {
+	s := &m.gitStatus
+	if !m.config.GitStatusDecorations {
+		*s = gitStatusState{}
+		return
+	}
+	if m.showChangesOnly || m.showGitReposOnly || m.showTrashOnly {
+		return
+	}
+	root := m.findGitRoot(m.currentPath)
+	if root == s.root {
+		return
+	}
+	s.root = root
+	s.cache = nil
+	s.stale = time.Time{}
+	if root != "" {
+		s.stale = time.Now().Add(-gitStatusDebounce)
+	}
+}
+
+// invalidateGitStatus marks the status out of date (file watcher events, git actions).
+// The refresh waits for the burst to settle; changes during a run start another one.
+func (m *model) invalidateGitStatus() {
+	s := &m.gitStatus
+	if s.root != "" && (s.stale.IsZero() || s.loading) {
+		s.stale = time.Now()
+	}
+}
+
+// gitStatusCmd runs git status in the background once the status has been out of
+// date for gitStatusDebounce. Called on every tick.
+func (m *model) gitStatusCmd() tea.Cmd {
+	s := &m.gitStatus
+	if s.root == "" || s.loading || s.stale.IsZero() || time.Since(s.stale) < gitStatusDebounce {
+		return nil
+	}
+	s.loading = true
+	root, started := s.root, time.Now()
+	return func() tea.Msg {
+		out, err := runGit(root, "", "status", "--porcelain", "-z")
+		if err != nil {
+			// Not a usable repository (e.g. a bare .git): no markers
+			out = ""
+		}
+		return gitStatusMsg{cache: parseGitStatusZ(root, out), started: started}
+	}
+}
+
+// applyGitStatusMsg shows a finished run unless the directory moved to another repository
+func (m *model) applyGitStatusMsg(msg gitStatusMsg) {
+	s := &m.gitStatus
+	s.loading = false
+	if msg.cache.root != s.root {
+		return
+	}
+	s.cache = msg.cache
+	if !s.stale.After(msg.started) {
+		s.stale = time.Time{}
+	}
+}
+
+// gitStatusCodeMarker condenses a porcelain XY code into one marker: conflicts,
+// then additions and renames in the index, then the worktree change
+func gitStatusCodeMarker(code string) string {
+	x, y := code[0], code[1]
+	switch {
+	case code == "??":
+		return "??"
+	case x == 'U' || y == 'U' || code == "AA" || code == "DD":
+		return "U"
+	case x == 'A' || x == 'R' || x == 'C':
+		return string(x)
+	case y != ' ':
+		return string(y)
+	}
+	return string(x)
+}
+
+// gitStatusMarker returns a row's marker: the status of a changed file, ● for a folder
+// containing changes, "" when clean or outside the repository
+func (m model) gitStatusMarker(file fileItem) string {
+	s := m.gitStatus.cache
+	if s == nil || !m.config.GitStatusDecorations || m.showChangesOnly || m.showGitReposOnly || m.showTrashOnly || file.name == ".." {
+		return ""
+	}
+	rel, err := filepath.Rel(s.root, file.path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	rel = filepath.ToSlash(rel)
+	if code, ok := s.files[rel]; ok {
+		return gitStatusCodeMarker(code)
+	}
+	if file.isDir && s.dirs[rel] {
+		return gitStatusDirMarker
+	}
+	// Everything inside an untracked folder is untracked
+	for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+		if s.files[dir] == "??" {
+			return "??"
+		}
+	}
+	return ""
+}
+
+// gitStatusBadge renders a row's marker in its color, after a space ("" when there's none)
+func (m model) gitStatusBadge(file fileItem) string {
+	marker := m.gitStatusMarker(file)
+	if marker == "" {
+		return ""
+	}
+	return " " + gitStatusStyle(marker).Render(marker)
+}
+
+// gitStatusStyle colors markers like the diff: additions green, deletions and conflicts red
+func gitStatusStyle(marker string) lipgloss.Style {
+	switch marker {
+	case "A", "??":
+		return diffAddedStyle
+	case "D", "U":
+		return diffRemovedStyle
+	}
+	return diffHunkHeaderStyle
+}
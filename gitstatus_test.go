@@ -0,0 +1,151 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitStatusZ(t *testing.T) {
+	s := parseGitStatusZ("/repo", " M src/app/main.go\x00R  new.go\x00old.go\x00?? build/\x00A  docs/a.md\x00")
+	want := map[string]string{"src/app/main.go": " M", "new.go": "R ", "build": "??", "docs/a.md": "A "}
+	if len(s.files) != len(want) {
+		t.Fatalf("Expected %d files, got %v", len(want), s.files)
+	}
+	for path, code := range want {
+		if s.files[path] != code {
+			t.Errorf("Expected %s to be %q, got %q", path, code, s.files[path])
+		}
+	}
+	if !s.dirs["src"] || !s.dirs["src/app"] || !s.dirs["docs"] || s.dirs["build"] || len(s.dirs) != 3 {
+		t.Errorf("Unexpected folders with changes %v", s.dirs)
+	}
+
+	for code, marker := range map[string]string{
+		" M": "M", "MM": "M", "M ": "M", "A ": "A", "AM": "A", "RM": "R",
+		" D": "D", "D ": "D", "UU": "U", "AA": "U", "??": "??",
+	} {
+		if got := gitStatusCodeMarker(code); got != marker {
+			t.Errorf("Expected %q for %q, got %q", marker, code, got)
+		}
+	}
+}
+
+// gitStatusMarkerOf returns the marker of the row named name
+func gitStatusMarkerOf(t *testing.T, m model, name string) string {
+	t.Helper()
+	for _, file := range m.files {
+		if file.name == name {
+			return m.gitStatusMarker(file)
+		}
+	}
+	t.Fatalf("No row %s", name)
+	return ""
+}
+
+// runGitStatus runs the background git status the next tick would start, skipping the debounce
+func runGitStatus(t *testing.T, m *model) {
+	t.Helper()
+	if !m.gitStatus.stale.IsZero() {
+		m.gitStatus.stale = m.gitStatus.stale.Add(-gitStatusDebounce)
+	}
+	cmd := m.gitStatusCmd()
+	if cmd == nil {
+		t.Fatal("Expected a git status run")
+	}
+	m.applyGitStatusMsg(cmd().(gitStatusMsg))
+}
+
+func TestGitStatusMarkers(t *testing.T) {
+	dir := initTestRepo(t, "clean.txt", "clean\n")
+	os.MkdirAll(filepath.Join(dir, "src", "app"), 0755)
+	os.WriteFile(filepath.Join(dir, "src", "app", "main.go"), []byte("package main\n"), 0644)
+	gitCommitAll(t, dir, "Add main")
+	os.WriteFile(filepath.Join(dir, "src", "app", "main.go"), []byte("package app\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "build"), 0755)
+	os.WriteFile(filepath.Join(dir, "build", "out.bin"), []byte("out\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0644)
+
+	m := model{height: 30, width: 120, viewMode: viewSinglePane, currentPath: dir}
+	m.config.GitStatusDecorations = true
+	m.loadFiles()
+	if got := gitStatusMarkerOf(t, m, "new.txt"); got != "" {
+		t.Errorf("Expected no markers before git status returns, got %q", got)
+	}
+	runGitStatus(t, &m)
+	for name, marker := range map[string]string{"clean.txt": "", "new.txt": "??", "src": gitStatusDirMarker, "build": "??"} {
+		if got := gitStatusMarkerOf(t, m, name); got != marker {
+			t.Errorf("Expected %q on %s, got %q", marker, name, got)
+		}
+	}
+	if !strings.Contains(m.renderListView(20), "new.txt ??") {
+		t.Errorf("Expected the marker after the name:\n%s", m.renderListView(20))
+	}
+	m.displayMode = modeDetail
+	if !strings.Contains(m.renderDetailView(20), "new.txt ??") {
+		t.Errorf("Expected the marker in the detail view:\n%s", m.renderDetailView(20))
+	}
+
+	// The folder marker reaches every parent; files in untracked folders are untracked
+	m.currentPath = filepath.Join(dir, "src")
+	m.loadFiles()
+	if got := gitStatusMarkerOf(t, m, "app"); got != gitStatusDirMarker {
+		t.Errorf("Expected the nested folder marked, got %q", got)
+	}
+	m.currentPath = filepath.Join(dir, "src", "app")
+	m.loadFiles()
+	if got := gitStatusMarkerOf(t, m, "main.go"); got != "M" {
+		t.Errorf("Expected main.go modified, got %q", got)
+	}
+	m.currentPath = filepath.Join(dir, "build")
+	m.loadFiles()
+	if got := gitStatusMarkerOf(t, m, "out.bin"); got != "??" {
+		t.Errorf("Expected a file in an untracked folder untracked, got %q", got)
+	}
+
+	// The cache holds until invalidated (file watcher events, git actions), and the
+	// old markers stay while the debounced refresh is pending
+	gitCommitAll(t, dir, "Commit everything")
+	m.currentPath = dir
+	m.loadFiles()
+	if got := gitStatusMarkerOf(t, m, "new.txt"); got != "??" {
+		t.Errorf("Expected the cached status, got %q", got)
+	}
+	if m.gitStatusCmd() != nil {
+		t.Error("Expected no git status run without a change")
+	}
+	m.invalidateGitStatus()
+	m.loadFiles()
+	if m.gitStatusCmd() != nil {
+		t.Error("Expected the refresh to wait for the debounce")
+	}
+	if got := gitStatusMarkerOf(t, m, "new.txt"); got != "??" {
+		t.Errorf("Expected the old marker until the refresh returns, got %q", got)
+	}
+	runGitStatus(t, &m)
+	for _, name := range []string{"new.txt", "src", "build"} {
+		if got := gitStatusMarkerOf(t, m, name); got != "" {
+			t.Errorf("Expected %s clean after the commit, got %q", name, got)
+		}
+	}
+
+	// The setting turns the markers off
+	os.WriteFile(filepath.Join(dir, "clean.txt"), []byte("dirty\n"), 0644)
+	m.invalidateGitStatus()
+	m.config.GitStatusDecorations = false
+	m.loadFiles()
+	if m.gitStatus.cache != nil || m.gitStatusCmd() != nil || gitStatusMarkerOf(t, m, "clean.txt") != "" {
+		t.Error("Expected no markers and no git status with the setting off")
+	}
+
+	// Names starting with ".." are inside the repository
+	os.WriteFile(filepath.Join(dir, "..foo"), []byte("dots\n"), 0644)
+	m.config.GitStatusDecorations = true
+	m.showHidden = true
+	m.loadFiles()
+	runGitStatus(t, &m)
+	if got := gitStatusMarkerOf(t, m, "..foo"); got != "??" {
+		t.Errorf("Expected ..foo untracked, got %q", got)
+	}
+}
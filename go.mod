@@ -15,6 +15,8 @@ require (
 	github.com/fsnotify/fsnotify v1.9.0
 	github.com/mattn/go-runewidth v0.0.19
 	golang.org/x/image v0.32.0
+	golang.org/x/sys v0.37.0
+	golang.org/x/term v0.31.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -43,7 +45,5 @@ require (
 	github.com/yuin/goldmark v1.7.8 // indirect
 	github.com/yuin/goldmark-emoji v1.0.5 // indirect
 	golang.org/x/net v0.33.0 // indirect
-	golang.org/x/sys v0.37.0 // indirect
-	golang.org/x/term v0.31.0 // indirect
 	golang.org/x/text v0.30.0 // indirect
 )
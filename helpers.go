@@ -85,36 +85,87 @@ func (m model) isNarrowTerminal() bool {
 	return m.width < 100
 }
 
-// performPreviewSearch searches the preview content for the current query
-// and populates searchMatches with line numbers
+// performPreviewSearch searches the displayed preview lines for the current query
+// and populates searchMatches with one entry per occurrence (see previewsearch.go)
 func (m *model) performPreviewSearch() {
 	m.preview.searchMatches = nil
+	m.preview.searchOccurrence = nil
+	m.preview.searchRe = nil
+	m.preview.searchErr = ""
 	m.preview.currentMatch = -1
 
 	if m.preview.searchQuery == "" {
-		m.setStatusMessage("🔍 Search: (type to search, Enter/n: next, Esc: exit)", false)
+		m.setStatusMessage("🔍 Search: (type to search, Enter/↓: next, ↑: prev, Alt+R/C/W: regex/case/word, Esc: exit)", false)
 		return
 	}
 
-	queryLower := strings.ToLower(m.preview.searchQuery)
+	// Pager and hex view files are too large to rescan on every keystroke - search runs on Enter/↓ (or n once the bar is closed)
+	if m.preview.pager != nil || m.preview.hex != nil {
+		return
+	}
+
+	// Tables are searched with the row filter instead
+	if m.preview.table != nil {
+		m.setStatusMessage("🔍 Tables: press . to filter rows (Esc: close search)", false)
+		return
+	}
+	if m.preview.db != nil {
+		m.setStatusMessage("🔍 Databases: press . to run a SQL query (Esc: close search)", false)
+		return
+	}
+
+	re, err := compilePreviewSearch(m.preview.searchQuery, m.previewSearchOpts)
+	if err != nil {
+		m.preview.searchErr = "invalid regex"
+		m.setStatusMessage(fmt.Sprintf("🔍 Invalid regex: %v", err), true)
+		return
+	}
+	m.preview.searchRe = re
 
-	// Search through preview content
-	for i, line := range m.preview.content {
-		if strings.Contains(strings.ToLower(line), queryLower) {
-			m.preview.searchMatches = append(m.preview.searchMatches, i)
+	if m.dataTreeActive() {
+		// Tree view: match member names and values of the unfolded rows
+		for i, row := range m.preview.tree.rows {
+			if re.MatchString(row.node.key) || (!row.node.isContainer() && re.MatchString(row.node.value)) {
+				m.preview.searchMatches = append(m.preview.searchMatches, i)
+				m.preview.searchOccurrence = append(m.preview.searchOccurrence, 0)
+			}
 		}
+	} else {
+		m.collectSearchMatches(re)
 	}
 
 	if len(m.preview.searchMatches) > 0 {
+		// Start at the first match from the current position
 		m.preview.currentMatch = 0
-		// Scroll to first match
-		m.preview.scrollPos = m.preview.searchMatches[0]
-		m.setStatusMessage(fmt.Sprintf("🔍 Found %d matches (1/%d) - n: next, Shift+n: prev, Esc: exit", len(m.preview.searchMatches), len(m.preview.searchMatches)), false)
+		for i, line := range m.preview.searchMatches {
+			if line >= m.preview.scrollPos {
+				m.preview.currentMatch = i
+				break
+			}
+		}
+		m.showSearchMatch()
 	} else {
 		m.setStatusMessage(fmt.Sprintf("🔍 No matches for '%s' - Esc: exit", m.preview.searchQuery), false)
 	}
 }
 
+// showSearchMatch scrolls to the current search match
+func (m *model) showSearchMatch() {
+	line := m.preview.searchMatches[m.preview.currentMatch]
+	if m.dataTreeActive() {
+		m.preview.scrollPos = line
+		m.preview.tree.cursor = line
+	} else if visible := m.getPreviewVisibleLines(); line < m.preview.scrollPos || line >= m.preview.scrollPos+visible-1 {
+		// Off screen: show the match with a few lines of context above it
+		m.preview.scrollPos = max(0, line-min(3, visible/4))
+	}
+	keys := "n: next, N: prev"
+	if m.preview.searchActive {
+		keys = "Enter/↓: next, ↑: prev, Esc: exit"
+	}
+	m.setStatusMessage(fmt.Sprintf("🔍 Match %d/%d - %s", m.preview.currentMatch+1, len(m.preview.searchMatches), keys), false)
+}
+
 // findNextSearchMatch navigates to the next search match
 func (m *model) findNextSearchMatch() {
 	if len(m.preview.searchMatches) == 0 {
@@ -126,10 +177,7 @@ func (m *model) findNextSearchMatch() {
 	if m.preview.currentMatch >= len(m.preview.searchMatches) {
 		m.preview.currentMatch = 0 // Wrap around
 	}
-
-	// Scroll to the match
-	m.preview.scrollPos = m.preview.searchMatches[m.preview.currentMatch]
-	m.setStatusMessage(fmt.Sprintf("🔍 Match %d/%d - n: next, Shift+n: prev, Esc: exit", m.preview.currentMatch+1, len(m.preview.searchMatches)), false)
+	m.showSearchMatch()
 }
 
 // findPreviousSearchMatch navigates to the previous search match
@@ -143,10 +191,7 @@ func (m *model) findPreviousSearchMatch() {
 	if m.preview.currentMatch < 0 {
 		m.preview.currentMatch = len(m.preview.searchMatches) - 1 // Wrap around
 	}
-
-	// Scroll to the match
-	m.preview.scrollPos = m.preview.searchMatches[m.preview.currentMatch]
-	m.setStatusMessage(fmt.Sprintf("🔍 Match %d/%d - n: next, Shift+n: prev, Esc: exit", m.preview.currentMatch+1, len(m.preview.searchMatches)), false)
+	m.showSearchMatch()
 }
 
 // getHelpSectionName returns the appropriate help section name based on current context
@@ -520,12 +565,25 @@ func (m *model) navigateToPath(newPath string) {
 func (m *model) exitChangesMode() {
 	m.showChangesOnly = false
 	m.showDiffPreview = false
+	m.hunkSel = nil
+	m.stashMarked = nil
 	m.agentSessions = nil
 	m.agentFileMap = nil
 	m.displayMode = m.changesRestoreDisplay
 	m.calculateLayout()
 }
 
+// previewBoxWidth returns the width available inside the preview box
+// Used by: dbbrowser.go (schema list, row grid), imageview.go (image + metadata layout)
+func (m model) previewBoxWidth() int {
+	if m.viewMode == viewFullPreview {
+		return m.width - 6
+	} else if m.displayMode == modeDetail || m.isNarrowTerminal() {
+		return m.width - 6
+	}
+	return m.rightWidth - 2
+}
+
 // getFileListVisibleLines returns the number of file items visible in the file list
 // This accounts for header, footer, and borders
 func (m model) getFileListVisibleLines() int {
@@ -0,0 +1,494 @@
+package main
+
+// Module: hexview.go
+// Purpose: Built-in hex + ASCII viewer
+// Responsibilities:
+// - Rendering offset / hex / ASCII rows for any file (reads only the visible rows)
+// - Jumping to a byte offset or percentage
+// - Streaming search for hex byte patterns or ASCII text in the background
+// - Toggling the hex view on and off over the regular preview
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	hexDefaultBytesPerRow = 16
+	hexWindowPages        = 3 // Pages of rows cached around the viewport
+)
+
+// hexViewState is the hex view for one file. Rows are computed from byte
+// offsets, so no index is needed and only the visible bytes are read.
+type hexViewState struct {
+	path        string
+	size        int64
+	bytesPerRow int
+	savedScroll int // Scroll position of the preview underneath (restored when toggled off)
+
+	// Bytes read around the viewport
+	windowOffset int64
+	window       []byte
+
+	// Highlighted bytes (last search hit or jump target)
+	markOffset int64 // -1 = none
+	markLen    int
+
+	// Background search
+	searchGen   atomic.Int64 // Bumped to cancel an in-flight search
+	searching   bool
+	searchQuery string // Query of the last hit
+}
+
+// hexSearchMsg reports the result of a streaming hex search
+type hexSearchMsg struct {
+	path    string
+	gen     int64
+	query   string
+	offset  int64
+	length  int
+	found   bool
+	wrapped bool
+	err     error
+}
+
+// newHexViewState creates a hex view for path
+func newHexViewState(path string, size int64, bytesPerRow int) *hexViewState {
+	if bytesPerRow < 4 || bytesPerRow > 64 {
+		bytesPerRow = hexDefaultBytesPerRow
+	}
+	return &hexViewState{
+		path:        path,
+		size:        size,
+		bytesPerRow: bytesPerRow,
+		markOffset:  -1,
+	}
+}
+
+// rows returns the number of rows in the hex view
+func (h *hexViewState) rows() int {
+	bpr := int64(h.bytesPerRow)
+	return int((h.size + bpr - 1) / bpr)
+}
+
+// setBytesPerRow changes the row width, keeping the same offset at the top
+func (h *hexViewState) setBytesPerRow(n int, scrollPos int) int {
+	top := int64(scrollPos) * int64(h.bytesPerRow)
+	if n < 4 || n > 64 {
+		n = hexDefaultBytesPerRow
+	}
+	h.bytesPerRow = n
+	return int(top / int64(n))
+}
+
+// bytesAt returns up to n bytes at offset, refreshing the window cache from disk
+// only when the viewport leaves it
+func (h *hexViewState) bytesAt(offset int64, n int) ([]byte, error) {
+	if n <= 0 || offset >= h.size {
+		return nil, nil
+	}
+	end := offset + int64(n)
+	if end > h.size {
+		end = h.size
+	}
+
+	windowEnd := h.windowOffset + int64(len(h.window))
+	if h.window == nil || offset < h.windowOffset || end > windowEnd {
+		from := offset - int64(n)
+		if from < 0 {
+			from = 0
+		}
+		length := int64(n * hexWindowPages)
+		if length > h.size-from {
+			length = h.size - from
+		}
+		buf := make([]byte, length)
+
+		f, err := os.Open(h.path)
+		if err != nil {
+			return nil, err
+		}
+		read, err := f.ReadAt(buf, from)
+		f.Close()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		h.windowOffset, h.window = from, buf[:read]
+	}
+
+	lo := offset - h.windowOffset
+	hi := end - h.windowOffset
+	if hi > int64(len(h.window)) {
+		hi = int64(len(h.window))
+	}
+	if lo >= hi {
+		return nil, nil
+	}
+	return h.window[lo:hi], nil
+}
+
+// parseHexJump parses a jump target: "0x1f00" (hex offset), "4096" (decimal offset),
+// "50%" (percentage) or "$" / "end". Returns the byte offset.
+func parseHexJump(input string, size int64) (int64, error) {
+	input = strings.TrimSpace(input)
+	lower := strings.ToLower(input)
+
+	var offset int64
+	switch {
+	case input == "":
+		return 0, errors.New("enter an offset (0x1f00 or 4096), a percentage or $")
+	case input == "$" || lower == "end":
+		offset = size - 1
+	case strings.HasSuffix(input, "%"):
+		pct, err := strconv.Atoi(strings.TrimSuffix(input, "%"))
+		if err != nil || pct < 0 || pct > 100 {
+			return 0, fmt.Errorf("invalid percentage: %s", input)
+		}
+		offset = size * int64(pct) / 100
+	case strings.HasPrefix(lower, "0x"):
+		n, err := strconv.ParseInt(lower[2:], 16, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid hex offset: %s", input)
+		}
+		offset = n
+	default:
+		n, err := strconv.ParseInt(input, 10, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid offset: %s", input)
+		}
+		offset = n
+	}
+
+	if offset > size-1 {
+		offset = size - 1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return offset, nil
+}
+
+// parseHexPattern converts a search query to the bytes to look for.
+// Hex: byte pairs separated by spaces ("de ad be ef") or a 0x prefix ("0xdeadbeef").
+// Anything else is searched as ASCII text; wrap it in quotes to force ASCII ("\"ab cd\"").
+func parseHexPattern(query string) ([]byte, error) {
+	if len(query) >= 2 && strings.HasPrefix(query, `"`) && strings.HasSuffix(query, `"`) {
+		return []byte(query[1 : len(query)-1]), nil
+	}
+
+	if lower := strings.ToLower(query); strings.HasPrefix(lower, "0x") {
+		pattern, err := hex.DecodeString(strings.ReplaceAll(lower[2:], " ", ""))
+		if err != nil || len(pattern) == 0 {
+			return nil, fmt.Errorf("invalid hex pattern: %s", query)
+		}
+		return pattern, nil
+	}
+
+	fields := strings.Fields(query)
+	if len(fields) >= 2 {
+		var pattern []byte
+		for _, field := range fields {
+			b, err := hex.DecodeString(field)
+			if err != nil || len(b) != 1 {
+				pattern = nil
+				break
+			}
+			pattern = append(pattern, b[0])
+		}
+		if pattern != nil {
+			return pattern, nil
+		}
+	}
+
+	return []byte(query), nil
+}
+
+// toggleHexView switches the current preview between hex view and its regular rendering.
+// Used by: keyboard ("x" in full-screen and standalone preview).
+func (m *model) toggleHexView() {
+	if !m.preview.loaded || m.preview.filePath == "" {
+		return
+	}
+
+	if h := m.preview.hex; h != nil {
+		h.searchGen.Add(1) // Cancel any in-flight search
+		m.preview.hex = nil
+		m.preview.scrollPos = h.savedScroll
+		m.setStatusMessage("Hex view off", false)
+		return
+	}
+
+	info, err := os.Stat(m.preview.filePath)
+	if err != nil || info.IsDir() {
+		m.setStatusMessage("Hex view is only available for files", true)
+		return
+	}
+	m.preview.hex = newHexViewState(m.preview.filePath, info.Size(), m.config.HexBytesPerRow)
+	m.preview.hex.savedScroll = m.preview.scrollPos
+	m.preview.scrollPos = 0
+	m.setStatusMessage("Hex view on (x: toggle, :: jump to offset, Ctrl+F: search bytes)", false)
+}
+
+// hexMaxScroll returns the last scroll position that still fills the viewport
+func (m model) hexMaxScroll() int {
+	return max(0, m.preview.hex.rows()-m.getPreviewVisibleLines())
+}
+
+// hexJumpTo scrolls the hex view so offset is visible and highlights it
+func (m *model) hexJumpTo(offset int64) {
+	h := m.preview.hex
+	if h == nil || h.size == 0 {
+		return
+	}
+	h.markOffset, h.markLen = offset, 1
+	m.preview.scrollPos = min(int(offset/int64(h.bytesPerRow)), m.hexMaxScroll())
+	m.setStatusMessage(fmt.Sprintf("Offset 0x%x (%d)", offset, offset), false)
+}
+
+// hexSearchNext starts a background search for the current query after the last hit
+// (or from the top of the view)
+func (m *model) hexSearchNext() tea.Cmd {
+	h := m.preview.hex
+	if h == nil || m.preview.searchQuery == "" {
+		return nil
+	}
+
+	pattern, err := parseHexPattern(m.preview.searchQuery)
+	if err != nil {
+		m.setStatusMessage(err.Error(), true)
+		return nil
+	}
+
+	from := int64(m.preview.scrollPos) * int64(h.bytesPerRow)
+	if h.markOffset >= 0 {
+		from = h.markOffset + 1
+	}
+
+	h.searching = true
+	m.setStatusMessage(fmt.Sprintf("🔍 Searching for %d-byte pattern...", len(pattern)), false)
+	return hexSearchCmd(h, m.preview.searchQuery, pattern, from)
+}
+
+// hexSearchCmd searches forward from offset from for pattern, wrapping around once.
+// Superseded searches stop early.
+func hexSearchCmd(h *hexViewState, query string, pattern []byte, from int64) tea.Cmd {
+	gen := h.searchGen.Add(1)
+	path := h.path
+	return func() tea.Msg {
+		msg := hexSearchMsg{path: path, gen: gen, query: query, length: len(pattern)}
+		cancelled := func() bool { return h.searchGen.Load() != gen }
+
+		msg.offset, msg.err = scanBytes(path, pattern, from, -1, cancelled)
+		if msg.offset < 0 && msg.err == nil && from > 0 {
+			// Wrap around: search the start of the file up to where we began
+			msg.offset, msg.err = scanBytes(path, pattern, 0, from+int64(len(pattern))-1, cancelled)
+			msg.wrapped = msg.offset >= 0
+		}
+		msg.found = msg.offset >= 0
+		return msg
+	}
+}
+
+// scanBytes streams the file from offset from looking for pattern, stopping at
+// limit (-1 = EOF). Returns the match offset or -1.
+func scanBytes(path string, pattern []byte, from, limit int64, cancelled func() bool) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return -1, err
+	}
+	defer f.Close()
+
+	// Chunks overlap by len(pattern)-1 so matches across boundaries are found
+	overlap := int64(len(pattern) - 1)
+	buf := make([]byte, pagerReadChunk+int(overlap))
+	for offset := from; limit < 0 || offset < limit; offset += pagerReadChunk {
+		if cancelled() {
+			return -1, nil
+		}
+		n, err := f.ReadAt(buf, offset)
+		chunk := buf[:n]
+		if limit >= 0 && offset+int64(n) > limit {
+			chunk = chunk[:limit-offset]
+		}
+		if i := bytes.Index(chunk, pattern); i >= 0 {
+			return offset + int64(i), nil
+		}
+		if err == io.EOF {
+			return -1, nil
+		} else if err != nil {
+			return -1, err
+		}
+	}
+	return -1, nil
+}
+
+// applyHexSearchResult scrolls to a finished search's hit (stale results are ignored)
+func (m *model) applyHexSearchResult(msg hexSearchMsg) {
+	h := m.preview.hex
+	if h == nil || h.path != msg.path || h.searchGen.Load() != msg.gen {
+		return
+	}
+	h.searching = false
+
+	if msg.err != nil {
+		m.setStatusMessage(fmt.Sprintf("Search failed: %v", msg.err), true)
+		return
+	}
+	if !msg.found {
+		h.markOffset = -1
+		m.setStatusMessage(fmt.Sprintf("🔍 No matches for '%s'", msg.query), false)
+		return
+	}
+
+	h.searchQuery = msg.query
+	h.markOffset, h.markLen = msg.offset, msg.length
+	m.preview.scrollPos = min(int(msg.offset/int64(h.bytesPerRow)), m.hexMaxScroll())
+
+	wrapped := ""
+	if msg.wrapped {
+		wrapped = " (wrapped to top)"
+	}
+	m.setStatusMessage(fmt.Sprintf("🔍 Match at 0x%x%s - %s", msg.offset, wrapped, m.streamingSearchKeys()), false)
+}
+
+// hexSearchText returns the search bar text while the hex view is active
+func (m model) hexSearchText() string {
+	h := m.preview.hex
+	switch {
+	case m.preview.searchQuery == "":
+		return "Search bytes: (de ad be ef, 0xcafe or text, then Enter/↓; Esc: close)"
+	case h.searching:
+		return fmt.Sprintf("Search bytes: %s (searching...)", m.preview.searchQuery)
+	case h.markOffset >= 0 && h.searchQuery == m.preview.searchQuery:
+		return fmt.Sprintf("Search bytes: %s (at 0x%x, Enter/↓: next)", m.preview.searchQuery, h.markOffset)
+	default:
+		return fmt.Sprintf("Search bytes: %s (Enter/↓: search)", m.preview.searchQuery)
+	}
+}
+
+// hexStatusText returns the hex view's position summary for info lines
+func (m model) hexStatusText() string {
+	h := m.preview.hex
+	top := int64(m.preview.scrollPos) * int64(h.bytesPerRow)
+	return fmt.Sprintf("Hex view (%d bytes/row) | Offset 0x%x / 0x%x", h.bytesPerRow, top, h.size)
+}
+
+// renderHexPreview renders the visible rows as offset | hex bytes | ASCII
+func (m model) renderHexPreview(maxVisible int) string {
+	var s strings.Builder
+	h := m.preview.hex
+
+	var boxContentWidth int
+	if m.viewMode == viewFullPreview {
+		boxContentWidth = m.width - 6
+	} else if m.displayMode == modeDetail || m.isNarrowTerminal() {
+		boxContentWidth = m.width - 6
+	} else {
+		boxContentWidth = m.rightWidth - 2
+	}
+
+	totalRows := max(h.rows(), 1)
+	targetLines := maxVisible
+	if m.viewMode == viewDualPane {
+		targetLines = maxVisible - 1
+	}
+
+	start := max(0, min(m.preview.scrollPos, totalRows-targetLines))
+
+	linesRendered := 0
+	writeLine := func(line string) {
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(line)
+		linesRendered++
+	}
+
+	bpr := h.bytesPerRow
+	data, err := h.bytesAt(int64(start)*int64(bpr), targetLines*bpr)
+	if err != nil {
+		writeLine(fmt.Sprintf("Error reading file: %v", err))
+	} else if h.size == 0 {
+		writeLine("(empty file)")
+	}
+
+	// Offsets beyond 4GB need more than 8 hex digits
+	offsetWidth := max(8, len(strconv.FormatInt(h.size, 16)))
+	offsetStyle := lipgloss.NewStyle().Foreground(uiSubtleText())
+	zeroStyle := lipgloss.NewStyle().Foreground(uiMutedText())
+	markStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.SelectionFg.adaptiveColor()).
+		Background(currentTheme.SelectionBg.adaptiveColor())
+	marked := func(offset int64) bool {
+		return h.markOffset >= 0 && offset >= h.markOffset && offset < h.markOffset+int64(h.markLen)
+	}
+
+	for i := 0; i*bpr < len(data); i++ {
+		rowOffset := int64(start+i) * int64(bpr)
+		row := data[i*bpr : min(len(data), (i+1)*bpr)]
+
+		var hexPart, asciiPart strings.Builder
+		for j := 0; j < bpr; j++ {
+			if j > 0 && j%8 == 0 {
+				hexPart.WriteString(" ") // Extra gap every 8 bytes
+			}
+			if j >= len(row) {
+				hexPart.WriteString("   ")
+				continue
+			}
+
+			b := row[j]
+			cell := fmt.Sprintf("%02x", b)
+			char := "."
+			if b >= 0x20 && b < 0x7f {
+				char = string(rune(b))
+			}
+
+			switch {
+			case marked(rowOffset + int64(j)):
+				cell, char = markStyle.Render(cell), markStyle.Render(char)
+			case b == 0:
+				cell = zeroStyle.Render(cell)
+			}
+			hexPart.WriteString(cell + " ")
+			asciiPart.WriteString(char)
+		}
+
+		line := offsetStyle.Render(fmt.Sprintf("%0*x", offsetWidth, rowOffset))
+		line += m.renderScrollbar(i, maxVisible, totalRows) + " "
+		line += hexPart.String() + " " + asciiPart.String()
+		if visualWidth(line) > boxContentWidth {
+			line = truncateToWidth(line, boxContentWidth)
+		}
+		writeLine(line + "\033[0m")
+	}
+
+	if m.viewMode == viewDualPane {
+		scrollStyle := lipgloss.NewStyle().
+			Foreground(uiSubtleText()).
+			Italic(true)
+		for linesRendered < targetLines {
+			writeLine("\033[0m")
+		}
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(scrollStyle.Render(fmt.Sprintf(" 0x%x/0x%x [hex] ", int64(start)*int64(bpr), h.size)))
+	} else {
+		for linesRendered < maxVisible {
+			writeLine("\033[0m")
+		}
+	}
+
+	return s.String()
+}
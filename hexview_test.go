@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeHexTestFile writes data to a temp file and returns the path
+func writeHexTestFile(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	return path
+}
+
+// TestParseHexJump tests offset prompt parsing
+func TestParseHexJump(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"0x10", 16, false},
+		{"0X1F", 31, false},
+		{"4096", 999, false}, // Clamped to the last byte
+		{"100", 100, false},
+		{"50%", 500, false},
+		{"$", 999, false},
+		{"end", 999, false},
+		{"0xzz", 0, true},
+		{"-5", 0, true},
+		{"150%", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseHexJump(tt.input, 1000)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseHexJump(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseHexJump(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestParseHexPattern tests hex vs ASCII search pattern detection
+func TestParseHexPattern(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    []byte
+		wantErr bool
+	}{
+		{"de ad be ef", []byte{0xde, 0xad, 0xbe, 0xef}, false},
+		{"0xCAFE", []byte{0xca, 0xfe}, false},
+		{"0xca fe", []byte{0xca, 0xfe}, false},
+		{"hello", []byte("hello"), false},
+		{"ab", []byte("ab"), false},                  // Single pair is ambiguous - searched as text
+		{"hello world", []byte("hello world"), false}, // Not all fields are hex pairs
+		{`"ab cd"`, []byte("ab cd"), false},
+		{"0xabc", nil, true}, // Odd number of digits
+	}
+
+	for _, tt := range tests {
+		got, err := parseHexPattern(tt.query)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseHexPattern(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !bytes.Equal(got, tt.want) {
+			t.Errorf("parseHexPattern(%q) = %x, want %x", tt.query, got, tt.want)
+		}
+	}
+}
+
+// TestHexViewRows tests row counts and re-flowing to a new row width
+func TestHexViewRows(t *testing.T) {
+	h := newHexViewState("unused", 100, 16)
+	if h.rows() != 7 {
+		t.Errorf("Expected 7 rows, got %d", h.rows())
+	}
+
+	// Row 4 at 16 bytes/row is offset 64, which is row 8 at 8 bytes/row
+	if scroll := h.setBytesPerRow(8, 4); scroll != 8 {
+		t.Errorf("Expected scroll 8 after re-flow, got %d", scroll)
+	}
+	if h.rows() != 13 {
+		t.Errorf("Expected 13 rows, got %d", h.rows())
+	}
+
+	if h := newHexViewState("unused", 10, 1000); h.bytesPerRow != hexDefaultBytesPerRow {
+		t.Errorf("Expected invalid width to fall back to %d, got %d", hexDefaultBytesPerRow, h.bytesPerRow)
+	}
+}
+
+// TestHexViewBytesAt tests windowed reads
+func TestHexViewBytesAt(t *testing.T) {
+	data := make([]byte, 10000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	h := newHexViewState(writeHexTestFile(t, data), int64(len(data)), 16)
+
+	got, err := h.bytesAt(4096, 64)
+	if err != nil {
+		t.Fatalf("bytesAt failed: %v", err)
+	}
+	if !bytes.Equal(got, data[4096:4160]) {
+		t.Error("bytesAt returned wrong bytes")
+	}
+
+	// Nearby reads are served from the cached window
+	windowOffset := h.windowOffset
+	got, _ = h.bytesAt(4100, 64)
+	if h.windowOffset != windowOffset {
+		t.Error("Expected window cache to be reused")
+	}
+	if !bytes.Equal(got, data[4100:4164]) {
+		t.Error("bytesAt returned wrong bytes from cache")
+	}
+
+	// Reads are clipped at EOF
+	got, _ = h.bytesAt(9990, 64)
+	if len(got) != 10 {
+		t.Errorf("Expected 10 bytes at EOF, got %d", len(got))
+	}
+}
+
+// TestHexSearch tests streaming search across chunk boundaries and wrap-around
+func TestHexSearch(t *testing.T) {
+	data := make([]byte, pagerReadChunk*3)
+	pattern := []byte{0xde, 0xad, 0xbe, 0xef}
+	at := int64(pagerReadChunk - 2) // Straddles the first chunk boundary
+	copy(data[at:], pattern)
+	h := newHexViewState(writeHexTestFile(t, data), int64(len(data)), 16)
+
+	msg := hexSearchCmd(h, "de ad be ef", pattern, 0)().(hexSearchMsg)
+	if !msg.found || msg.offset != at || msg.wrapped {
+		t.Fatalf("Unexpected search result: %+v", msg)
+	}
+
+	// Searching past the only hit wraps around to it
+	msg = hexSearchCmd(h, "de ad be ef", pattern, at+1)().(hexSearchMsg)
+	if !msg.found || msg.offset != at || !msg.wrapped {
+		t.Errorf("Expected wrapped hit at %d, got %+v", at, msg)
+	}
+
+	msg = hexSearchCmd(h, "cafe", []byte("cafe"), 0)().(hexSearchMsg)
+	if msg.found {
+		t.Errorf("Expected no match, got %+v", msg)
+	}
+
+	// Stale results are ignored
+	m := model{height: 40, width: 120, viewMode: viewFullPreview}
+	m.preview.hex = h
+	m.preview.loaded = true
+	stale := hexSearchCmd(h, "de ad be ef", pattern, 0)().(hexSearchMsg)
+	h.searchGen.Add(1)
+	m.applyHexSearchResult(stale)
+	if h.markOffset != -1 {
+		t.Error("Expected stale search result to be ignored")
+	}
+}
+
+// TestLoadPreviewBinaryOpensHex tests that binary files open in the hex view and toggle off
+func TestLoadPreviewBinaryOpensHex(t *testing.T) {
+	path := writeHexTestFile(t, []byte{0x7f, 'E', 'L', 'F', 0, 0, 1, 2})
+
+	m := &model{height: 40, width: 120, viewMode: viewFullPreview}
+	m.loadPreview(path)
+	if m.preview.hex == nil {
+		t.Fatal("Expected binary file to open in the hex view")
+	}
+
+	m.toggleHexView()
+	if m.preview.hex != nil {
+		t.Error("Expected hex view to toggle off")
+	}
+	m.toggleHexView()
+	if m.preview.hex == nil {
+		t.Error("Expected hex view to toggle back on")
+	}
+
+	// Text files don't open in hex view automatically
+	text := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(text, []byte("hello\n"), 0644)
+	m.loadPreview(text)
+	if m.preview.hex != nil {
+		t.Error("Expected text file to use the regular preview")
+	}
+}
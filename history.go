@@ -0,0 +1,550 @@
+package main
+
+// Module: history.go
+// Purpose: Per-file git history browser in the preview
+// Responsibilities:
+// - Listing the commits that touched a file, following renames (`git log --follow`)
+// - Showing each commit's diff against its parent, or the file as it was at that commit
+// - Restoring an old version to the working tree (the current file goes to trash first)
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const historyMaxCommits = 1000 // Commits listed per file
+
+// historyCommit is one commit that touched the file
+type historyCommit struct {
+	hash    string
+	author  string
+	time    time.Time
+	subject string
+	status  string // Change to the file: A, M, D, R (renamed), C (copied)
+	path    string // File path (relative to the repository root) in this commit
+	oldPath string // Path before a rename or copy
+}
+
+// rev returns the revision holding this commit's version of the file
+// (a commit that deletes it has none, so its parent's is used)
+func (c *historyCommit) rev() string {
+	if c.status == "D" {
+		return c.hash + "^"
+	}
+	return c.hash
+}
+
+// historyState is the history browser for the previewed file
+type historyState struct {
+	path    string // File in the working tree
+	root    string // Repository root
+	rel     string // path relative to root
+	loading bool
+	err     error
+	commits []historyCommit
+	cursor  int
+	confirm bool // Waiting for y to restore the selected version
+
+	view *historyView // Diff or file content opened from the list
+}
+
+// historyView is the diff of one commit, or the file's content at that commit
+type historyView struct {
+	commit      *historyCommit
+	file        bool // File content instead of the diff
+	loading     bool
+	lines       []string // Diff lines, or syntax-highlighted file lines
+	err         error
+	savedScroll int
+}
+
+// historyMsg delivers the parsed commit list
+type historyMsg struct {
+	history *historyState
+	commits []historyCommit
+	err     error
+}
+
+// historyViewMsg delivers a commit's diff or the file at a revision
+type historyViewMsg struct {
+	view  *historyView
+	lines []string
+	err   error
+}
+
+// parseHistoryLog parses `git log --name-status` output whose commits start with
+// "\x1e<hash>\x1f<author>\x1f<unix time>\x1f<subject>". Commits listing no file
+// (merges) keep the path of the newer commit before them.
+func parseHistoryLog(out string, rel string) []historyCommit {
+	var commits []historyCommit
+	path := rel
+	for _, record := range strings.Split(out, "\x1e") {
+		header, rest, _ := strings.Cut(record, "\n")
+		fields := strings.Split(header, "\x1f")
+		if len(fields) < 4 {
+			continue
+		}
+		c := historyCommit{hash: fields[0], author: fields[1], subject: fields[3], status: "M", path: path}
+		if sec, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			c.time = time.Unix(sec, 0)
+		}
+		for _, line := range strings.Split(rest, "\n") {
+			parts := strings.Split(line, "\t")
+			if len(parts) < 2 || parts[0] == "" {
+				continue
+			}
+			c.status = parts[0][:1]
+			c.path = parts[len(parts)-1]
+			if len(parts) == 3 {
+				c.oldPath = parts[1]
+			}
+			break
+		}
+		// Older commits see the file under its name before the rename
+		path = c.path
+		if c.oldPath != "" {
+			path = c.oldPath
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+// gitErrorText returns git's error message when it printed one
+func gitErrorText(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
+
+// historyCmd runs git log for the file in the background
+func historyCmd(h *historyState) tea.Cmd {
+	root, rel := h.root, h.rel
+	return func() tea.Msg {
+		cmd := exec.Command("git", "-C", root, "log", "--follow", "--name-status",
+			fmt.Sprintf("--max-count=%d", historyMaxCommits),
+			"--format=%x1e%H%x1f%an%x1f%at%x1f%s", "--", rel)
+		out, err := cmd.Output()
+		if err != nil {
+			return historyMsg{history: h, err: gitErrorText(err)}
+		}
+		return historyMsg{history: h, commits: parseHistoryLog(string(out), rel)}
+	}
+}
+
+// showAtRevision returns the file's content at a revision (`git show rev:path`)
+func showAtRevision(root, rev, path string) ([]byte, error) {
+	out, err := exec.Command("git", "-C", root, "show", rev+":"+path).Output()
+	if err != nil {
+		return nil, gitErrorText(err)
+	}
+	return out, nil
+}
+
+// historyViewCmd loads a commit's diff against its parent, or the file at that commit
+func historyViewCmd(root string, v *historyView) tea.Cmd {
+	c := *v.commit
+	file := v.file
+	return func() tea.Msg {
+		if file {
+			content, err := showAtRevision(root, c.rev(), c.path)
+			if err != nil {
+				return historyViewMsg{view: v, err: err}
+			}
+			if bytes.IndexByte(content, 0) >= 0 {
+				return historyViewMsg{view: v, err: fmt.Errorf("binary file (%s)", formatFileSize(int64(len(content))))}
+			}
+			text := strings.TrimRight(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+			if highlighted, ok := highlightCode(text, c.path); ok {
+				text = strings.TrimRight(highlighted, "\n")
+			}
+			return historyViewMsg{view: v, lines: strings.Split(text, "\n")}
+		}
+
+		// --follow shows a rename as such instead of a new file
+		cmd := exec.Command("git", "-C", root, "log", "-1", "--follow", "-M", "--no-color", "--stat", "--patch",
+			c.hash, "--", c.path)
+		out, err := cmd.Output()
+		if err != nil {
+			return historyViewMsg{view: v, err: gitErrorText(err)}
+		}
+		return historyViewMsg{view: v, lines: strings.Split(strings.TrimRight(string(out), "\n"), "\n")}
+	}
+}
+
+// toggleFileHistory opens or closes the history browser for the previewed file
+func (m *model) toggleFileHistory() tea.Cmd {
+	if m.preview.history != nil {
+		m.preview.history = nil
+		m.preview.scrollPos = 0
+		m.setStatusMessage("History closed", false)
+		return statusTimeoutCmd()
+	}
+	return m.openFileHistory(m.preview.filePath)
+}
+
+// openFileHistory starts listing the commits of a file (it needn't exist any more)
+func (m *model) openFileHistory(path string) tea.Cmd {
+	if path == "" || m.preview.dir != nil {
+		m.setStatusMessage("Select a file to show its history", true)
+		return statusTimeoutCmd()
+	}
+	root, err := m.gitRevParseRoot(filepath.Dir(path))
+	if err != nil {
+		m.setStatusMessage("Not inside a git repository", true)
+		return statusTimeoutCmd()
+	}
+	// Compare resolved paths so symlinked checkouts still give a relative path
+	absPath := path
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		absPath = filepath.Join(resolved, filepath.Base(path))
+	}
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		m.setStatusMessage("Not inside a git repository", true)
+		return statusTimeoutCmd()
+	}
+
+	m.preview.blame = nil
+	h := &historyState{path: path, root: root, rel: filepath.ToSlash(rel), loading: true}
+	m.preview.history = h
+	m.preview.scrollPos = 0
+	m.setStatusMessage("Loading history...", false)
+	return historyCmd(h)
+}
+
+// applyHistoryMsg shows the loaded commit list (results for a closed browser are ignored)
+func (m *model) applyHistoryMsg(msg historyMsg) tea.Cmd {
+	h := m.preview.history
+	if h == nil || msg.history != h {
+		return nil
+	}
+	h.loading = false
+	h.err = msg.err
+	h.commits = msg.commits
+	if h.err != nil {
+		m.setStatusMessage(fmt.Sprintf("History failed: %v", h.err), true)
+		return statusTimeoutCmd()
+	}
+	if len(h.commits) == 0 {
+		m.setStatusMessage("No commits touch this file", false)
+		return statusTimeoutCmd()
+	}
+	m.setStatusMessage(fmt.Sprintf("%s | Enter: diff, v: file at commit, R: restore", pluralize(len(h.commits), "commit")), false)
+	return statusTimeoutCmd()
+}
+
+// applyHistoryViewMsg shows a loaded diff or file version
+func (m *model) applyHistoryViewMsg(msg historyViewMsg) {
+	h := m.preview.history
+	if h == nil || h.view != msg.view {
+		return
+	}
+	h.view.loading = false
+	h.view.lines, h.view.err = msg.lines, msg.err
+}
+
+// selected returns the open commit, or the one under the cursor
+func (h *historyState) selected() *historyCommit {
+	if h.view != nil {
+		return h.view.commit
+	}
+	if h.cursor < len(h.commits) {
+		return &h.commits[h.cursor]
+	}
+	return nil
+}
+
+// openHistoryView shows the selected commit's diff, or the file at that commit
+func (m *model) openHistoryView(file bool) tea.Cmd {
+	h := m.preview.history
+	c := h.selected()
+	if c == nil {
+		return nil
+	}
+	saved := m.preview.scrollPos
+	if h.view != nil {
+		saved = h.view.savedScroll // Switching between diff and file keeps the list position
+	}
+	h.view = &historyView{commit: c, file: file, loading: true, savedScroll: saved}
+	m.preview.scrollPos = 0
+	return historyViewCmd(h.root, h.view)
+}
+
+// closeHistoryView returns to the commit list
+func (m *model) closeHistoryView() {
+	h := m.preview.history
+	m.preview.scrollPos = h.view.savedScroll
+	h.view = nil
+}
+
+// moveHistoryCursor moves the selected commit and scrolls to keep it visible
+func (m *model) moveHistoryCursor(delta int) {
+	h := m.preview.history
+	h.cursor = max(0, min(h.cursor+delta, len(h.commits)-1))
+	visible := max(m.getPreviewVisibleLines(), 1)
+	if h.cursor < m.preview.scrollPos {
+		m.preview.scrollPos = h.cursor
+	} else if h.cursor >= m.preview.scrollPos+visible {
+		m.preview.scrollPos = h.cursor - visible + 1
+	}
+}
+
+// restoreHistoryVersion writes the selected version over the working tree file.
+// The current file is moved to trash first, so the restore can be undone from there.
+func (m *model) restoreHistoryVersion() tea.Cmd {
+	h := m.preview.history
+	c := h.selected()
+	if c == nil {
+		return nil
+	}
+	content, err := showAtRevision(h.root, c.rev(), c.path)
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Cannot read version %s: %v", c.hash[:blameHashWidth], err), true)
+		return statusTimeoutCmd()
+	}
+
+	mode := os.FileMode(0644)
+	trashed := false
+	if info, err := os.Stat(h.path); err == nil {
+		if info.IsDir() {
+			m.setStatusMessage("A folder is in the way: "+h.path, true)
+			return statusTimeoutCmd()
+		}
+		mode = info.Mode().Perm()
+		if err := m.trashPath(h.path); err != nil {
+			m.setStatusMessage(fmt.Sprintf("Cannot move the current file to trash: %v", err), true)
+			return statusTimeoutCmd()
+		}
+		trashed = true
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err == nil {
+		err = os.WriteFile(h.path, content, mode)
+	}
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Restore failed: %v", err), true)
+		return statusTimeoutCmd()
+	}
+
+	// Reload the file underneath, keeping the browser open
+	scroll := m.preview.scrollPos
+	m.loadPreview(h.path)
+	m.populatePreviewCache()
+	m.preview.history = h
+	m.preview.scrollPos = scroll
+	m.loadFiles()
+
+	msg := fmt.Sprintf("✓ Restored %s from %s", filepath.Base(h.path), c.hash[:blameHashWidth])
+	if trashed {
+		msg += " (previous version is in trash)"
+	}
+	m.setStatusMessage(msg, false)
+	return statusTimeoutCmd()
+}
+
+// historyLineCount returns the number of displayed lines (open view or commit list)
+func (m model) historyLineCount() int {
+	h := m.preview.history
+	if v := h.view; v != nil {
+		if v.loading || v.err != nil {
+			return 1
+		}
+		if v.file {
+			return len(m.historyFileRows())
+		}
+		wrapped := m.diffDisplayLines(v.lines)
+		return len(wrapped)
+	}
+	return max(len(h.commits), 1)
+}
+
+// historyDisplayLines returns the displayed text for preview search
+func (m model) historyDisplayLines() []string {
+	h := m.preview.history
+	if v := h.view; v != nil {
+		if v.file {
+			rows := m.historyFileRows()
+			lines := make([]string, len(rows))
+			for i, row := range rows {
+				lines[i] = row.text
+			}
+			return lines
+		}
+		wrapped := m.diffDisplayLines(v.lines)
+		return wrapped
+	}
+	lines := make([]string, len(h.commits))
+	for i := range h.commits {
+		lines[i] = historyRow(&h.commits[i], h.rel)
+	}
+	return lines
+}
+
+// historyFileRows wraps the file version's lines to the preview width
+// (blameRow marks which rows start a file line, for line numbers)
+func (m model) historyFileRows() []blameRow {
+	width := max(m.previewBoxWidth()-8, 20) // Line numbers (6) + scrollbar (1) + space (1)
+	var rows []blameRow
+	for line, text := range m.preview.history.view.lines {
+		for k, w := range wrapLine(text, width) {
+			rows = append(rows, blameRow{line: line, first: k == 0, text: w})
+		}
+	}
+	return rows
+}
+
+// historyRow formats a commit for the list: short hash, date, author, subject and
+// the file's earlier name when it was renamed
+func historyRow(c *historyCommit, rel string) string {
+	row := fmt.Sprintf("%s  %s %s %s", c.hash[:blameHashWidth],
+		padToWidth(formatLastCommitTime(c.time), blameDateWidth),
+		padToWidth(truncateToWidth(c.author, blameAuthorWidth), blameAuthorWidth),
+		c.subject)
+	switch {
+	case c.status == "D":
+		row += "  [deleted]"
+	case c.status == "A" && c.path == rel:
+		row += "  [added]"
+	case c.oldPath != "":
+		row += "  [renamed from " + c.oldPath + "]"
+	case c.path != rel:
+		row += "  [as " + c.path + "]"
+	}
+	return row
+}
+
+// renderHistoryPreview renders the commit list, or the diff / file version opened from it
+func (m model) renderHistoryPreview(maxVisible int) string {
+	h := m.preview.history
+	subtle := lipgloss.NewStyle().Foreground(uiSubtleText()).Italic(true)
+	message := func(text string) string {
+		return subtle.Render(text) + strings.Repeat("\n\033[0m", max(maxVisible-1, 0))
+	}
+
+	if v := h.view; v != nil {
+		short := v.commit.hash[:blameHashWidth]
+		switch {
+		case v.loading:
+			return message("Loading " + short + "...")
+		case v.err != nil:
+			return message(fmt.Sprintf("Cannot show %s: %v", short, v.err))
+		case !v.file:
+			return m.renderDiffLines(v.lines, maxVisible, "commit "+short)
+		}
+		return m.renderHistoryFile(maxVisible)
+	}
+
+	switch {
+	case h.loading:
+		return message("Loading history of " + h.rel + "...")
+	case h.err != nil:
+		return message(fmt.Sprintf("Cannot load history: %v", h.err))
+	case len(h.commits) == 0:
+		return message("No commits touch " + h.rel)
+	}
+
+	var s strings.Builder
+	totalLines := len(h.commits)
+	targetLines := maxVisible
+	if m.viewMode == viewDualPane {
+		targetLines = maxVisible - 1
+	}
+	start := max(0, min(m.preview.scrollPos, totalLines-targetLines))
+	width := m.previewBoxWidth() - 2
+
+	cursorStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.SelectionFg.adaptiveColor()).
+		Background(currentTheme.SelectionBg.adaptiveColor())
+	hashStyle := lipgloss.NewStyle().Foreground(blameAgeColors[0])
+
+	linesRendered := 0
+	writeLine := func(line string) {
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(line)
+		linesRendered++
+	}
+	for i := start; i < totalLines && linesRendered < targetLines; i++ {
+		row := truncateToWidth(historyRow(&h.commits[i], h.rel), width)
+		if i == h.cursor {
+			row = cursorStyle.Render(padToWidth(row, width))
+		} else {
+			row = hashStyle.Render(row[:blameHashWidth]) + m.highlightSearchLine(row[blameHashWidth:], i)
+		}
+		writeLine(m.renderScrollbar(i-start, maxVisible, totalLines) + " " + row + "\033[0m")
+	}
+
+	if m.viewMode == viewDualPane {
+		for linesRendered < targetLines {
+			writeLine("\033[0m")
+		}
+		s.WriteString("\n")
+		s.WriteString(subtle.Render(" " + m.historyStatusText() + " "))
+	} else {
+		for linesRendered < maxVisible {
+			writeLine("\033[0m")
+		}
+	}
+	return s.String()
+}
+
+// renderHistoryFile renders the file as it was at the opened commit, with line numbers
+func (m model) renderHistoryFile(maxVisible int) string {
+	var s strings.Builder
+	rows := m.historyFileRows()
+	totalLines := max(len(rows), 1)
+	start := max(0, min(m.preview.scrollPos, totalLines-maxVisible))
+	lineNumStyle := lipgloss.NewStyle().Foreground(uiSubtleText())
+
+	linesRendered := 0
+	for i := start; i < len(rows) && linesRendered < maxVisible; i++ {
+		lineNum := "      "
+		if rows[i].first {
+			lineNum = fmt.Sprintf("%5d ", rows[i].line+1)
+		}
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(lineNumStyle.Render(lineNum) + m.renderScrollbar(i-start, maxVisible, totalLines) + " " +
+			m.highlightSearchLine(rows[i].text, i) + "\033[0m")
+		linesRendered++
+	}
+	for linesRendered < maxVisible {
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString("\033[0m")
+		linesRendered++
+	}
+	return s.String()
+}
+
+// historyStatusText describes the selection for info lines
+func (m model) historyStatusText() string {
+	h := m.preview.history
+	switch {
+	case h.loading:
+		return "History | loading..."
+	case h.confirm:
+		c := h.selected()
+		return fmt.Sprintf("History | Restore %s from %s? The current file goes to trash. y/n", filepath.Base(h.path), c.hash[:blameHashWidth])
+	case h.view != nil && h.view.file:
+		return fmt.Sprintf("History | %s at %s (%s)", h.view.commit.path, h.view.commit.hash[:blameHashWidth], formatLastCommitTime(h.view.commit.time))
+	case h.view != nil:
+		return fmt.Sprintf("History | commit %s %s", h.view.commit.hash[:blameHashWidth], h.view.commit.subject)
+	case len(h.commits) == 0:
+		return "History | no commits"
+	}
+	return fmt.Sprintf("History | %d of %s", h.cursor+1, pluralize(len(h.commits), "commit"))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gitCommitAll(t *testing.T, dir, message string) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=Bob", "-c", "user.email=bob@example.com", "commit", "-q", "-m", message},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestParseHistoryLog(t *testing.T) {
+	a, b, c := strings.Repeat("a", 40), strings.Repeat("b", 40), strings.Repeat("c", 40)
+	out := "\x1e" + a + "\x1fBob\x1f1700000000\x1fEdit\n\nM\tsrc/new.go\n" +
+		"\x1e" + b + "\x1fBob\x1f1600000000\x1fMove\n\nR097\told.go\tsrc/new.go\n" +
+		"\x1e" + c + "\x1fAlice\x1f1500000000\x1fMerge branch\n"
+
+	commits := parseHistoryLog(out, "src/new.go")
+	if len(commits) != 3 {
+		t.Fatalf("Expected 3 commits, got %d", len(commits))
+	}
+	if commits[0].path != "src/new.go" || commits[0].status != "M" || commits[0].subject != "Edit" {
+		t.Errorf("Unexpected first commit %+v", commits[0])
+	}
+	if commits[1].status != "R" || commits[1].oldPath != "old.go" || commits[1].path != "src/new.go" {
+		t.Errorf("Expected the rename, got %+v", commits[1])
+	}
+	// A commit listing no file keeps the name from before the rename
+	if commits[2].path != "old.go" || commits[2].author != "Alice" || commits[2].time.Unix() != 1500000000 {
+		t.Errorf("Unexpected merge commit %+v", commits[2])
+	}
+	if !strings.Contains(historyRow(&commits[1], "src/new.go"), "[renamed from old.go]") {
+		t.Errorf("Expected the rename in the row, got %q", historyRow(&commits[1], "src/new.go"))
+	}
+}
+
+func TestFileHistory(t *testing.T) {
+	dir := initTestRepo(t, "old.txt", "one\n")
+	os.Rename(filepath.Join(dir, "old.txt"), filepath.Join(dir, "notes.txt"))
+	gitCommitAll(t, dir, "Rename notes")
+	path := filepath.Join(dir, "notes.txt")
+	os.WriteFile(path, []byte("one\ntwo\n"), 0600)
+	gitCommitAll(t, dir, "Add a line")
+	os.WriteFile(path, []byte("local edit\n"), 0644)
+	os.Chmod(path, 0600)
+
+	t.Setenv("HOME", t.TempDir()) // Trash goes here
+	m := model{height: 30, width: 100, viewMode: viewFullPreview, currentPath: dir}
+	m.loadPreview(path)
+	cmd := m.toggleFileHistory()
+	h := m.preview.history
+	if h == nil || cmd == nil {
+		t.Fatal("Expected the history browser to open")
+	}
+	m.applyHistoryMsg(cmd().(historyMsg))
+	if h.err != nil || len(h.commits) != 3 {
+		t.Fatalf("Expected 3 commits across the rename, got %d (%v)", len(h.commits), h.err)
+	}
+	if h.commits[0].subject != "Add a line" || h.commits[2].path != "old.txt" {
+		t.Errorf("Unexpected commits %+v", h.commits)
+	}
+	if out := m.renderPreview(20); !strings.Contains(out, "Rename notes") || !strings.Contains(out, "Initial commit") {
+		t.Errorf("Expected the commits listed:\n%s", out)
+	}
+
+	// Diff against the parent
+	m.moveHistoryCursor(0)
+	m.applyHistoryViewMsg(m.openHistoryView(false)().(historyViewMsg))
+	if out := plainLines(strings.Split(m.renderPreview(40), "\n")); !strings.Contains(out, "+two") {
+		t.Errorf("Expected the commit's diff:\n%s", out)
+	}
+
+	// The file as it was before the rename
+	m.closeHistoryView()
+	m.moveHistoryCursor(2)
+	m.applyHistoryViewMsg(m.openHistoryView(true)().(historyViewMsg))
+	if v := h.view; v.err != nil || plainLines(v.lines) != "one" {
+		t.Errorf("Expected the first version, got %q (%v)", v.lines, v.err)
+	}
+
+	// Restore it: the local edit goes to trash and the browser stays open
+	m.restoreHistoryVersion()
+	if data, _ := os.ReadFile(path); string(data) != "one\n" {
+		t.Errorf("Expected the old version restored, got %q", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file mode kept, got %v", info.Mode().Perm())
+	}
+	items, err := getTrashItems()
+	if err != nil || len(items) != 1 {
+		t.Fatalf("Expected the replaced file in trash, got %d (%v)", len(items), err)
+	}
+	if data, _ := os.ReadFile(items[0].TrashedPath); string(data) != "local edit\n" {
+		t.Errorf("Expected the local edit in trash, got %q", data)
+	}
+	if m.preview.history != h || !strings.HasPrefix(plainLines(m.preview.content), "one") {
+		t.Errorf("Expected the preview reloaded with the browser open, got %q", m.preview.content)
+	}
+}
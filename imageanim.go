@@ -0,0 +1,288 @@
+package main
+
+// Module: imageanim.go
+// Purpose: Playing animated GIFs in the preview pane
+// Responsibilities:
+// - Decoding and compositing all GIF frames (disposal modes, frame delays, loop count)
+// - Scheduling frame ticks, pausing while the preview isn't focused
+// - Swapping the current frame into the graphics protocol or block-character preview
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	imageAnimMaxFrames = 500                    // Longer animations are cut off
+	imageAnimMaxSide   = 480                    // Frames are stored scaled down to this size
+	imageAnimMaxPixels = 32 << 20               // Budget for all stored frames (4 bytes per pixel)
+	imageAnimDefDelay  = 100 * time.Millisecond // Used for frames with a 0-1cs delay
+)
+
+// imageAnimation is a decoded multi-frame image and its playback state
+type imageAnimation struct {
+	frames    []image.Image   // Fully composited frames
+	delays    []time.Duration // How long each frame is shown
+	loopCount int             // GIF loop count: 0 = forever, -1 = once, n = n extra times
+	truncated bool            // Frames were dropped (too many or too large)
+
+	frame     int  // Current frame
+	playing   bool // Paused by the user (or stopped at the end) when false
+	plays     int  // Completed passes through all frames
+	scheduled bool // A frame tick is pending
+
+	// Graphics protocol preview: encoded frames and the text around them
+	protocol bool
+	encoded  []string // Lazily encoded frames ("" = not encoded yet)
+	header   []string
+	footer   []string
+}
+
+// imageFrameMsg advances an animation to its next frame
+type imageFrameMsg struct {
+	anim *imageAnimation // The animation that scheduled the tick (stale after navigation)
+}
+
+// loadImageAnimation decodes every frame of an animated GIF. Returns nil for still images.
+func loadImageAnimation(path string) *imageAnimation {
+	if !strings.EqualFold(filepath.Ext(path), ".gif") {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	cfg, err := gif.DecodeConfig(f)
+	if err != nil || int64(cfg.Width)*int64(cfg.Height) > imageMaxPixels {
+		return nil
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil
+	}
+	g, err := gif.DecodeAll(f)
+	if err != nil || len(g.Image) < 2 {
+		return nil
+	}
+	return composeGIFFrames(g)
+}
+
+// composeGIFFrames draws each GIF frame onto a canvas, applying the previous frame's disposal
+func composeGIFFrames(g *gif.GIF) *imageAnimation {
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() {
+		for _, frame := range g.Image {
+			bounds = bounds.Union(frame.Bounds())
+		}
+	}
+	canvas := image.NewRGBA(bounds)
+	anim := &imageAnimation{loopCount: g.LoopCount, playing: true}
+
+	budget := imageAnimMaxPixels
+	for i, frame := range g.Image {
+		if i >= imageAnimMaxFrames {
+			anim.truncated = true
+			break
+		}
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		var previous []uint8
+		if disposal == gif.DisposalPrevious {
+			previous = append([]uint8(nil), canvas.Pix...)
+		}
+
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+		snapshot := scaleImage(canvas, imageAnimMaxSide, imageAnimMaxSide/2)
+		if snapshot == image.Image(canvas) {
+			snapshot = cloneRGBA(canvas)
+		}
+		size := snapshot.Bounds().Dx() * snapshot.Bounds().Dy()
+		if budget -= size; budget < 0 && len(anim.frames) > 0 {
+			anim.truncated = true
+			break
+		}
+		anim.frames = append(anim.frames, snapshot)
+		var delay int
+		if i < len(g.Delay) {
+			delay = g.Delay[i]
+		}
+		anim.delays = append(anim.delays, gifFrameDelay(delay))
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			copy(canvas.Pix, previous)
+		}
+	}
+	if len(anim.frames) < 2 {
+		return nil
+	}
+	anim.encoded = make([]string, len(anim.frames))
+	return anim
+}
+
+// gifFrameDelay converts a GIF delay (1/100 s) the way browsers do: 0-1 means 100ms
+func gifFrameDelay(centiseconds int) time.Duration {
+	if centiseconds <= 1 {
+		return imageAnimDefDelay
+	}
+	return time.Duration(centiseconds) * 10 * time.Millisecond
+}
+
+// cloneRGBA copies an RGBA image
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	out := image.NewRGBA(img.Bounds())
+	copy(out.Pix, img.Pix)
+	return out
+}
+
+// startProtocolAnimation sets up the graphics protocol preview for an animation.
+// Returns the content for the first frame, or false if the frame couldn't be encoded.
+func (m *model) startProtocolAnimation(anim *imageAnimation, header, footer []string) ([]string, bool) {
+	anim.protocol = true
+	anim.header = header
+	anim.footer = footer
+	lines, ok := anim.protocolContent()
+	if !ok {
+		return nil, false
+	}
+	m.preview.anim = anim
+	return lines, true
+}
+
+// protocolContent returns the preview lines with the current frame encoded for the terminal
+func (a *imageAnimation) protocolContent() ([]string, bool) {
+	if a.encoded[a.frame] == "" {
+		data, ok := encodeImageWithProtocol(a.frames[a.frame], 80, 30)
+		if !ok {
+			return nil, false
+		}
+		// Kitty keeps every placed image: remove the previous frame first
+		if detectTerminalProtocol() == ProtocolKitty {
+			data = clearKittyGraphics() + data
+		}
+		a.encoded[a.frame] = data
+	}
+	lines := append([]string(nil), a.header...)
+	lines = append(lines, strings.Split(strings.TrimRight(a.encoded[a.frame], "\n"), "\n")...)
+	return append(lines, a.footer...), true
+}
+
+// startImageAnimation shows an animation in the block-character preview
+func (m *model) startImageAnimation(anim *imageAnimation) {
+	if m.preview.image == nil || m.preview.image.img == nil {
+		return
+	}
+	m.preview.anim = anim
+	m.preview.image.img = anim.frames[0]
+}
+
+// showImageFrame displays the animation's current frame
+func (m *model) showImageFrame() {
+	anim := m.preview.anim
+	if anim.protocol {
+		if lines, ok := anim.protocolContent(); ok {
+			m.preview.content = lines
+		}
+		return
+	}
+	if v := m.preview.image; v != nil {
+		v.img = anim.frames[anim.frame]
+		v.cacheLines = nil
+	}
+}
+
+// previewHasFocus reports whether the preview is what the user is looking at
+func (m model) previewHasFocus() bool {
+	if m.terminalBlurred {
+		return false
+	}
+	return m.previewOnly || m.viewMode == viewFullPreview ||
+		(m.viewMode == viewDualPane && m.focusedPane == rightPane)
+}
+
+// imageAnimationCmd schedules the next frame tick when playback should be running.
+// Called from the global tick, so playback resumes on its own when the preview regains focus.
+func (m *model) imageAnimationCmd() tea.Cmd {
+	anim := m.preview.anim
+	if anim == nil || !anim.playing || anim.scheduled || m.preview.hex != nil || !m.previewHasFocus() {
+		return nil
+	}
+	anim.scheduled = true
+	return tea.Tick(anim.delays[anim.frame], func(time.Time) tea.Msg {
+		return imageFrameMsg{anim: anim}
+	})
+}
+
+// handleImageFrame moves to the next frame and schedules the one after it
+func (m *model) handleImageFrame(msg imageFrameMsg) tea.Cmd {
+	anim := m.preview.anim
+	if anim == nil || msg.anim != anim {
+		return nil // Navigated away since the tick was scheduled
+	}
+	anim.scheduled = false
+	if !anim.playing || m.preview.hex != nil || !m.previewHasFocus() {
+		return nil // Paused: the global tick restarts playback
+	}
+	if anim.frame == len(anim.frames)-1 {
+		anim.plays++
+		if anim.loopCount < 0 || (anim.loopCount > 0 && anim.plays > anim.loopCount) {
+			anim.playing = false // Finite loop count reached: stay on the last frame
+			return nil
+		}
+	}
+	anim.frame = (anim.frame + 1) % len(anim.frames)
+	m.showImageFrame()
+	return m.imageAnimationCmd()
+}
+
+// toggleImageAnimation pauses or resumes playback (restarting a finished animation)
+func (m *model) toggleImageAnimation() {
+	anim := m.preview.anim
+	anim.playing = !anim.playing
+	if anim.playing {
+		if anim.loopCount != 0 && anim.frame == len(anim.frames)-1 {
+			anim.frame, anim.plays = 0, 0
+			m.showImageFrame()
+		}
+		m.setStatusMessage("▶ Playing animation", false)
+	} else {
+		m.setStatusMessage("⏸ Animation paused", false)
+	}
+}
+
+// stepImageFrame pauses playback and moves delta frames (wrapping around)
+func (m *model) stepImageFrame(delta int) {
+	anim := m.preview.anim
+	anim.playing = false
+	n := len(anim.frames)
+	anim.frame = ((anim.frame+delta)%n + n) % n
+	m.showImageFrame()
+}
+
+// imageAnimationStatus returns the frame counter for the info line
+func (m model) imageAnimationStatus() string {
+	anim := m.preview.anim
+	state := "▶"
+	if !anim.playing {
+		state = "⏸"
+	} else if !m.previewHasFocus() {
+		state = "⏸ (unfocused)"
+	}
+	total := fmt.Sprintf("%d", len(anim.frames))
+	if anim.truncated {
+		total += "+"
+	}
+	return fmt.Sprintf("%s frame %d/%s (%dms)", state, anim.frame+1, total, anim.delays[anim.frame].Milliseconds())
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	testRed   = color.RGBA{255, 0, 0, 255}
+	testGreen = color.RGBA{0, 255, 0, 255}
+	testBlue  = color.RGBA{0, 0, 255, 255}
+)
+
+// buildTestGIF returns a 4×4 three-frame GIF: red background, a green 2×2 square
+// (disposed to background) and a blue pixel in the bottom-right corner
+func buildTestGIF(loopCount int) *gif.GIF {
+	pal := color.Palette{color.Transparent, testRed, testGreen, testBlue}
+	frame := func(r image.Rectangle, idx uint8) *image.Paletted {
+		img := image.NewPaletted(r, pal)
+		for i := range img.Pix {
+			img.Pix[i] = idx
+		}
+		return img
+	}
+	return &gif.GIF{
+		Image: []*image.Paletted{
+			frame(image.Rect(0, 0, 4, 4), 1),
+			frame(image.Rect(0, 0, 2, 2), 2),
+			frame(image.Rect(3, 3, 4, 4), 3),
+		},
+		Delay:     []int{0, 5, 20},
+		Disposal:  []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalNone},
+		LoopCount: loopCount,
+		Config:    image.Config{ColorModel: pal, Width: 4, Height: 4},
+	}
+}
+
+func writeTestGIF(t *testing.T, name string, loopCount int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, buildTestGIF(loopCount)); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestComposeGIFFrames(t *testing.T) {
+	anim := composeGIFFrames(buildTestGIF(0))
+	if anim == nil || len(anim.frames) != 3 {
+		t.Fatalf("Expected 3 frames, got %+v", anim)
+	}
+
+	same := func(c color.Color, want color.RGBA) bool {
+		r, g, b, a := c.RGBA()
+		wr, wg, wb, wa := want.RGBA()
+		return r == wr && g == wg && b == wb && a == wa
+	}
+	// Frame 2 draws over frame 1
+	if !same(anim.frames[1].At(0, 0), testGreen) || !same(anim.frames[1].At(3, 3), testRed) {
+		t.Error("Expected the green square over the red background")
+	}
+	// Frame 2's area is cleared (disposal to background) before frame 3
+	if _, _, _, a := anim.frames[2].At(0, 0).RGBA(); a != 0 {
+		t.Error("Expected the disposed square to be transparent")
+	}
+	if !same(anim.frames[2].At(3, 3), testBlue) || !same(anim.frames[2].At(2, 2), testRed) {
+		t.Error("Expected the blue pixel over the red background")
+	}
+
+	want := []time.Duration{100 * time.Millisecond, 50 * time.Millisecond, 200 * time.Millisecond}
+	for i, d := range anim.delays {
+		if d != want[i] {
+			t.Errorf("Frame %d: expected delay %v, got %v", i, want[i], d)
+		}
+	}
+
+	// Still images aren't animations
+	still := buildTestGIF(0)
+	still.Image, still.Delay, still.Disposal = still.Image[:1], still.Delay[:1], still.Disposal[:1]
+	var buf bytes.Buffer
+	gif.EncodeAll(&buf, still)
+	path := filepath.Join(t.TempDir(), "still.gif")
+	os.WriteFile(path, buf.Bytes(), 0644)
+	if loadImageAnimation(path) != nil {
+		t.Error("Expected no animation for a single-frame GIF")
+	}
+}
+
+func TestImageAnimationPlayback(t *testing.T) {
+	t.Setenv("TFE_TERMINAL_PROTOCOL", "none")
+	path := writeTestGIF(t, "spinner.gif", 0)
+
+	var tm tea.Model = model{height: 30, width: 120, viewMode: viewFullPreview}
+	m := tm.(model)
+	m.loadPreview(path)
+	anim := m.preview.anim
+	if anim == nil || m.preview.image == nil || m.preview.image.img != anim.frames[0] {
+		t.Fatal("Expected the GIF to play in the block-character preview")
+	}
+
+	// The global tick schedules the first frame once
+	if m.imageAnimationCmd() == nil || !anim.scheduled || m.imageAnimationCmd() != nil {
+		t.Error("Expected exactly one pending frame tick")
+	}
+	if cmd := m.handleImageFrame(imageFrameMsg{anim: anim}); cmd == nil || anim.frame != 1 {
+		t.Errorf("Expected frame 2 and the next tick, got frame %d", anim.frame+1)
+	}
+	if m.preview.image.img != anim.frames[1] || m.preview.image.cacheLines != nil {
+		t.Error("Expected the preview to show the new frame")
+	}
+	if !strings.Contains(m.imageStatusText(), "frame 2/3 (50ms)") {
+		t.Errorf("Unexpected status %q", m.imageStatusText())
+	}
+
+	// Ticks from another animation are ignored
+	if m.handleImageFrame(imageFrameMsg{anim: &imageAnimation{}}) != nil || anim.frame != 1 {
+		t.Error("Expected a stale tick to be ignored")
+	}
+
+	// Losing focus pauses until the preview is focused again
+	anim.scheduled = false
+	m.terminalBlurred = true
+	if m.imageAnimationCmd() != nil {
+		t.Error("Expected no ticks while the terminal is in the background")
+	}
+	m.terminalBlurred = false
+	m.viewMode, m.focusedPane = viewDualPane, leftPane
+	if m.imageAnimationCmd() != nil || !strings.Contains(m.imageAnimationStatus(), "unfocused") {
+		t.Error("Expected no ticks while the file list has focus")
+	}
+	m.viewMode = viewFullPreview
+
+	// Frame stepping pauses playback and wraps around
+	for _, step := range []struct {
+		key  string
+		want int
+	}{{"]", 2}, {"]", 0}, {"[", 2}} {
+		tm, _ = m.handleKeyEvent(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(step.key)})
+		m = tm.(model)
+		if anim.frame != step.want || anim.playing {
+			t.Errorf("%s: expected paused on frame %d, got %d", step.key, step.want+1, anim.frame+1)
+		}
+	}
+	tm, _ = m.handleKeyEvent(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = tm.(model)
+	if !anim.playing || m.imageAnimationCmd() == nil {
+		t.Error("Expected p to resume playback")
+	}
+
+	// Navigating away drops the animation; its pending tick does nothing
+	m.loadPreview(writeTestImage(t, "photo.png", 8, 8, nil))
+	if m.preview.anim != nil || m.handleImageFrame(imageFrameMsg{anim: anim}) != nil {
+		t.Error("Expected navigation to stop playback")
+	}
+}
+
+func TestImageAnimationLoopCount(t *testing.T) {
+	t.Setenv("TFE_TERMINAL_PROTOCOL", "iterm2")
+	path := writeTestGIF(t, "once.gif", -1)
+
+	m := model{height: 30, width: 120, viewMode: viewFullPreview}
+	m.loadPreview(path)
+	anim := m.preview.anim
+	if anim == nil || !anim.protocol || !m.preview.hasGraphicsProtocol {
+		t.Fatal("Expected the GIF to play through the graphics protocol")
+	}
+	first := strings.Join(m.preview.content, "\n")
+	if !strings.Contains(first, "\033]1337;File=") {
+		t.Error("Expected iTerm2 image data in the preview")
+	}
+
+	for i := 0; i < 2; i++ {
+		m.handleImageFrame(imageFrameMsg{anim: anim})
+	}
+	if anim.frame != 2 || strings.Join(m.preview.content, "\n") == first {
+		t.Errorf("Expected the last frame to be shown, got frame %d", anim.frame+1)
+	}
+	// Played once: stops on the last frame
+	if m.handleImageFrame(imageFrameMsg{anim: anim}) != nil || anim.playing || anim.frame != 2 {
+		t.Error("Expected playback to stop after one pass")
+	}
+	// Resuming restarts from the first frame
+	m.toggleImageAnimation()
+	if !anim.playing || anim.frame != 0 {
+		t.Error("Expected a finished animation to restart")
+	}
+}
@@ -0,0 +1,547 @@
+package main
+
+// Module: imageview.go
+// Purpose: Built-in image preview for terminals without a graphics protocol
+// Responsibilities:
+// - Drawing images with Unicode half-block / quadrant characters in truecolor or 256 colors
+// - Showing format, dimensions and EXIF metadata alongside the image
+// - Caching the rendered lines for the current pane size
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	imageMaxPixels   = 64 << 20 // Larger images are not decoded (metadata only)
+	imageMetaWidth   = 40       // Max width of the metadata column
+	imageMinColumns  = 24       // Narrower space for the image stacks the metadata above it
+	imageMetaSpacing = 3
+)
+
+// imageBlockMode selects the characters used to draw an image
+type imageBlockMode int
+
+const (
+	imageHalfBlocks imageBlockMode = iota // ▀ - two pixels per cell, stacked
+	imageQuadrants                        // ▘▝▖▗... - four pixels per cell, two colors per cell
+)
+
+// quadrantChars maps a mask of lit quadrants (TL=1, TR=2, BL=4, BR=8) to its character
+var quadrantChars = [16]string{" ", "▘", "▝", "▀", "▖", "▌", "▞", "▛", "▗", "▚", "▐", "▜", "▄", "▙", "▟", "█"}
+
+// imagePreviewState is the block-character image preview
+type imagePreviewState struct {
+	img        image.Image // nil when the image couldn't be decoded
+	format     string
+	width      int
+	height     int
+	colorModel string
+	fileSize   int64
+	exif       *exifData
+	mode       imageBlockMode
+	trueColor  bool
+	err        string // Why the image isn't drawn
+
+	// Rendered lines for the last pane size
+	cacheKey   [3]int // Width, height, mode
+	cacheLines []string
+}
+
+// openImagePreview decodes path for the block-character preview. Returns false if it isn't a supported image.
+func (m *model) openImagePreview(path string, size int64) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	cfg, format, err := image.DecodeConfig(f)
+	f.Close()
+	if err != nil {
+		return false
+	}
+
+	v := &imagePreviewState{
+		format:     strings.ToUpper(format),
+		width:      cfg.Width,
+		height:     cfg.Height,
+		colorModel: imageColorModelName(cfg.ColorModel),
+		fileSize:   size,
+		exif:       readImageEXIF(path),
+		trueColor:  terminalSupportsTrueColor(),
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > imageMaxPixels {
+		v.err = "Image is too large to draw"
+	} else if img, err := loadImageFile(path); err != nil {
+		v.err = fmt.Sprintf("Cannot decode image: %s", err)
+	} else {
+		v.img = img
+	}
+	m.preview.image = v
+	return true
+}
+
+// imageColorModelName describes a decoder's color model
+func imageColorModelName(model color.Model) string {
+	if p, ok := model.(color.Palette); ok {
+		return fmt.Sprintf("Indexed (%d colors)", len(p))
+	}
+	switch model {
+	case color.RGBAModel, color.NRGBAModel:
+		return "RGBA"
+	case color.RGBA64Model, color.NRGBA64Model:
+		return "RGBA (16-bit)"
+	case color.GrayModel:
+		return "Grayscale"
+	case color.Gray16Model:
+		return "Grayscale (16-bit)"
+	case color.YCbCrModel:
+		return "YCbCr"
+	case color.NYCbCrAModel:
+		return "YCbCr + alpha"
+	case color.CMYKModel:
+		return "CMYK"
+	}
+	return ""
+}
+
+// displaySize returns the image size after applying the EXIF orientation
+func (v *imagePreviewState) displaySize() (int, int) {
+	if v.exif != nil && v.exif.orientation >= 5 {
+		return v.height, v.width
+	}
+	return v.width, v.height
+}
+
+// orientation returns the EXIF orientation (1 when not set)
+func (v *imagePreviewState) orientation() int {
+	if v.exif == nil || v.exif.orientation == 0 {
+		return 1
+	}
+	return v.exif.orientation
+}
+
+// modeText describes how the image is drawn
+func (v *imagePreviewState) modeText() string {
+	mode := "half blocks"
+	if v.mode == imageQuadrants {
+		mode = "quadrant blocks"
+	}
+	if v.trueColor {
+		return mode + ", truecolor"
+	}
+	return mode + ", 256 colors"
+}
+
+// imageCellSize fits a w×h image into at most maxCols×maxRows cells, keeping its aspect ratio.
+// A cell is about twice as tall as it is wide; images are not enlarged past one pixel per column.
+func imageCellSize(w, h, maxCols, maxRows int) (cols, rows int) {
+	if w <= 0 || h <= 0 || maxCols <= 0 || maxRows <= 0 {
+		return 0, 0
+	}
+	cols = min(maxCols, w)
+	rows = int(math.Round(float64(cols) * float64(h) / float64(w) / 2))
+	if rows > maxRows {
+		rows = maxRows
+		cols = int(math.Round(float64(rows) * 2 * float64(w) / float64(h)))
+	}
+	return max(1, min(cols, maxCols)), max(1, rows)
+}
+
+// renderImageBlocks draws img in cols×rows cells, one string per row
+func renderImageBlocks(img image.Image, orientation, cols, rows int, mode imageBlockMode, trueColor bool) []string {
+	pxW, pxH := cols, rows*2
+	if mode == imageQuadrants {
+		pxW *= 2
+	}
+	// Cheap nearest-neighbour pass down to about twice the grid size, then averaged into the grid
+	srcW, srcH := pxW, pxH
+	if orientation >= 5 {
+		srcW, srcH = pxH, pxW
+	}
+	scaled := scaleImage(img, srcW*2, srcH)
+	grid := sampleImageGrid(orientImage(scaled, orientation), pxW, pxH)
+	at := func(x, y int) color.NRGBA { return grid[y*pxW+x] }
+
+	lines := make([]string, rows)
+	for r := 0; r < rows; r++ {
+		w := blockWriter{trueColor: trueColor}
+		for c := 0; c < cols; c++ {
+			if mode == imageQuadrants {
+				w.quadrant([4]color.NRGBA{at(2*c, 2*r), at(2*c+1, 2*r), at(2*c, 2*r+1), at(2*c+1, 2*r+1)})
+			} else {
+				w.halfBlock(at(c, 2*r), at(c, 2*r+1))
+			}
+		}
+		w.sb.WriteString("\033[0m")
+		lines[r] = w.sb.String()
+	}
+	return lines
+}
+
+// orientImage applies an EXIF orientation (2-8) so the image is shown upright
+func orientImage(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	out := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored
+				sx, sy = w-1-x, y
+			case 3: // Rotated 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // Flipped
+				sx, sy = x, h-1-y
+			case 5: // Transposed
+				sx, sy = y, x
+			case 6: // Rotate 90° CW to display
+				sx, sy = y, h-1-x
+			case 7: // Transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // Rotate 90° CCW to display
+				sx, sy = w-1-y, x
+			}
+			out.Set(x, y, img.At(b.Min.X+sx, b.Min.Y+sy))
+		}
+	}
+	return out
+}
+
+// sampleImageGrid averages img down (or repeats pixels up) to a w×h grid of colors
+func sampleImageGrid(img image.Image, w, h int) []color.NRGBA {
+	b := img.Bounds()
+	iw, ih := b.Dx(), b.Dy()
+	grid := make([]color.NRGBA, w*h)
+	if iw == 0 || ih == 0 {
+		return grid
+	}
+	for y := 0; y < h; y++ {
+		y0 := y * ih / h
+		y1 := max(y0+1, (y+1)*ih/h)
+		for x := 0; x < w; x++ {
+			x0 := x * iw / w
+			x1 := max(x0+1, (x+1)*iw/w)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(b.Min.X+sx, b.Min.Y+sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			if a == 0 {
+				continue // Transparent
+			}
+			// Colors are premultiplied: divide by the alpha sum to get the straight color
+			grid[y*w+x] = color.NRGBA{
+				R: uint8(r * 0xffff / a >> 8),
+				G: uint8(g * 0xffff / a >> 8),
+				B: uint8(bl * 0xffff / a >> 8),
+				A: uint8(a / n >> 8),
+			}
+		}
+	}
+	return grid
+}
+
+// blockWriter writes block characters, only emitting color escapes when the color changes
+type blockWriter struct {
+	sb        strings.Builder
+	trueColor bool
+	fg, bg    string // Current SGR color parameters ("" = terminal default)
+}
+
+// keepColor leaves the current color as it is (the cell doesn't show it)
+const keepColor = "keep"
+
+// opaque reports whether a pixel is drawn (mostly transparent pixels show the terminal background)
+func opaque(c color.NRGBA) bool {
+	return c.A >= 128
+}
+
+// colorParam returns the SGR parameter for c as a foreground (38) or background (48) color
+func (w *blockWriter) colorParam(c color.NRGBA, base int) string {
+	if w.trueColor {
+		return fmt.Sprintf("%d;2;%d;%d;%d", base, c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("%d;5;%d", base, ansi256(c))
+}
+
+// cell writes ch with the given foreground and background parameters
+func (w *blockWriter) cell(ch, fg, bg string) {
+	if fg != keepColor && fg != w.fg {
+		if fg == "" {
+			w.sb.WriteString("\033[39m")
+		} else {
+			w.sb.WriteString("\033[" + fg + "m")
+		}
+		w.fg = fg
+	}
+	if bg != keepColor && bg != w.bg {
+		if bg == "" {
+			w.sb.WriteString("\033[49m")
+		} else {
+			w.sb.WriteString("\033[" + bg + "m")
+		}
+		w.bg = bg
+	}
+	w.sb.WriteString(ch)
+}
+
+// halfBlock draws two vertically stacked pixels in one cell
+func (w *blockWriter) halfBlock(top, bottom color.NRGBA) {
+	switch {
+	case !opaque(top) && !opaque(bottom):
+		w.cell(" ", keepColor, "")
+	case !opaque(top):
+		w.cell("▄", w.colorParam(bottom, 38), "")
+	case !opaque(bottom):
+		w.cell("▀", w.colorParam(top, 38), "")
+	default:
+		fg, bg := w.colorParam(top, 38), w.colorParam(bottom, 48)
+		if fg[2:] == bg[2:] {
+			w.cell(" ", keepColor, bg)
+		} else {
+			w.cell("▀", fg, bg)
+		}
+	}
+}
+
+// quadrant draws four pixels (TL, TR, BL, BR) in one cell using the two colors that fit them best
+func (w *blockWriter) quadrant(px [4]color.NRGBA) {
+	lit := 0
+	for i, c := range px {
+		if opaque(c) {
+			lit |= 1 << i
+		}
+	}
+	if lit == 0 {
+		w.cell(" ", keepColor, "")
+		return
+	}
+	if lit != 15 {
+		// Partly transparent: draw the opaque quadrants over the terminal background
+		w.cell(quadrantChars[lit], w.colorParam(meanColor(px, lit), 38), "")
+		return
+	}
+
+	// Try every split of the four pixels into two groups; keep the one with the least error.
+	// Counting down prefers the solid cell (15) when the pixels are all the same.
+	best, bestErr := 15, math.MaxFloat64
+	for mask := 15; mask >= 1; mask-- {
+		var e float64
+		fg := meanColor(px, mask)
+		bg := meanColor(px, 15&^mask)
+		for i, c := range px {
+			if mask&(1<<i) != 0 {
+				e += colorDistance(c, fg)
+			} else {
+				e += colorDistance(c, bg)
+			}
+		}
+		if e < bestErr {
+			best, bestErr = mask, e
+		}
+	}
+	if best == 15 {
+		w.cell(" ", keepColor, w.colorParam(meanColor(px, 15), 48))
+		return
+	}
+	w.cell(quadrantChars[best], w.colorParam(meanColor(px, best), 38), w.colorParam(meanColor(px, 15&^best), 48))
+}
+
+// meanColor averages the pixels selected by mask
+func meanColor(px [4]color.NRGBA, mask int) color.NRGBA {
+	var r, g, b, n int
+	for i, c := range px {
+		if mask&(1<<i) != 0 {
+			r, g, b, n = r+int(c.R), g+int(c.G), b+int(c.B), n+1
+		}
+	}
+	if n == 0 {
+		return color.NRGBA{}
+	}
+	return color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 255}
+}
+
+// colorDistance is the squared RGB distance between two colors
+func colorDistance(a, b color.NRGBA) float64 {
+	dr, dg, db := float64(a.R)-float64(b.R), float64(a.G)-float64(b.G), float64(a.B)-float64(b.B)
+	return dr*dr + dg*dg + db*db
+}
+
+// ansi256 returns the closest color in the xterm 256-color palette (6×6×6 cube or gray ramp)
+func ansi256(c color.NRGBA) int {
+	levels := [6]int{0, 95, 135, 175, 215, 255}
+	nearest := func(v uint8) int {
+		best := 0
+		for i, l := range levels {
+			if abs(int(v)-l) < abs(int(v)-levels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	ri, gi, bi := nearest(c.R), nearest(c.G), nearest(c.B)
+	cube := color.NRGBA{R: uint8(levels[ri]), G: uint8(levels[gi]), B: uint8(levels[bi])}
+
+	// Gray ramp: 232-255 = 8, 18, ..., 238
+	avg := (int(c.R) + int(c.G) + int(c.B)) / 3
+	grayIdx := max(0, min(23, (avg-3)/10))
+	gv := uint8(8 + grayIdx*10)
+	gray := color.NRGBA{R: gv, G: gv, B: gv}
+
+	if colorDistance(c, gray) < colorDistance(c, cube) {
+		return 232 + grayIdx
+	}
+	return 16 + 36*ri + 6*gi + bi
+}
+
+// abs returns the absolute value of an int
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// imageMetadataLines returns the styled metadata shown next to the image
+func (v *imagePreviewState) imageMetadataLines() []string {
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(uiMutedText())
+	mutedStyle := lipgloss.NewStyle().Foreground(uiSubtleText())
+	field := func(label, value string) string {
+		return labelStyle.Render(fmt.Sprintf("%-13s", label)) + value
+	}
+
+	lines := []string{titleStyle.Render(v.format + " image")}
+	lines = append(lines, field("Dimensions", fmt.Sprintf("%d × %d", v.width, v.height)))
+	if v.colorModel != "" {
+		lines = append(lines, field("Colors", v.colorModel))
+	}
+	lines = append(lines, field("File size", formatFileSize(v.fileSize)))
+	if v.exif != nil && len(v.exif.fields) > 0 {
+		lines = append(lines, "", titleStyle.Render("EXIF"))
+		for _, f := range v.exif.fields {
+			lines = append(lines, field(f.label, f.value))
+		}
+	}
+	lines = append(lines, "")
+	if v.err != "" {
+		lines = append(lines, mutedStyle.Render("⚠ "+v.err))
+	} else {
+		lines = append(lines, mutedStyle.Render("Drawn with "+v.modeText()))
+	}
+	return lines
+}
+
+// imagePreviewLines lays out the image and its metadata for the pane (cached per pane size)
+func (m model) imagePreviewLines(maxVisible int) []string {
+	v := m.preview.image
+	width := m.previewBoxWidth() - 1 // Left padding
+	rows := maxVisible
+	if m.viewMode == viewDualPane {
+		rows = maxVisible - 1
+	}
+	key := [3]int{width, rows, int(v.mode)}
+	if v.cacheLines != nil && v.cacheKey == key {
+		return v.cacheLines
+	}
+
+	meta := v.imageMetadataLines()
+	metaWidth := 0
+	for _, line := range meta {
+		metaWidth = max(metaWidth, visualWidth(line))
+	}
+	metaWidth = min(metaWidth, imageMetaWidth)
+	dispW, dispH := v.displaySize()
+
+	var lines []string
+	if imgCols := width - metaWidth - imageMetaSpacing; imgCols >= imageMinColumns {
+		// Image on the left, metadata on the right
+		var img []string
+		cols := 0
+		if v.img != nil {
+			var imgRows int
+			cols, imgRows = imageCellSize(dispW, dispH, imgCols, rows)
+			img = renderImageBlocks(v.img, v.orientation(), cols, imgRows, v.mode, v.trueColor)
+		}
+		for i := 0; i < min(rows, max(len(img), len(meta))); i++ {
+			line := strings.Repeat(" ", cols)
+			if i < len(img) {
+				line = img[i]
+			}
+			if i < len(meta) {
+				line += strings.Repeat(" ", imageMetaSpacing) + truncateToWidth(meta[i], metaWidth)
+			}
+			lines = append(lines, line)
+		}
+	} else {
+		// Narrow pane: metadata first, then the image in the remaining rows
+		for _, line := range meta {
+			lines = append(lines, truncateToWidth(line, width))
+		}
+		if imgRows := rows - len(lines) - 1; v.img != nil && imgRows >= 3 {
+			cols, imgRows := imageCellSize(dispW, dispH, width, imgRows)
+			lines = append(lines, "")
+			lines = append(lines, renderImageBlocks(v.img, v.orientation(), cols, imgRows, v.mode, v.trueColor)...)
+		}
+		if len(lines) > rows {
+			lines = lines[:rows]
+		}
+	}
+
+	v.cacheKey = key
+	v.cacheLines = lines
+	return lines
+}
+
+// renderImagePreview renders the block-character image preview
+func (m model) renderImagePreview(maxVisible int) string {
+	lines := m.imagePreviewLines(maxVisible)
+	var s strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(" ")
+		s.WriteString(line)
+		s.WriteString("\033[0m")
+	}
+	return s.String()
+}
+
+// imageStatusText returns the info line text for the image preview
+func (m model) imageStatusText() string {
+	v := m.preview.image
+	text := fmt.Sprintf("%s %d×%d | %s", v.format, v.width, v.height, v.modeText())
+	if m.preview.anim != nil {
+		text += " | " + m.imageAnimationStatus()
+	}
+	return text
+}
+
+// toggleImageBlockMode switches between half-block and quadrant rendering
+func (m *model) toggleImageBlockMode() {
+	v := m.preview.image
+	if v.mode == imageHalfBlocks {
+		v.mode = imageQuadrants
+	} else {
+		v.mode = imageHalfBlocks
+	}
+	m.setStatusMessage("🖼 Drawing with "+v.modeText(), false)
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// buildTestEXIF builds a little-endian TIFF block with camera, exposure, orientation and GPS tags
+func buildTestEXIF(orientation uint16) []byte {
+	type entry struct {
+		tag, typ uint16
+		count    uint32
+		data     []byte
+	}
+	le := binary.LittleEndian
+	short := func(v uint16) []byte { b := make([]byte, 2); le.PutUint16(b, v); return b }
+	long := func(v uint32) []byte { b := make([]byte, 4); le.PutUint32(b, v); return b }
+	rat := func(pairs ...uint32) []byte {
+		var b []byte
+		for _, v := range pairs {
+			b = append(b, long(v)...)
+		}
+		return b
+	}
+	ascii := func(s string) []byte { return append([]byte(s), 0) }
+
+	// Layout: header, IFD0, Exif IFD, GPS IFD, then out-of-line values
+	ifd0 := []entry{
+		{exifTagMake, 2, 6, ascii("Canon")},
+		{exifTagModel, 2, 14, ascii("Canon EOS R5\x00")},
+		{exifTagOrientation, 3, 1, short(orientation)},
+		{exifTagExifIFD, 4, 1, nil},
+		{exifTagGPSIFD, 4, 1, nil},
+	}
+	sub := []entry{
+		{exifTagExposureTime, 5, 1, rat(1, 250)},
+		{exifTagFNumber, 5, 1, rat(28, 10)},
+		{exifTagISO, 3, 1, short(400)},
+		{exifTagDateTimeOriginal, 2, 20, ascii("2024:06:01 14:03:22")},
+	}
+	gps := []entry{
+		{exifTagGPSLatitudeRef, 2, 2, ascii("N")},
+		{exifTagGPSLatitude, 5, 3, rat(37, 1, 46, 1, 2964, 100)},
+		{exifTagGPSLongitudeRef, 2, 2, ascii("W")},
+		{exifTagGPSLongitude, 5, 3, rat(122, 1, 25, 1, 984, 100)},
+	}
+	ifdSize := func(n int) int { return 2 + 12*n + 4 }
+	ifd0Off := 8
+	subOff := ifd0Off + ifdSize(len(ifd0))
+	gpsOff := subOff + ifdSize(len(sub))
+	dataOff := gpsOff + ifdSize(len(gps))
+	ifd0[3].data = long(uint32(subOff))
+	ifd0[4].data = long(uint32(gpsOff))
+
+	out := []byte("II")
+	out = append(out, short(42)...)
+	out = append(out, long(uint32(ifd0Off))...)
+	var extra []byte
+	for _, ifd := range [][]entry{ifd0, sub, gps} {
+		out = append(out, short(uint16(len(ifd)))...)
+		for _, e := range ifd {
+			out = append(out, short(e.tag)...)
+			out = append(out, short(e.typ)...)
+			out = append(out, long(e.count)...)
+			if len(e.data) <= 4 {
+				out = append(out, append(e.data, make([]byte, 4-len(e.data))...)...)
+			} else {
+				out = append(out, long(uint32(dataOff+len(extra)))...)
+				extra = append(extra, e.data...)
+			}
+		}
+		out = append(out, long(0)...) // No next IFD
+	}
+	return append(out, extra...)
+}
+
+// writeTestImage writes a small gradient image as JPEG or PNG, optionally with an EXIF block
+func writeTestImage(t *testing.T, name string, w, h int, exif []byte) string {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{uint8(x * 255 / w), uint8(y * 255 / h), 100, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if strings.HasSuffix(name, ".png") {
+		png.Encode(&buf, img)
+	} else {
+		jpeg.Encode(&buf, img, nil)
+	}
+	data := buf.Bytes()
+	if exif != nil {
+		if strings.HasSuffix(name, ".png") {
+			// eXIf chunk right after the 8-byte signature + IHDR chunk (25 bytes)
+			chunk := binary.BigEndian.AppendUint32(nil, uint32(len(exif)))
+			chunk = append(chunk, "eXIf"...)
+			chunk = append(chunk, exif...)
+			chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+			data = append(append(append([]byte{}, data[:33]...), chunk...), data[33:]...)
+		} else {
+			// APP1 segment right after SOI
+			payload := append([]byte("Exif\x00\x00"), exif...)
+			seg := []byte{0xFF, 0xE1}
+			seg = binary.BigEndian.AppendUint16(seg, uint16(len(payload)+2))
+			seg = append(seg, payload...)
+			data = append(append(append([]byte{}, data[:2]...), seg...), data[2:]...)
+		}
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write test image: %v", err)
+	}
+	return path
+}
+
+// TestReadImageEXIF tests finding and parsing EXIF in JPEG and PNG files
+func TestReadImageEXIF(t *testing.T) {
+	for _, name := range []string{"photo.jpg", "photo.png"} {
+		x := readImageEXIF(writeTestImage(t, name, 16, 8, buildTestEXIF(6)))
+		if x == nil {
+			t.Fatalf("%s: expected EXIF data", name)
+		}
+		got := map[string]string{}
+		for _, f := range x.fields {
+			got[f.label] = f.value
+		}
+		want := map[string]string{
+			"Camera":      "Canon EOS R5",
+			"Taken":       "2024-06-01 14:03:22",
+			"Exposure":    "1/250s",
+			"Aperture":    "f/2.8",
+			"ISO":         "400",
+			"Orientation": "Rotated 90° CW",
+			"Location":    "37.77490, -122.41940",
+		}
+		for label, value := range want {
+			if got[label] != value {
+				t.Errorf("%s: %s = %q, want %q", name, label, got[label], value)
+			}
+		}
+		if x.orientation != 6 {
+			t.Errorf("%s: orientation = %d, want 6", name, x.orientation)
+		}
+	}
+
+	if x := readImageEXIF(writeTestImage(t, "plain.jpg", 16, 8, nil)); x != nil {
+		t.Errorf("Expected no EXIF data, got %+v", x)
+	}
+	if x := parseEXIF([]byte("II*\x00\xff\xff\xff\xff")); x != nil {
+		t.Error("Expected nil for an IFD offset past the end")
+	}
+}
+
+// TestImageCellSize tests fitting images into the pane
+func TestImageCellSize(t *testing.T) {
+	tests := []struct {
+		w, h, maxCols, maxRows int
+		cols, rows             int
+	}{
+		{200, 100, 80, 40, 80, 20}, // Width-bound
+		{100, 200, 80, 20, 20, 20}, // Height-bound
+		{16, 16, 80, 40, 16, 8},    // Small images aren't enlarged
+		{1000, 1, 50, 10, 50, 1},   // At least one row
+		{0, 10, 50, 10, 0, 0},      // Empty image
+	}
+	for _, tt := range tests {
+		cols, rows := imageCellSize(tt.w, tt.h, tt.maxCols, tt.maxRows)
+		if cols != tt.cols || rows != tt.rows {
+			t.Errorf("imageCellSize(%d, %d, %d, %d) = %d, %d; want %d, %d",
+				tt.w, tt.h, tt.maxCols, tt.maxRows, cols, rows, tt.cols, tt.rows)
+		}
+	}
+}
+
+// TestRenderImageBlocks tests the half-block and quadrant cell encodings
+func TestRenderImageBlocks(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	blue := color.NRGBA{0, 0, 255, 255}
+
+	// 1×2 pixels: red over blue is one half-block cell
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 2))
+	img.Set(0, 0, red)
+	img.Set(0, 1, blue)
+	lines := renderImageBlocks(img, 1, 1, 1, imageHalfBlocks, true)
+	if want := "\033[38;2;255;0;0m\033[48;2;0;0;255m▀\033[0m"; len(lines) != 1 || lines[0] != want {
+		t.Errorf("Half block = %q, want %q", lines, want)
+	}
+
+	// 2×2 checkerboard is one quadrant cell with the diagonal lit
+	img = image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, red)
+	img.Set(1, 1, red)
+	img.Set(1, 0, blue)
+	img.Set(0, 1, blue)
+	lines = renderImageBlocks(img, 1, 1, 1, imageQuadrants, false)
+	if !strings.Contains(lines[0], "▚") && !strings.Contains(lines[0], "▞") {
+		t.Errorf("Expected a diagonal quadrant, got %q", lines[0])
+	}
+	if !strings.Contains(lines[0], "38;5;196") || !strings.Contains(lines[0], "48;5;21") {
+		t.Errorf("Expected 256-color red and blue, got %q", lines[0])
+	}
+
+	// Transparent pixels leave the terminal background
+	img = image.NewNRGBA(image.Rect(0, 0, 1, 2))
+	img.Set(0, 1, red)
+	lines = renderImageBlocks(img, 1, 1, 1, imageHalfBlocks, true)
+	if !strings.Contains(lines[0], "▄") || strings.Contains(lines[0], "48;") {
+		t.Errorf("Expected a lower half block without background, got %q", lines[0])
+	}
+
+	// Rotated 90° CW: red on the left of a row ends up on top
+	img = image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, red)
+	img.Set(1, 0, blue)
+	rotated := orientImage(img, 6)
+	if b := rotated.Bounds(); b.Dx() != 1 || b.Dy() != 2 || rotated.At(0, 0) != red || rotated.At(0, 1) != blue {
+		t.Errorf("Unexpected rotation %v", rotated.Bounds())
+	}
+}
+
+// TestANSI256 tests mapping colors to the 256-color palette
+func TestANSI256(t *testing.T) {
+	tests := []struct {
+		c    color.NRGBA
+		want int
+	}{
+		{color.NRGBA{255, 0, 0, 255}, 196},
+		{color.NRGBA{255, 255, 255, 255}, 231},
+		{color.NRGBA{0, 0, 0, 255}, 16},
+		{color.NRGBA{128, 128, 128, 255}, 244}, // Gray ramp is closer than the cube
+	}
+	for _, tt := range tests {
+		if got := ansi256(tt.c); got != tt.want {
+			t.Errorf("ansi256(%v) = %d, want %d", tt.c, got, tt.want)
+		}
+	}
+}
+
+// TestImagePreviewFallback tests that images are drawn when there's no graphics protocol
+func TestImagePreviewFallback(t *testing.T) {
+	t.Setenv("TFE_TERMINAL_PROTOCOL", "none")
+	t.Setenv("COLORTERM", "truecolor")
+	path := writeTestImage(t, "photo.jpg", 64, 32, buildTestEXIF(6))
+
+	var tm tea.Model = model{height: 30, width: 120, viewMode: viewFullPreview}
+	m := tm.(model)
+	m.loadPreview(path)
+	v := m.preview.image
+	if v == nil {
+		t.Fatal("Expected the block-character image preview")
+	}
+	if w, h := v.displaySize(); v.format != "JPEG" || w != 32 || h != 64 {
+		t.Errorf("Unexpected image %s %dx%d", v.format, w, h)
+	}
+
+	out := m.renderPreview(20)
+	for _, want := range []string{"JPEG image", "64 × 32", "Canon EOS R5", "f/2.8", "▀", "38;2;"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected preview to contain %q", want)
+		}
+	}
+	if n := m.getWrappedLineCount(); n == 0 || n > 20 {
+		t.Errorf("Expected the preview to fit the pane, got %d lines", n)
+	}
+	if !strings.Contains(m.imageStatusText(), "half blocks, truecolor") {
+		t.Errorf("Unexpected status %q", m.imageStatusText())
+	}
+
+	tm, _ = m.handleKeyEvent(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m = tm.(model)
+	if m.preview.image.mode != imageQuadrants || !strings.Contains(m.renderPreview(20), "quadrant blocks") {
+		t.Error("Expected b to switch to quadrant blocks")
+	}
+
+	// Narrow panes stack the metadata above the image
+	m.width = 40
+	lines := strings.Split(m.renderPreview(20), "\n")
+	if len(lines) > 20 || !strings.Contains(lines[0], "JPEG image") {
+		t.Errorf("Unexpected narrow layout (%d lines): %q", len(lines), lines[0])
+	}
+}
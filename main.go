@@ -18,6 +18,7 @@ var (
 	selectFile      string // File to select (basename)
 	autoPreview     bool   // Auto-open preview pane
 	previewFile     string // Standalone preview file path (viewer-only mode)
+	followPreview   bool   // Start the standalone preview in follow mode (--follow)
 )
 
 func main() {
@@ -29,12 +30,21 @@ func main() {
 		case arg == "--version" || arg == "-v":
 			fmt.Printf("TFE (Terminal File Explorer) v%s\n", Version)
 			os.Exit(0)
+		case arg == "--repair-trash":
+			recovered, dropped, err := repairTrashMetadata()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: trash repair failed: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Trash index rebuilt: %d recovered, %d stale entries dropped\n", recovered, dropped)
+			os.Exit(0)
 		case arg == "--light":
 			forceLightTheme = true
 		case arg == "--dark":
 			forceLightTheme = false // Explicit dark mode (default)
-		case arg == "--preview" || arg == "-p":
+		case arg == "--preview" || arg == "-p" || arg == "--follow":
 			autoPreview = true
+			followPreview = followPreview || arg == "--follow"
 			// Check if next arg is a file path (not a flag) for standalone viewer mode
 			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
 				targetPath := args[i+1]
@@ -73,8 +83,10 @@ func main() {
 			fmt.Println("Options:")
 			fmt.Println("  --preview    Auto-open preview pane (useful with file path)")
 			fmt.Println("               --preview <file>  Standalone file viewer mode")
+			fmt.Println("  --follow <file>  Standalone viewer following the file as it grows (like tail -f)")
 			fmt.Println("  --light      Use light theme (for light terminal backgrounds)")
 			fmt.Println("  --dark       Use dark theme (default)")
+			fmt.Println("  --repair-trash  Rebuild the trash index from ~/.config/tfe/trash")
 			fmt.Println("  --version    Show version information")
 			fmt.Println("  --help       Show this help message")
 			fmt.Println()
@@ -84,6 +96,7 @@ func main() {
 			fmt.Println("  tfe ~/projects/main.go     Open ~/projects with main.go selected")
 			fmt.Println("  tfe --preview src/app.ts   Standalone file viewer (for tmux splits)")
 			fmt.Println("  tfe -p README.md           Standalone file viewer (short form)")
+			fmt.Println("  tfe --follow app.log       Watch a log or agent session grow")
 			os.Exit(0)
 		case !strings.HasPrefix(arg, "-"):
 			// Non-flag argument is the path
@@ -122,6 +135,9 @@ func main() {
 		}
 	}
 
+	// Ask the terminal which image protocols it supports (before Bubbletea reads the input)
+	probeTerminalGraphics()
+
 	// Ensure terminal cleanup on exit (defer runs even if panic/interrupt)
 	defer cleanupTerminal()
 
@@ -133,6 +149,7 @@ func main() {
 		initialModel(),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
+		tea.WithReportFocus(), // Pauses GIF playback while the terminal is in the background
 	)
 
 	// Handle signals in a goroutine
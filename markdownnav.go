@@ -0,0 +1,684 @@
+package main
+
+// Module: markdownnav.go
+// Purpose: Navigating rendered markdown previews
+// Responsibilities:
+// - Extracting headings and links (relative, #anchor, URLs, Obsidian [[wiki-links]]) from the source
+// - Outline and link panels with type-to-filter, jumping to headings in the rendered output
+// - Following links to other files and headings, with a back stack
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	markdownBackLimit   = 50    // Entries kept on the back stack
+	markdownVaultLimit  = 50000 // Files searched when resolving a wiki-link
+	markdownMatchPrefix = 24    // Heading characters compared against rendered lines
+)
+
+var (
+	mdATXHeadingRe = regexp.MustCompile(`^ {0,3}(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	mdSetextRe     = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	mdFenceRe      = regexp.MustCompile("^ {0,3}(```|~~~)")
+	mdCodeSpanRe   = regexp.MustCompile("`+[^`]*`+")
+	// [text](target "title") - one level of nested brackets for image links like [![badge](img)](url)
+	mdLinkRe  = regexp.MustCompile(`(!?)\[((?:[^\[\]]|\[[^\]]*\])*)\]\(\s*(<[^>]*>|[^)\s]+)(?:\s+["'(][^)]*)?\s*\)`)
+	mdWikiRe  = regexp.MustCompile(`(!?)\[\[([^\]|]+)(?:\|([^\]]*))?\]\]`)
+	mdImageRe = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+)
+
+// markdownPanel is the list shown over a markdown preview
+type markdownPanel int
+
+const (
+	mdPanelNone markdownPanel = iota
+	mdPanelOutline
+	mdPanelLinks
+)
+
+// markdownHeading is a heading in the markdown source
+type markdownHeading struct {
+	level   int
+	text    string // Inline markdown removed
+	slug    string // GitHub-style anchor, unique within the file
+	srcLine int
+}
+
+// markdownLink is a link in the markdown source
+type markdownLink struct {
+	text    string
+	target  string // URL, relative path and/or #anchor, or wiki-link target
+	wiki    bool   // [[Note]] / [[Note#Heading]]
+	srcLine int
+}
+
+// markdownNavState holds the headings and links of a markdown preview and the open panel
+type markdownNavState struct {
+	headings []markdownHeading
+	links    []markdownLink
+	srcLines int
+
+	panel  markdownPanel
+	filter string
+	items  []int // Filtered heading/link indexes
+	cursor int   // Index into items
+	offset int   // First visible item
+}
+
+// markdownLocation is a back stack entry
+type markdownLocation struct {
+	path      string
+	scrollPos int
+}
+
+// parseMarkdownNav extracts headings and links, skipping front matter and code blocks
+func parseMarkdownNav(source []string) *markdownNavState {
+	nav := &markdownNavState{srcLines: len(source)}
+	lines := make([]string, len(source))
+	for i, line := range source {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	slugs := make(map[string]int)
+	addHeading := func(level int, text string, line int) {
+		text = stripInlineMarkdown(text)
+		slug := markdownSlug(text)
+		if n := slugs[slug]; n > 0 {
+			slugs[slug] = n + 1
+			slug = fmt.Sprintf("%s-%d", slug, n)
+		} else {
+			slugs[slug] = 1
+		}
+		nav.headings = append(nav.headings, markdownHeading{level: level, text: text, slug: slug, srcLine: line})
+	}
+
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if t := strings.TrimSpace(lines[i]); t == "---" || t == "..." {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	var fence string
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		if m := mdFenceRe.FindStringSubmatch(line); m != nil {
+			if fence == "" {
+				fence = m[1]
+			} else if m[1] == fence {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+
+		if m := mdATXHeadingRe.FindStringSubmatch(line); m != nil {
+			addHeading(len(m[1]), m[2], i)
+		} else if m := mdSetextRe.FindStringSubmatch(line); m != nil && i > start && isSetextText(lines[i-1]) {
+			level := 1
+			if m[1][0] == '-' {
+				level = 2
+			}
+			addHeading(level, strings.TrimSpace(lines[i-1]), i-1)
+		}
+
+		text := mdCodeSpanRe.ReplaceAllString(line, "")
+		for _, m := range mdLinkRe.FindAllStringSubmatch(text, -1) {
+			target := strings.Trim(m[3], "<>")
+			if m[1] == "!" || target == "" {
+				continue // Images aren't followed
+			}
+			label := stripInlineMarkdown(mdImageRe.ReplaceAllString(m[2], "$1"))
+			nav.links = append(nav.links, markdownLink{text: label, target: target, srcLine: i})
+		}
+		for _, m := range mdWikiRe.FindAllStringSubmatch(text, -1) {
+			if m[1] == "!" {
+				continue // Embeds
+			}
+			label := m[3]
+			if label == "" {
+				label = m[2]
+			}
+			nav.links = append(nav.links, markdownLink{text: label, target: strings.TrimSpace(m[2]), wiki: true, srcLine: i})
+		}
+	}
+	return nav
+}
+
+// isSetextText reports whether a line can be the text of a setext heading (underlined with === or ---)
+func isSetextText(line string) bool {
+	t := strings.TrimSpace(line)
+	if t == "" || strings.HasPrefix(line, "    ") || mdATXHeadingRe.MatchString(line) || mdSetextRe.MatchString(line) {
+		return false
+	}
+	// "---" under a list item or table row is a thematic break
+	return !strings.HasPrefix(t, "- ") && !strings.HasPrefix(t, "* ") && !strings.HasPrefix(t, "|") && !strings.HasPrefix(t, ">")
+}
+
+// stripInlineMarkdown removes links, code and emphasis markers from heading/link text
+func stripInlineMarkdown(s string) string {
+	s = mdLinkRe.ReplaceAllString(s, "$2")
+	s = mdWikiRe.ReplaceAllStringFunc(s, func(w string) string {
+		m := mdWikiRe.FindStringSubmatch(w)
+		if m[3] != "" {
+			return m[3]
+		}
+		return m[2]
+	})
+	s = strings.NewReplacer("`", "", "**", "", "__", "", "~~", "").Replace(s)
+	return strings.TrimSpace(strings.Trim(s, "*"))
+}
+
+// markdownSlug returns the GitHub anchor for a heading: lowercase, punctuation dropped, spaces to dashes
+func markdownSlug(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// markdownMatchKey reduces text to lowercase letters and digits for comparing against rendered lines
+func markdownMatchKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// markdownDisplayLines returns the preview lines as shown (Glamour output or wrapped text)
+func (m model) markdownDisplayLines() []string {
+	if m.preview.isMarkdown && m.preview.cachedRenderedContent != "" {
+		return strings.Split(strings.TrimRight(m.preview.cachedRenderedContent, "\n"), "\n")
+	}
+	if m.preview.cacheValid && len(m.preview.cachedWrappedLines) > 0 {
+		return m.preview.cachedWrappedLines
+	}
+	return m.preview.content
+}
+
+// headingDisplayLines finds each heading's line in the displayed output.
+// Headings are matched in order; unmatched ones are placed proportionally to their source line.
+func (n *markdownNavState) headingDisplayLines(display []string) []int {
+	result := make([]int, len(n.headings))
+	next := 0
+	for i, h := range n.headings {
+		key := markdownMatchKey(h.text)
+		if len([]rune(key)) > markdownMatchPrefix {
+			key = string([]rune(key)[:markdownMatchPrefix])
+		}
+		result[i] = -1
+		if key != "" {
+			for j := next; j < len(display); j++ {
+				// Long headings wrap: their first line is a prefix of the key
+				text, _ := ansiPlainText(display[j])
+				lineKey := markdownMatchKey(text)
+				if strings.HasPrefix(lineKey, key) || (len(lineKey) >= 8 && strings.HasPrefix(key, lineKey)) {
+					result[i] = j
+					next = j + 1
+					break
+				}
+			}
+		}
+		if result[i] < 0 {
+			result[i] = max(next, h.srcLine*len(display)/max(1, n.srcLines))
+		}
+	}
+	return result
+}
+
+// currentHeading returns the heading whose section contains display line pos (-1 before the first)
+func currentHeading(lines []int, pos int) int {
+	current := -1
+	for i, line := range lines {
+		if line > pos {
+			break
+		}
+		current = i
+	}
+	return current
+}
+
+// markdownPanelOpen reports whether the outline/link panel is shown (full-screen and standalone preview only)
+func (m model) markdownPanelOpen() bool {
+	return m.preview.markdown != nil && m.preview.markdown.panel != mdPanelNone &&
+		(m.viewMode == viewFullPreview || m.previewOnly)
+}
+
+// openMarkdownPanel shows the outline or link list, with the cursor at the current position
+func (m *model) openMarkdownPanel(panel markdownPanel) {
+	nav := m.preview.markdown
+	if panel == mdPanelOutline && len(nav.headings) == 0 {
+		m.setStatusMessage("No headings in this document", false)
+		return
+	}
+	if panel == mdPanelLinks && len(nav.links) == 0 {
+		m.setStatusMessage("No links in this document", false)
+		return
+	}
+	nav.panel = panel
+	nav.filter = ""
+	nav.applyFilter()
+
+	// Start at the section being read
+	display := m.markdownDisplayLines()
+	lines := nav.headingDisplayLines(display)
+	section := currentHeading(lines, m.preview.scrollPos)
+	switch panel {
+	case mdPanelOutline:
+		nav.cursor = max(0, section)
+	case mdPanelLinks:
+		srcLine := m.preview.scrollPos * nav.srcLines / max(1, len(display))
+		if section >= 0 {
+			srcLine = max(srcLine, nav.headings[section].srcLine)
+		}
+		nav.cursor = len(nav.items) - 1
+		for i, link := range nav.links {
+			if link.srcLine >= srcLine {
+				nav.cursor = i
+				break
+			}
+		}
+	}
+	nav.offset = max(0, nav.cursor-m.getPreviewVisibleLines()/2)
+}
+
+// applyFilter rebuilds the item list for the panel's filter (case-insensitive substring)
+func (n *markdownNavState) applyFilter() {
+	n.items = n.items[:0]
+	filter := strings.ToLower(n.filter)
+	count := len(n.headings)
+	if n.panel == mdPanelLinks {
+		count = len(n.links)
+	}
+	for i := 0; i < count; i++ {
+		text := ""
+		if n.panel == mdPanelLinks {
+			text = n.links[i].text + " " + n.links[i].target
+		} else {
+			text = n.headings[i].text
+		}
+		if strings.Contains(strings.ToLower(text), filter) {
+			n.items = append(n.items, i)
+		}
+	}
+	n.cursor = max(0, min(n.cursor, len(n.items)-1))
+}
+
+// moveMarkdownPanelCursor moves the panel selection, keeping it visible
+func (m *model) moveMarkdownPanelCursor(delta int) {
+	nav := m.preview.markdown
+	if len(nav.items) == 0 {
+		return
+	}
+	nav.cursor = max(0, min(len(nav.items)-1, nav.cursor+delta))
+	visible := m.getPreviewVisibleLines() - 2 // Title and blank line
+	if nav.cursor < nav.offset {
+		nav.offset = nav.cursor
+	} else if nav.cursor >= nav.offset+visible {
+		nav.offset = nav.cursor - visible + 1
+	}
+}
+
+// selectMarkdownPanelItem jumps to the selected heading or follows the selected link
+func (m *model) selectMarkdownPanelItem() tea.Cmd {
+	nav := m.preview.markdown
+	if len(nav.items) == 0 {
+		return nil
+	}
+	index := nav.items[nav.cursor]
+	panel := nav.panel
+	nav.panel = mdPanelNone
+	nav.filter = ""
+	if panel == mdPanelLinks {
+		return m.followMarkdownLink(nav.links[index])
+	}
+	m.pushMarkdownBack()
+	m.scrollToMarkdownHeading(index)
+	return nil
+}
+
+// scrollToMarkdownHeading scrolls the preview so the heading is the top line
+func (m *model) scrollToMarkdownHeading(index int) {
+	display := m.markdownDisplayLines()
+	lines := m.preview.markdown.headingDisplayLines(display)
+	m.setMarkdownScroll(lines[index], len(display))
+}
+
+// setMarkdownScroll scrolls to a display line, stopping where the last page starts
+func (m *model) setMarkdownScroll(line, total int) {
+	m.preview.scrollPos = max(0, min(line, total-m.getPreviewVisibleLines()))
+}
+
+// jumpMarkdownHeading scrolls to the next (delta > 0) or previous heading
+func (m *model) jumpMarkdownHeading(delta int) {
+	nav := m.preview.markdown
+	if len(nav.headings) == 0 {
+		return
+	}
+	display := m.markdownDisplayLines()
+	lines := nav.headingDisplayLines(display)
+	pos := m.preview.scrollPos
+	if delta > 0 {
+		for _, line := range lines {
+			if line > pos {
+				m.setMarkdownScroll(line, len(display))
+				return
+			}
+		}
+		return
+	}
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i] < pos {
+			m.setMarkdownScroll(lines[i], len(display))
+			return
+		}
+	}
+	m.preview.scrollPos = 0
+}
+
+// pushMarkdownBack remembers the current document and position for Backspace
+func (m *model) pushMarkdownBack() {
+	m.markdownBack = append(m.markdownBack, markdownLocation{path: m.preview.filePath, scrollPos: m.preview.scrollPos})
+	if len(m.markdownBack) > markdownBackLimit {
+		m.markdownBack = m.markdownBack[len(m.markdownBack)-markdownBackLimit:]
+	}
+}
+
+// markdownGoBack returns to the document and position before the last followed link
+func (m *model) markdownGoBack() bool {
+	if len(m.markdownBack) == 0 {
+		return false
+	}
+	loc := m.markdownBack[len(m.markdownBack)-1]
+	m.markdownBack = m.markdownBack[:len(m.markdownBack)-1]
+	if loc.path != m.preview.filePath {
+		m.loadPreview(loc.path)
+		m.populatePreviewCache()
+	}
+	m.preview.scrollPos = loc.scrollPos
+	m.setStatusMessage("← "+filepath.Base(loc.path), false)
+	return true
+}
+
+// followMarkdownLink opens a link: URLs in the browser, files in the preview (jumping to the #anchor)
+func (m *model) followMarkdownLink(link markdownLink) tea.Cmd {
+	var path, anchor string
+	if link.wiki {
+		name := link.target
+		if i := strings.Index(name, "#"); i >= 0 {
+			name, anchor = name[:i], markdownSlug(strings.TrimPrefix(name[i+1:], "^"))
+		}
+		if name != "" {
+			path = resolveWikiLink(m.preview.filePath, name)
+			if path == "" {
+				m.setStatusMessage("Note not found: "+name, true)
+				return nil
+			}
+		}
+	} else {
+		target := link.target
+		if u, err := url.Parse(target); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+			if u.Scheme == "file" {
+				target = u.Path
+			} else {
+				m.setStatusMessage("Opening "+target, false)
+				return openInBrowser(target)
+			}
+		}
+		if i := strings.Index(target, "#"); i >= 0 {
+			target, anchor = target[:i], strings.ToLower(target[i+1:])
+		}
+		if target != "" {
+			if unescaped, err := url.PathUnescape(target); err == nil {
+				target = unescaped
+			}
+			path = m.resolveMarkdownPath(target)
+		}
+	}
+
+	if path == "" {
+		// Anchor in this document
+		return m.jumpToMarkdownAnchor(anchor, true)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		m.setStatusMessage("Link target not found: "+link.target, true)
+		return nil
+	}
+	if info.IsDir() {
+		m.setStatusMessage("Link points to a folder: "+path, true)
+		return nil
+	}
+
+	m.pushMarkdownBack()
+	m.loadPreview(path)
+	m.populatePreviewCache()
+	if anchor != "" && m.preview.markdown != nil {
+		m.jumpToMarkdownAnchor(anchor, false)
+	}
+	m.setStatusMessage("→ "+filepath.Base(path)+" (Backspace: back)", false)
+	return nil
+}
+
+// jumpToMarkdownAnchor scrolls to the heading with the given slug
+func (m *model) jumpToMarkdownAnchor(anchor string, pushBack bool) tea.Cmd {
+	nav := m.preview.markdown
+	if nav == nil || anchor == "" {
+		return nil
+	}
+	for i, h := range nav.headings {
+		if h.slug == anchor || markdownSlug(h.text) == anchor {
+			if pushBack {
+				m.pushMarkdownBack()
+			}
+			m.scrollToMarkdownHeading(i)
+			return nil
+		}
+	}
+	m.setStatusMessage("Heading not found: #"+anchor, true)
+	return nil
+}
+
+// resolveMarkdownPath resolves a link path relative to the previewed file.
+// Paths starting with / are tried from the repository root first.
+func (m *model) resolveMarkdownPath(target string) string {
+	dir := filepath.Dir(m.preview.filePath)
+	if strings.HasPrefix(target, "/") {
+		if root := m.findGitRoot(dir); root != "" {
+			if candidate := filepath.Join(root, target); fileExists(candidate) {
+				return candidate
+			}
+		}
+		return target
+	}
+	return filepath.Join(dir, filepath.FromSlash(target))
+}
+
+// resolveWikiLink finds the note for [[name]] the way Obsidian does: inside the vault containing
+// the file, by file name (shortest path wins), or by path when the name contains a slash.
+// Outside a vault, the note is looked up next to the file.
+func resolveWikiLink(fromFile, name string) string {
+	name = filepath.FromSlash(strings.TrimSpace(name))
+	withExt := name
+	if filepath.Ext(name) == "" {
+		withExt = name + ".md"
+	}
+
+	dir := filepath.Dir(fromFile)
+	vault := ""
+	for d := dir; ; d = filepath.Dir(d) {
+		if isObsidianVault(d) {
+			vault = d
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if vault == "" {
+		if candidate := filepath.Join(dir, withExt); fileExists(candidate) {
+			return candidate
+		}
+		return ""
+	}
+	if candidate := filepath.Join(vault, withExt); strings.ContainsRune(name, filepath.Separator) && fileExists(candidate) {
+		return candidate
+	}
+
+	var matches []string
+	want := strings.ToLower(string(filepath.Separator) + withExt)
+	seen := 0
+	filepath.WalkDir(vault, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != vault && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if seen++; seen > markdownVaultLimit {
+			return filepath.SkipAll
+		}
+		if strings.HasSuffix(strings.ToLower(path), want) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if len(matches) == 0 {
+		return ""
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		di, dj := strings.Count(matches[i], string(filepath.Separator)), strings.Count(matches[j], string(filepath.Separator))
+		if di != dj {
+			return di < dj
+		}
+		return matches[i] < matches[j]
+	})
+	return matches[0]
+}
+
+// fileExists reports whether path exists and is a regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// markdownStatusText returns the info line text for the outline/link panel
+func (m model) markdownStatusText() string {
+	nav := m.preview.markdown
+	what := "headings"
+	if nav.panel == mdPanelLinks {
+		what = "links"
+	}
+	text := fmt.Sprintf("%s %d/%d", what, min(nav.cursor+1, len(nav.items)), len(nav.items))
+	if nav.filter != "" {
+		text += fmt.Sprintf(" matching %q", nav.filter)
+	}
+	return text
+}
+
+// renderMarkdownPanel renders the outline or link list in place of the document
+func (m model) renderMarkdownPanel(maxVisible int) string {
+	nav := m.preview.markdown
+	width := m.previewBoxWidth() - 1
+
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(uiMutedText())
+	subtleStyle := lipgloss.NewStyle().Foreground(uiSubtleText())
+	levelStyles := []lipgloss.Style{
+		lipgloss.NewStyle().Bold(true).Foreground(currentTheme.Folder.adaptiveColor()),
+		lipgloss.NewStyle().Bold(true),
+		lipgloss.NewStyle(),
+	}
+	cursorStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.SelectionFg.adaptiveColor()).
+		Background(currentTheme.SelectionBg.adaptiveColor())
+
+	title := "Outline"
+	if nav.panel == mdPanelLinks {
+		title = "Links"
+	}
+	header := titleStyle.Render(title) + subtleStyle.Render(fmt.Sprintf("  (%d)  Tab: switch • Enter: go • Esc: close", len(nav.items)))
+	if nav.filter != "" {
+		header = titleStyle.Render(title) + "  / " + nav.filter + subtleStyle.Render("▏")
+	}
+
+	targetLines := maxVisible
+	if m.viewMode == viewDualPane {
+		targetLines = maxVisible - 1
+	}
+	lines := []string{header, ""}
+	if len(nav.items) == 0 {
+		lines = append(lines, mutedStyle.Render("  (no matches)"))
+	}
+	for i := nav.offset; i < len(nav.items) && len(lines) < targetLines; i++ {
+		index := nav.items[i]
+		var text, detail string
+		style := levelStyles[len(levelStyles)-1]
+		if nav.panel == mdPanelLinks {
+			link := nav.links[index]
+			icon := "→"
+			switch {
+			case link.wiki:
+				icon = "◆"
+			case strings.HasPrefix(link.target, "#"):
+				icon = "#"
+			case strings.Contains(link.target, "://") || strings.HasPrefix(link.target, "mailto:"):
+				icon = "↗"
+			}
+			text = fmt.Sprintf("%s %s", icon, link.text)
+			detail = "  " + link.target
+		} else {
+			h := nav.headings[index]
+			text = strings.Repeat("  ", h.level-1) + h.text
+			style = levelStyles[min(h.level, len(levelStyles))-1]
+		}
+
+		if i == nav.cursor {
+			line := truncateToWidth("▸ "+text+detail, width)
+			lines = append(lines, cursorStyle.Render(line+strings.Repeat(" ", max(0, width-visualWidth(line)))))
+			continue
+		}
+		line := "  " + style.Render(text)
+		if detail != "" {
+			line += mutedStyle.Render(detail)
+		}
+		lines = append(lines, truncateToWidth(line, width))
+	}
+
+	var s strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(" " + line + "\033[0m")
+	}
+	return s.String()
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseMarkdownNav(t *testing.T) {
+	source := strings.Split(`---
+title: "# not a heading"
+---
+# Getting **Started**
+
+See [the guide](docs/guide.md#setup "Guide") and [usage](#usage).
+[![build](https://ci.example/badge.svg)](https://ci.example/)
+![diagram](diagram.png) and `+"`[code](not-a-link.md)`"+`
+
+`+"```sh"+`
+# not a heading either
+[nope](nope.md)
+`+"```"+`
+
+Usage
+-----
+Linked from [[Daily Notes/Today|today]], [[Ideas#Later]] and ![[embedded.png]].
+
+## Usage
+## `+"`tfe --help`"+` Options ##`, "\n")
+
+	nav := parseMarkdownNav(source)
+	var headings []string
+	for _, h := range nav.headings {
+		headings = append(headings, strings.Repeat("#", h.level)+" "+h.text+" ("+h.slug+")")
+	}
+	want := []string{
+		"# Getting Started (getting-started)",
+		"## Usage (usage)",
+		"## Usage (usage-1)",
+		"## tfe --help Options (tfe---help-options)",
+	}
+	if strings.Join(headings, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected headings:\n%s", strings.Join(headings, "\n"))
+	}
+
+	var links []string
+	for _, l := range nav.links {
+		kind := ""
+		if l.wiki {
+			kind = "wiki:"
+		}
+		links = append(links, kind+l.text+" -> "+l.target)
+	}
+	wantLinks := []string{
+		"the guide -> docs/guide.md#setup",
+		"usage -> #usage",
+		"build -> https://ci.example/",
+		"wiki:today -> Daily Notes/Today",
+		"wiki:Ideas#Later -> Ideas#Later",
+	}
+	if strings.Join(links, "\n") != strings.Join(wantLinks, "\n") {
+		t.Errorf("Unexpected links:\n%s", strings.Join(links, "\n"))
+	}
+}
+
+func TestHeadingDisplayLines(t *testing.T) {
+	nav := parseMarkdownNav([]string{"# Intro", "text", "## A very long heading that wraps over two lines", "text", "## Missing", "text"})
+	display := []string{
+		"",
+		"\033[1m  Intro  \033[0m",
+		"  text",
+		"## A very long heading that",
+		"wraps over two lines",
+		"  text",
+		"  text",
+		"  text",
+	}
+	lines := nav.headingDisplayLines(display)
+	if lines[0] != 1 || lines[1] != 3 {
+		t.Errorf("Expected headings on lines 1 and 3, got %v", lines)
+	}
+	// Not found: placed proportionally after the previous match
+	if lines[2] < 4 || lines[2] >= len(display) {
+		t.Errorf("Expected an estimate for the missing heading, got %d", lines[2])
+	}
+	if currentHeading(lines, 2) != 0 || currentHeading(lines, 0) != -1 {
+		t.Error("Unexpected current heading")
+	}
+}
+
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResolveWikiLink(t *testing.T) {
+	vault := t.TempDir()
+	os.Mkdir(filepath.Join(vault, ".obsidian"), 0755)
+	writeTestFiles(t, vault, map[string]string{
+		"Home.md":                 "[[Ideas]]",
+		"Projects/Ideas.md":       "",
+		"Archive/2023/Ideas.md":   "",
+		"Archive/2023/Retro.md":   "",
+		"Daily Notes/Today.md":    "",
+		"assets/diagram.png":      "",
+		".trash/Retro.md":         "",
+		"Projects/sub/Nested.md":  "",
+		"Projects/sub/Unique.txt": "",
+	})
+	home := filepath.Join(vault, "Home.md")
+	for name, want := range map[string]string{
+		"Ideas":              "Projects/Ideas.md", // Shortest path wins
+		"archive/2023/ideas": "Archive/2023/Ideas.md",
+		"Retro":              "Archive/2023/Retro.md", // Hidden folders are skipped
+		"Daily Notes/Today":  "Daily Notes/Today.md",
+		"diagram.png":        "assets/diagram.png",
+		"Nested":             "Projects/sub/Nested.md",
+		"Missing":            "",
+	} {
+		got := resolveWikiLink(home, name)
+		if want != "" {
+			want = filepath.Join(vault, filepath.FromSlash(want))
+		}
+		if !strings.EqualFold(got, want) {
+			t.Errorf("[[%s]]: expected %q, got %q", name, want, got)
+		}
+	}
+
+	// Outside a vault: next to the file
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"a.md": "", "b.md": ""})
+	if got := resolveWikiLink(filepath.Join(dir, "a.md"), "b"); got != filepath.Join(dir, "b.md") {
+		t.Errorf("Expected the sibling note, got %q", got)
+	}
+}
+
+func TestMarkdownLinkFollowing(t *testing.T) {
+	dir := t.TempDir()
+	filler := strings.Repeat("Some text.\n\n", 30)
+	writeTestFiles(t, dir, map[string]string{
+		"README.md": "# Project\n\n" + filler + "See the [setup guide](docs/guide%20v2.md#setup) or [usage](#usage).\n\n" +
+			filler + "## Usage\n\n" + filler,
+		"docs/guide v2.md": "# Guide\n\n" + filler + "## Setup\n\nRun it.\n\n" + filler,
+	})
+	readme := filepath.Join(dir, "README.md")
+
+	var tm tea.Model = model{height: 40, width: 100, viewMode: viewFullPreview}
+	m := tm.(model)
+	m.loadPreview(readme)
+	m.populatePreviewCache()
+	if m.preview.markdown == nil || len(m.preview.markdown.headings) != 2 {
+		t.Fatal("Expected the markdown outline")
+	}
+	key := func(k string) {
+		t.Helper()
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		}
+		tm, _ = m.handleKeyEvent(msg)
+		m = tm.(model)
+	}
+
+	// Outline: jump to "Usage"
+	key("o")
+	if !m.markdownPanelOpen() || !strings.Contains(m.renderPreview(30), "Outline") {
+		t.Fatal("Expected the outline panel")
+	}
+	key("u")
+	key("s")
+	key("enter")
+	lines := m.preview.markdown.headingDisplayLines(m.markdownDisplayLines())
+	if m.markdownPanelOpen() || m.preview.scrollPos != lines[1] || lines[1] == 0 {
+		t.Errorf("Expected to jump to Usage (line %d), at %d", lines[1], m.preview.scrollPos)
+	}
+	key("[")
+	if m.preview.scrollPos != lines[0] {
+		t.Errorf("Expected [ to go to the previous heading (line %d), at %d", lines[0], m.preview.scrollPos)
+	}
+	key("]")
+	if m.preview.scrollPos != lines[1] {
+		t.Errorf("Expected ] to go to the next heading (line %d), at %d", lines[1], m.preview.scrollPos)
+	}
+	key("backspace") // Back to where the outline was opened
+	if m.preview.scrollPos != 0 || len(m.markdownBack) != 0 {
+		t.Errorf("Expected Backspace to return to the top, at %d", m.preview.scrollPos)
+	}
+
+	// Links: follow the relative link into the guide's Setup section
+	m.preview.scrollPos = 0
+	key("l")
+	key("tab")
+	key("tab")
+	if m.preview.markdown.panel != mdPanelLinks || !strings.Contains(m.renderPreview(30), "docs/guide%20v2.md#setup") {
+		t.Fatal("Expected the link panel")
+	}
+	key("enter")
+	if filepath.Base(m.preview.filePath) != "guide v2.md" {
+		t.Fatalf("Expected the guide to open, got %s", m.preview.filePath)
+	}
+	setupLine := m.preview.markdown.headingDisplayLines(m.markdownDisplayLines())[1]
+	if m.preview.scrollPos != setupLine || setupLine == 0 {
+		t.Errorf("Expected to jump to Setup (line %d), at %d", setupLine, m.preview.scrollPos)
+	}
+
+	key("backspace")
+	if m.preview.filePath != readme || m.preview.scrollPos != 0 || len(m.markdownBack) != 0 {
+		t.Errorf("Expected Backspace to return to the README, got %s:%d", m.preview.filePath, m.preview.scrollPos)
+	}
+	// Nothing left on the back stack: Backspace isn't handled
+	if handled, _ := m.handleMarkdownNavKey(tea.KeyMsg{Type: tea.KeyBackspace}); handled {
+		t.Error("Expected an empty back stack")
+	}
+}
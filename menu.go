@@ -80,6 +80,8 @@ func (m model) getMenus() map[string]Menu {
 				{Label: "📝 Prompts", Action: "go-prompts", Shortcut: "F11"},
 				{Label: "🔀 Git Repos", Action: "go-git-repos"},
 				{Label: "🗑  Trash", Action: "go-trash", Shortcut: "F12"},
+				{Label: "🧽 Clean Up Trash", Action: "trash-cleanup"},
+				{Label: "🔧 Repair Trash Index", Action: "trash-repair"},
 				{IsSeparator: true},
 				{Label: "📂 Quick CD", Action: "go-quickcd", Shortcut: "Ctrl+D"},
 				{Label: "🎯 Fuzzy Search", Action: "go-fuzzy", Shortcut: "Ctrl+P"},
@@ -90,6 +92,12 @@ func (m model) getMenus() map[string]Menu {
 			Items: []MenuItem{
 				{Label: "⚡ Changes Mode", Action: "git-changes-mode", Shortcut: "Ctrl+G", IsCheckable: true, IsChecked: m.showChangesOnly},
 				{Label: "📋 Toggle Diff", Action: "git-toggle-diff", Shortcut: "d", IsCheckable: true, IsChecked: m.showDiffPreview},
+				{Label: "⬌ Side-by-Side Diff", Action: "git-toggle-side-by-side", Shortcut: "D", IsCheckable: true, IsChecked: m.diffSideBySide},
+				{Label: "📜 File History", Action: "git-file-history", Shortcut: "L"},
+				{Label: "🕘 Log", Action: "git-log", Shortcut: "L"},
+				{Label: "📝 Commit...", Action: "git-commit", Shortcut: "c"},
+				{Label: "🌿 Branches...", Action: "git-branches", Shortcut: "B"},
+				{Label: "📦 Stashes", Action: "git-stashes", Shortcut: "S"},
 				{IsSeparator: true},
 				{Label: "⬇  Pull", Action: "git-pull"},
 				{Label: "⬆  Push", Action: "git-push"},
@@ -821,12 +829,11 @@ Additional context: {{variable2}}
 		// Delete selected file/folder
 		file := m.getCurrentFile()
 		if file != nil && file.name != ".." {
-			m.dialog = dialogModel{
+			return m, m.openTrashDeleteDialog(*file, dialogModel{
 				dialogType: dialogConfirm,
 				title:      "Move to Trash",
 				message:    fmt.Sprintf("Move '%s' to trash?", file.name),
-			}
-			m.showDialog = true
+			})
 		}
 
 	// View menu
@@ -1018,6 +1025,12 @@ Additional context: {{variable2}}
 	case "go-trash":
 		m.toggleTrash()
 
+	case "trash-cleanup":
+		m.cleanupTrashNow()
+
+	case "trash-repair":
+		m.repairTrashNow()
+
 	case "go-quickcd":
 		// Quick CD: write current directory as CD target and quit
 		m.menuOpen = false
@@ -1060,6 +1073,59 @@ Additional context: {{variable2}}
 			m.setStatusMessage("Toggle diff only works in Changes Mode (Ctrl+G)", false)
 		}
 
+	case "git-toggle-side-by-side":
+		// Diff layout (changes mode diff, history and blame commits)
+		m.toggleDiffSideBySide()
+
+	case "git-file-history":
+		// History of the selected file, opened in the full-screen preview
+		file := m.getCurrentFile()
+		if file == nil || file.isDir {
+			m.setStatusMessage("Select a file to show its history", true)
+			break
+		}
+		m.loadPreview(file.path)
+		m.viewMode = viewFullPreview
+		m.searchMode = false
+		m.calculateLayout()
+		m.populatePreviewCache()
+		m.menuOpen = false
+		m.activeMenu = ""
+		m.selectedMenuItem = -1
+		return m, tea.Batch(tea.ClearScreen, m.openFileHistory(file.path))
+
+	case "git-commit":
+		// Commit composer for the staged changes
+		m.menuOpen = false
+		m.activeMenu = ""
+		m.selectedMenuItem = -1
+		m.openCommitComposer()
+		return m, tea.ClearScreen
+
+	case "git-branches":
+		// Branch manager for the current repository
+		m.menuOpen = false
+		m.activeMenu = ""
+		m.selectedMenuItem = -1
+		m.openBranchManager(m.selectedRepoRoot())
+		return m, tea.ClearScreen
+
+	case "git-log":
+		// Commit log in the full-screen preview
+		m.menuOpen = false
+		m.activeMenu = ""
+		m.selectedMenuItem = -1
+		cmd := m.openGitLog()
+		return m, tea.Batch(cmd, tea.ClearScreen)
+
+	case "git-stashes":
+		// Stash browser in the full-screen preview
+		m.menuOpen = false
+		m.activeMenu = ""
+		m.selectedMenuItem = -1
+		m.openStashBrowser()
+		return m, tea.ClearScreen
+
 	case "git-pull":
 		// Git pull in current directory's git root
 		gitRoot := m.resolveGitRoot()
@@ -174,6 +174,9 @@ func initialModel() model {
 		m.loadPreview(previewFile)
 		m.calculateLayout()
 		m.populatePreviewCache()
+		if followPreview {
+			m.startFollow() // Not followable (binary etc.): just a regular preview
+		}
 		return m
 	}
 
@@ -0,0 +1,804 @@
+package main
+
+// Module: pager.go
+// Purpose: Streaming pager for large text files
+// Responsibilities:
+// - Lazily indexing line offsets with a memory-bounded sparse index, in the background
+// - Reading only the visible window of lines from disk
+// - Jump to line, percentage and end (deferred until indexing gets there)
+// - Streaming search through the file in the background
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	pagerThreshold      = 1024 * 1024      // Text files larger than this open in the pager (was the 1MB preview cutoff)
+	pagerReadChunk      = 64 * 1024        // Read buffer size for indexing and line reads
+	pagerIndexBudget    = 32 * 1024 * 1024 // Bytes indexed per background step (one progress message each)
+	pagerOpenBudget     = 1024 * 1024      // Bytes indexed when opening, so the first screen shows at once
+	pagerInitialStride  = 256              // Lines between index checkpoints (doubles as the index fills up)
+	pagerMaxCheckpoints = 1 << 16          // Index size cap - the stride doubles instead of the index growing
+	pagerMaxLineBytes   = 4096             // Longer lines are clipped for display and search
+	pagerWindowPages    = 3                // Pages of lines cached around the viewport
+)
+
+// pagerState is the streaming pager for one large file.
+// Only a sparse line index and a small window of lines are held in memory,
+// so memory use stays bounded regardless of file size.
+type pagerState struct {
+	path string
+	size int64
+
+	// Sparse line index: checkpoints[i] is the byte offset of line i*stride
+	stride      int
+	checkpoints []int64
+	lines       int   // Line starts found so far
+	scanned     int64 // Bytes indexed so far
+	indexed     bool  // Whole file indexed (lines is exact)
+	indexErr    error // Indexing stopped on a read error
+	indexing    bool  // A background index step is in flight
+
+	// Known line position beyond the indexed range (from a search hit)
+	anchorLine   int
+	anchorOffset int64
+
+	// Deferred jump, applied once indexing reaches it
+	pendingLine   int   // -1 = none
+	pendingOffset int64 // -1 = none (percentage jumps)
+	pendingEnd    bool
+
+	// Window of lines read around the viewport
+	windowStart int
+	window      []string
+	windowEOF   bool
+
+	// Background search
+	searchGen   atomic.Int64 // Bumped to cancel an in-flight search
+	searching   bool
+	searchQuery string               // Query of the last hit (next search continues after it)
+	searchOpts  previewSearchOptions // Options of the last hit
+	searchLine  int                  // Line of the last hit (-1 = none)
+}
+
+// pagerJump is a parsed jump target: a line, a percentage of the file, or the end
+type pagerJump struct {
+	line    int // 0-based line (-1 = unset)
+	percent int // 0-100 (-1 = unset)
+	end     bool
+}
+
+// pagerSearchMsg reports the result of a streaming pager search
+type pagerSearchMsg struct {
+	path    string
+	gen     int64
+	query   string
+	opts    previewSearchOptions
+	line    int
+	offset  int64
+	found   bool
+	wrapped bool
+	err     error
+}
+
+// pagerIndexMsg reports a background index step: the line starts found in
+// [from, scanned), of which marks are the ones that may become checkpoints
+type pagerIndexMsg struct {
+	pager   *pagerState
+	from    int64
+	size    int64 // p.size when the step started
+	lines   int   // p.lines when the step started
+	added   int   // Line starts found
+	marks   []pagerMark
+	scanned int64
+	eof     bool
+	err     error
+}
+
+// pagerMark is a line start whose line number is a multiple of the stride
+type pagerMark struct {
+	line   int
+	offset int64
+}
+
+// newPagerState opens path in the pager and indexes the first chunk so the
+// first screen can be shown immediately. The rest is indexed in the background.
+func newPagerState(path string, size int64) (*pagerState, error) {
+	p := &pagerState{
+		path:          path,
+		size:          size,
+		stride:        pagerInitialStride,
+		checkpoints:   []int64{0},
+		lines:         1,
+		anchorLine:    -1,
+		pendingLine:   -1,
+		pendingOffset: -1,
+		searchLine:    -1,
+	}
+	if size == 0 {
+		p.lines = 0
+		p.indexed = true
+	}
+	if err := p.indexStep(pagerOpenBudget); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// indexStep scans up to budget more bytes for line starts (synchronously)
+func (p *pagerState) indexStep(budget int64) error {
+	if p.indexed || p.indexErr != nil {
+		return p.indexErr
+	}
+	scanned, eof, err := scanLineStarts(p.path, p.scanned, p.size, budget, p.addLine)
+	p.scanned = scanned
+	if err != nil {
+		p.indexErr = err
+		return err
+	}
+	p.indexed = eof
+	return nil
+}
+
+// scanLineStarts calls fn with the offset of every line start in [from, size),
+// reading at most budget bytes. Returns how far it got and whether that is the end.
+func scanLineStarts(path string, from, size, budget int64, fn func(int64)) (int64, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return from, false, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(from, io.SeekStart); err != nil {
+		return from, false, err
+	}
+
+	buf := make([]byte, pagerReadChunk)
+	scanned := from
+	for scanned-from < budget {
+		n, err := f.Read(buf)
+		// Only index up to the size seen when the pager opened
+		if remaining := size - scanned; int64(n) > remaining {
+			n = int(remaining)
+		}
+
+		chunk := buf[:n]
+		for pos := 0; ; {
+			i := bytes.IndexByte(chunk[pos:], '\n')
+			if i < 0 {
+				break
+			}
+			pos += i + 1
+			if next := scanned + int64(pos); next < size {
+				fn(next)
+			}
+		}
+		scanned += int64(n)
+
+		if scanned >= size || err == io.EOF {
+			return scanned, true, nil
+		} else if err != nil {
+			return scanned, false, err
+		}
+	}
+	return scanned, false, nil
+}
+
+// pagerIndexCmd indexes the next chunk of the file off the UI goroutine.
+// The state isn't touched here: the result is merged by applyPagerIndexMsg.
+func pagerIndexCmd(p *pagerState) tea.Cmd {
+	p.indexing = true
+	path, from, size, lines, stride := p.path, p.scanned, p.size, p.lines, p.stride
+	return func() tea.Msg {
+		msg := pagerIndexMsg{pager: p, from: from, size: size, lines: lines}
+		msg.scanned, msg.eof, msg.err = scanLineStarts(path, from, size, pagerIndexBudget, func(offset int64) {
+			if line := lines + msg.added; line%stride == 0 {
+				msg.marks = append(msg.marks, pagerMark{line: line, offset: offset})
+			}
+			msg.added++
+		})
+		return msg
+	}
+}
+
+// mergeIndex adds a background index step's line starts. Returns false when the
+// state moved on while it ran (follow mode growth), so the step must be redone.
+func (p *pagerState) mergeIndex(msg pagerIndexMsg) bool {
+	if p.scanned != msg.from || p.lines != msg.lines {
+		return false
+	}
+	// The stride can only have doubled since the step started, so the marks
+	// cover every checkpoint
+	for _, mark := range msg.marks {
+		p.lines = mark.line
+		p.addLine(mark.offset)
+	}
+	p.lines = msg.lines + msg.added
+	p.scanned = msg.scanned
+	if msg.err != nil {
+		p.indexErr = msg.err
+	} else {
+		// Reaching the old size isn't the end if the file grew meanwhile
+		p.indexed = msg.eof && (msg.scanned >= p.size || msg.scanned < msg.size)
+	}
+	return true
+}
+
+// addLine records the start offset of the next line
+func (p *pagerState) addLine(offset int64) {
+	if p.lines%p.stride == 0 {
+		p.checkpoints = append(p.checkpoints, offset)
+		if len(p.checkpoints) > pagerMaxCheckpoints {
+			// Keep every other checkpoint and double the stride
+			kept := p.checkpoints[:0]
+			for i := 0; i < len(p.checkpoints); i += 2 {
+				kept = append(kept, p.checkpoints[i])
+			}
+			p.checkpoints = kept
+			p.stride *= 2
+		}
+	}
+	p.lines++
+}
+
+// grow extends the pager to a file that was appended to (follow mode).
+// Indexing resumes from where it stopped on the next ticks.
+func (p *pagerState) grow(size int64) {
+	if size <= p.size {
+		return
+	}
+	if p.indexed {
+		if p.size == 0 {
+			p.lines = 1
+		} else if last, err := readFileRange(p.path, p.size-1, p.size); err == nil && len(last) == 1 && last[0] == '\n' {
+			// The old end was a newline, so a new line starts there
+			p.addLine(p.size)
+		}
+	}
+	p.size = size
+	p.indexed = false
+	p.indexErr = nil
+	p.window = nil // The cached window may end at the old end of file
+}
+
+// lineCount returns the number of lines known so far (exact once indexed)
+func (p *pagerState) lineCount() int {
+	if !p.indexed && p.anchorLine >= p.lines {
+		return p.anchorLine + 1
+	}
+	return p.lines
+}
+
+// progress returns how much of the file has been indexed (0-100)
+func (p *pagerState) progress() int {
+	if p.indexed || p.size == 0 {
+		return 100
+	}
+	return int(p.scanned * 100 / p.size)
+}
+
+// nearestLineStart returns the closest known line start at or before line
+func (p *pagerState) nearestLineStart(line int) (int64, int) {
+	ci := line / p.stride
+	if ci >= len(p.checkpoints) {
+		ci = len(p.checkpoints) - 1
+	}
+	baseLine, base := ci*p.stride, p.checkpoints[ci]
+	if p.anchorLine > baseLine && p.anchorLine <= line {
+		baseLine, base = p.anchorLine, p.anchorOffset
+	}
+	return base, baseLine
+}
+
+// openAtLine opens the file positioned at the start of line.
+// The returned offset is the byte offset of that line (file size if past EOF).
+func (p *pagerState) openAtLine(line int) (*os.File, *bufio.Reader, int64, error) {
+	base, baseLine := p.nearestLineStart(line)
+
+	f, err := os.Open(p.path)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if _, err := f.Seek(base, io.SeekStart); err != nil {
+		f.Close()
+		return nil, nil, 0, err
+	}
+
+	r := bufio.NewReaderSize(f, pagerReadChunk)
+	offset := base
+	for skip := line - baseLine; skip > 0; skip-- {
+		_, n, err := readPagerLine(r)
+		offset += int64(n)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			f.Close()
+			return nil, nil, 0, err
+		}
+	}
+	return f, r, offset, nil
+}
+
+// offsetOfLine returns the byte offset where line starts
+func (p *pagerState) offsetOfLine(line int) (int64, error) {
+	f, _, offset, err := p.openAtLine(line)
+	if err != nil {
+		return 0, err
+	}
+	f.Close()
+	return offset, nil
+}
+
+// lineAtOffset returns the line containing byte offset (within the indexed range)
+func (p *pagerState) lineAtOffset(offset int64) int {
+	ci := sort.Search(len(p.checkpoints), func(i int) bool { return p.checkpoints[i] > offset }) - 1
+	if ci < 0 {
+		return 0
+	}
+	line := ci * p.stride
+
+	f, err := os.Open(p.path)
+	if err != nil {
+		return line
+	}
+	defer f.Close()
+
+	start := p.checkpoints[ci]
+	r := io.NewSectionReader(f, start, offset-start)
+	buf := make([]byte, pagerReadChunk)
+	for {
+		n, err := r.Read(buf)
+		line += bytes.Count(buf[:n], []byte{'\n'})
+		if err != nil {
+			break
+		}
+	}
+	if line >= p.lines && p.lines > 0 {
+		line = p.lines - 1
+	}
+	return line
+}
+
+// readLines reads up to n lines starting at start. eof reports that the file ended first.
+func (p *pagerState) readLines(start, n int) ([]string, bool, error) {
+	f, r, _, err := p.openAtLine(start)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	lines := make([]string, 0, n)
+	for len(lines) < n {
+		line, _, err := readPagerLine(r)
+		if err == io.EOF {
+			return lines, true, nil
+		} else if err != nil {
+			return lines, false, err
+		}
+		lines = append(lines, line)
+	}
+	return lines, false, nil
+}
+
+// visibleLines returns up to n lines starting at start, refreshing the
+// window cache from disk only when the viewport leaves it
+func (p *pagerState) visibleLines(start, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	inWindow := p.window != nil && start >= p.windowStart &&
+		(start+n <= p.windowStart+len(p.window) || p.windowEOF)
+	if !inWindow {
+		from := max(0, start-n)
+		lines, eof, err := p.readLines(from, n*pagerWindowPages)
+		if err != nil {
+			return nil, err
+		}
+		p.windowStart, p.window, p.windowEOF = from, lines, eof
+	}
+
+	off := start - p.windowStart
+	if off >= len(p.window) {
+		return nil, nil
+	}
+	return p.window[off:min(off+n, len(p.window))], nil
+}
+
+// readPagerLine reads the next line, clipped to pagerMaxLineBytes.
+// Also returns the number of bytes consumed (the full line length).
+// Returns io.EOF once there are no more lines.
+func readPagerLine(r *bufio.Reader) (string, int, error) {
+	var line []byte
+	consumed := 0
+	for {
+		chunk, err := r.ReadSlice('\n')
+		consumed += len(chunk)
+		if room := pagerMaxLineBytes - len(line); room > 0 {
+			line = append(line, chunk[:min(len(chunk), room)]...)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil && !(err == io.EOF && consumed > 0) {
+			return "", consumed, err
+		}
+		return strings.ToValidUTF8(strings.TrimRight(string(line), "\r\n"), "�"), consumed, nil
+	}
+}
+
+// parsePagerJump parses a jump target: "120" (line), "50%" (percentage) or "$" / "end".
+func parsePagerJump(input string) (pagerJump, error) {
+	input = strings.TrimSpace(input)
+	jump := pagerJump{line: -1, percent: -1}
+
+	switch {
+	case input == "":
+		return jump, errors.New("enter a line number, a percentage or $")
+	case input == "$" || strings.EqualFold(input, "end"):
+		jump.end = true
+	case strings.HasSuffix(input, "%"):
+		pct, err := strconv.Atoi(strings.TrimSuffix(input, "%"))
+		if err != nil || pct < 0 || pct > 100 {
+			return jump, fmt.Errorf("invalid percentage: %s", input)
+		}
+		if pct == 100 {
+			jump.end = true
+		} else {
+			jump.percent = pct
+		}
+	default:
+		line, err := strconv.Atoi(input)
+		if err != nil || line < 1 {
+			return jump, fmt.Errorf("invalid line number: %s", input)
+		}
+		jump.line = line - 1
+	}
+	return jump, nil
+}
+
+// pagerMaxScroll returns the last scroll position that still fills the viewport
+func (m model) pagerMaxScroll() int {
+	return max(0, m.preview.pager.lineCount()-m.getPreviewVisibleLines())
+}
+
+// pagerJumpTo scrolls the pager to jump, deferring it if indexing hasn't reached the target yet
+func (m *model) pagerJumpTo(jump pagerJump) {
+	p := m.preview.pager
+	if p == nil {
+		return
+	}
+	p.pendingLine, p.pendingOffset, p.pendingEnd = -1, -1, false
+
+	switch {
+	case jump.end:
+		if p.indexed {
+			m.preview.scrollPos = m.pagerMaxScroll()
+		} else {
+			p.pendingEnd = true
+		}
+	case jump.percent >= 0:
+		offset := p.size * int64(jump.percent) / 100
+		if p.indexed || offset < p.scanned {
+			m.preview.scrollPos = min(p.lineAtOffset(offset), m.pagerMaxScroll())
+		} else {
+			p.pendingOffset = offset
+		}
+	default:
+		if p.indexed || jump.line < p.lines {
+			m.preview.scrollPos = min(jump.line, m.pagerMaxScroll())
+		} else {
+			p.pendingLine = jump.line
+		}
+	}
+
+	if p.pendingEnd || p.pendingOffset >= 0 || p.pendingLine >= 0 {
+		m.setStatusMessage(fmt.Sprintf("⏳ Indexing %s (%d%%) - will jump when reached", m.preview.fileName, p.progress()), false)
+	}
+}
+
+// advancePagerIndex starts indexing the next chunk of the pager file in the
+// background, unless a step is already running. Called on every tick.
+func (m *model) advancePagerIndex() tea.Cmd {
+	p := m.preview.pager
+	if p == nil || p.indexed || p.indexErr != nil || p.indexing {
+		return nil
+	}
+	return pagerIndexCmd(p)
+}
+
+// applyPagerIndexMsg merges a finished index step, applies a deferred jump once
+// the index has reached it and goes on with the next step
+func (m *model) applyPagerIndexMsg(msg pagerIndexMsg) tea.Cmd {
+	p := msg.pager
+	p.indexing = false
+	if p != m.preview.pager || !p.mergeIndex(msg) {
+		return m.advancePagerIndex()
+	}
+	if p.indexErr != nil {
+		m.setStatusMessage(fmt.Sprintf("Pager: indexing stopped: %v", p.indexErr), true)
+		return nil
+	}
+
+	switch {
+	case p.pendingEnd && p.indexed:
+		m.pagerJumpTo(pagerJump{line: -1, percent: -1, end: true})
+	case p.pendingOffset >= 0 && (p.indexed || p.pendingOffset < p.scanned):
+		m.preview.scrollPos = min(p.lineAtOffset(p.pendingOffset), m.pagerMaxScroll())
+		p.pendingOffset = -1
+	case p.pendingLine >= 0 && (p.indexed || p.pendingLine < p.lines):
+		m.preview.scrollPos = min(p.pendingLine, m.pagerMaxScroll())
+		p.pendingLine = -1
+	}
+	return m.advancePagerIndex()
+}
+
+// pagerSearchNext starts a background search for the current query (with the
+// regex / case / whole-word options), continuing after the last hit
+func (m *model) pagerSearchNext() tea.Cmd {
+	p := m.preview.pager
+	query := m.preview.searchQuery
+	if p == nil || query == "" {
+		return nil
+	}
+	opts := m.previewSearchOpts
+	m.preview.searchErr = ""
+	if _, err := compilePreviewSearch(query, opts); err != nil {
+		m.preview.searchErr = "invalid regex"
+		m.setStatusMessage(fmt.Sprintf("🔍 Invalid regex: %v", err), true)
+		return nil
+	}
+
+	from := m.preview.scrollPos
+	if p.searchLine >= 0 && p.searchQuery == query && p.searchOpts == opts {
+		from = p.searchLine + 1
+	}
+	offset, err := p.offsetOfLine(from)
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Search failed: %v", err), true)
+		return nil
+	}
+
+	p.searching = true
+	m.setStatusMessage(fmt.Sprintf("🔍 Searching %s for '%s'...", m.preview.fileName, query), false)
+	return pagerSearchCmd(p, query, opts, from, offset)
+}
+
+// pagerSearchCmd searches forward from fromLine (starting at byte fromOffset),
+// wrapping around to the top once. Superseded searches stop early.
+func pagerSearchCmd(p *pagerState, query string, opts previewSearchOptions, fromLine int, fromOffset int64) tea.Cmd {
+	gen := p.searchGen.Add(1)
+	path := p.path
+	return func() tea.Msg {
+		msg := pagerSearchMsg{path: path, gen: gen, query: query, opts: opts}
+		re, err := compilePreviewSearch(query, opts)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		cancelled := func() bool { return p.searchGen.Load() != gen }
+
+		msg.line, msg.offset, msg.found, msg.err = scanPagerLines(path, re, fromLine, fromOffset, -1, cancelled)
+		if !msg.found && msg.err == nil && fromOffset > 0 {
+			msg.line, msg.offset, msg.found, msg.err = scanPagerLines(path, re, 0, 0, fromOffset, cancelled)
+			msg.wrapped = msg.found
+		}
+		return msg
+	}
+}
+
+// scanPagerLines streams lines from offset (line number line) until one matches
+// re, limit is reached (-1 = EOF) or the search is cancelled
+func scanPagerLines(path string, re *regexp.Regexp, line int, offset, limit int64, cancelled func() bool) (int, int64, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, 0, false, err
+	}
+
+	r := bufio.NewReaderSize(f, pagerReadChunk)
+	for limit < 0 || offset < limit {
+		if line%4096 == 0 && cancelled() {
+			return 0, 0, false, nil
+		}
+		text, n, err := readPagerLine(r)
+		if err == io.EOF {
+			return 0, 0, false, nil
+		} else if err != nil {
+			return 0, 0, false, err
+		}
+		if countMatches(text, re) > 0 {
+			return line, offset, true, nil
+		}
+		line++
+		offset += int64(n)
+	}
+	return 0, 0, false, nil
+}
+
+// applyPagerSearchResult scrolls to a finished search's hit (stale results are ignored)
+func (m *model) applyPagerSearchResult(msg pagerSearchMsg) {
+	p := m.preview.pager
+	if p == nil || p.path != msg.path || p.searchGen.Load() != msg.gen {
+		return
+	}
+	p.searching = false
+
+	if msg.err != nil {
+		m.setStatusMessage(fmt.Sprintf("Search failed: %v", msg.err), true)
+		return
+	}
+	if !msg.found {
+		p.searchLine = -1
+		m.setStatusMessage(fmt.Sprintf("🔍 No matches for '%s'", msg.query), false)
+		return
+	}
+
+	p.searchQuery = msg.query
+	p.searchOpts = msg.opts
+	p.searchLine = msg.line
+	if msg.line >= p.lines {
+		// Hit is past the indexed range - remember where it is so it can be shown
+		p.anchorLine, p.anchorOffset = msg.line, msg.offset
+	}
+	m.preview.scrollPos = min(msg.line, m.pagerMaxScroll())
+
+	wrapped := ""
+	if msg.wrapped {
+		wrapped = " (wrapped to top)"
+	}
+	m.setStatusMessage(fmt.Sprintf("🔍 Match at line %d%s - %s", msg.line+1, wrapped, m.streamingSearchKeys()), false)
+}
+
+// pagerSearchText returns the search bar text while the pager is active
+func (m model) pagerSearchText() string {
+	p := m.preview.pager
+	opts := m.previewSearchOpts.describe()
+	switch {
+	case m.preview.searchQuery == "":
+		return "Search: (type, then Enter/↓ to search forward, Alt+R/C/W: regex/case/word, Esc: close)" + opts
+	case m.preview.searchErr != "":
+		return fmt.Sprintf("Search: %s (%s)%s", m.preview.searchQuery, m.preview.searchErr, opts)
+	case p.searching:
+		return fmt.Sprintf("Search: %s (searching...)%s", m.preview.searchQuery, opts)
+	case p.searchLine >= 0 && p.searchQuery == m.preview.searchQuery && p.searchOpts == m.previewSearchOpts:
+		return fmt.Sprintf("Search: %s (line %d, Enter/↓: next)%s", m.preview.searchQuery, p.searchLine+1, opts)
+	default:
+		return fmt.Sprintf("Search: %s (Enter/↓: search)%s", m.preview.searchQuery, opts)
+	}
+}
+
+// renderPagerGotoPrompt renders the jump prompt shown after pressing ":" (pager and hex view)
+// or the tree / table view filter prompt (".")
+func (m model) renderPagerGotoPrompt() string {
+	promptStyle := lipgloss.NewStyle().
+		Background(uiInfoBackground()).
+		Foreground(uiInfoForeground()).
+		Bold(true).
+		Padding(0, 1)
+
+	text := fmt.Sprintf("Go to: %s█ (line, 50%%, $ = end · Enter: jump, Esc: cancel)", m.preview.gotoInput)
+	if m.preview.db != nil {
+		text = fmt.Sprintf("SQL: %s█ (read-only SELECT · Enter: run, Esc: cancel)", m.preview.gotoInput)
+	} else if m.preview.table != nil {
+		text = fmt.Sprintf("Filter rows: %s█ (text, col=value, col~text, col>10, #2<5 · Enter: apply, empty to clear, Esc: cancel)", m.preview.gotoInput)
+	} else if m.dataTreeActive() {
+		text = fmt.Sprintf("Filter: %s█ (.a.b, .items[] | select(.id == 3), keys, length · Enter: apply, . to clear, Esc: cancel)", m.preview.gotoInput)
+	} else if m.preview.hex != nil {
+		text = fmt.Sprintf("Go to offset: %s█ (0x1f00, 4096, 50%%, $ = end · Enter: jump, Esc: cancel)", m.preview.gotoInput)
+	}
+	if m.visualWidthCompensated(text) > m.width-4 {
+		text = m.truncateToWidthCompensated(text, m.width-4)
+	}
+	return promptStyle.Render(text)
+}
+
+// pagerStatusText returns the pager's position/indexing summary for info lines
+func (m model) pagerStatusText(lastVisibleLine int) string {
+	p := m.preview.pager
+	total := strconv.Itoa(p.lineCount())
+	if !p.indexed {
+		total += fmt.Sprintf("+ (indexing %d%%)", p.progress())
+	}
+	return fmt.Sprintf("Line %d/%s", lastVisibleLine, total)
+}
+
+// renderPagerPreview renders the visible window of a pager file with line numbers and scrollbar
+func (m model) renderPagerPreview(maxVisible int) string {
+	var s strings.Builder
+	p := m.preview.pager
+
+	var boxContentWidth int
+	if m.viewMode == viewFullPreview {
+		boxContentWidth = m.width - 6
+	} else if m.displayMode == modeDetail || m.isNarrowTerminal() {
+		boxContentWidth = m.width - 6
+	} else {
+		boxContentWidth = m.rightWidth - 2
+	}
+
+	// Line numbers in multi-GB files can need more than the usual 5 digits
+	numWidth := max(5, len(strconv.Itoa(p.lineCount())))
+	availableWidth := boxContentWidth - numWidth - 3 // number + space + scrollbar + space
+	if availableWidth < 20 {
+		availableWidth = 20
+	}
+
+	totalLines := max(p.lineCount(), 1)
+	targetLines := maxVisible
+	if m.viewMode == viewDualPane {
+		targetLines = maxVisible - 1
+	}
+
+	start := max(m.preview.scrollPos, 0)
+	if p.indexed && start > totalLines-targetLines {
+		start = max(0, totalLines-targetLines)
+	}
+
+	linesRendered := 0
+	writeLine := func(line string) {
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(line)
+		linesRendered++
+	}
+
+	lines, err := p.visibleLines(start, targetLines)
+	if err != nil {
+		writeLine(fmt.Sprintf("Error reading file: %v", err))
+	}
+
+	lineNumStyle := lipgloss.NewStyle().Foreground(uiSubtleText())
+	matchNumStyle := lipgloss.NewStyle().Foreground(currentTheme.Title.adaptiveColor()).Bold(true)
+	for i, line := range lines {
+		numStyle := lineNumStyle
+		if start+i == p.searchLine {
+			numStyle = matchNumStyle
+		}
+		renderedLine := numStyle.Render(fmt.Sprintf("%*d ", numWidth, start+i+1))
+		renderedLine += m.renderScrollbar(i, maxVisible, totalLines)
+		renderedLine += " "
+
+		if visualWidth(line) > availableWidth {
+			line = truncateToWidth(line, availableWidth)
+		}
+		writeLine(renderedLine + line + "\033[0m")
+	}
+
+	if m.viewMode == viewDualPane {
+		scrollStyle := lipgloss.NewStyle().
+			Foreground(uiSubtleText()).
+			Italic(true)
+		for linesRendered < targetLines {
+			writeLine("\033[0m")
+		}
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(scrollStyle.Render(fmt.Sprintf(" %s [pager] ", m.pagerStatusText(start+len(lines)))))
+	} else {
+		for linesRendered < maxVisible {
+			writeLine("\033[0m")
+		}
+	}
+
+	return s.String()
+}
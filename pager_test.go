@@ -0,0 +1,287 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePagerTestFile writes n numbered lines ("line 1" ... "line n") and returns the path
+func writePagerTestFile(t *testing.T, n int) string {
+	t.Helper()
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	path := filepath.Join(t.TempDir(), "big.log")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	return path
+}
+
+// newTestPager opens path in the pager and indexes it completely
+func newTestPager(t *testing.T, path string) *pagerState {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	p, err := newPagerState(path, info.Size())
+	if err != nil {
+		t.Fatalf("newPagerState failed: %v", err)
+	}
+	for !p.indexed {
+		if err := p.indexStep(pagerIndexBudget); err != nil {
+			t.Fatalf("indexStep failed: %v", err)
+		}
+	}
+	return p
+}
+
+// TestPagerIndex tests line counting and incremental indexing
+func TestPagerIndex(t *testing.T) {
+	path := writePagerTestFile(t, 10000)
+	info, _ := os.Stat(path)
+
+	p, err := newPagerState(path, info.Size())
+	if err != nil {
+		t.Fatalf("newPagerState failed: %v", err)
+	}
+	p.indexed, p.lines, p.scanned, p.checkpoints = false, 1, 0, []int64{0}
+
+	// Index in small steps to exercise chunk boundaries
+	for !p.indexed {
+		if err := p.indexStep(1000); err != nil {
+			t.Fatalf("indexStep failed: %v", err)
+		}
+	}
+	if p.lineCount() != 10000 {
+		t.Errorf("Expected 10000 lines, got %d", p.lineCount())
+	}
+	if len(p.checkpoints) != (10000+pagerInitialStride-1)/pagerInitialStride {
+		t.Errorf("Unexpected checkpoint count %d", len(p.checkpoints))
+	}
+}
+
+// TestPagerIndex_NoTrailingNewline tests that a final unterminated line is counted
+func TestPagerIndex_NoTrailingNewline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "f.txt")
+	os.WriteFile(path, []byte("a\nb\nc"), 0644)
+
+	p := newTestPager(t, path)
+	if p.lineCount() != 3 {
+		t.Errorf("Expected 3 lines, got %d", p.lineCount())
+	}
+	lines, eof, err := p.readLines(2, 5)
+	if err != nil || !eof || len(lines) != 1 || lines[0] != "c" {
+		t.Errorf("readLines(2, 5) = %q, %v, %v", lines, eof, err)
+	}
+}
+
+// TestPagerReadLines tests reading windows at arbitrary positions
+func TestPagerReadLines(t *testing.T) {
+	p := newTestPager(t, writePagerTestFile(t, 5000))
+
+	for _, start := range []int{0, 255, 256, 257, 4000, 4998} {
+		lines, _, err := p.readLines(start, 2)
+		if err != nil {
+			t.Fatalf("readLines(%d) failed: %v", start, err)
+		}
+		if len(lines) == 0 || lines[0] != fmt.Sprintf("line %d", start+1) {
+			t.Errorf("readLines(%d) = %q", start, lines)
+		}
+	}
+
+	// Window cache serves nearby reads without going back to disk
+	if _, err := p.visibleLines(1000, 20); err != nil {
+		t.Fatalf("visibleLines failed: %v", err)
+	}
+	windowStart := p.windowStart
+	lines, _ := p.visibleLines(1005, 20)
+	if p.windowStart != windowStart {
+		t.Error("Expected window cache to be reused")
+	}
+	if lines[0] != "line 1006" {
+		t.Errorf("Expected line 1006, got %q", lines[0])
+	}
+}
+
+// TestPagerLongLines tests that long lines are clipped but still advance correctly
+func TestPagerLongLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "long.txt")
+	long := strings.Repeat("x", pagerMaxLineBytes*20)
+	os.WriteFile(path, []byte(long+"\nshort\n"), 0644)
+
+	p := newTestPager(t, path)
+	lines, _, err := p.readLines(0, 2)
+	if err != nil {
+		t.Fatalf("readLines failed: %v", err)
+	}
+	if len(lines[0]) != pagerMaxLineBytes {
+		t.Errorf("Expected long line clipped to %d bytes, got %d", pagerMaxLineBytes, len(lines[0]))
+	}
+	if lines[1] != "short" {
+		t.Errorf("Expected 'short' after long line, got %q", lines[1])
+	}
+}
+
+// TestPagerCheckpointCap tests that the index stays bounded by doubling the stride
+func TestPagerCheckpointCap(t *testing.T) {
+	p := &pagerState{stride: pagerInitialStride, checkpoints: []int64{0}, lines: 1}
+	total := pagerMaxCheckpoints*pagerInitialStride*2 + 1
+	for i := 1; i < total; i++ {
+		p.addLine(int64(i))
+	}
+
+	if len(p.checkpoints) > pagerMaxCheckpoints {
+		t.Errorf("Checkpoints exceed cap: %d", len(p.checkpoints))
+	}
+	if p.stride != pagerInitialStride*4 {
+		t.Errorf("Expected stride %d, got %d", pagerInitialStride*4, p.stride)
+	}
+	// Checkpoint i must still point at line i*stride (offsets equal line numbers here)
+	for i, offset := range p.checkpoints {
+		if offset != int64(i*p.stride) {
+			t.Fatalf("checkpoint %d = %d, want %d", i, offset, i*p.stride)
+		}
+	}
+}
+
+// TestPagerLineAtOffset tests mapping byte offsets (percentage jumps) to lines
+func TestPagerLineAtOffset(t *testing.T) {
+	p := newTestPager(t, writePagerTestFile(t, 1000))
+
+	if line := p.lineAtOffset(0); line != 0 {
+		t.Errorf("lineAtOffset(0) = %d", line)
+	}
+	offset, err := p.offsetOfLine(700)
+	if err != nil {
+		t.Fatalf("offsetOfLine failed: %v", err)
+	}
+	if line := p.lineAtOffset(offset + 2); line != 700 {
+		t.Errorf("lineAtOffset = %d, want 700", line)
+	}
+	if line := p.lineAtOffset(p.size); line != 999 {
+		t.Errorf("lineAtOffset(size) = %d, want 999", line)
+	}
+}
+
+// TestParsePagerJump tests jump prompt parsing
+func TestParsePagerJump(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    pagerJump
+		wantErr bool
+	}{
+		{"120", pagerJump{line: 119, percent: -1}, false},
+		{" 1 ", pagerJump{line: 0, percent: -1}, false},
+		{"50%", pagerJump{line: -1, percent: 50}, false},
+		{"100%", pagerJump{line: -1, percent: -1, end: true}, false},
+		{"$", pagerJump{line: -1, percent: -1, end: true}, false},
+		{"END", pagerJump{line: -1, percent: -1, end: true}, false},
+		{"0", pagerJump{}, true},
+		{"150%", pagerJump{}, true},
+		{"abc", pagerJump{}, true},
+		{"", pagerJump{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parsePagerJump(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePagerJump(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parsePagerJump(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestPagerSearch tests streaming search, continuation and wrap-around
+func TestPagerSearch(t *testing.T) {
+	p := newTestPager(t, writePagerTestFile(t, 3000))
+
+	msg := pagerSearchCmd(p, "LINE 2500", previewSearchOptions{}, 0, 0)().(pagerSearchMsg)
+	if !msg.found || msg.line != 2499 || msg.wrapped {
+		t.Fatalf("Unexpected search result: %+v", msg)
+	}
+	if offset, _ := p.offsetOfLine(2499); msg.offset != offset {
+		t.Errorf("Expected offset %d, got %d", offset, msg.offset)
+	}
+
+	// Searching past the only hit wraps around to it
+	from, _ := p.offsetOfLine(2600)
+	msg = pagerSearchCmd(p, "line 2500", previewSearchOptions{}, 2600, from)().(pagerSearchMsg)
+	if !msg.found || msg.line != 2499 || !msg.wrapped {
+		t.Errorf("Expected wrapped hit at 2499, got %+v", msg)
+	}
+
+	msg = pagerSearchCmd(p, "no such text", previewSearchOptions{}, 0, 0)().(pagerSearchMsg)
+	if msg.found {
+		t.Errorf("Expected no match, got %+v", msg)
+	}
+
+	// The regex / case / whole-word options apply
+	msg = pagerSearchCmd(p, "LINE 2500", previewSearchOptions{caseSensitive: true}, 0, 0)().(pagerSearchMsg)
+	if msg.found {
+		t.Errorf("Expected no case-sensitive match, got %+v", msg)
+	}
+	msg = pagerSearchCmd(p, `^line 2\d7$`, previewSearchOptions{regex: true}, 0, 0)().(pagerSearchMsg)
+	if !msg.found || msg.line != 206 {
+		t.Errorf("Expected the regex to match line 207, got %+v", msg)
+	}
+	from, _ = p.offsetOfLine(30)
+	msg = pagerSearchCmd(p, "line 25", previewSearchOptions{wholeWord: true}, 30, from)().(pagerSearchMsg)
+	if !msg.found || msg.line != 24 || !msg.wrapped {
+		t.Errorf("Expected the whole word to skip line 250 and wrap to line 25, got %+v", msg)
+	}
+	msg = pagerSearchCmd(p, "(", previewSearchOptions{regex: true}, 0, 0)().(pagerSearchMsg)
+	if msg.found || msg.err == nil {
+		t.Errorf("Expected an invalid regex error, got %+v", msg)
+	}
+}
+
+// TestPagerJumpDeferred tests that jumps past the indexed range wait for indexing
+func TestPagerJumpDeferred(t *testing.T) {
+	path := writePagerTestFile(t, 20000)
+	info, _ := os.Stat(path)
+	p, err := newPagerState(path, info.Size())
+	if err != nil {
+		t.Fatalf("newPagerState failed: %v", err)
+	}
+	// Pretend only the first part has been indexed
+	p.indexed, p.lines, p.scanned, p.checkpoints = false, 1, 0, []int64{0}
+	p.indexStep(1000)
+
+	m := model{height: 40, width: 120, viewMode: viewFullPreview}
+	m.preview.pager = p
+	m.preview.loaded = true
+
+	m.pagerJumpTo(pagerJump{line: -1, percent: -1, end: true})
+	if !p.pendingEnd {
+		t.Fatal("Expected end jump to be deferred")
+	}
+	for cmd := m.advancePagerIndex(); cmd != nil; {
+		cmd = m.applyPagerIndexMsg(cmd().(pagerIndexMsg))
+	}
+	if !p.indexed || p.indexing {
+		t.Fatal("Expected the background steps to finish indexing")
+	}
+	ref, _ := newPagerState(path, info.Size())
+	for !ref.indexed {
+		ref.indexStep(pagerIndexBudget)
+	}
+	if p.lines != ref.lines || fmt.Sprint(p.checkpoints) != fmt.Sprint(ref.checkpoints) {
+		t.Errorf("Expected the same index as a synchronous scan, got %d lines, %d checkpoints (want %d, %d)",
+			p.lines, len(p.checkpoints), ref.lines, len(ref.checkpoints))
+	}
+	if p.pendingEnd {
+		t.Error("Expected deferred jump to be applied")
+	}
+	if m.preview.scrollPos != m.pagerMaxScroll() || m.preview.scrollPos == 0 {
+		t.Errorf("Expected scroll at end (%d), got %d", m.pagerMaxScroll(), m.preview.scrollPos)
+	}
+}
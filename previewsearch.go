@@ -0,0 +1,292 @@
+package main
+
+// Module: previewsearch.go
+// Purpose: Searching the preview as it is displayed
+// Responsibilities:
+// - Building the search pattern from the query and options (regex, case-sensitive, whole word)
+// - Collecting every occurrence in the displayed lines (Glamour output, highlighted code, JSONL conversations)
+// - Highlighting occurrences inside styled lines without breaking their ANSI sequences
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Highlight styles for search matches (the current match stands out)
+const (
+	searchMatchSGR   = "\033[30;43m"
+	searchCurrentSGR = "\033[1;30;48;5;208m"
+)
+
+// previewSearchOptions changes how the preview search query is matched
+type previewSearchOptions struct {
+	regex         bool // Query is a regular expression
+	caseSensitive bool
+	wholeWord     bool
+}
+
+// describe lists the enabled options for the search bar
+func (o previewSearchOptions) describe() string {
+	var parts []string
+	if o.regex {
+		parts = append(parts, "regex")
+	}
+	if o.caseSensitive {
+		parts = append(parts, "case")
+	}
+	if o.wholeWord {
+		parts = append(parts, "word")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// compilePreviewSearch builds the pattern for a query
+func compilePreviewSearch(query string, opts previewSearchOptions) (*regexp.Regexp, error) {
+	pattern := query
+	if !opts.regex {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if opts.wholeWord {
+		if opts.regex {
+			pattern = `\b(?:` + pattern + `)\b`
+		} else {
+			// \b only applies next to word characters ("foo(" should still match "foo(bar)")
+			first, _ := utf8.DecodeRuneInString(query)
+			last, _ := utf8.DecodeLastRuneInString(query)
+			if isWordRune(first) {
+				pattern = `\b` + pattern
+			}
+			if isWordRune(last) {
+				pattern += `\b`
+			}
+		}
+	}
+	if !opts.caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// toggleSearchOption flips a search option and re-runs the search
+func (m *model) toggleSearchOption(option string) {
+	switch option {
+	case "regex":
+		m.previewSearchOpts.regex = !m.previewSearchOpts.regex
+	case "case":
+		m.previewSearchOpts.caseSensitive = !m.previewSearchOpts.caseSensitive
+	case "word":
+		m.previewSearchOpts.wholeWord = !m.previewSearchOpts.wholeWord
+	}
+	m.performPreviewSearch()
+}
+
+// previewDisplayLines returns the lines the preview currently shows (scrollPos indexes into these)
+func (m model) previewDisplayLines() []string {
+	if m.stash != nil {
+		return m.stashDisplayLines()
+	}
+	if m.gitLog != nil {
+		return m.gitLogDisplayLines()
+	}
+	if m.preview.history != nil {
+		return m.historyDisplayLines()
+	}
+	if m.preview.blame != nil {
+		return m.blameDisplayLines()
+	}
+	if m.preview.compare != nil {
+		return m.compareDisplayLines()
+	}
+	if m.preview.isJSONL && len(m.preview.cachedJSONLMessages) > 0 {
+		return renderJSONLFromMessages(m.preview.cachedJSONLMessages, m.jsonlContentWidth(), m.preview.cachedJSONLIsTailed, m.preview.fileSize)
+	}
+	return m.markdownDisplayLines()
+}
+
+// collectSearchMatches records every occurrence of re in the lines as displayed
+// (rendered markdown, highlighted code, conversations)
+func (m *model) collectSearchMatches(re *regexp.Regexp) {
+	m.preview.searchMatches = nil
+	m.preview.searchOccurrence = nil
+	for i, line := range m.previewDisplayLines() {
+		n := countMatches(line, re)
+		for k := 0; k < n; k++ {
+			m.preview.searchMatches = append(m.preview.searchMatches, i)
+			m.preview.searchOccurrence = append(m.preview.searchOccurrence, k)
+		}
+	}
+}
+
+// refreshSearchMatches recounts the matches after the preview changed underneath the search
+// (follow mode), keeping the current match without scrolling to it
+func (m *model) refreshSearchMatches() {
+	re := m.preview.searchRe
+	if re == nil || m.preview.pager != nil || m.preview.hex != nil || m.dataTreeActive() {
+		return
+	}
+	m.collectSearchMatches(re)
+	m.preview.currentMatch = min(m.preview.currentMatch, len(m.preview.searchMatches)-1)
+}
+
+// searchHighlightActive reports whether displayed lines should get search highlights
+func (m model) searchHighlightActive() bool {
+	return m.preview.searchRe != nil && m.preview.searchQuery != "" &&
+		m.preview.hex == nil && m.preview.pager == nil && !m.preview.hasGraphicsProtocol
+}
+
+// highlightSearchLine highlights the search matches in display line i
+func (m model) highlightSearchLine(line string, i int) string {
+	if !m.searchHighlightActive() {
+		return line
+	}
+	current := -1
+	if c := m.preview.currentMatch; c >= 0 && c < len(m.preview.searchMatches) && m.preview.searchMatches[c] == i {
+		current = m.preview.searchOccurrence[c]
+	}
+	return highlightMatches(line, m.preview.searchRe, current)
+}
+
+// ansiPlainText returns the text of a styled line and, for every byte of it, its index in line
+// (plus len(line) at the end). Handles CSI (colors) and OSC (hyperlinks) sequences.
+func ansiPlainText(line string) (string, []int) {
+	var plain strings.Builder
+	offsets := make([]int, 0, len(line)+1)
+	for i := 0; i < len(line); {
+		if n := ansiSequenceLen(line, i); n > 0 {
+			i += n
+			continue
+		}
+		plain.WriteByte(line[i])
+		offsets = append(offsets, i)
+		i++
+	}
+	return plain.String(), append(offsets, len(line))
+}
+
+// ansiSequenceLen returns the length of the escape sequence starting at s[i] (0 if there is none)
+func ansiSequenceLen(s string, i int) int {
+	if s[i] != '\033' || i+1 >= len(s) {
+		return 0
+	}
+	switch s[i+1] {
+	case '[': // CSI: parameters then a final byte in @-~
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j - i + 1
+			}
+		}
+		return len(s) - i
+	case ']', 'P', '_': // OSC / DCS / APC: until BEL or ST
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == '\a' {
+				return j - i + 1
+			}
+			if s[j] == '\033' && j+1 < len(s) && s[j+1] == '\\' {
+				return j - i + 2
+			}
+		}
+		return len(s) - i
+	}
+	return 2
+}
+
+// highlightMatches wraps every match of re in a styled line with the highlight colors.
+// The line's own styling is restored after each match; current is the match index drawn as current (-1 = none).
+func highlightMatches(line string, re *regexp.Regexp, current int) string {
+	plain, offsets := ansiPlainText(line)
+	var matches [][]int
+	for _, loc := range re.FindAllStringIndex(plain, -1) {
+		if loc[1] > loc[0] {
+			matches = append(matches, loc)
+		}
+	}
+	if len(matches) == 0 {
+		return line
+	}
+
+	var b strings.Builder
+	active := "" // SGR sequences in effect (since the last reset)
+	// copyStyled copies line[from:to], tracking SGR state and re-applying sgr after each one
+	copyStyled := func(from, to int, sgr string) {
+		for i := from; i < to; {
+			n := ansiSequenceLen(line, i)
+			if n == 0 {
+				b.WriteByte(line[i])
+				i++
+				continue
+			}
+			seq := line[i : i+n]
+			b.WriteString(seq)
+			if strings.HasPrefix(seq, "\033[") && strings.HasSuffix(seq, "m") {
+				if seq == "\033[0m" || seq == "\033[m" {
+					active = ""
+				} else {
+					active += seq
+				}
+				b.WriteString(sgr)
+			}
+			i += n
+		}
+	}
+
+	pos := 0
+	for k, loc := range matches {
+		sgr := searchMatchSGR
+		if k == current {
+			sgr = searchCurrentSGR
+		}
+		start, end := offsets[loc[0]], offsets[loc[1]-1]+1
+		copyStyled(pos, start, "")
+		b.WriteString(sgr)
+		copyStyled(start, end, sgr)
+		b.WriteString("\033[0m" + active)
+		pos = end
+	}
+	copyStyled(pos, len(line), "")
+	return b.String()
+}
+
+// countMatches returns the number of non-empty matches of re in a styled line
+func countMatches(line string, re *regexp.Regexp) int {
+	plain, _ := ansiPlainText(line)
+	n := 0
+	for _, loc := range re.FindAllStringIndex(plain, -1) {
+		if loc[1] > loc[0] {
+			n++
+		}
+	}
+	return n
+}
+
+// streamingSearchKeys names the next-match keys after a pager or hex search hit:
+// Enter/↓ while the search bar is open, n once Enter has closed it (standalone preview)
+func (m model) streamingSearchKeys() string {
+	if m.preview.searchActive {
+		return "Enter/↓: next, Esc: exit"
+	}
+	return "n: next"
+}
+
+// previewSearchText returns the search bar text (regular previews; hex and pager have their own)
+func (m model) previewSearchText(cursor string) string {
+	opts := m.previewSearchOpts.describe()
+	switch {
+	case m.preview.searchErr != "":
+		return fmt.Sprintf("Search: %s%s (%s)%s", m.preview.searchQuery, cursor, m.preview.searchErr, opts)
+	case m.preview.searchQuery == "":
+		return fmt.Sprintf("Search: %s (type to search, ↑/↓: prev/next, Alt+R/C/W: regex/case/word, Esc: close)%s", cursor, opts)
+	case len(m.preview.searchMatches) == 0:
+		return fmt.Sprintf("Search: %s%s (no matches)%s", m.preview.searchQuery, cursor, opts)
+	}
+	return fmt.Sprintf("Search: %s%s (%d/%d)%s", m.preview.searchQuery, cursor, m.preview.currentMatch+1, len(m.preview.searchMatches), opts)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCompilePreviewSearch(t *testing.T) {
+	text := "Foo foo food (foo) foo_bar Foo."
+	tests := []struct {
+		query string
+		opts  previewSearchOptions
+		want  int
+	}{
+		{"foo", previewSearchOptions{}, 6},
+		{"foo", previewSearchOptions{caseSensitive: true}, 4},
+		{"foo", previewSearchOptions{wholeWord: true}, 4},
+		{"(foo)", previewSearchOptions{wholeWord: true}, 1},
+		{"fo+d?", previewSearchOptions{regex: true}, 6},
+		{"fo+d", previewSearchOptions{regex: true, wholeWord: true}, 1},
+		{"foo.", previewSearchOptions{}, 1}, // Literal dot
+		{"^Foo", previewSearchOptions{regex: true, caseSensitive: true}, 1},
+	}
+	for _, tt := range tests {
+		re, err := compilePreviewSearch(tt.query, tt.opts)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.query, err)
+		}
+		if got := countMatches(text, re); got != tt.want {
+			t.Errorf("%q %+v: expected %d matches, got %d", tt.query, tt.opts, tt.want, got)
+		}
+	}
+	if _, err := compilePreviewSearch("foo(", previewSearchOptions{regex: true}); err == nil {
+		t.Error("Expected an invalid regex error")
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	re, _ := compilePreviewSearch("foo", previewSearchOptions{})
+	link := "\033]8;;https://example.com/foo\033\\"
+	line := "\033[31mred foo\033[0m and " + link + "a f\033[1moo\033[0m link" + "\033]8;;\033\\"
+
+	out := highlightMatches(line, re, 1)
+	plainIn, _ := ansiPlainText(line)
+	plainOut, _ := ansiPlainText(out)
+	if plainIn != plainOut || plainIn != "red foo and a foo link" {
+		t.Fatalf("Highlighting changed the text: %q", plainOut)
+	}
+	// First match: highlighted, then the red foreground is restored
+	if !strings.Contains(out, searchMatchSGR+"foo\033[0m\033[31m") {
+		t.Errorf("Expected the first match highlighted and red restored: %q", out)
+	}
+	// Second match is the current one; the bold inside it keeps the highlight
+	if !strings.Contains(out, searchCurrentSGR+"f\033[1m"+searchCurrentSGR+"oo") {
+		t.Errorf("Expected the current match highlighted across its styling: %q", out)
+	}
+	// The hyperlink (and the URL's "foo") is left alone
+	if !strings.Contains(out, link) {
+		t.Errorf("Expected the hyperlink sequence intact: %q", out)
+	}
+	if highlightMatches("no match", re, 0) != "no match" {
+		t.Error("Expected lines without matches unchanged")
+	}
+}
+
+func TestPreviewSearch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	os.WriteFile(path, []byte("function one\nnothing here\nfunction two function three\nFUNCTION four\n"), 0644)
+
+	var tm tea.Model = model{height: 30, width: 100, viewMode: viewFullPreview}
+	m := tm.(model)
+	m.loadPreview(path)
+	m.populatePreviewCache()
+	key := func(msg tea.KeyMsg) {
+		t.Helper()
+		tm, _ = m.handleKeyEvent(msg)
+		m = tm.(model)
+	}
+	typeText := func(s string) {
+		for _, r := range s {
+			key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+
+	key(tea.KeyMsg{Type: tea.KeyCtrlF})
+	typeText("function") // "n" is typed, not "next match"
+	if m.preview.searchQuery != "function" || len(m.preview.searchMatches) != 4 {
+		t.Fatalf("Expected 4 matches for %q, got %d", m.preview.searchQuery, len(m.preview.searchMatches))
+	}
+	if got := m.previewSearchText(""); !strings.Contains(got, "(1/4)") {
+		t.Errorf("Unexpected search bar %q", got)
+	}
+
+	// Two matches on the same line: the second is the current one after Enter
+	key(tea.KeyMsg{Type: tea.KeyEnter})
+	key(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.preview.currentMatch != 2 || m.preview.searchMatches[2] != 2 || m.preview.searchOccurrence[2] != 1 {
+		t.Errorf("Expected the second match on line 3, got match %d", m.preview.currentMatch)
+	}
+	out := m.renderPreview(20)
+	if strings.Count(out, searchMatchSGR) != 3 || strings.Count(out, searchCurrentSGR) != 1 {
+		t.Errorf("Expected 4 highlighted matches, one current:\n%q", out)
+	}
+	key(tea.KeyMsg{Type: tea.KeyUp})
+	if m.preview.currentMatch != 1 {
+		t.Errorf("Expected ↑ to go back, at %d", m.preview.currentMatch)
+	}
+
+	// Options: case-sensitive drops FUNCTION, regex and whole word
+	key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c"), Alt: true})
+	if len(m.preview.searchMatches) != 3 || !strings.Contains(m.previewSearchText(""), "[case]") {
+		t.Errorf("Expected 3 case-sensitive matches, got %d", len(m.preview.searchMatches))
+	}
+	key(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r"), Alt: true})
+	typeText(" (one|two)")
+	if len(m.preview.searchMatches) != 2 {
+		t.Errorf("Expected 2 regex matches, got %d", len(m.preview.searchMatches))
+	}
+	typeText("(")
+	if m.preview.searchErr == "" || len(m.preview.searchMatches) != 0 {
+		t.Error("Expected an invalid regex to be reported")
+	}
+
+	key(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.preview.searchActive || strings.Contains(m.renderPreview(20), searchMatchSGR) {
+		t.Error("Expected Esc to clear the highlights")
+	}
+}
+
+func TestPreviewSearchJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	os.WriteFile(path, []byte(
+		`{"type":"user","message":{"role":"user","content":"Where is the config parser?"}}`+"\n"+
+			`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"The parser lives in config.go."}]}}`+"\n"), 0644)
+
+	m := model{height: 30, width: 100, viewMode: viewFullPreview}
+	m.loadPreview(path)
+	if !m.preview.isJSONL {
+		t.Fatal("Expected the conversation view")
+	}
+	m.preview.searchActive = true
+	m.preview.searchQuery = "parser"
+	m.performPreviewSearch()
+	if len(m.preview.searchMatches) != 2 {
+		t.Fatalf("Expected 2 matches in the rendered conversation, got %d", len(m.preview.searchMatches))
+	}
+	if out := m.renderPreview(20); strings.Count(out, "parser") != 2 || !strings.Contains(out, searchCurrentSGR+"parser") {
+		t.Errorf("Expected the conversation matches highlighted:\n%q", out)
+	}
+}
@@ -98,6 +98,9 @@ func (m model) renderListView(maxVisible int) string {
 		if m.isFavorite(file.path) {
 			favIndicator = "⭐"
 		}
+		if m.stashMarked[file.path] {
+			favIndicator += "📦"
+		}
 
 		// Truncate long filenames to prevent wrapping
 		// In dual-pane mode, use narrower width to fit in left pane
@@ -180,6 +183,9 @@ func (m model) renderListView(maxVisible int) string {
 			}
 		}
 
+		// Git status marker (M, A, ??, ● for folders with changes)
+		line += m.gitStatusBadge(file)
+
 		s.WriteString(line)
 		s.WriteString("\033[0m") // Reset ANSI codes
 		s.WriteString("\n")
@@ -378,24 +384,24 @@ func (m model) renderDetailView(maxVisible int) string {
 		paddedNameHeader := m.padToVisualWidth(nameHeader, nameWidth)
 		header = fmt.Sprintf("%s  %-*s  %-*s", paddedNameHeader, modifiedWidth, modifiedHeader, extraWidth, descHeader)
 	} else if m.showChangesOnly {
-		// Changes mode: Name (with status), Size, Modified, Location
+		// Changes mode: Name (with status), Staged (index), Unstaged (worktree), Location
 		nameHeader := "Name"
-		sizeHeader := "Size"
-		modifiedHeader := "Modified"
+		stagedHeader := "Staged"
+		unstagedHeader := "Unstaged"
 		locationHeader := "Location"
 
-		// Add indicator to active column
+		// Staged and Unstaged aren't sort keys, so the Name header shows the active sort
 		switch m.sortBy {
 		case "name":
 			nameHeader += sortIndicator
-		case "size":
-			sizeHeader += sortIndicator
 		case "modified":
-			modifiedHeader += sortIndicator
+			nameHeader += " (date" + sortIndicator + ")"
+		default:
+			nameHeader += " (" + m.sortBy + sortIndicator + ")"
 		}
 
 		paddedNameHeader := m.padToVisualWidth(nameHeader, nameWidth)
-		header = fmt.Sprintf("%s  %-*s  %-*s  %-*s", paddedNameHeader, sizeWidth, sizeHeader, modifiedWidth, modifiedHeader, extraWidth, locationHeader)
+		header = fmt.Sprintf("%s  %-*s  %-*s  %-*s", paddedNameHeader, sizeWidth, stagedHeader, modifiedWidth, unstagedHeader, extraWidth, locationHeader)
 	} else {
 		// Regular mode: Name, Size, Modified, Type
 		nameHeader := "Name"
@@ -458,6 +464,9 @@ func (m model) renderDetailView(maxVisible int) string {
 		if m.isFavorite(file.path) {
 			favIndicator = "⭐"
 		}
+		if m.stashMarked[file.path] {
+			favIndicator += "📦"
+		}
 
 		// Truncate long names based on dynamic width
 		displayName := file.name
@@ -484,8 +493,14 @@ func (m model) renderDetailView(maxVisible int) string {
 		if maxNameTextLen < 10 {
 			maxNameTextLen = 10
 		}
-		if visualWidth(displayName) > maxNameTextLen {
-			displayName = truncateToWidth(displayName, maxNameTextLen-2) + ".."
+		// Leave room for the git status marker (plain text so the row keeps one style)
+		gitMarker := m.gitStatusMarker(file)
+		nameTextLen := maxNameTextLen
+		if gitMarker != "" {
+			nameTextLen -= visualWidth(gitMarker) + 1
+		}
+		if visualWidth(displayName) > nameTextLen {
+			displayName = truncateToWidth(displayName, nameTextLen-2) + ".."
 		}
 
 		// Extract leading emoji for global virtual folders to preserve color
@@ -504,6 +519,9 @@ func (m model) renderDetailView(maxVisible int) string {
 		// Pad icon to 2 cells for consistent alignment across different emoji widths
 		paddedIcon := m.padIconToWidth(icon)
 		name := fmt.Sprintf("%s%s %s", paddedIcon, favIndicator, displayName)
+		if gitMarker != "" {
+			name += " " + gitMarker
+		}
 		size := "-"
 		if file.isDir {
 			// Show item count for directories
@@ -536,8 +554,8 @@ func (m model) renderDetailView(maxVisible int) string {
 
 			// Look up original location from trash metadata
 			location := "-"
-			if trashItem, found := getTrashItemByPath(m.trashItems, file.path); found {
-				location = filepath.Dir(trashItem.OriginalPath)
+			if originalPath, found := trashOriginalPath(m.trashItems, file.path); found {
+				location = filepath.Dir(originalPath)
 				// Shorten home directory to ~
 				homeDir, _ := os.UserHomeDir()
 				if homeDir != "" && strings.HasPrefix(location, homeDir) {
@@ -668,7 +686,8 @@ func (m model) renderDetailView(maxVisible int) string {
 			paddedName := m.padToVisualWidth(name, nameWidth)
 			line = fmt.Sprintf("%s  %-*s  %-*s", paddedName, modifiedWidth, modified, extraWidth, desc)
 		} else if m.showChangesOnly {
-			// Changes mode: Name (includes status prefix), Size, Modified, Location
+			// Changes mode: Name (includes status prefix), Staged, Unstaged, Location
+			staged, unstaged := changeStatusColumns(file)
 			location := filepath.Dir(file.path)
 			homeDir, _ := os.UserHomeDir()
 			if homeDir != "" && strings.HasPrefix(location, homeDir) {
@@ -691,7 +710,7 @@ func (m model) renderDetailView(maxVisible int) string {
 				location = "..." + location[len(location)-(extraWidth-3):]
 			}
 			paddedName := m.padToVisualWidth(name, nameWidth)
-			line = fmt.Sprintf("%s  %-*s  %-*s  %-*s", paddedName, sizeWidth, size, modifiedWidth, modified, extraWidth, location)
+			line = fmt.Sprintf("%s  %-*s  %-*s  %-*s", paddedName, sizeWidth, staged, modifiedWidth, unstaged, extraWidth, location)
 		} else {
 			// Regular mode: Name, Size, Modified, Type
 			fileType := getFileType(file)
@@ -840,8 +859,13 @@ func (m model) applyHorizontalScroll(content string, viewWidth int) string {
 // extractVisiblePortion extracts the visible portion of a line based on scroll offset
 // Properly handles ANSI escape codes and multi-column characters (emojis)
 func (m model) extractVisiblePortion(line string, viewWidth int) string {
+	return m.extractVisibleColumns(line, m.detailScrollX, viewWidth)
+}
+
+// extractVisibleColumns extracts viewWidth columns of line starting at column scrollOffset
+// Used by: detail view (detailScrollX), CSV table preview (csvTableState.scrollX)
+func (m model) extractVisibleColumns(line string, scrollOffset, viewWidth int) string {
 	// Calculate visible window based on scroll offset
-	scrollOffset := m.detailScrollX
 	if scrollOffset < 0 {
 		scrollOffset = 0
 	}
@@ -1182,6 +1206,9 @@ func (m model) renderTreeView(maxVisible int) string {
 		if m.isFavorite(file.path) {
 			favIndicator = "⭐"
 		}
+		if m.stashMarked[file.path] {
+			favIndicator += "📦"
+		}
 
 		// Truncate long filenames to prevent wrapping
 		displayName := file.name
@@ -1279,6 +1306,9 @@ func (m model) renderTreeView(maxVisible int) string {
 			}
 		}
 
+		// Git status marker (M, A, ??, ● for folders with changes)
+		line += m.gitStatusBadge(file)
+
 		s.WriteString(line)
 		s.WriteString("\033[0m") // Reset ANSI codes
 		s.WriteString("\n")
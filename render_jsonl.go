@@ -448,19 +448,7 @@ func (m *model) loadJSONLPreview(path string, fileSize int64) {
 	}
 
 	// Parse JSON messages once (expensive part)
-	rawLines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
-	messages := make([]jsonlMessage, 0, len(rawLines))
-	for _, line := range rawLines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		var msg jsonlMessage
-		if err := json.Unmarshal([]byte(line), &msg); err != nil {
-			continue
-		}
-		messages = append(messages, msg)
-	}
+	messages := parseJSONLMessages(strings.Split(strings.TrimRight(string(data), "\n"), "\n"))
 
 	m.preview.isJSONL = true
 	m.preview.cachedJSONLMessages = messages
@@ -485,11 +473,9 @@ func renderJSONLFromMessages(messages []jsonlMessage, width int, isTailed bool,
 	return rendered
 }
 
-// renderJSONLPreview renders a JSONL conversation in the preview pane with
-// scrolling, scrollbar, and color-coded messages.
-func (m model) renderJSONLPreview(maxVisible int) string {
-	var s strings.Builder
-
+// jsonlContentWidth returns the width conversations are rendered at in the preview
+// Used by: renderJSONLPreview, previewDisplayLines (search)
+func (m model) jsonlContentWidth() int {
 	var boxContentWidth int
 	if m.viewMode == viewFullPreview {
 		boxContentWidth = m.width - 6
@@ -500,13 +486,16 @@ func (m model) renderJSONLPreview(maxVisible int) string {
 		// Horizontal split: box is Width(m.rightWidth - 2)
 		boxContentWidth = m.rightWidth - 2
 	}
-	availableWidth := boxContentWidth - 2 // scrollbar + space
-	if availableWidth < 20 {
-		availableWidth = 20
-	}
+	return max(20, boxContentWidth-2) // scrollbar + space
+}
+
+// renderJSONLPreview renders a JSONL conversation in the preview pane with
+// scrolling, scrollbar, and color-coded messages.
+func (m model) renderJSONLPreview(maxVisible int) string {
+	var s strings.Builder
 
 	// Render from cached parsed messages (JSON parsing already done)
-	renderedLines := renderJSONLFromMessages(m.preview.cachedJSONLMessages, availableWidth, m.preview.cachedJSONLIsTailed, m.preview.fileSize)
+	renderedLines := renderJSONLFromMessages(m.preview.cachedJSONLMessages, m.jsonlContentWidth(), m.preview.cachedJSONLIsTailed, m.preview.fileSize)
 	if len(renderedLines) == 0 {
 		emptyStyle := lipgloss.NewStyle().
 			Foreground(uiSubtleText()).
@@ -552,7 +541,7 @@ func (m model) renderJSONLPreview(maxVisible int) string {
 
 	for i := start; i < end; i++ {
 		scrollbar := m.renderScrollbar(i-start, maxVisible, totalLines)
-		renderedLine := scrollbar + " " + renderedLines[i] + "\033[0m"
+		renderedLine := scrollbar + " " + m.highlightSearchLine(renderedLines[i], i) + "\033[0m"
 		writeLine(renderedLine)
 	}
 
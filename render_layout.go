@@ -157,11 +157,36 @@ func (m model) renderPreviewOnly() string {
 		Padding(0, 1)
 
 	titleText := m.preview.fileName
-	if m.preview.tooLarge || m.preview.isBinary {
+	if m.stash != nil {
+		titleText = m.stashTitle()
+	} else if m.gitLog != nil {
+		titleText = m.gitLogTitle()
+	} else if m.preview.history != nil {
+		titleText += " [History]"
+	} else if m.preview.hex != nil {
+		titleText += " [Hex]"
+	} else if m.preview.blame != nil {
+		titleText += " [Blame]"
+	} else if m.preview.compare != nil {
+		titleText += " [Compare]"
+	} else if m.preview.image != nil {
+		titleText += " [Image]"
+	} else if m.preview.tooLarge || m.preview.isBinary {
 		titleText += " [Cannot Preview]"
+	} else if m.preview.pager != nil {
+		titleText += " [Pager]"
+	} else if m.preview.table != nil {
+		titleText += " [Table]"
+	} else if m.preview.db != nil {
+		titleText += " [SQLite]"
+	} else if m.dataTreeActive() {
+		titleText += " [Tree]"
+	} else if m.preview.dir != nil {
+		titleText += " [Folder]"
 	} else if m.preview.isMarkdown {
 		titleText += " [Markdown]"
 	}
+	titleText += m.followBadge()
 	s.WriteString(previewTitleStyle.Render(titleText))
 	s.WriteString("\033[0m")
 	s.WriteString("\n")
@@ -189,15 +214,68 @@ func (m model) renderPreviewOnly() string {
 
 	// Minimal help line
 	helpStyle := lipgloss.NewStyle().Foreground(uiSubtleText()).PaddingLeft(2)
-	helpText := "q/Esc: quit | j/k: scroll | Ctrl+F: search"
+	helpText := "q/Esc: quit | j/k: scroll | Ctrl+F: search | x: hex"
+	if s := m.stash; s != nil && s.view != nil {
+		helpText = "q: quit | j/k: scroll | a: apply | p: pop | x: drop | Backspace: back to stashes"
+	} else if m.stash != nil {
+		helpText = "q: quit | j/k: select stash | Enter: diff | a: apply | p: pop | x: drop | S/Esc: close"
+	} else if l := m.gitLog; l != nil && l.commit != nil && l.commit.file != nil {
+		helpText = "q: quit | j/k: scroll | D: side-by-side | Backspace: back to commit"
+	} else if l := m.gitLog; l != nil && l.commit != nil {
+		helpText = "q: quit | j/k: select file | Enter: file diff | Space: scroll | D: side-by-side | Backspace: back to log"
+	} else if m.gitLog != nil {
+		helpText = "q: quit | j/k: select commit | Enter: files and diff | a/p/t: author/path/text filter | c: clear | L/Esc: close"
+	} else if h := m.preview.history; h != nil && h.view != nil {
+		helpText = "q: quit | j/k: scroll | v: diff/file | R: restore this version | Backspace: back to history"
+	} else if m.preview.history != nil {
+		helpText = "q: quit | j/k: select commit | Enter: diff | v: file at commit | R: restore | L/Esc: close"
+	} else if m.preview.hex != nil {
+		helpText += " | :: jump (offset, %, $)"
+	} else if m.preview.blame != nil && m.preview.blame.detail != nil {
+		helpText = "q/Esc: quit | j/k: scroll | Backspace: back to blame | Ctrl+F: search"
+	} else if m.preview.blame != nil {
+		helpText = "q: quit | j/k: select line | Enter: show commit | b/Esc: hide blame | Ctrl+F: search"
+	} else if m.preview.compare != nil {
+		helpText = "q/Esc: quit | j/k: scroll | [/]: prev/next hunk | D: side-by-side | =: close compare | Ctrl+F: search"
+	} else if m.preview.image != nil && m.preview.anim != nil {
+		helpText = "q/Esc: quit | p/Space: play/pause | [/]: frame | b: half/quadrant blocks | x: hex"
+	} else if m.preview.image != nil {
+		helpText = "q/Esc: quit | b: half/quadrant blocks | x: hex"
+	} else if m.preview.anim != nil {
+		helpText = "q/Esc: quit | p/Space: play/pause | [/]: frame | x: hex"
+	} else if m.preview.table != nil {
+		helpText = "q/Esc: quit | j/k: scroll | h/l: scroll sideways | [/]: column | s: sort | .: filter | i: stats"
+	} else if m.preview.db != nil && m.preview.db.grid != nil {
+		helpText = "q/Esc: quit | j/k: scroll | h/l: scroll sideways | Backspace: schema | .: SQL query"
+	} else if m.preview.db != nil {
+		helpText = "q/Esc: quit | j/k: select | Enter: browse rows | .: SQL query | y: copy schema"
+	} else if m.dataTreeActive() {
+		helpText = "q/Esc: quit | j/k: move | h/l: fold | .: filter | y/Y: copy path/value | t: text"
+	} else if m.markdownPanelOpen() {
+		helpText = "Esc: close | ↑/↓: select | Enter: go | Tab: outline/links | type to filter"
+	} else if m.preview.dir != nil {
+		helpText = "q/Esc: quit | j/k: scroll | Ctrl+F: search"
+	} else if m.preview.follow != nil {
+		helpText = "q/Esc: quit | j/k: scroll (up pauses) | G: resume | w: stop following | Ctrl+F: search"
+	} else if m.preview.markdown != nil {
+		helpText = "q/Esc: quit | j/k: scroll | o: outline | l: links | [/]: prev/next heading | Backspace: back"
+	} else if m.preview.pager != nil {
+		helpText += " | :: jump (line, %, $) | w: follow"
+	} else if !m.preview.isBinary && !m.preview.tooLarge {
+		helpText += " | w: follow | b: blame | L: history"
+	}
 	if m.visualWidthCompensated(helpText) > m.width-4 {
 		helpText = m.truncateToWidthCompensated(helpText, m.width-4)
 	}
 	s.WriteString(helpStyle.Render(helpText))
 	s.WriteString("\033[0m")
 
-	// Show search input if search is active
-	if m.preview.searchActive {
+	// Show pager jump prompt or search input if active
+	if m.preview.gotoActive {
+		s.WriteString("\n")
+		s.WriteString(m.renderPagerGotoPrompt())
+		s.WriteString("\033[0m")
+	} else if m.preview.searchActive {
 		s.WriteString("\n")
 		searchStyle := lipgloss.NewStyle().
 			Background(uiInfoBackground()).
@@ -205,15 +283,13 @@ func (m model) renderPreviewOnly() string {
 			Bold(true).
 			Padding(0, 1)
 
-		matchCount := len(m.preview.searchMatches)
 		var searchText string
-		if matchCount > 0 {
-			currentPos := m.preview.currentMatch + 1
-			searchText = fmt.Sprintf("Search: %s (match %d/%d)", m.preview.searchQuery, currentPos, matchCount)
-		} else if m.preview.searchQuery == "" {
-			searchText = "Search: (type to search, n/N: navigate, Esc: close)"
+		if m.preview.hex != nil {
+			searchText = m.hexSearchText()
+		} else if m.preview.pager != nil {
+			searchText = m.pagerSearchText()
 		} else {
-			searchText = fmt.Sprintf("Search: %s (no matches)", m.preview.searchQuery)
+			searchText = m.previewSearchText("")
 		}
 
 		if m.visualWidthCompensated(searchText) > m.width-4 {
@@ -242,14 +318,39 @@ func (m model) renderFullPreview() string {
 			Padding(0, 1)
 
 		titleText := fmt.Sprintf("Preview: %s", m.preview.fileName)
-		if m.preview.tooLarge || m.preview.isBinary {
+		if m.stash != nil {
+			titleText = m.stashTitle()
+		} else if m.gitLog != nil {
+			titleText = m.gitLogTitle()
+		} else if m.preview.history != nil {
+			titleText += " [History]"
+		} else if m.preview.hex != nil {
+			titleText += " [Hex]"
+		} else if m.preview.blame != nil {
+			titleText += " [Blame]"
+		} else if m.preview.compare != nil {
+			titleText += " [Compare]"
+		} else if m.preview.image != nil {
+			titleText += " [Image]"
+		} else if m.preview.tooLarge || m.preview.isBinary {
 			titleText += " [Cannot Preview]"
+		} else if m.preview.pager != nil {
+			titleText += " [Pager]"
+		} else if m.preview.table != nil {
+			titleText += " [Table]"
+		} else if m.preview.db != nil {
+			titleText += " [SQLite]"
+		} else if m.dataTreeActive() {
+			titleText += " [Tree]"
+		} else if m.preview.dir != nil {
+			titleText += " [Folder]"
 		}
 		if m.preview.isPrompt {
 			titleText += " [Prompt Template]"
 		} else if m.preview.isMarkdown {
 			titleText += " [Markdown]"
 		}
+		titleText += m.followBadge()
 		s.WriteString(previewTitleStyle.Render(titleText))
 		s.WriteString("\033[0m") // Reset ANSI codes
 		s.WriteString("\n")
@@ -287,7 +388,47 @@ func (m model) renderFullPreview() string {
 			}
 		}
 
-		if m.preview.isMarkdown {
+		if m.stash != nil {
+			infoText = fmt.Sprintf("%s (%d%%)", m.stashStatusText(), scrollPercent)
+		} else if m.gitLog != nil {
+			infoText = fmt.Sprintf("%s (%d%%)", m.gitLogStatusText(), scrollPercent)
+		} else if m.preview.history != nil {
+			infoText = fmt.Sprintf("Size: %s | %s (%d%%)", formatFileSize(m.preview.fileSize), m.historyStatusText(), scrollPercent)
+		} else if m.preview.hex != nil {
+			infoText = fmt.Sprintf("Size: %s | %s (%d%%)",
+				formatFileSize(m.preview.fileSize),
+				m.hexStatusText(),
+				scrollPercent)
+		} else if m.preview.blame != nil {
+			infoText = fmt.Sprintf("Size: %s | %s (%d%%)", formatFileSize(m.preview.fileSize), m.blameStatusText(), scrollPercent)
+		} else if m.preview.compare != nil {
+			infoText = fmt.Sprintf("%s (%d%%)", m.compareStatusText(), scrollPercent)
+		} else if m.preview.image != nil {
+			infoText = fmt.Sprintf("Size: %s | %s", formatFileSize(m.preview.fileSize), m.imageStatusText())
+		} else if m.preview.anim != nil {
+			infoText = fmt.Sprintf("Size: %s | GIF | %s", formatFileSize(m.preview.fileSize), m.imageAnimationStatus())
+		} else if m.preview.table != nil {
+			// Rows shown = header and separator lines excluded
+			infoText = fmt.Sprintf("Size: %s | %s (%d%%)",
+				formatFileSize(m.preview.fileSize),
+				m.csvTableStatusText(max(0, lastVisibleLine-2)),
+				scrollPercent)
+		} else if m.preview.db != nil {
+			infoText = fmt.Sprintf("Size: %s | %s (%d%%)",
+				formatFileSize(m.preview.fileSize),
+				m.dbBrowserStatusText(lastVisibleLine),
+				scrollPercent)
+		} else if m.dataTreeActive() {
+			infoText = fmt.Sprintf("Size: %s | %s", formatFileSize(m.preview.fileSize), m.dataTreeStatusText())
+		} else if m.preview.pager != nil {
+			// Pager: line count grows while the file is being indexed
+			infoText = fmt.Sprintf("Size: %s | Streaming from disk | %s (%d%%)",
+				formatFileSize(m.preview.fileSize),
+				m.pagerStatusText(lastVisibleLine),
+				scrollPercent)
+		} else if m.markdownPanelOpen() {
+			infoText = fmt.Sprintf("Size: %s | %s", formatFileSize(m.preview.fileSize), m.markdownStatusText())
+		} else if m.preview.isMarkdown {
 			// Show scroll position for markdown too
 			if totalLines > 0 {
 				infoText = fmt.Sprintf("Size: %s | Markdown Rendered | Line %d/%d (%d%%)",
@@ -376,8 +517,57 @@ func (m model) renderFullPreview() string {
 	}
 
 	// Build help text
-	if m.preview.isBinary && isImageFile(m.preview.filePath) {
-		helpText = fmt.Sprintf("F1: help • V: view image • m: %s • F4: edit • Esc: close", modeText)
+	if s := m.stash; s != nil && s.view != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • a: apply • p: pop • x: drop • Backspace/Esc: back to stashes • m: %s", modeText)
+	} else if m.stash != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: select stash • Enter: diff • a: apply • p: pop • x: drop • S/Esc: close stashes • m: %s", modeText)
+	} else if l := m.gitLog; l != nil && l.commit != nil && l.commit.file != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • D: side-by-side • Backspace/Esc: back to commit • m: %s", modeText)
+	} else if l := m.gitLog; l != nil && l.commit != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: select file • Enter: file diff • Space: scroll • D: side-by-side • Backspace/Esc: back to log • m: %s", modeText)
+	} else if m.gitLog != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: select commit • Enter: files and diff • a/p/t: filter author/path/text • c: clear • L/Esc: close log • m: %s", modeText)
+	} else if h := m.preview.history; h != nil && h.view != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • v: diff/file • R: restore this version • Backspace/Esc: back to history • m: %s", modeText)
+	} else if m.preview.history != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: select commit • Enter: diff • v: file at commit • R: restore • L/Esc: close history • m: %s", modeText)
+	} else if m.preview.blame != nil && m.preview.blame.detail != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • Backspace/Esc: back to blame • Ctrl+F: search • m: %s", modeText)
+	} else if m.preview.blame != nil && m.preview.hex == nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: select line • Enter: show commit • b/Esc: hide blame • Ctrl+F: search • m: %s", modeText)
+	} else if m.preview.compare != nil && m.preview.hex == nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • [/]: prev/next hunk • D: side-by-side • =: close compare • Ctrl+F: search • m: %s • Esc: close", modeText)
+	} else if m.preview.hex != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • :: jump to offset • Ctrl+F: search bytes • x: exit hex • m: %s • Esc: close", modeText)
+	} else if m.preview.anim != nil {
+		helpText = "F1: help • p/Space: play/pause • [/]: step frame • V: view image • x: hex • Esc: close"
+		if m.preview.image != nil {
+			helpText = "F1: help • p/Space: play/pause • [/]: step frame • b: half/quadrant blocks • V: view image • x: hex • Esc: close"
+		}
+	} else if m.preview.image != nil {
+		helpText = fmt.Sprintf("F1: help • b: half/quadrant blocks • V: view image • x: hex • m: %s • F4: edit • Esc: close", modeText)
+	} else if m.preview.isBinary && isImageFile(m.preview.filePath) {
+		helpText = fmt.Sprintf("F1: help • V: view image • x: hex • m: %s • F4: edit • Esc: close", modeText)
+	} else if m.preview.table != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • ←/→: scroll sideways • [/]: column • s: sort • .: filter • i: stats • F4: VisiData • m: %s • Esc: close", modeText)
+	} else if m.preview.db != nil && m.preview.db.grid != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • ←/→: scroll sideways • Backspace: schema • .: SQL query • x: hex • m: %s • Esc: close", modeText)
+	} else if m.preview.db != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: select • Enter: browse rows • .: SQL query • y: copy schema • x: hex • F4: harlequin • m: %s • Esc: close", modeText)
+	} else if m.dataTreeActive() {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: move • ←/→: fold • +/-: all • .: filter • y: copy path • Y: copy value • t: text • m: %s • Esc: close", modeText)
+	} else if m.markdownPanelOpen() {
+		helpText = "↑/↓: select • Enter: go • Tab: outline/links • type to filter • Backspace: delete • Esc: close list"
+	} else if m.preview.dir != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • Ctrl+F: search • m: %s • Esc: close", modeText)
+	} else if m.preview.follow != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll (↑ pauses) • G: resume • w: stop following • Ctrl+F: search • m: %s • Esc: close", modeText)
+	} else if m.preview.markdown != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • o: outline • l: links • [/]: prev/next heading • Backspace: back • m: %s • F4: edit • F5: %s • Esc: close", modeText, f5Text)
+	} else if m.preview.pager != nil {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • :: jump (line, %%, $) • g/G: top/end • Ctrl+F: search • w: follow • m: %s • F4: edit • Esc: close", modeText)
+	} else if !m.preview.isBinary && !m.preview.tooLarge {
+		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • w: follow • b: blame • L: history • m: %s • F4: edit • F5: %s • Esc: close", modeText, f5Text)
 	} else {
 		helpText = fmt.Sprintf("F1: help • ↑/↓: scroll • m: %s • F4: edit • F5: %s • Esc: close", modeText, f5Text)
 	}
@@ -386,8 +576,12 @@ func (m model) renderFullPreview() string {
 	s.WriteString(helpStyle.Render(helpText))
 	s.WriteString("\033[0m") // Reset ANSI codes
 
-	// Show search input if search is active
-	if m.preview.searchActive {
+	// Show pager jump prompt or search input if active
+	if m.preview.gotoActive {
+		s.WriteString("\n")
+		s.WriteString(m.renderPagerGotoPrompt())
+		s.WriteString("\033[0m") // Reset ANSI codes
+	} else if m.preview.searchActive {
 		s.WriteString("\n")
 		searchStyle := lipgloss.NewStyle().
 			Background(uiInfoBackground()).
@@ -395,15 +589,13 @@ func (m model) renderFullPreview() string {
 			Bold(true).
 			Padding(0, 1)
 
-		matchCount := len(m.preview.searchMatches)
 		var searchText string
-		if matchCount > 0 {
-			currentPos := m.preview.currentMatch + 1
-			searchText = fmt.Sprintf("🔍 Search: %s█ (%d/%d matches)", m.preview.searchQuery, currentPos, matchCount)
-		} else if m.preview.searchQuery == "" {
-			searchText = "🔍 Search: █ (type to search, n/Shift+N: navigate, Esc: exit)"
+		if m.preview.hex != nil {
+			searchText = "🔍 " + m.hexSearchText()
+		} else if m.preview.pager != nil {
+			searchText = "🔍 " + m.pagerSearchText()
 		} else {
-			searchText = fmt.Sprintf("🔍 Search: %s█ (no matches)", m.preview.searchQuery)
+			searchText = "🔍 " + m.previewSearchText("█")
 		}
 
 		// Truncate search text to terminal width to prevent wrapping/corruption
@@ -453,6 +645,10 @@ func (m model) renderDualPane() string {
 		if m.filePickerMode {
 			if m.filePickerCopySource != "" {
 				titleText += " [📋 Copy Mode - Select Destination]"
+			} else if m.filePickerRestoreSource != "" {
+				titleText += " [♻ Restore - Select Destination]"
+			} else if m.filePickerCompareSource != "" {
+				titleText += " [⚖ Compare - Select File]"
 			} else {
 				titleText += " [📁 File Picker]"
 			}
@@ -809,6 +1005,11 @@ func (m model) renderDualPane() string {
 		favoritesIndicator = " • ⭐ favorites only"
 	}
 
+	trashIndicator := ""
+	if m.showTrashOnly {
+		trashIndicator = fmt.Sprintf(" • 🗑 %s in trash (%s)", formatFileSize(m.trashTotalSize), m.config.trashPolicy())
+	}
+
 	promptsIndicator := ""
 	if m.showPromptsOnly {
 		promptsIndicator = " • 📝 prompts only"
@@ -822,7 +1023,9 @@ func (m model) renderDualPane() string {
 	changesIndicator := ""
 	if m.showChangesOnly {
 		diffMode := "file"
-		if m.showDiffPreview {
+		if m.showDiffPreview && m.diffSideBySide {
+			diffMode = "side-by-side"
+		} else if m.showDiffPreview {
 			diffMode = "diff"
 		}
 		changesIndicator = fmt.Sprintf(" • ⚡ %d changes [%s]", len(m.changedFiles), diffMode)
@@ -895,7 +1098,7 @@ func (m model) renderDualPane() string {
 
 	// Split status into two lines to prevent truncation
 	// Line 1: Counts, indicators, view mode, focus, help
-	statusLine1 := fmt.Sprintf("%s%s%s%s%s%s%s • %s%s%s", itemsInfo, hiddenIndicator, favoritesIndicator, promptsIndicator, gitReposIndicator, changesIndicator, tabsIndicator, m.displayMode.String(), focusInfo, helpHint)
+	statusLine1 := fmt.Sprintf("%s%s%s%s%s%s%s%s • %s%s%s", itemsInfo, hiddenIndicator, favoritesIndicator, trashIndicator, promptsIndicator, gitReposIndicator, changesIndicator, tabsIndicator, m.displayMode.String(), focusInfo, helpHint)
 	// Use scrolling footer (click to activate) or truncate if too long
 	statusLine1 = m.renderScrollingFooter(statusLine1, m.width-4)
 	s.WriteString(statusStyle.Render(statusLine1))
@@ -19,11 +19,39 @@ import (
 func (m model) renderPreview(maxVisible int) string {
 	var s strings.Builder
 
+	// Stash browser covers the full preview, even when no file is loaded
+	if m.stash != nil {
+		return m.renderStashPreview(maxVisible)
+	}
+	if m.gitLog != nil {
+		return m.renderGitLogPreview(maxVisible)
+	}
+
 	if !m.preview.loaded {
 		s.WriteString("No file loaded")
 		return s.String()
 	}
 
+	// File history browser overlays every other preview type while open
+	if m.preview.history != nil {
+		return m.renderHistoryPreview(maxVisible)
+	}
+
+	// Hex view overlays every other preview type while toggled on
+	if m.preview.hex != nil {
+		return m.renderHexPreview(maxVisible)
+	}
+
+	// Git blame overlay (or the commit opened from it)
+	if m.preview.blame != nil {
+		return m.renderBlamePreview(maxVisible)
+	}
+
+	// Comparison with another file (see compare.go)
+	if m.preview.compare != nil {
+		return m.renderComparePreview(maxVisible)
+	}
+
 	// If this is a prompt file, show metadata header
 	if m.preview.isPrompt && m.preview.promptTemplate != nil {
 		return m.renderPromptPreview(maxVisible)
@@ -39,6 +67,36 @@ func (m model) renderPreview(maxVisible int) string {
 		return m.renderJSONLPreview(maxVisible)
 	}
 
+	// CSV/TSV table view
+	if m.preview.table != nil {
+		return m.renderCSVTablePreview(maxVisible)
+	}
+
+	// SQLite schema list / row grid
+	if m.preview.db != nil {
+		return m.renderDBBrowserPreview(maxVisible)
+	}
+
+	// Images drawn with block characters (no graphics protocol)
+	if m.preview.image != nil {
+		return m.renderImagePreview(maxVisible)
+	}
+
+	// JSON/YAML/TOML tree view
+	if m.dataTreeActive() {
+		return m.renderDataTreePreview(maxVisible)
+	}
+
+	// Markdown outline / link list
+	if m.markdownPanelOpen() {
+		return m.renderMarkdownPanel(maxVisible)
+	}
+
+	// Large files stream from disk (only the visible window is read)
+	if m.preview.pager != nil {
+		return m.renderPagerPreview(maxVisible)
+	}
+
 	// Calculate available width for content based on file type and view mode
 	var availableWidth int
 	var boxContentWidth int // Width of the box content area
@@ -107,7 +165,7 @@ func (m model) renderPreview(maxVisible int) string {
 			}
 
 			for i := start; i < end; i++ {
-				line := renderedLines[i]
+				line := m.highlightSearchLine(renderedLines[i], i)
 
 				// Add scrollbar indicator for markdown files (since they don't have line numbers)
 				scrollbar := m.renderScrollbar(outputLines, targetLines, totalLines)
@@ -240,7 +298,7 @@ func (m model) renderPreview(maxVisible int) string {
 		renderedLine += " "
 
 		// Content line - ensure it doesn't exceed available width to prevent wrapping
-		contentLine := wrappedLines[i]
+		contentLine := m.highlightSearchLine(wrappedLines[i], i)
 
 		// IMPORTANT: Don't truncate graphics protocol data - it contains escape sequences
 		// that must remain intact. Only truncate regular text content.
@@ -307,18 +365,9 @@ func (m model) renderPreview(maxVisible int) string {
 func (m model) renderDiffPreview(maxVisible int) string {
 	var s strings.Builder
 
-	// Calculate widths (same logic as normal preview)
-	var boxContentWidth int
-	if m.viewMode == viewFullPreview {
-		boxContentWidth = m.width - 6
-	} else {
-		boxContentWidth = m.rightWidth - 2
-	}
-
-	// Diff lines: scrollbar (1) + space (1) = 2 chars overhead (no line numbers for diff)
-	availableWidth := boxContentWidth - 2
-	if availableWidth < 20 {
-		availableWidth = 20
+	// A hunk picked for staging shows the staged or unstaged diff it belongs to
+	if m.activeHunkSelection() != nil {
+		return m.renderHunkSelection(maxVisible)
 	}
 
 	// Get the current file's git status code by matching preview path against changedFiles
@@ -350,24 +399,31 @@ func (m model) renderDiffPreview(maxVisible int) string {
 		return s.String()
 	}
 
-	// Split diff into lines and wrap
-	rawLines := strings.Split(strings.TrimRight(diffOutput, "\n"), "\n")
-	var wrappedLines []string
-	// Track which style applies to each wrapped line (inherit from source line)
-	var lineStyles []int // 0=normal, 1=added, 2=removed, 3=hunk, 4=meta
-	for _, line := range rawLines {
-		style := classifyDiffLine(line)
-		wrapped := wrapLine(line, availableWidth)
-		for range wrapped {
-			wrappedLines = append(wrappedLines, "")
-			lineStyles = append(lineStyles, style)
-		}
-		// Replace empty placeholders with actual wrapped content
-		startIdx := len(wrappedLines) - len(wrapped)
-		for j, w := range wrapped {
-			wrappedLines[startIdx+j] = w
-		}
+	return m.renderDiffLines(strings.Split(strings.TrimRight(diffOutput, "\n"), "\n"), maxVisible, "diff")
+}
+
+// diffWrapWidth returns the width diff lines are wrapped to
+// (scrollbar (1) + space (1) = 2 chars overhead, no line numbers for diff)
+func (m model) diffWrapWidth() int {
+	var boxContentWidth int
+	if m.viewMode == viewFullPreview {
+		boxContentWidth = m.width - 6
+	} else {
+		boxContentWidth = m.rightWidth - 2
 	}
+	return max(boxContentWidth-2, 20)
+}
+
+// renderDiffLines renders colorized diff lines (git diff or git show output) with a
+// scrollbar; label names the view in the dual-pane scroll indicator
+func (m model) renderDiffLines(rawLines []string, maxVisible int, label string) string {
+	return m.renderDiffDisplayLines(m.diffDisplayLines(rawLines), maxVisible, label)
+}
+
+// renderDiffDisplayLines renders diff lines already laid out by diffDisplayLines
+func (m model) renderDiffDisplayLines(wrappedLines []string, maxVisible int, label string) string {
+	var s strings.Builder
+	availableWidth := m.diffWrapWidth()
 
 	// Calculate visible range based on scroll position
 	totalLines := len(wrappedLines)
@@ -408,23 +464,12 @@ func (m model) renderDiffPreview(maxVisible int) string {
 		// Space after scrollbar
 		renderedLine := scrollbar + " "
 
-		// Colorize the diff line based on its type
-		contentLine := wrappedLines[i]
+		// Lines come colorized (and laid out side by side when enabled)
+		contentLine := m.highlightSearchLine(wrappedLines[i], i)
 		if visualWidth(contentLine) > availableWidth {
 			contentLine = truncateToWidth(contentLine, availableWidth)
 		}
 
-		switch lineStyles[i] {
-		case 1: // Added
-			contentLine = diffAddedStyle.Render(contentLine)
-		case 2: // Removed
-			contentLine = diffRemovedStyle.Render(contentLine)
-		case 3: // Hunk header
-			contentLine = diffHunkHeaderStyle.Render(contentLine)
-		case 4: // Meta/header
-			contentLine = diffMetaStyle.Render(contentLine)
-		}
-
 		renderedLine += contentLine
 		renderedLine += "\033[0m"
 		writeLine(renderedLine)
@@ -444,7 +489,7 @@ func (m model) renderDiffPreview(maxVisible int) string {
 		}
 
 		lastVisibleLine := end
-		scrollIndicator := fmt.Sprintf(" %d/%d (%d%%) [diff]", lastVisibleLine, totalLines, scrollPercent)
+		scrollIndicator := fmt.Sprintf(" %d/%d (%d%%) [%s]", lastVisibleLine, totalLines, scrollPercent, label)
 		scrollStyle := lipgloss.NewStyle().
 			Foreground(uiSubtleText()).
 			Italic(true)
@@ -10,6 +10,7 @@ package main
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -34,7 +35,7 @@ const (
 )
 
 // settingsCategories defines the category tabs
-var settingsCategoryNames = []string{"General", "Appearance", "File Watcher"}
+var settingsCategoryNames = []string{"General", "Appearance", "File Watcher", "Trash"}
 
 // settingsByCategory returns the settings items for a given category index
 func settingsByCategory(cat int) []settingsItem {
@@ -42,6 +43,7 @@ func settingsByCategory(cat int) []settingsItem {
 	case 0: // General
 		return []settingsItem{
 			{label: "Show Hidden Files", key: "show_hidden", kind: settingsToggle},
+			{label: "Git Status Markers", key: "git_status_decorations", kind: settingsToggle},
 			{label: "Panel Lock", key: "panel_lock", kind: settingsToggle},
 			{label: "Start in Dual Pane", key: "startup_dual_pane", kind: settingsToggle},
 			{label: "Startup Focus", key: "startup_focus", kind: settingsSelect, options: []string{"files", "preview"}},
@@ -49,16 +51,24 @@ func settingsByCategory(cat int) []settingsItem {
 			{label: "Sort Order", key: "sort_order", kind: settingsSelect, options: []string{"name", "size", "modified", "type"}},
 			{label: "Default View Mode", key: "default_view_mode", kind: settingsSelect, options: []string{"tree", "list", "detail"}},
 			{label: "Editor", key: "editor", kind: settingsString},
+			{label: "Commit Message Command", key: "commit_message_command", kind: settingsString},
 		}
 	case 1: // Appearance
 		return []settingsItem{
 			{label: "Dark Mode", key: "dark_mode", kind: settingsToggle},
+			{label: "Hex Bytes Per Row", key: "hex_bytes_per_row", kind: settingsSelect, options: []string{"8", "16", "24", "32"}},
 		}
 	case 2: // File Watcher
 		return []settingsItem{
 			{label: "File Watcher Enabled", key: "file_watcher_enabled", kind: settingsToggle},
 			{label: "Auto Changes (Agent)", key: "auto_changes", kind: settingsToggle},
 		}
+	case 3: // Trash
+		return []settingsItem{
+			{label: "Keep Items For", key: "trash_max_age_days", kind: settingsSelect, options: []string{"off", "7d", "14d", "30d", "60d", "90d"}},
+			{label: "Max Trash Size", key: "trash_max_size_mb", kind: settingsSelect, options: []string{"off", "256 MB", "512 MB", "1024 MB", "2048 MB", "4096 MB", "10240 MB"}},
+			{label: "Confirm Items Over", key: "trash_confirm_size_mb", kind: settingsSelect, options: []string{"off", "100 MB", "256 MB", "512 MB", "1024 MB"}},
+		}
 	default:
 		return nil
 	}
@@ -73,6 +83,8 @@ func (m model) getConfigBool(key string) bool {
 		return m.config.FileWatcherEnabled
 	case "show_hidden":
 		return m.config.ShowHidden
+	case "git_status_decorations":
+		return m.config.GitStatusDecorations
 	case "panel_lock":
 		return m.config.PanelLock
 	case "startup_dual_pane":
@@ -102,6 +114,10 @@ func (m *model) setConfigBool(key string, val bool) {
 		m.config.ShowHidden = val
 		m.showHidden = val
 		m.loadFiles()
+	case "git_status_decorations":
+		m.config.GitStatusDecorations = val
+		m.invalidateGitStatus()
+		m.loadFiles()
 	case "panel_lock":
 		m.config.PanelLock = val
 		m.panelsLocked = val
@@ -127,11 +143,38 @@ func (m model) getConfigString(key string) string {
 		return fmt.Sprintf("%d%%", m.config.FocusedPaneRatio)
 	case "editor":
 		return m.config.Editor
+	case "commit_message_command":
+		return m.config.CommitMessageCommand
+	case "hex_bytes_per_row":
+		return strconv.Itoa(m.config.HexBytesPerRow)
+	case "trash_max_age_days":
+		return formatTrashLimit(m.config.TrashMaxAgeDays, "d")
+	case "trash_max_size_mb":
+		return formatTrashLimit(m.config.TrashMaxSizeMB, " MB")
+	case "trash_confirm_size_mb":
+		return formatTrashLimit(m.config.TrashConfirmSizeMB, " MB")
 	default:
 		return ""
 	}
 }
 
+// formatTrashLimit renders a trash limit for the settings panel ("off" when disabled)
+func formatTrashLimit(n int, unit string) string {
+	if n <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d%s", n, unit)
+}
+
+// parseTrashLimit parses a settings value like "30d", "512 MB" or "off" back to a number
+func parseTrashLimit(val string) int {
+	var n int
+	if _, err := fmt.Sscanf(strings.TrimSpace(val), "%d", &n); err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
 // setConfigString sets a string config value by key and syncs to model
 func (m *model) setConfigString(key, val string) {
 	switch key {
@@ -160,6 +203,22 @@ func (m *model) setConfigString(key, val string) {
 		}
 	case "editor":
 		m.config.Editor = val
+	case "commit_message_command":
+		m.config.CommitMessageCommand = val
+	case "hex_bytes_per_row":
+		if n, err := strconv.Atoi(val); err == nil {
+			m.config.HexBytesPerRow = n
+			// Re-flow an open hex view, keeping the same offset at the top
+			if m.preview.hex != nil {
+				m.preview.scrollPos = m.preview.hex.setBytesPerRow(n, m.preview.scrollPos)
+			}
+		}
+	case "trash_max_age_days":
+		m.config.TrashMaxAgeDays = parseTrashLimit(val)
+	case "trash_max_size_mb":
+		m.config.TrashMaxSizeMB = parseTrashLimit(val)
+	case "trash_confirm_size_mb":
+		m.config.TrashConfirmSizeMB = parseTrashLimit(val)
 	}
 }
 
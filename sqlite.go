@@ -0,0 +1,929 @@
+package main
+
+// Module: sqlite.go
+// Purpose: Read-only SQLite file reader (pure Go, no cgo)
+// Responsibilities:
+// - Parsing the database header and committed WAL frames
+// - Walking table and index b-trees (overflow pages included)
+// - Decoding records into values
+// - Loading the schema (tables, views, indexes, triggers) and table column layouts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	sqliteHeaderMagic = "SQLite format 3\x00"
+	sqliteCachePages  = 512     // Pages cached per connection
+	sqliteMaxDepth    = 64      // B-tree depth limit (guards against corrupt page loops)
+	sqliteMaxPayload  = 1 << 30 // Largest record read (1GB)
+)
+
+// sqlValue is a decoded SQLite value: nil, int64, float64, string or []byte
+type sqlValue interface{}
+
+// sqliteDB is an opened database file. It holds only the header, WAL page map
+// and schema - pages are read through a sqliteConn.
+type sqliteDB struct {
+	path      string
+	pageSize  int
+	usable    int              // Page size minus reserved bytes
+	pageCount uint32           // Pages in the database (including committed WAL frames)
+	encoding  uint32           // 1 = UTF-8, 2 = UTF-16le, 3 = UTF-16be
+	walPages  map[uint32]int64 // Latest committed WAL frame offset for each page
+	walFrames int
+	schema    []*sqliteObject
+}
+
+// sqliteObject is one entry of the schema table
+type sqliteObject struct {
+	kind  string // "table", "view", "index" or "trigger"
+	name  string
+	table string // Table the object belongs to (indexes and triggers)
+	root  uint32 // Root b-tree page (0 for views, triggers and virtual tables)
+	sql   string
+
+	// Tables
+	columns      []sqliteColumn
+	stored       []int // Column index of each value in a stored record
+	rowidAlias   int   // Column that aliases the rowid (INTEGER PRIMARY KEY), -1 if none
+	withoutRowid bool
+	virtual      bool // CREATE VIRTUAL TABLE - contents live in shadow tables
+}
+
+// sqliteColumn is a declared table column
+type sqliteColumn struct {
+	name      string
+	declType  string
+	notNull   bool
+	pk        bool
+	generated bool     // VIRTUAL generated column (not stored in records)
+	dflt      sqlValue // DEFAULT value for rows written before an ADD COLUMN
+}
+
+// sqliteConn reads pages of a database. Each background task opens its own.
+type sqliteConn struct {
+	db    *sqliteDB
+	f     *os.File
+	wal   *os.File
+	pages map[uint32][]byte
+}
+
+// isSQLiteFile reports whether path starts with the SQLite header
+func isSQLiteFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	magic := make([]byte, len(sqliteHeaderMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return false
+	}
+	return string(magic) == sqliteHeaderMagic
+}
+
+// openSQLiteDB reads the header, WAL and schema of the database at path
+func openSQLiteDB(path string) (*sqliteDB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 100)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return nil, errors.New("not a SQLite database (file too short)")
+	}
+	if string(header[:16]) != sqliteHeaderMagic {
+		return nil, errors.New("not a SQLite database")
+	}
+
+	db := &sqliteDB{path: path}
+	db.pageSize = int(binary.BigEndian.Uint16(header[16:18]))
+	if db.pageSize == 1 {
+		db.pageSize = 65536
+	}
+	if db.pageSize < 512 || db.pageSize&(db.pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid page size %d", db.pageSize)
+	}
+	db.usable = db.pageSize - int(header[20])
+	if db.usable < 480 {
+		return nil, fmt.Errorf("invalid reserved space %d", header[20])
+	}
+	db.encoding = binary.BigEndian.Uint32(header[56:60])
+	if db.encoding == 0 {
+		db.encoding = 1
+	}
+
+	// The in-header page count is only valid when the change counters agree
+	db.pageCount = binary.BigEndian.Uint32(header[28:32])
+	if db.pageCount == 0 || !bytes.Equal(header[24:28], header[92:96]) {
+		if info, err := f.Stat(); err == nil {
+			db.pageCount = uint32(info.Size() / int64(db.pageSize))
+		}
+	}
+
+	db.readWAL()
+
+	if err := db.loadSchema(); err != nil {
+		return nil, fmt.Errorf("reading schema: %w", err)
+	}
+	return db, nil
+}
+
+// readWAL maps pages to their latest committed frame in the -wal file, so
+// changes that haven't been checkpointed yet are visible. Frames after the
+// last valid commit (or with a bad checksum) are ignored, as SQLite does.
+// Frames are read one at a time, so a large WAL isn't loaded into memory.
+func (db *sqliteDB) readWAL() {
+	f, err := os.Open(db.path + "-wal")
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	header := make([]byte, 32)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return
+	}
+	magic := binary.BigEndian.Uint32(header[0:4])
+	if magic&^1 != 0x377f0682 || int(binary.BigEndian.Uint32(header[8:12])) != db.pageSize {
+		return
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if magic&1 == 1 {
+		order = binary.BigEndian
+	}
+	s0, s1 := walChecksum(order, header[:24], 0, 0)
+	if s0 != binary.BigEndian.Uint32(header[24:28]) || s1 != binary.BigEndian.Uint32(header[28:32]) {
+		return
+	}
+
+	salt := header[16:24]
+	committed := make(map[uint32]int64)
+	pending := make(map[uint32]int64)
+	frameSize := 24 + db.pageSize
+	frame := make([]byte, frameSize)
+	for off := 32; ; off += frameSize {
+		if _, err := f.ReadAt(frame, int64(off)); err != nil {
+			break // End of the file (or a partly written frame)
+		}
+		if !bytes.Equal(frame[8:16], salt) {
+			break
+		}
+		s0, s1 = walChecksum(order, frame[:8], s0, s1)
+		s0, s1 = walChecksum(order, frame[24:], s0, s1)
+		if s0 != binary.BigEndian.Uint32(frame[16:20]) || s1 != binary.BigEndian.Uint32(frame[20:24]) {
+			break
+		}
+		pending[binary.BigEndian.Uint32(frame[0:4])] = int64(off + 24)
+		if size := binary.BigEndian.Uint32(frame[4:8]); size != 0 {
+			// Commit frame: everything up to here is part of the database
+			for page, at := range pending {
+				committed[page] = at
+			}
+			pending = make(map[uint32]int64)
+			db.pageCount = size
+			db.walFrames = (off-32)/frameSize + 1
+		}
+	}
+	if len(committed) > 0 {
+		db.walPages = committed
+	}
+}
+
+// walChecksum continues the WAL checksum (s0, s1) over data
+func walChecksum(order binary.ByteOrder, data []byte, s0, s1 uint32) (uint32, uint32) {
+	for i := 0; i+8 <= len(data); i += 8 {
+		s0 += order.Uint32(data[i:]) + s1
+		s1 += order.Uint32(data[i+4:]) + s0
+	}
+	return s0, s1
+}
+
+// connect opens the database for reading pages
+func (db *sqliteDB) connect() (*sqliteConn, error) {
+	f, err := os.Open(db.path)
+	if err != nil {
+		return nil, err
+	}
+	c := &sqliteConn{db: db, f: f, pages: make(map[uint32][]byte)}
+	if db.walPages != nil {
+		if c.wal, err = os.Open(db.path + "-wal"); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Close closes the connection's files
+func (c *sqliteConn) Close() {
+	c.f.Close()
+	if c.wal != nil {
+		c.wal.Close()
+	}
+}
+
+// page returns page n (1-based)
+func (c *sqliteConn) page(n uint32) ([]byte, error) {
+	if n == 0 || n > c.db.pageCount {
+		return nil, fmt.Errorf("page %d out of range (corrupt database?)", n)
+	}
+	if data, ok := c.pages[n]; ok {
+		return data, nil
+	}
+
+	data := make([]byte, c.db.pageSize)
+	var err error
+	if off, ok := c.db.walPages[n]; ok {
+		_, err = c.wal.ReadAt(data, off)
+	} else {
+		_, err = c.f.ReadAt(data, int64(n-1)*int64(c.db.pageSize))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading page %d: %w", n, err)
+	}
+
+	if len(c.pages) >= sqliteCachePages {
+		c.pages = make(map[uint32][]byte)
+	}
+	c.pages[n] = data
+	return data, nil
+}
+
+// btreePage reads a b-tree page and returns its type, cell offsets and
+// right-most child (interior pages)
+func (c *sqliteConn) btreePage(n uint32) (data []byte, kind byte, cells []int, right uint32, err error) {
+	data, err = c.page(n)
+	if err != nil {
+		return nil, 0, nil, 0, err
+	}
+	hdr := 0
+	if n == 1 {
+		hdr = 100 // Page 1 starts with the database header
+	}
+	kind = data[hdr]
+	hdrLen := 8
+	switch kind {
+	case 2, 5:
+		hdrLen = 12
+		right = binary.BigEndian.Uint32(data[hdr+8:])
+	case 10, 13:
+	default:
+		return nil, 0, nil, 0, fmt.Errorf("page %d is not a b-tree page (corrupt database?)", n)
+	}
+
+	count := int(binary.BigEndian.Uint16(data[hdr+3:]))
+	ptrs := hdr + hdrLen
+	if ptrs+2*count > len(data) {
+		return nil, 0, nil, 0, fmt.Errorf("page %d: bad cell count %d", n, count)
+	}
+	cells = make([]int, count)
+	for i := range cells {
+		cells[i] = int(binary.BigEndian.Uint16(data[ptrs+2*i:]))
+		if cells[i] < ptrs || cells[i] >= len(data) {
+			return nil, 0, nil, 0, fmt.Errorf("page %d: bad cell offset", n)
+		}
+	}
+	return data, kind, cells, right, nil
+}
+
+// localPayload returns how much of a payload of size p is stored on the b-tree page
+func (db *sqliteDB) localPayload(p int64, table bool) int {
+	u := int64(db.usable)
+	x := u - 35
+	if !table {
+		x = (u-12)*64/255 - 23
+	}
+	if p <= x {
+		return int(p)
+	}
+	m := (u-12)*32/255 - 23
+	k := m + (p-m)%(u-4)
+	if k <= x {
+		return int(k)
+	}
+	return int(m)
+}
+
+// payload reads a cell payload of size bytes starting at off, following overflow pages
+func (c *sqliteConn) payload(data []byte, off int, size int64, table bool) ([]byte, error) {
+	if size < 0 || size > sqliteMaxPayload {
+		return nil, fmt.Errorf("bad record size %d", size)
+	}
+	local := c.db.localPayload(size, table)
+	if off+local > len(data) {
+		return nil, errors.New("record runs past its page (corrupt database?)")
+	}
+	if int64(local) == size {
+		return data[off : off+local], nil
+	}
+	if off+local+4 > len(data) {
+		return nil, errors.New("record runs past its page (corrupt database?)")
+	}
+
+	buf := make([]byte, local, size)
+	copy(buf, data[off:off+local])
+	next := binary.BigEndian.Uint32(data[off+local:])
+	for hops := uint32(0); int64(len(buf)) < size; hops++ {
+		if next == 0 || hops > c.db.pageCount {
+			return nil, errors.New("overflow chain ends early (corrupt database?)")
+		}
+		page, err := c.page(next)
+		if err != nil {
+			return nil, err
+		}
+		n := min(int(size)-len(buf), c.db.usable-4)
+		buf = append(buf, page[4:4+n]...)
+		next = binary.BigEndian.Uint32(page[0:4])
+	}
+	return buf, nil
+}
+
+// walkTableLeaves calls fn with each leaf page of the table b-tree at root, in
+// rowid order. fn returns false to stop.
+func (c *sqliteConn) walkTableLeaves(root uint32, fn func(page uint32, data []byte, cells []int) (bool, error)) error {
+	var walk func(n uint32, depth int) (bool, error)
+	walk = func(n uint32, depth int) (bool, error) {
+		if depth > sqliteMaxDepth {
+			return false, errors.New("b-tree too deep (corrupt database?)")
+		}
+		data, kind, cells, right, err := c.btreePage(n)
+		if err != nil {
+			return false, err
+		}
+		switch kind {
+		case 13:
+			return fn(n, data, cells)
+		case 5:
+			// Interior cells don't hold rows - copy the children out, since the
+			// page can be evicted from the cache while walking them
+			children := make([]uint32, 0, len(cells)+1)
+			for _, off := range cells {
+				children = append(children, binary.BigEndian.Uint32(data[off:]))
+			}
+			children = append(children, right)
+			for _, child := range children {
+				if cont, err := walk(child, depth+1); !cont || err != nil {
+					return false, err
+				}
+			}
+			return true, nil
+		}
+		return false, fmt.Errorf("page %d is not a table b-tree page", n)
+	}
+	_, err := walk(root, 0)
+	return err
+}
+
+// leafRows decodes the rows of a table leaf page, starting at cell from
+func (c *sqliteConn) leafRows(data []byte, cells []int, from int, fn func(rowid int64, rec []sqlValue) bool) (bool, error) {
+	for _, off := range cells[from:] {
+		size, n := sqliteVarint(data[off:])
+		rowid, k := sqliteVarint(data[off+n:])
+		if n == 0 || k == 0 {
+			return false, errors.New("bad table cell (corrupt database?)")
+		}
+		payload, err := c.payload(data, off+n+k, size, true)
+		if err != nil {
+			return false, err
+		}
+		rec, err := decodeSQLiteRecord(payload, c.db.encoding)
+		if err != nil {
+			return false, err
+		}
+		if !fn(rowid, rec) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// scanTable calls fn for each row of the table b-tree at root in rowid order.
+// fn returns false to stop.
+func (c *sqliteConn) scanTable(root uint32, fn func(rowid int64, rec []sqlValue) bool) error {
+	return c.walkTableLeaves(root, func(_ uint32, data []byte, cells []int) (bool, error) {
+		return c.leafRows(data, cells, 0, fn)
+	})
+}
+
+// sqliteLeaf is a table leaf page and the position of its first row
+type sqliteLeaf struct {
+	page  uint32
+	first int
+}
+
+// tableLeaves lists the leaf pages of a table b-tree and counts its rows, so
+// rows can be paged without decoding the ones before them
+func (c *sqliteConn) tableLeaves(root uint32, cancelled func() bool) ([]sqliteLeaf, int, error) {
+	var leaves []sqliteLeaf
+	total := 0
+	err := c.walkTableLeaves(root, func(page uint32, _ []byte, cells []int) (bool, error) {
+		if len(leaves)%sqlCancelCheck == 0 && cancelled != nil && cancelled() {
+			return false, errSQLCancelled
+		}
+		leaves = append(leaves, sqliteLeaf{page: page, first: total})
+		total += len(cells)
+		return true, nil
+	})
+	return leaves, total, err
+}
+
+// tableRowsAt decodes up to n rows of a table starting at row start, using its leaf list
+func (c *sqliteConn) tableRowsAt(leaves []sqliteLeaf, start, n int, fn func(rowid int64, rec []sqlValue)) error {
+	i := sort.Search(len(leaves), func(i int) bool { return leaves[i].first > start }) - 1
+	for ; i >= 0 && i < len(leaves) && n > 0; i++ {
+		data, kind, cells, _, err := c.btreePage(leaves[i].page)
+		if err != nil {
+			return err
+		}
+		if kind != 13 {
+			return fmt.Errorf("page %d is no longer a table leaf (database changed?)", leaves[i].page)
+		}
+		from := max(0, start-leaves[i].first)
+		if from >= len(cells) {
+			continue
+		}
+		_, err = c.leafRows(data, cells, from, func(rowid int64, rec []sqlValue) bool {
+			fn(rowid, rec)
+			n--
+			return n > 0
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanIndex calls fn for each record of the index b-tree at root, in key order.
+// WITHOUT ROWID tables are stored this way too. fn returns false to stop.
+func (c *sqliteConn) scanIndex(root uint32, fn func(rec []sqlValue) bool) error {
+	var walk func(n uint32, depth int) (bool, error)
+	walk = func(n uint32, depth int) (bool, error) {
+		if depth > sqliteMaxDepth {
+			return false, errors.New("b-tree too deep (corrupt database?)")
+		}
+		data, kind, cells, right, err := c.btreePage(n)
+		if err != nil {
+			return false, err
+		}
+		if kind != 2 && kind != 10 {
+			return false, fmt.Errorf("page %d is not an index b-tree page", n)
+		}
+
+		// Decode the page before descending - it can be evicted from the cache meanwhile
+		type entry struct {
+			child uint32
+			rec   []sqlValue
+		}
+		entries := make([]entry, len(cells))
+		for i, off := range cells {
+			if kind == 2 {
+				entries[i].child = binary.BigEndian.Uint32(data[off:])
+				off += 4
+			}
+			size, k := sqliteVarint(data[off:])
+			if k == 0 {
+				return false, errors.New("bad index cell (corrupt database?)")
+			}
+			payload, err := c.payload(data, off+k, size, false)
+			if err != nil {
+				return false, err
+			}
+			if entries[i].rec, err = decodeSQLiteRecord(payload, c.db.encoding); err != nil {
+				return false, err
+			}
+		}
+
+		// Interior cells hold keys too: left child, then the cell itself
+		for _, e := range entries {
+			if kind == 2 {
+				if cont, err := walk(e.child, depth+1); !cont || err != nil {
+					return false, err
+				}
+			}
+			if !fn(e.rec) {
+				return false, nil
+			}
+		}
+		if kind == 2 {
+			return walk(right, depth+1)
+		}
+		return true, nil
+	}
+	_, err := walk(root, 0)
+	return err
+}
+
+// sqliteVarint decodes a SQLite varint, returning 0 bytes read if it's truncated
+func sqliteVarint(b []byte) (int64, int) {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		if i >= len(b) {
+			return 0, 0
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i] < 0x80 {
+			return int64(v), i + 1
+		}
+	}
+	if len(b) < 9 {
+		return 0, 0
+	}
+	return int64(v<<8 | uint64(b[8])), 9
+}
+
+// decodeSQLiteRecord decodes a record payload into its values
+func decodeSQLiteRecord(payload []byte, encoding uint32) ([]sqlValue, error) {
+	hdrLen, n := sqliteVarint(payload)
+	if n == 0 || hdrLen < int64(n) || hdrLen > int64(len(payload)) {
+		return nil, errors.New("bad record header (corrupt database?)")
+	}
+
+	var values []sqlValue
+	body := int(hdrLen)
+	for p := n; p < int(hdrLen); {
+		typ, k := sqliteVarint(payload[p:hdrLen])
+		if k == 0 {
+			return nil, errors.New("bad record header (corrupt database?)")
+		}
+		p += k
+
+		var size int
+		switch {
+		case typ >= 1 && typ <= 6:
+			size = []int{0, 1, 2, 3, 4, 6, 8}[typ]
+		case typ == 7:
+			size = 8
+		case typ >= 12:
+			size = int((typ - 12) / 2)
+		}
+		if body+size > len(payload) {
+			return nil, errors.New("record value runs past its payload (corrupt database?)")
+		}
+		raw := payload[body : body+size]
+		body += size
+
+		switch {
+		case typ == 0:
+			values = append(values, nil)
+		case typ >= 1 && typ <= 6:
+			var u uint64
+			for _, b := range raw {
+				u = u<<8 | uint64(b)
+			}
+			shift := 64 - 8*size
+			values = append(values, int64(u<<shift)>>shift) // Sign-extend
+		case typ == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(raw)))
+		case typ == 8:
+			values = append(values, int64(0))
+		case typ == 9:
+			values = append(values, int64(1))
+		case typ >= 12 && typ%2 == 0:
+			values = append(values, append([]byte(nil), raw...))
+		case typ >= 13:
+			values = append(values, decodeSQLiteText(raw, encoding))
+		default:
+			return nil, fmt.Errorf("bad record serial type %d", typ)
+		}
+	}
+	return values, nil
+}
+
+// decodeSQLiteText converts stored text to a Go string
+func decodeSQLiteText(raw []byte, encoding uint32) string {
+	if encoding != 2 && encoding != 3 {
+		return string(raw)
+	}
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		if encoding == 2 {
+			units[i] = binary.LittleEndian.Uint16(raw[2*i:])
+		} else {
+			units[i] = binary.BigEndian.Uint16(raw[2*i:])
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// loadSchema reads the schema table (page 1) and parses table definitions
+func (db *sqliteDB) loadSchema() error {
+	c, err := db.connect()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	db.schema = nil
+	return c.scanTable(1, func(_ int64, rec []sqlValue) bool {
+		if len(rec) < 5 {
+			return true
+		}
+		obj := &sqliteObject{rowidAlias: -1}
+		obj.kind, _ = rec[0].(string)
+		obj.name, _ = rec[1].(string)
+		obj.table, _ = rec[2].(string)
+		if root, ok := rec[3].(int64); ok && root > 0 {
+			obj.root = uint32(root)
+		}
+		obj.sql, _ = rec[4].(string)
+		if obj.kind == "table" {
+			obj.parseTableSQL()
+		}
+		db.schema = append(db.schema, obj)
+		return true
+	})
+}
+
+// object returns the schema object named name (case-insensitive, like SQL)
+func (db *sqliteDB) object(name string) *sqliteObject {
+	if strings.EqualFold(name, "sqlite_master") || strings.EqualFold(name, "sqlite_schema") {
+		return sqliteSchemaTable
+	}
+	for _, obj := range db.schema {
+		if strings.EqualFold(obj.name, name) {
+			return obj
+		}
+	}
+	return nil
+}
+
+// sqliteSchemaTable describes the schema table itself, so it can be queried
+var sqliteSchemaTable = func() *sqliteObject {
+	obj := &sqliteObject{kind: "table", name: "sqlite_schema", root: 1,
+		sql: "CREATE TABLE sqlite_schema(type text, name text, tbl_name text, rootpage integer, sql text)"}
+	obj.parseTableSQL()
+	return obj
+}()
+
+// parseTableSQL fills in the columns and record layout from CREATE TABLE
+func (obj *sqliteObject) parseTableSQL() {
+	obj.rowidAlias = -1
+	tokens := sqlTokenize(obj.sql)
+	if len(tokens) > 1 && strings.EqualFold(tokens[1].text, "virtual") {
+		obj.virtual = true
+		return
+	}
+
+	// Column definitions are the comma-separated groups inside the first parentheses
+	start := -1
+	for i, tok := range tokens {
+		if tok.isKeyword("as") && start < 0 {
+			return // CREATE TABLE ... AS SELECT - columns come from the records
+		}
+		if tok.is("(") {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return
+	}
+	var groups [][]sqlToken
+	var group []sqlToken
+	depth := 0
+	end := len(tokens)
+	for i := start; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.is("("):
+			depth++
+		case tok.is(")"):
+			depth--
+		}
+		if depth < 0 {
+			end = i + 1
+			break
+		}
+		if depth == 0 && tok.is(",") {
+			groups = append(groups, group)
+			group = nil
+			continue
+		}
+		group = append(group, tok)
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	for i := end; i+1 < len(tokens); i++ {
+		if tokens[i].isKeyword("without") && tokens[i+1].isKeyword("rowid") {
+			obj.withoutRowid = true
+		}
+	}
+
+	var pkNames []string
+	for _, g := range groups {
+		if len(g) == 0 {
+			continue
+		}
+		switch strings.ToLower(g[0].text) {
+		case "constraint", "primary", "unique", "check", "foreign":
+			if g[0].kind != sqlTokQuoted {
+				// Table constraint: PRIMARY KEY (a, b) names the key columns
+				for i := 0; i+1 < len(g); i++ {
+					if g[i].isKeyword("primary") && g[i+1].isKeyword("key") {
+						for _, tok := range g[i+2:] {
+							if tok.is(")") {
+								break
+							}
+							if tok.kind == sqlTokIdent || tok.kind == sqlTokQuoted {
+								if tok.isKeyword("asc") || tok.isKeyword("desc") || tok.isKeyword("collate") {
+									continue
+								}
+								pkNames = append(pkNames, tok.text)
+							}
+						}
+					}
+				}
+				continue
+			}
+		}
+		obj.columns = append(obj.columns, parseColumnDef(g))
+		if obj.columns[len(obj.columns)-1].pk {
+			pkNames = append(pkNames, g[0].text)
+		}
+	}
+
+	pkCols := make([]int, 0, len(pkNames))
+	for _, name := range pkNames {
+		for j, col := range obj.columns {
+			if strings.EqualFold(col.name, name) {
+				obj.columns[j].pk = true
+				pkCols = append(pkCols, j)
+			}
+		}
+	}
+
+	// Record layout: rowid tables store the columns in order (the INTEGER
+	// PRIMARY KEY is stored as NULL); WITHOUT ROWID tables store the key first
+	if obj.withoutRowid {
+		obj.stored = append(obj.stored, pkCols...)
+	} else if len(pkCols) == 1 && strings.EqualFold(obj.columns[pkCols[0]].declType, "integer") {
+		obj.rowidAlias = pkCols[0]
+	}
+	for j, col := range obj.columns {
+		if col.generated || (obj.withoutRowid && col.pk) {
+			continue
+		}
+		obj.stored = append(obj.stored, j)
+	}
+}
+
+// parseColumnDef parses one column definition (name, type and constraints)
+func parseColumnDef(g []sqlToken) sqliteColumn {
+	col := sqliteColumn{name: g[0].text}
+	constraints := map[string]bool{"constraint": true, "primary": true, "not": true, "null": true,
+		"unique": true, "check": true, "default": true, "collate": true, "references": true,
+		"generated": true, "as": true}
+
+	i := 1
+	var typ []string
+	for ; i < len(g) && !(g[i].kind == sqlTokIdent && constraints[strings.ToLower(g[i].text)]); i++ {
+		typ = append(typ, g[i].text)
+	}
+	col.declType = strings.Join(typ, " ")
+
+	for ; i < len(g); i++ {
+		switch {
+		case g[i].isKeyword("primary"):
+			col.pk = true
+		case g[i].isKeyword("not") && i+1 < len(g) && g[i+1].isKeyword("null"):
+			col.notNull = true
+		case g[i].isKeyword("default") && i+1 < len(g):
+			next := g[i+1]
+			if next.is("-") && i+2 < len(g) {
+				next = sqlToken{kind: g[i+2].kind, text: "-" + g[i+2].text}
+			}
+			col.dflt = next.literal()
+		case g[i].isKeyword("as") && i+1 < len(g) && g[i+1].is("("):
+			// Generated column: virtual unless STORED
+			col.generated = !g[len(g)-1].isKeyword("stored")
+		}
+	}
+	return col
+}
+
+// indexColumns returns the column names of an index record
+func (db *sqliteDB) indexColumns(obj *sqliteObject) []string {
+	var cols []string
+	tokens := sqlTokenize(obj.sql)
+	for i, tok := range tokens {
+		if !tok.is("(") {
+			continue
+		}
+		// CREATE INDEX name ON table (a, b DESC, lower(c))
+		depth := 0
+		var expr []string
+		for _, t := range tokens[i+1:] {
+			if t.is("(") {
+				depth++
+			} else if t.is(")") {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+			if depth == 0 && t.is(",") {
+				cols = append(cols, strings.Join(expr, " "))
+				expr = nil
+				continue
+			}
+			if depth == 0 && (t.isKeyword("asc") || t.isKeyword("desc")) {
+				continue
+			}
+			expr = append(expr, t.text)
+		}
+		if len(expr) > 0 {
+			cols = append(cols, strings.Join(expr, " "))
+		}
+		break
+	}
+
+	// Automatic indexes have no SQL: they cover the table's UNIQUE / PRIMARY KEY columns
+	if len(cols) == 0 {
+		cols = append(cols, "key")
+	}
+
+	// The rest of the record locates the table row
+	if table := db.object(obj.table); table != nil && table.withoutRowid {
+		for _, j := range table.stored {
+			if table.columns[j].pk {
+				cols = append(cols, table.columns[j].name)
+			}
+		}
+	} else {
+		cols = append(cols, "rowid")
+	}
+	return cols
+}
+
+// columnNames returns the declared column names of a table
+func (obj *sqliteObject) columnNames() []string {
+	names := make([]string, len(obj.columns))
+	for j, col := range obj.columns {
+		names[j] = col.name
+	}
+	return names
+}
+
+// tableRow maps a stored record to the table's declared columns
+func (obj *sqliteObject) tableRow(rowid int64, rec []sqlValue) []sqlValue {
+	if len(obj.columns) == 0 {
+		return rec // Columns unknown - show the record as stored
+	}
+	row := make([]sqlValue, len(obj.columns))
+	for j, col := range obj.columns {
+		row[j] = col.dflt // Rows written before an ALTER TABLE ADD COLUMN
+	}
+	for k, j := range obj.stored {
+		if k < len(rec) {
+			row[j] = rec[k]
+		}
+	}
+	if obj.rowidAlias >= 0 {
+		row[obj.rowidAlias] = rowid
+	}
+	for j, col := range obj.columns {
+		if col.generated {
+			row[j] = nil // Computed on read by SQLite - not available here
+		}
+	}
+	return row
+}
+
+// formatSQLValue renders a value for display
+func formatSQLValue(v sqlValue) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return strconv.FormatFloat(v, 'f', 1, 64) // 3.0, as sqlite3 prints it
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return v
+	case []byte:
+		const shown = 16
+		if len(v) > shown {
+			return fmt.Sprintf("x'%X…' (%s)", v[:shown], formatFileSize(int64(len(v))))
+		}
+		return fmt.Sprintf("x'%X'", v)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createTestSQLiteDB builds a database with the sqlite3 CLI (skips the test without it)
+func createTestSQLiteDB(t *testing.T, sql string) string {
+	t.Helper()
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not available on this system")
+	}
+	path := filepath.Join(t.TempDir(), "test.db")
+	cmd := exec.Command("sqlite3", path)
+	cmd.Stdin = strings.NewReader(sql)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("sqlite3 failed: %v\n%s", err, out)
+	}
+	return path
+}
+
+const sqliteTestSchema = `
+PRAGMA page_size=1024;
+CREATE TABLE users(id INTEGER PRIMARY KEY, name TEXT NOT NULL, age INT, bio BLOB);
+CREATE INDEX idx_users_age ON users(age DESC);
+CREATE TABLE kv(k TEXT PRIMARY KEY, v) WITHOUT ROWID;
+CREATE VIEW adults AS SELECT name, age FROM users WHERE age >= 18;
+WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c WHERE x < 2000)
+  INSERT INTO users(name, age) SELECT 'user' || x, x % 90 FROM c;
+INSERT INTO kv VALUES ('b', 2), ('a', 'one'), ('c', 3.5);
+ALTER TABLE users ADD COLUMN flag INT DEFAULT 7;
+UPDATE users SET flag = 1 WHERE id = 2;
+UPDATE users SET name = printf('%.3000c', 'x'), bio = zeroblob(5000) WHERE id = 10;
+`
+
+// TestSQLiteVarint tests varint decoding
+func TestSQLiteVarint(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want int64
+		n    int
+	}{
+		{[]byte{0x05}, 5, 1},
+		{[]byte{0x81, 0x00}, 128, 2},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, -1, 9},
+		{[]byte{0x81}, 0, 0}, // Truncated
+	}
+	for _, tt := range tests {
+		got, n := sqliteVarint(tt.in)
+		if got != tt.want || n != tt.n {
+			t.Errorf("sqliteVarint(%x) = %d, %d; want %d, %d", tt.in, got, n, tt.want, tt.n)
+		}
+	}
+}
+
+// TestDecodeSQLiteRecord tests record decoding for each serial type
+func TestDecodeSQLiteRecord(t *testing.T) {
+	// Header: size 8, types NULL, int8, int16, 0, 1, text(2), blob(1)
+	payload := []byte{8, 0, 1, 2, 8, 9, 17, 14, 0xfe, 0x01, 0x00, 'h', 'i', 0xaa}
+	values, err := decodeSQLiteRecord(payload, 1)
+	if err != nil {
+		t.Fatalf("decodeSQLiteRecord failed: %v", err)
+	}
+	want := []string{"NULL", "-2", "256", "0", "1", "hi", "x'AA'"}
+	for i, v := range values {
+		if got := formatSQLValue(v); got != want[i] {
+			t.Errorf("value %d = %s, want %s", i, got, want[i])
+		}
+	}
+
+	if _, err := decodeSQLiteRecord([]byte{3, 6, 1}, 1); err == nil {
+		t.Error("Expected error for a value past the payload")
+	}
+}
+
+// TestParseTableSQL tests column and record layout parsing of CREATE TABLE
+func TestParseTableSQL(t *testing.T) {
+	obj := &sqliteObject{sql: `CREATE TABLE "my table" (
+		id integer PRIMARY KEY AUTOINCREMENT, -- the rowid
+		[name] TEXT NOT NULL DEFAULT 'x',
+		total REAL DEFAULT -1.5,
+		half AS (total / 2),
+		CONSTRAINT u UNIQUE (name)
+	)`}
+	obj.parseTableSQL()
+	if got := strings.Join(obj.columnNames(), ","); got != "id,name,total,half" {
+		t.Fatalf("Unexpected columns %s", got)
+	}
+	if obj.rowidAlias != 0 || !obj.columns[1].notNull || obj.columns[2].dflt != -1.5 || !obj.columns[3].generated {
+		t.Errorf("Unexpected column details %+v (alias %d)", obj.columns, obj.rowidAlias)
+	}
+	row := obj.tableRow(42, []sqlValue{nil}) // Older row: only id stored
+	if row[0] != int64(42) || row[1] != "x" || row[2] != -1.5 || row[3] != nil {
+		t.Errorf("Unexpected row %v", row)
+	}
+
+	obj = &sqliteObject{sql: "CREATE TABLE kv(v, k TEXT, PRIMARY KEY (k)) WITHOUT ROWID"}
+	obj.parseTableSQL()
+	if !obj.withoutRowid || obj.rowidAlias != -1 {
+		t.Fatal("Expected WITHOUT ROWID table")
+	}
+	// The key is stored first
+	if row := obj.tableRow(0, []sqlValue{"key", "value"}); row[0] != "value" || row[1] != "key" {
+		t.Errorf("Unexpected row %v", row)
+	}
+}
+
+// TestSQLCompareAndLike tests value ordering and LIKE matching
+func TestSQLCompareAndLike(t *testing.T) {
+	if sqlCompare(int64(2), 10.5) >= 0 || sqlCompare("10", int64(9)) <= 0 || sqlCompare(int64(5), "a") >= 0 {
+		t.Error("Unexpected numeric comparison")
+	}
+	if sqlCompare(nil, int64(0)) >= 0 || sqlCompare("b", "a") <= 0 {
+		t.Error("Unexpected ordering of NULL / text")
+	}
+
+	tests := []struct {
+		pattern, text string
+		want          bool
+	}{
+		{"user%", "User42", true},
+		{"%42", "user42", true},
+		{"u_er%", "user", true},
+		{"%x%", "user", false},
+		{"user", "user1", false},
+	}
+	for _, tt := range tests {
+		if got := sqlLike(tt.pattern, tt.text); got != tt.want {
+			t.Errorf("sqlLike(%q, %q) = %v", tt.pattern, tt.text, got)
+		}
+	}
+}
+
+// TestSQLExpressions tests expressions against the results SQLite gives
+func TestSQLExpressions(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string // Values joined by ","
+	}{
+		{"SELECT 9223372036854775807 + 1, -9223372036854775807 - 2, 4611686018427387904 * 2", "9.223372036854776e+18,-9.223372036854776e+18,9.223372036854776e+18"},
+		{"SELECT -9223372036854775807 - 1, 9223372036854775807 * -1, 7 % -3", "-9223372036854775808,-9223372036854775807,1"},
+		{"SELECT replace('aaa', '', 'b'), replace('aaa', 'a', 'bc')", "aaa,bcbcbc"},
+		{"SELECT round(1e308, 500), round(2.5), round(1.005, 2), round(3.14159, -2)", "1e+308,3.0,1.0,3.0"},
+		{"SELECT x'ff00', typeof(X'AB'), length(x'')", "x'FF00',blob,0"},
+		{"SELECT trim('xxhixx', 'x'), count()", "hi,1"},
+		{"SELECT 1 LIMIT -1", "1"},
+		{"SELECT 1 LIMIT 5 OFFSET -2", "1"},
+	}
+	for _, tt := range tests {
+		q, err := parseSQLSelect(tt.sql)
+		if err != nil {
+			t.Errorf("%s: %v", tt.sql, err)
+			continue
+		}
+		res, err := (&sqliteDB{}).run(nil, q, 100, 0, nil)
+		if err != nil || len(res.rows) != 1 {
+			t.Errorf("%s: expected one row, got %v (%v)", tt.sql, res, err)
+			continue
+		}
+		var cells []string
+		for _, v := range res.rows[0] {
+			cells = append(cells, formatSQLValue(v))
+		}
+		if got := strings.Join(cells, ","); got != tt.want {
+			t.Errorf("%s\n got: %s\nwant: %s", tt.sql, got, tt.want)
+		}
+	}
+}
+
+// TestParseSQLSelectErrors tests that unsupported statements are rejected
+func TestParseSQLSelectErrors(t *testing.T) {
+	for _, sql := range []string{
+		"DELETE FROM users",
+		"SELECT * FROM a JOIN b ON a.id = b.id",
+		"SELECT * FROM users WHERE",
+		"SELECT name FROM users LIMIT x",
+		"SELECT (SELECT 1)",
+		"SELECT max()",
+		"SELECT replace('a', 'b')",
+		"SELECT x'f'",
+		"",
+	} {
+		if _, err := parseSQLSelect(sql); err == nil {
+			t.Errorf("Expected error for %q", sql)
+		}
+	}
+}
+
+// TestSQLiteQuery tests reading and querying a database written by sqlite3
+func TestSQLiteQuery(t *testing.T) {
+	db, err := openSQLiteDB(createTestSQLiteDB(t, sqliteTestSchema))
+	if err != nil {
+		t.Fatalf("openSQLiteDB failed: %v", err)
+	}
+
+	var names []string
+	for _, obj := range db.schema {
+		names = append(names, obj.kind+":"+obj.name)
+	}
+	if got := strings.Join(names, " "); got != "table:users index:idx_users_age table:kv view:adults" {
+		t.Errorf("Unexpected schema %s", got)
+	}
+
+	tests := []struct {
+		sql  string
+		want string // Rows joined by ";", values by ","
+	}{
+		{"SELECT count(*), sum(age), min(name) FROM users", "2000,88320,user1"},
+		{"select id, length(name), flag, length(bio) from users where id in (1, 2, 10)", "1,5,7,NULL;2,5,1,NULL;10,3000,7,5000"},
+		{"SELECT * FROM kv", "a,one;b,2;c,3.5"},
+		{"SELECT count(*) FROM adults", "1587"},
+		{"SELECT age, count(*) AS n FROM users GROUP BY age ORDER BY n DESC, age LIMIT 2", "1,23;2,23"},
+		{"SELECT name FROM users ORDER BY rowid DESC LIMIT 2 OFFSET 1", "user1999;user1998"},
+		{"SELECT DISTINCT age / 30 FROM users ORDER BY 1", "0;1;2"},
+		{"SELECT name FROM users WHERE name LIKE 'USER19_' AND NOT age BETWEEN 0 AND 10", "user191;user192;user193;user194;user195;user196;user197;user198;user199"},
+		{"SELECT age, rowid FROM idx_users_age LIMIT 1", "89,89"},
+		{"SELECT name FROM sqlite_schema WHERE type = 'view'", "adults"},
+		{"SELECT upper('a') || 'b', 7 / 2, 7.0 / 2, coalesce(NULL, 3)", "Ab,3,3.5,3"},
+	}
+	for _, tt := range tests {
+		res, err := db.query(tt.sql, 100, nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.sql, err)
+			continue
+		}
+		var rows []string
+		for _, row := range res.rows {
+			var cells []string
+			for _, v := range row {
+				cells = append(cells, formatSQLValue(v))
+			}
+			rows = append(rows, strings.Join(cells, ","))
+		}
+		if got := strings.Join(rows, ";"); got != tt.want {
+			t.Errorf("%s\n got: %s\nwant: %s", tt.sql, got, tt.want)
+		}
+	}
+
+	res, err := db.query("SELECT * FROM users", 50, nil)
+	if err != nil || len(res.rows) != 50 || !res.truncated {
+		t.Errorf("Expected 50 rows and a truncated result, got %d (%v)", len(res.rows), err)
+	}
+	for _, sql := range []string{"SELECT nope FROM users", "SELECT * FROM missing", "SELECT foo(1)"} {
+		if _, err := db.query(sql, 100, nil); err == nil {
+			t.Errorf("Expected error for %q", sql)
+		}
+	}
+}
+
+// TestSQLiteTablePaging tests reading rows by position through the leaf list
+func TestSQLiteTablePaging(t *testing.T) {
+	db, err := openSQLiteDB(createTestSQLiteDB(t, sqliteTestSchema))
+	if err != nil {
+		t.Fatalf("openSQLiteDB failed: %v", err)
+	}
+	c, err := db.connect()
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer c.Close()
+
+	users := db.object("users")
+	leaves, total, err := c.tableLeaves(users.root, nil)
+	if err != nil || total != 2000 || len(leaves) < 2 {
+		t.Fatalf("Expected 2000 rows over several leaves, got %d rows in %d leaves (%v)", total, len(leaves), err)
+	}
+
+	var rowids []int64
+	err = c.tableRowsAt(leaves, 1995, 10, func(rowid int64, rec []sqlValue) {
+		rowids = append(rowids, rowid)
+	})
+	if err != nil || len(rowids) != 5 || rowids[0] != 1996 || rowids[4] != 2000 {
+		t.Errorf("Unexpected rowids %v (%v)", rowids, err)
+	}
+}
+
+// TestSQLiteWAL tests that committed WAL frames are read (and a partly written frame isn't)
+func TestSQLiteWAL(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not available on this system")
+	}
+	dir := t.TempDir()
+	// Copy the database while sqlite3 still has it open, before the WAL is checkpointed
+	cmd := exec.Command("sqlite3", "live.db",
+		"PRAGMA journal_mode=WAL; CREATE TABLE t(x); INSERT INTO t VALUES (1), (2), (3);",
+		".shell cp live.db test.db; cp live.db-wal test.db-wal")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("sqlite3 failed: %v\n%s", err, out)
+	}
+	path := filepath.Join(dir, "test.db")
+	f, err := os.OpenFile(path+"-wal", os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Expected a WAL file: %v", err)
+	}
+	f.Write(make([]byte, 100))
+	f.Close()
+
+	db, err := openSQLiteDB(path)
+	if err != nil {
+		t.Fatalf("openSQLiteDB failed: %v", err)
+	}
+	if db.walFrames == 0 {
+		t.Error("Expected committed WAL frames")
+	}
+	res, err := db.query("SELECT sum(x) FROM t", 10, nil)
+	if err != nil || len(res.rows) != 1 || formatSQLValue(res.rows[0][0]) != "6" {
+		t.Errorf("Expected the rows from the WAL, got %v (%v)", res, err)
+	}
+}
@@ -0,0 +1,1703 @@
+package main
+
+// Module: sqlquery.go
+// Purpose: Read-only SQL queries over the pure-Go SQLite reader
+// Responsibilities:
+// - Tokenizing SQL (also used to parse CREATE statements in the schema)
+// - Parsing a SELECT subset: WHERE, GROUP BY, ORDER BY, LIMIT/OFFSET, DISTINCT,
+//   aggregates (count, sum, avg, min, max, group_concat) and common scalar functions
+// - Running queries against tables, indexes and views (views are queried by running their SELECT)
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	sqlMaxResultRows = 10000   // Result rows kept for display
+	sqlMaxSortRows   = 1000000 // Rows collected at most for ORDER BY / GROUP BY / DISTINCT
+	sqlMaxViewDepth  = 8       // Views selecting from views
+	sqlCancelCheck   = 4096    // Rows scanned between cancellation checks
+)
+
+var errSQLCancelled = errors.New("query cancelled")
+
+// Token kinds
+const (
+	sqlTokIdent  = iota // Keywords and bare identifiers
+	sqlTokQuoted        // "quoted", `quoted` or [quoted] identifier (text is unquoted)
+	sqlTokString        // 'string literal' (text is unquoted)
+	sqlTokBlob          // x'hex' blob literal (text is the hex digits)
+	sqlTokNumber
+	sqlTokOp
+	sqlTokEnd
+)
+
+type sqlToken struct {
+	kind int
+	text string
+}
+
+// is reports whether the token is the operator / punctuation op
+func (t sqlToken) is(op string) bool {
+	return t.kind == sqlTokOp && t.text == op
+}
+
+// isKeyword reports whether the token is the bare word kw (case-insensitive)
+func (t sqlToken) isKeyword(kw string) bool {
+	return t.kind == sqlTokIdent && strings.EqualFold(t.text, kw)
+}
+
+// literal returns the value of a literal token (strings, numbers, blobs, NULL, TRUE, FALSE)
+func (t sqlToken) literal() sqlValue {
+	switch t.kind {
+	case sqlTokBlob:
+		if b, err := hex.DecodeString(t.text); err == nil {
+			return b
+		}
+	case sqlTokNumber:
+		if i, err := strconv.ParseInt(t.text, 0, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(t.text, 64); err == nil {
+			return f
+		}
+	case sqlTokIdent:
+		switch strings.ToLower(t.text) {
+		case "null":
+			return nil
+		case "true":
+			return int64(1)
+		case "false":
+			return int64(0)
+		}
+	}
+	return t.text
+}
+
+// sqlTokenize splits SQL into tokens, dropping comments and whitespace
+func sqlTokenize(s string) []sqlToken {
+	var tokens []sqlToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case strings.HasPrefix(s[i:], "--"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(s)
+			}
+		case c == '\'' || c == '"' || c == '`' || c == '[' || (c == 'x' || c == 'X') && strings.HasPrefix(s[i+1:], "'"):
+			closing := c
+			kind := sqlTokQuoted
+			if c == 'x' || c == 'X' {
+				kind = sqlTokBlob
+				closing = '\''
+				i++
+			} else if c == '\'' {
+				kind = sqlTokString
+			} else if c == '[' {
+				closing = ']'
+			}
+			var b strings.Builder
+			j := i + 1
+			for j < len(s) {
+				if s[j] == closing {
+					if closing != ']' && j+1 < len(s) && s[j+1] == closing {
+						b.WriteByte(closing) // Doubled quote
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(s[j])
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: b.String()})
+			i = j + 1
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			j := i
+			if strings.HasPrefix(strings.ToLower(s[i:]), "0x") {
+				j += 2
+				for j < len(s) && strings.IndexByte("0123456789abcdefABCDEF", s[j]) >= 0 {
+					j++
+				}
+			} else {
+				for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.') {
+					j++
+				}
+				if j < len(s) && (s[j] == 'e' || s[j] == 'E') {
+					j++
+					if j < len(s) && (s[j] == '+' || s[j] == '-') {
+						j++
+					}
+					for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+						j++
+					}
+				}
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokNumber, text: s[i:j]})
+			i = j
+		case c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80:
+			j := i
+			for j < len(s) && (s[j] == '_' || s[j] == '$' || s[j] >= 'a' && s[j] <= 'z' ||
+				s[j] >= 'A' && s[j] <= 'Z' || s[j] >= '0' && s[j] <= '9' || s[j] >= 0x80) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokIdent, text: s[i:j]})
+			i = j
+		default:
+			op := s[i : i+1]
+			for _, two := range []string{"<=", ">=", "<>", "!=", "==", "||"} {
+				if strings.HasPrefix(s[i:], two) {
+					op = two
+				}
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokOp, text: op})
+			i += len(op)
+		}
+	}
+	return tokens
+}
+
+// sqlExpr is a parsed expression. bind resolves column names before evaluation.
+type sqlExpr interface {
+	bind(resolve func(name string) (int, error)) error
+	eval(row []sqlValue) sqlValue
+}
+
+type sqlLiteral struct{ v sqlValue }
+
+type sqlColumnRef struct {
+	name  string
+	index int
+}
+
+type sqlUnary struct {
+	op string // "not" or "-"
+	x  sqlExpr
+}
+
+type sqlBinary struct {
+	op   string // Comparison, arithmetic, "||", "and", "or", "like"
+	l, r sqlExpr
+}
+
+type sqlIsNull struct {
+	x   sqlExpr
+	not bool
+}
+
+type sqlIn struct {
+	x    sqlExpr
+	list []sqlExpr
+	not  bool
+}
+
+type sqlBetween struct {
+	x, lo, hi sqlExpr
+	not       bool
+}
+
+type sqlFunc struct {
+	name string
+	args []sqlExpr
+	star bool // count(*)
+
+	// Aggregates keep one state per group (group points at the query's current group key)
+	group  *string
+	states map[string]*sqlAggState
+}
+
+type sqlAggState struct {
+	count   int64
+	sumInt  int64
+	sumF    float64
+	isFloat bool
+	best    sqlValue
+	parts   []string
+}
+
+func (e *sqlLiteral) bind(func(string) (int, error)) error { return nil }
+func (e *sqlLiteral) eval([]sqlValue) sqlValue             { return e.v }
+
+func (e *sqlColumnRef) bind(resolve func(string) (int, error)) error {
+	var err error
+	e.index, err = resolve(e.name)
+	return err
+}
+
+func (e *sqlColumnRef) eval(row []sqlValue) sqlValue {
+	if e.index < len(row) {
+		return row[e.index]
+	}
+	return nil
+}
+
+func (e *sqlUnary) bind(resolve func(string) (int, error)) error { return e.x.bind(resolve) }
+
+func (e *sqlUnary) eval(row []sqlValue) sqlValue {
+	v := e.x.eval(row)
+	if v == nil {
+		return nil
+	}
+	if e.op == "not" {
+		return sqlBool(!sqlTruth(v))
+	}
+	switch n := sqlNumeric(v).(type) {
+	case int64:
+		if n == math.MinInt64 {
+			return -float64(n) // Overflows to REAL, as in SQLite
+		}
+		return -n
+	case float64:
+		return -n
+	}
+	return int64(0)
+}
+
+func (e *sqlBinary) bind(resolve func(string) (int, error)) error {
+	if err := e.l.bind(resolve); err != nil {
+		return err
+	}
+	return e.r.bind(resolve)
+}
+
+func (e *sqlBinary) eval(row []sqlValue) sqlValue {
+	switch e.op {
+	case "and":
+		l := e.l.eval(row)
+		if l != nil && !sqlTruth(l) {
+			return int64(0)
+		}
+		r := e.r.eval(row)
+		if r != nil && !sqlTruth(r) {
+			return int64(0)
+		}
+		if l == nil || r == nil {
+			return nil
+		}
+		return int64(1)
+	case "or":
+		l := e.l.eval(row)
+		if l != nil && sqlTruth(l) {
+			return int64(1)
+		}
+		r := e.r.eval(row)
+		if r != nil && sqlTruth(r) {
+			return int64(1)
+		}
+		if l == nil || r == nil {
+			return nil
+		}
+		return int64(0)
+	}
+
+	l, r := e.l.eval(row), e.r.eval(row)
+	if l == nil || r == nil {
+		return nil
+	}
+	switch e.op {
+	case "=", "==":
+		return sqlBool(sqlCompare(l, r) == 0)
+	case "!=", "<>":
+		return sqlBool(sqlCompare(l, r) != 0)
+	case "<":
+		return sqlBool(sqlCompare(l, r) < 0)
+	case "<=":
+		return sqlBool(sqlCompare(l, r) <= 0)
+	case ">":
+		return sqlBool(sqlCompare(l, r) > 0)
+	case ">=":
+		return sqlBool(sqlCompare(l, r) >= 0)
+	case "like":
+		return sqlBool(sqlLike(sqlText(r), sqlText(l)))
+	case "||":
+		return sqlText(l) + sqlText(r)
+	}
+	return sqlArithmetic(e.op, sqlNumeric(l), sqlNumeric(r))
+}
+
+func (e *sqlIsNull) bind(resolve func(string) (int, error)) error { return e.x.bind(resolve) }
+func (e *sqlIsNull) eval(row []sqlValue) sqlValue {
+	return sqlBool((e.x.eval(row) == nil) != e.not)
+}
+
+func (e *sqlIn) bind(resolve func(string) (int, error)) error {
+	for _, x := range append([]sqlExpr{e.x}, e.list...) {
+		if err := x.bind(resolve); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *sqlIn) eval(row []sqlValue) sqlValue {
+	v := e.x.eval(row)
+	if v == nil {
+		return nil
+	}
+	for _, item := range e.list {
+		if w := item.eval(row); w != nil && sqlCompare(v, w) == 0 {
+			return sqlBool(!e.not)
+		}
+	}
+	return sqlBool(e.not)
+}
+
+func (e *sqlBetween) bind(resolve func(string) (int, error)) error {
+	for _, x := range []sqlExpr{e.x, e.lo, e.hi} {
+		if err := x.bind(resolve); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *sqlBetween) eval(row []sqlValue) sqlValue {
+	v, lo, hi := e.x.eval(row), e.lo.eval(row), e.hi.eval(row)
+	if v == nil || lo == nil || hi == nil {
+		return nil
+	}
+	return sqlBool((sqlCompare(v, lo) >= 0 && sqlCompare(v, hi) <= 0) != e.not)
+}
+
+// sqlFuncArgs lists the supported functions with their least and most arguments (-1 = any)
+var sqlFuncArgs = map[string][2]int{
+	"count": {0, 1}, "sum": {1, 1}, "avg": {1, 1}, "total": {1, 1}, "group_concat": {1, 2},
+	"min": {1, -1}, "max": {1, -1}, "lower": {1, 1}, "upper": {1, 1}, "length": {1, 1},
+	"abs": {1, 1}, "typeof": {1, 1}, "hex": {1, 1}, "coalesce": {2, -1}, "ifnull": {2, 2},
+	"nullif": {2, 2}, "substr": {2, 3}, "substring": {2, 3}, "round": {1, 2}, "trim": {1, 2},
+	"replace": {3, 3}, "instr": {2, 2},
+}
+
+// isAggregate reports whether the call is an aggregate (min/max with one argument are)
+func (e *sqlFunc) isAggregate() bool {
+	switch e.name {
+	case "count", "sum", "avg", "total", "group_concat":
+		return true
+	case "min", "max":
+		return len(e.args) == 1
+	}
+	return false
+}
+
+func (e *sqlFunc) bind(resolve func(string) (int, error)) error {
+	for _, arg := range e.args {
+		if err := arg.bind(resolve); err != nil {
+			return err
+		}
+	}
+	if _, ok := sqlFuncArgs[e.name]; ok {
+		return nil
+	}
+	return fmt.Errorf("unsupported function %s()", e.name)
+}
+
+// accumulate adds a row to the aggregate's current group
+func (e *sqlFunc) accumulate(row []sqlValue) {
+	st := e.states[*e.group]
+	if st == nil {
+		st = &sqlAggState{}
+		e.states[*e.group] = st
+	}
+	if e.star {
+		st.count++
+		return
+	}
+	v := e.args[0].eval(row)
+	if v == nil {
+		return
+	}
+	st.count++
+	switch e.name {
+	case "sum", "avg", "total":
+		switch n := sqlNumeric(v).(type) {
+		case int64:
+			st.sumInt += n
+			st.sumF += float64(n)
+		case float64:
+			st.isFloat = true
+			st.sumF += n
+		}
+	case "min":
+		if st.best == nil || sqlCompare(v, st.best) < 0 {
+			st.best = v
+		}
+	case "max":
+		if st.best == nil || sqlCompare(v, st.best) > 0 {
+			st.best = v
+		}
+	case "group_concat":
+		st.parts = append(st.parts, sqlText(v))
+	}
+}
+
+func (e *sqlFunc) eval(row []sqlValue) sqlValue {
+	if e.isAggregate() {
+		st := e.states[*e.group]
+		if st == nil {
+			st = &sqlAggState{}
+		}
+		switch e.name {
+		case "count":
+			return st.count
+		case "sum":
+			if st.count == 0 {
+				return nil
+			} else if st.isFloat {
+				return st.sumF
+			}
+			return st.sumInt
+		case "total":
+			return st.sumF
+		case "avg":
+			if st.count == 0 {
+				return nil
+			}
+			return st.sumF / float64(st.count)
+		case "min", "max":
+			return st.best
+		case "group_concat":
+			if st.count == 0 {
+				return nil
+			}
+			sep := ","
+			if len(e.args) > 1 {
+				sep = sqlText(e.args[1].eval(row))
+			}
+			return strings.Join(st.parts, sep)
+		}
+	}
+
+	args := make([]sqlValue, len(e.args))
+	for i, arg := range e.args {
+		args[i] = arg.eval(row)
+	}
+	arg := func(i int) sqlValue {
+		if i < len(args) {
+			return args[i]
+		}
+		return nil
+	}
+
+	switch e.name {
+	case "coalesce", "ifnull":
+		for _, v := range args {
+			if v != nil {
+				return v
+			}
+		}
+		return nil
+	case "nullif":
+		if arg(0) != nil && arg(1) != nil && sqlCompare(arg(0), arg(1)) == 0 {
+			return nil
+		}
+		return arg(0)
+	case "typeof":
+		switch arg(0).(type) {
+		case nil:
+			return "null"
+		case int64:
+			return "integer"
+		case float64:
+			return "real"
+		case []byte:
+			return "blob"
+		}
+		return "text"
+	case "min", "max":
+		var best sqlValue
+		for _, v := range args {
+			if v == nil {
+				return nil
+			}
+			if best == nil || (e.name == "min") == (sqlCompare(v, best) < 0) {
+				best = v
+			}
+		}
+		return best
+	}
+
+	v := arg(0)
+	if v == nil {
+		return nil
+	}
+	switch e.name {
+	case "lower":
+		return strings.ToLower(sqlText(v))
+	case "upper":
+		return strings.ToUpper(sqlText(v))
+	case "length":
+		if b, ok := v.([]byte); ok {
+			return int64(len(b))
+		}
+		return int64(len([]rune(sqlText(v))))
+	case "hex":
+		return fmt.Sprintf("%X", sqlText(v))
+	case "trim":
+		if len(args) > 1 {
+			if arg(1) == nil {
+				return nil
+			}
+			return strings.Trim(sqlText(v), sqlText(arg(1)))
+		}
+		return strings.TrimSpace(sqlText(v))
+	case "abs":
+		switch n := sqlNumeric(v).(type) {
+		case int64:
+			if n < 0 {
+				return -n
+			}
+			return n
+		case float64:
+			return math.Abs(n)
+		}
+	case "round":
+		// Like SQLite: 0 to 30 digits, and values too large to have a fraction are kept
+		digits := int64(0)
+		if d, ok := sqlNumeric(arg(1)).(int64); ok {
+			digits = min64(max64(d, 0), 30)
+		}
+		f, _ := sqlFloat(v)
+		scaled := f * math.Pow(10, float64(digits))
+		if math.IsInf(scaled, 0) || math.IsNaN(scaled) || math.Abs(scaled) >= 1<<52 {
+			return f
+		}
+		return math.Round(scaled) / math.Pow(10, float64(digits))
+	case "instr":
+		if arg(1) == nil {
+			return nil
+		}
+		text := sqlText(v)
+		idx := strings.Index(text, sqlText(arg(1)))
+		if idx < 0 {
+			return int64(0)
+		}
+		return int64(utf8.RuneCountInString(text[:idx]) + 1)
+	case "replace":
+		if arg(1) == nil || arg(2) == nil {
+			return nil
+		} else if sqlText(arg(1)) == "" {
+			return sqlText(v) // An empty pattern matches nothing
+		}
+		return strings.ReplaceAll(sqlText(v), sqlText(arg(1)), sqlText(arg(2)))
+	case "substr", "substring":
+		runes := []rune(sqlText(v))
+		start, _ := sqlNumeric(arg(1)).(int64)
+		if start > 0 {
+			start--
+		} else if start < 0 {
+			start += int64(len(runes))
+		}
+		end := int64(len(runes))
+		if n, ok := sqlNumeric(arg(2)).(int64); ok && len(args) > 2 {
+			end = start + n
+		}
+		start = min64(max64(start, 0), int64(len(runes)))
+		end = min64(max64(end, start), int64(len(runes)))
+		return string(runes[start:end])
+	}
+	return nil
+}
+
+// min64 returns the smaller of two int64 values
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// sqlBool converts a Go bool to SQLite's 1 / 0
+func sqlBool(b bool) sqlValue {
+	if b {
+		return int64(1)
+	}
+	return int64(0)
+}
+
+// sqlTruth reports whether a non-NULL value is true (non-zero)
+func sqlTruth(v sqlValue) bool {
+	f, _ := sqlFloat(v)
+	return f != 0
+}
+
+// sqlText converts a value to text
+func sqlText(v sqlValue) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	}
+	return formatSQLValue(v)
+}
+
+// sqlNumeric converts a value to int64 or float64 (text that isn't a number becomes 0)
+func sqlNumeric(v sqlValue) sqlValue {
+	switch v := v.(type) {
+	case int64, float64:
+		return v
+	case nil:
+		return nil
+	}
+	s := strings.TrimSpace(sqlText(v))
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return int64(0)
+}
+
+// sqlFloat returns v as a float64 and whether it was already a number
+func sqlFloat(v sqlValue) (float64, bool) {
+	switch n := sqlNumeric(v).(type) {
+	case int64:
+		_, isNum := v.(int64)
+		return float64(n), isNum
+	case float64:
+		_, isNum := v.(float64)
+		return n, isNum
+	}
+	return 0, false
+}
+
+// sqlArithmetic applies + - * / % to numeric values. Integer results that
+// overflow are computed as REAL, as SQLite does.
+func sqlArithmetic(op string, l, r sqlValue) sqlValue {
+	li, lInt := l.(int64)
+	ri, rInt := r.(int64)
+	if lInt && rInt {
+		switch op {
+		case "+":
+			if sum := li + ri; (sum > li) == (ri > 0) {
+				return sum
+			}
+		case "-":
+			if diff := li - ri; (diff < li) == (ri > 0) {
+				return diff
+			}
+		case "*":
+			if li == 0 || ri == 0 {
+				return int64(0)
+			}
+			if prod := li * ri; prod/ri == li && !(li == -1 && ri == math.MinInt64) && !(ri == -1 && li == math.MinInt64) {
+				return prod
+			}
+		case "/", "%":
+			if ri == 0 {
+				return nil
+			}
+			if op == "%" {
+				return li % ri
+			} else if li != math.MinInt64 || ri != -1 {
+				return li / ri
+			}
+		}
+	}
+	lf, _ := sqlFloat(l)
+	rf, _ := sqlFloat(r)
+	switch op {
+	case "+":
+		return lf + rf
+	case "-":
+		return lf - rf
+	case "*":
+		return lf * rf
+	case "/":
+		if rf == 0 {
+			return nil
+		}
+		return lf / rf
+	case "%":
+		if int64(rf) == 0 {
+			return nil
+		}
+		return float64(int64(lf) % int64(rf))
+	}
+	return nil
+}
+
+// sqlTypeRank orders values of different types like SQLite: NULL < numbers < text < blobs
+func sqlTypeRank(v sqlValue) int {
+	switch v.(type) {
+	case nil:
+		return 0
+	case int64, float64:
+		return 1
+	case string:
+		return 2
+	}
+	return 3
+}
+
+// sqlCompare compares two values. Text that looks like a number compares
+// numerically against numbers (as with a column's numeric affinity).
+func sqlCompare(a, b sqlValue) int {
+	ra, rb := sqlTypeRank(a), sqlTypeRank(b)
+	if ra != rb && ra+rb == 3 { // Number vs text
+		text := a
+		if ra == 1 {
+			text = b
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(text.(string)), 64); err == nil {
+			ra, rb = 1, 1
+			a, b = sqlNumeric(a), sqlNumeric(b)
+		}
+	}
+	if ra != rb {
+		return ra - rb
+	}
+
+	switch ra {
+	case 0:
+		return 0
+	case 1:
+		ai, aInt := a.(int64)
+		bi, bInt := b.(int64)
+		if aInt && bInt {
+			switch {
+			case ai < bi:
+				return -1
+			case ai > bi:
+				return 1
+			}
+			return 0
+		}
+		af, _ := sqlFloat(a)
+		bf, _ := sqlFloat(b)
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(sqlText(a), sqlText(b))
+}
+
+// sqlLike matches text against a LIKE pattern (% and _ wildcards, ASCII case-insensitive)
+func sqlLike(pattern, text string) bool {
+	p, t := []rune(strings.ToLower(pattern)), []rune(strings.ToLower(text))
+	var match func(pi, ti int) bool
+	match = func(pi, ti int) bool {
+		for pi < len(p) {
+			switch p[pi] {
+			case '%':
+				for pi < len(p) && p[pi] == '%' {
+					pi++
+				}
+				if pi == len(p) {
+					return true
+				}
+				for k := ti; k <= len(t); k++ {
+					if match(pi, k) {
+						return true
+					}
+				}
+				return false
+			case '_':
+				if ti >= len(t) {
+					return false
+				}
+			default:
+				if ti >= len(t) || t[ti] != p[pi] {
+					return false
+				}
+			}
+			pi++
+			ti++
+		}
+		return ti == len(t)
+	}
+	return match(0, 0)
+}
+
+// sqlSelect is a parsed SELECT statement
+type sqlSelect struct {
+	distinct bool
+	items    []sqlSelectItem
+	from     string
+	where    sqlExpr
+	groupBy  []sqlExpr
+	orderBy  []sqlOrderTerm
+	limit    int64 // -1 = no limit
+	offset   int64
+
+	aggregates []*sqlFunc
+	group      string // Current group key (aggregates read their state through a pointer to it)
+}
+
+type sqlSelectItem struct {
+	star bool
+	expr sqlExpr
+	name string // Output column name
+	text string // Expression as written (matched by ORDER BY)
+}
+
+type sqlOrderTerm struct {
+	expr   sqlExpr
+	text   string
+	desc   bool
+	column int // Output column the term refers to (-1 = evaluated on the source row)
+}
+
+// sqlParser is a recursive-descent parser over the token list
+type sqlParser struct {
+	toks []sqlToken
+	pos  int
+	q    *sqlSelect
+}
+
+func (p *sqlParser) peek() sqlToken {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return sqlToken{kind: sqlTokEnd}
+}
+
+func (p *sqlParser) next() sqlToken {
+	t := p.peek()
+	if p.pos < len(p.toks) {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the operator op if it's next
+func (p *sqlParser) accept(op string) bool {
+	if p.peek().is(op) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// acceptKeyword consumes the keywords kws if they come next, in order
+func (p *sqlParser) acceptKeyword(kws ...string) bool {
+	for i, kw := range kws {
+		if p.pos+i >= len(p.toks) || !p.toks[p.pos+i].isKeyword(kw) {
+			return false
+		}
+	}
+	p.pos += len(kws)
+	return true
+}
+
+func (p *sqlParser) expect(op string) error {
+	if !p.accept(op) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *sqlParser) unexpected() error {
+	t := p.peek()
+	if t.kind == sqlTokEnd {
+		return errors.New("unexpected end of query")
+	}
+	return fmt.Errorf("syntax error near %q", t.text)
+}
+
+// text joins the tokens from start to the current position
+func (p *sqlParser) text(start int) string {
+	var b strings.Builder
+	for i, t := range p.toks[start:p.pos] {
+		if i > 0 {
+			prev := p.toks[start+i-1]
+			attached := t.is(")") || t.is(",") || t.is(".") || prev.is("(") || prev.is(".") ||
+				(t.is("(") && prev.kind == sqlTokIdent)
+			if !attached {
+				b.WriteByte(' ')
+			}
+		}
+		switch t.kind {
+		case sqlTokString:
+			b.WriteString("'" + strings.ReplaceAll(t.text, "'", "''") + "'")
+		case sqlTokBlob:
+			b.WriteString("x'" + t.text + "'")
+		case sqlTokQuoted:
+			b.WriteString(`"` + strings.ReplaceAll(t.text, `"`, `""`) + `"`)
+		default:
+			b.WriteString(t.text)
+		}
+	}
+	return b.String()
+}
+
+// sqlReservedAfterItem lists words that end a result column or table name (instead of being an alias)
+var sqlReservedAfterItem = map[string]bool{"from": true, "where": true, "group": true, "order": true,
+	"limit": true, "having": true, "join": true, "inner": true, "left": true, "cross": true,
+	"natural": true, "union": true, "on": true, "offset": true, "window": true}
+
+// parseSQLSelect parses a SELECT statement
+func parseSQLSelect(sql string) (*sqlSelect, error) {
+	p := &sqlParser{toks: sqlTokenize(sql), q: &sqlSelect{limit: -1}}
+	q := p.q
+
+	if !p.acceptKeyword("select") {
+		if p.peek().kind == sqlTokEnd {
+			return nil, errors.New("empty query")
+		}
+		return nil, errors.New("only SELECT queries are supported (the database is opened read-only)")
+	}
+	q.distinct = p.acceptKeyword("distinct")
+	p.acceptKeyword("all")
+
+	for {
+		start := p.pos
+		if p.accept("*") {
+			q.items = append(q.items, sqlSelectItem{star: true})
+		} else if p.peek().kind == sqlTokIdent && p.pos+2 < len(p.toks) && p.toks[p.pos+1].is(".") && p.toks[p.pos+2].is("*") {
+			p.pos += 3 // table.*
+			q.items = append(q.items, sqlSelectItem{star: true})
+		} else {
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			item := sqlSelectItem{expr: expr, text: p.text(start)}
+			item.name = item.text
+			if ref, ok := expr.(*sqlColumnRef); ok {
+				item.name = ref.name
+			}
+			if p.acceptKeyword("as") || (p.peek().kind == sqlTokIdent && !sqlReservedAfterItem[strings.ToLower(p.peek().text)]) ||
+				p.peek().kind == sqlTokQuoted || p.peek().kind == sqlTokString {
+				alias := p.next()
+				if alias.kind == sqlTokEnd || alias.kind == sqlTokOp || alias.kind == sqlTokNumber || alias.kind == sqlTokBlob {
+					return nil, p.unexpected()
+				}
+				item.name = alias.text
+			}
+			q.items = append(q.items, item)
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	if p.acceptKeyword("from") {
+		name := p.next()
+		if name.kind != sqlTokIdent && name.kind != sqlTokQuoted && name.kind != sqlTokString {
+			p.pos--
+			return nil, p.unexpected()
+		}
+		q.from = name.text
+		if p.accept(".") { // main.table
+			q.from = p.next().text
+		}
+		if p.acceptKeyword("as") || (p.peek().kind == sqlTokIdent && !sqlReservedAfterItem[strings.ToLower(p.peek().text)]) {
+			p.next() // Table alias - column qualifiers are ignored
+		}
+		if p.peek().is(",") || p.peek().isKeyword("join") || p.peek().isKeyword("inner") ||
+			p.peek().isKeyword("left") || p.peek().isKeyword("cross") || p.peek().isKeyword("natural") {
+			return nil, errors.New("joins aren't supported - query one table or view at a time")
+		}
+	}
+
+	if p.acceptKeyword("where") {
+		var err error
+		if q.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("group", "by") {
+		for {
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			q.groupBy = append(q.groupBy, expr)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if p.peek().isKeyword("having") {
+		return nil, errors.New("HAVING isn't supported - filter with WHERE instead")
+	}
+
+	if p.acceptKeyword("order", "by") {
+		for {
+			start := p.pos
+			expr, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			term := sqlOrderTerm{expr: expr, text: p.text(start), column: -1}
+			if p.acceptKeyword("desc") {
+				term.desc = true
+			} else {
+				p.acceptKeyword("asc")
+			}
+			q.orderBy = append(q.orderBy, term)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("limit") {
+		n, err := p.parseCount()
+		if err != nil {
+			return nil, err
+		}
+		q.limit = n
+		if p.acceptKeyword("offset") {
+			if q.offset, err = p.parseCount(); err != nil {
+				return nil, err
+			}
+		} else if p.accept(",") { // LIMIT offset, count
+			q.offset = n
+			if q.limit, err = p.parseCount(); err != nil {
+				return nil, err
+			}
+		}
+		// A negative limit means no limit and a negative offset skips nothing, as in SQLite
+		q.limit = max64(q.limit, -1)
+		q.offset = max64(q.offset, 0)
+	}
+
+	p.accept(";")
+	if p.peek().kind != sqlTokEnd {
+		if p.peek().isKeyword("union") {
+			return nil, errors.New("UNION isn't supported")
+		}
+		return nil, p.unexpected()
+	}
+	return q, nil
+}
+
+// parseCount parses a LIMIT / OFFSET number (optionally signed)
+func (p *sqlParser) parseCount() (int64, error) {
+	neg := p.accept("-")
+	if !neg {
+		p.accept("+")
+	}
+	t := p.next()
+	n, err := strconv.ParseInt(t.text, 10, 64)
+	if t.kind != sqlTokNumber || err != nil {
+		p.pos--
+		return 0, p.unexpected()
+	}
+	if neg {
+		n = -n
+	}
+	return n, nil
+}
+
+// Expression grammar, loosest binding first:
+// OR, AND, NOT, comparisons (= < IS IN LIKE BETWEEN), + -, * / %, ||, unary -, primary
+func (p *sqlParser) parseExpr() (sqlExpr, error) {
+	l, err := p.parseAnd()
+	for err == nil && p.acceptKeyword("or") {
+		var r sqlExpr
+		if r, err = p.parseAnd(); err == nil {
+			l = &sqlBinary{op: "or", l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *sqlParser) parseAnd() (sqlExpr, error) {
+	l, err := p.parseNot()
+	for err == nil && p.acceptKeyword("and") {
+		var r sqlExpr
+		if r, err = p.parseNot(); err == nil {
+			l = &sqlBinary{op: "and", l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *sqlParser) parseNot() (sqlExpr, error) {
+	if p.acceptKeyword("not") {
+		x, err := p.parseNot()
+		return &sqlUnary{op: "not", x: x}, err
+	}
+	return p.parseComparison()
+}
+
+func (p *sqlParser) parseComparison() (sqlExpr, error) {
+	l, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.is("=") || t.is("==") || t.is("!=") || t.is("<>") || t.is("<") || t.is("<=") || t.is(">") || t.is(">="):
+			p.next()
+			r, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			l = &sqlBinary{op: t.text, l: l, r: r}
+		case t.isKeyword("is"):
+			p.next()
+			not := p.acceptKeyword("not")
+			if p.acceptKeyword("null") {
+				l = &sqlIsNull{x: l, not: not}
+				continue
+			}
+			r, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			op := "=="
+			if not {
+				op = "!="
+			}
+			l = &sqlBinary{op: op, l: l, r: r}
+		case t.isKeyword("isnull"), t.isKeyword("notnull"):
+			p.next()
+			l = &sqlIsNull{x: l, not: t.isKeyword("notnull")}
+		default:
+			not := p.acceptKeyword("not")
+			switch {
+			case p.acceptKeyword("like"):
+				r, err := p.parseAdditive()
+				if err != nil {
+					return nil, err
+				}
+				l = &sqlBinary{op: "like", l: l, r: r}
+			case p.acceptKeyword("in"):
+				if err := p.expect("("); err != nil {
+					return nil, err
+				}
+				in := &sqlIn{x: l, not: not}
+				for !p.peek().is(")") {
+					item, err := p.parseExpr()
+					if err != nil {
+						return nil, err
+					}
+					in.list = append(in.list, item)
+					if !p.accept(",") {
+						break
+					}
+				}
+				if err := p.expect(")"); err != nil {
+					return nil, err
+				}
+				l, not = in, false
+			case p.acceptKeyword("between"):
+				lo, err := p.parseAdditive()
+				if err != nil {
+					return nil, err
+				}
+				if !p.acceptKeyword("and") {
+					return nil, p.unexpected()
+				}
+				hi, err := p.parseAdditive()
+				if err != nil {
+					return nil, err
+				}
+				l, not = &sqlBetween{x: l, lo: lo, hi: hi, not: not}, false
+			case not:
+				return nil, p.unexpected()
+			default:
+				return l, nil
+			}
+			if not {
+				l = &sqlUnary{op: "not", x: l}
+			}
+		}
+	}
+}
+
+func (p *sqlParser) parseAdditive() (sqlExpr, error) {
+	l, err := p.parseMultiplicative()
+	for err == nil && (p.peek().is("+") || p.peek().is("-")) {
+		op := p.next().text
+		var r sqlExpr
+		if r, err = p.parseMultiplicative(); err == nil {
+			l = &sqlBinary{op: op, l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *sqlParser) parseMultiplicative() (sqlExpr, error) {
+	l, err := p.parseConcat()
+	for err == nil && (p.peek().is("*") || p.peek().is("/") || p.peek().is("%")) {
+		op := p.next().text
+		var r sqlExpr
+		if r, err = p.parseConcat(); err == nil {
+			l = &sqlBinary{op: op, l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *sqlParser) parseConcat() (sqlExpr, error) {
+	l, err := p.parseUnary()
+	for err == nil && p.accept("||") {
+		var r sqlExpr
+		if r, err = p.parseUnary(); err == nil {
+			l = &sqlBinary{op: "||", l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *sqlParser) parseUnary() (sqlExpr, error) {
+	if p.accept("-") {
+		x, err := p.parseUnary()
+		return &sqlUnary{op: "-", x: x}, err
+	}
+	p.accept("+")
+	return p.parsePrimary()
+}
+
+func (p *sqlParser) parsePrimary() (sqlExpr, error) {
+	t := p.next()
+	switch t.kind {
+	case sqlTokNumber, sqlTokString:
+		return &sqlLiteral{v: t.literal()}, nil
+	case sqlTokBlob:
+		if _, err := hex.DecodeString(t.text); err != nil {
+			return nil, fmt.Errorf("unrecognized token: \"x'%s'\"", t.text)
+		}
+		return &sqlLiteral{v: t.literal()}, nil
+	case sqlTokOp:
+		if t.is("(") {
+			if p.peek().isKeyword("select") {
+				return nil, errors.New("subqueries aren't supported")
+			}
+			x, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	case sqlTokIdent, sqlTokQuoted:
+		if t.kind == sqlTokIdent {
+			switch strings.ToLower(t.text) {
+			case "null", "true", "false":
+				return &sqlLiteral{v: t.literal()}, nil
+			case "case", "cast", "exists":
+				return nil, fmt.Errorf("%s expressions aren't supported", strings.ToUpper(t.text))
+			}
+			if p.accept("(") {
+				return p.parseCall(strings.ToLower(t.text))
+			}
+		}
+		name := t.text
+		if p.accept(".") { // table.column - the qualifier is ignored
+			col := p.next()
+			if col.kind != sqlTokIdent && col.kind != sqlTokQuoted {
+				p.pos--
+				return nil, p.unexpected()
+			}
+			name = col.text
+		}
+		return &sqlColumnRef{name: name}, nil
+	}
+	p.pos--
+	return nil, p.unexpected()
+}
+
+// parseCall parses a function's arguments (after the opening parenthesis)
+func (p *sqlParser) parseCall(name string) (sqlExpr, error) {
+	fn := &sqlFunc{name: name, group: &p.q.group, states: make(map[string]*sqlAggState)}
+	if p.accept("*") {
+		fn.star = true
+	} else if p.peek().isKeyword("distinct") {
+		return nil, errors.New("DISTINCT inside functions isn't supported")
+	} else {
+		for !p.peek().is(")") {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			fn.args = append(fn.args, arg)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if fn.star && name != "count" {
+		return nil, fmt.Errorf("%s(*) isn't valid", name)
+	}
+	if args, ok := sqlFuncArgs[name]; ok && (len(fn.args) < args[0] || args[1] >= 0 && len(fn.args) > args[1]) {
+		return nil, fmt.Errorf("wrong number of arguments to function %s()", name)
+	}
+	if name == "count" && len(fn.args) == 0 {
+		fn.star = true // count() counts rows like count(*)
+	}
+	if fn.isAggregate() {
+		p.q.aggregates = append(p.q.aggregates, fn)
+	}
+	return fn, nil
+}
+
+// sqlSource is something rows can be selected from: a table, index or view
+type sqlSource struct {
+	columns  []string
+	hasRowid bool // Rows carry the rowid after the columns
+	scan     func(fn func(row []sqlValue) bool) error
+}
+
+// sqlResult is the output of a query
+type sqlResult struct {
+	columns   []string
+	rows      [][]sqlValue
+	truncated bool // More rows matched than were kept
+}
+
+// source returns the rows of the table, index or view called name
+func (db *sqliteDB) source(c *sqliteConn, name string, depth int, cancelled func() bool) (*sqlSource, error) {
+	obj := db.object(name)
+	if obj == nil {
+		return nil, fmt.Errorf("no such table: %s", name)
+	}
+
+	switch obj.kind {
+	case "table":
+		if obj.virtual {
+			return nil, fmt.Errorf("%s is a virtual table - its data can't be read without its module", obj.name)
+		}
+		if obj.root == 0 {
+			return nil, fmt.Errorf("%s has no data pages", obj.name)
+		}
+		src := &sqlSource{columns: obj.columnNames()}
+		if obj.withoutRowid {
+			src.scan = func(fn func([]sqlValue) bool) error {
+				return c.scanIndex(obj.root, func(rec []sqlValue) bool {
+					return fn(obj.tableRow(0, rec))
+				})
+			}
+			return src, nil
+		}
+		src.hasRowid = true
+		src.scan = func(fn func([]sqlValue) bool) error {
+			return c.scanTable(obj.root, func(rowid int64, rec []sqlValue) bool {
+				return fn(append(obj.tableRow(rowid, rec), rowid))
+			})
+		}
+		return src, nil
+
+	case "index":
+		if obj.root == 0 {
+			return nil, fmt.Errorf("%s has no data pages", obj.name)
+		}
+		return &sqlSource{columns: db.indexColumns(obj), scan: func(fn func([]sqlValue) bool) error {
+			return c.scanIndex(obj.root, fn)
+		}}, nil
+
+	case "view":
+		if depth >= sqlMaxViewDepth {
+			return nil, fmt.Errorf("views nest too deeply at %s", obj.name)
+		}
+		q, err := parseViewSQL(obj.sql)
+		if err != nil {
+			return nil, fmt.Errorf("view %s: %w", obj.name, err)
+		}
+		res, err := db.run(c, q, sqlMaxSortRows, depth+1, cancelled)
+		if err != nil {
+			return nil, fmt.Errorf("view %s: %w", obj.name, err)
+		}
+		return &sqlSource{columns: res.columns, scan: func(fn func([]sqlValue) bool) error {
+			for _, row := range res.rows {
+				if !fn(row) {
+					break
+				}
+			}
+			return nil
+		}}, nil
+	}
+	return nil, fmt.Errorf("%s is a %s, not a table", obj.name, obj.kind)
+}
+
+// parseViewSQL parses the SELECT of a CREATE VIEW statement
+func parseViewSQL(sql string) (*sqlSelect, error) {
+	toks := sqlTokenize(sql)
+	for i, t := range toks {
+		if t.isKeyword("as") && i+1 < len(toks) && toks[i+1].isKeyword("select") {
+			return parseSQLSelect(sqlJoinTokens(toks[i+1:]))
+		}
+	}
+	return nil, errors.New("unsupported view definition")
+}
+
+// sqlJoinTokens turns tokens back into SQL text
+func sqlJoinTokens(toks []sqlToken) string {
+	p := &sqlParser{toks: toks, pos: len(toks)}
+	return p.text(0)
+}
+
+// query parses and runs a read-only SELECT, keeping at most maxRows rows
+func (db *sqliteDB) query(sql string, maxRows int, cancelled func() bool) (*sqlResult, error) {
+	q, err := parseSQLSelect(sql)
+	if err != nil {
+		return nil, err
+	}
+	c, err := db.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return db.run(c, q, maxRows, 0, cancelled)
+}
+
+// run executes a parsed SELECT
+func (db *sqliteDB) run(c *sqliteConn, q *sqlSelect, maxRows int, depth int, cancelled func() bool) (*sqlResult, error) {
+	src := &sqlSource{scan: func(fn func([]sqlValue) bool) error {
+		fn(nil) // SELECT without FROM: one empty row
+		return nil
+	}}
+	if q.from != "" {
+		var err error
+		if src, err = db.source(c, q.from, depth, cancelled); err != nil {
+			return nil, err
+		}
+	}
+
+	resolve := func(name string) (int, error) {
+		for i, col := range src.columns {
+			if strings.EqualFold(col, name) {
+				return i, nil
+			}
+		}
+		switch strings.ToLower(name) {
+		case "rowid", "oid", "_rowid_":
+			if src.hasRowid {
+				return len(src.columns), nil
+			}
+		}
+		return 0, fmt.Errorf("no such column: %s", name)
+	}
+
+	// Output columns (* expands to the source columns)
+	res := &sqlResult{}
+	var exprs []sqlExpr
+	for _, item := range q.items {
+		if item.star {
+			if q.from == "" {
+				return nil, errors.New("SELECT * needs a FROM clause")
+			}
+			for i, col := range src.columns {
+				res.columns = append(res.columns, col)
+				exprs = append(exprs, &sqlColumnRef{name: col, index: i})
+			}
+			continue
+		}
+		if err := item.expr.bind(resolve); err != nil {
+			return nil, err
+		}
+		res.columns = append(res.columns, item.name)
+		exprs = append(exprs, item.expr)
+	}
+	if q.where != nil {
+		if err := q.where.bind(resolve); err != nil {
+			return nil, err
+		}
+	}
+	for _, g := range q.groupBy {
+		if err := g.bind(resolve); err != nil {
+			return nil, err
+		}
+	}
+
+	// ORDER BY terms name an output column (number, alias or same expression) or a source expression
+	aggregated := len(q.aggregates) > 0 || len(q.groupBy) > 0
+	for i := range q.orderBy {
+		term := &q.orderBy[i]
+		if lit, ok := term.expr.(*sqlLiteral); ok {
+			if n, ok := lit.v.(int64); ok {
+				if n < 1 || int(n) > len(res.columns) {
+					return nil, fmt.Errorf("ORDER BY term %d is out of range", n)
+				}
+				term.column = int(n) - 1
+				continue
+			}
+		}
+		for j, item := range q.items {
+			if !item.star && (strings.EqualFold(item.name, term.text) || strings.EqualFold(item.text, term.text)) {
+				term.column = j
+				break
+			}
+		}
+		if term.column < 0 {
+			if aggregated {
+				return nil, fmt.Errorf("ORDER BY %s must name a result column in an aggregate query", term.text)
+			}
+			if err := term.expr.bind(resolve); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Rows are collected in full when they need sorting, grouping or de-duplication
+	collectAll := len(q.orderBy) > 0 || aggregated || q.distinct
+	keep := int64(maxRows)
+	if q.limit >= 0 && !collectAll {
+		keep = min64(keep, q.limit)
+	}
+
+	type outRow struct {
+		values []sqlValue
+		keys   []sqlValue
+	}
+	var out []outRow
+	var groups []string
+	groupRows := make(map[string][]sqlValue)
+	skipped := int64(0)
+	scanned := 0
+	var scanErr error
+
+	err := src.scan(func(row []sqlValue) bool {
+		scanned++
+		if scanned%sqlCancelCheck == 0 && cancelled != nil && cancelled() {
+			scanErr = errSQLCancelled
+			return false
+		}
+		if q.where != nil {
+			if v := q.where.eval(row); v == nil || !sqlTruth(v) {
+				return true
+			}
+		}
+
+		if aggregated {
+			var key strings.Builder
+			for _, g := range q.groupBy {
+				v := g.eval(row)
+				fmt.Fprintf(&key, "%d:%s\x00", sqlTypeRank(v), sqlText(v))
+			}
+			q.group = key.String()
+			if _, ok := groupRows[q.group]; !ok {
+				if len(groups) >= sqlMaxSortRows {
+					scanErr = fmt.Errorf("more than %d groups", sqlMaxSortRows)
+					return false
+				}
+				groups = append(groups, q.group)
+			}
+			groupRows[q.group] = row // Bare columns show the group's last row
+			for _, agg := range q.aggregates {
+				agg.accumulate(row)
+			}
+			return true
+		}
+
+		if !collectAll && skipped < q.offset {
+			skipped++
+			return true
+		}
+		if !collectAll && int64(len(out)) >= keep {
+			// Another row matched: the result was cut short unless LIMIT did it
+			res.truncated = q.limit < 0 || keep < q.limit
+			return false
+		}
+		if collectAll && len(out) >= sqlMaxSortRows {
+			scanErr = fmt.Errorf("more than %d rows to sort - add a WHERE clause", sqlMaxSortRows)
+			return false
+		}
+		r := outRow{values: make([]sqlValue, len(exprs))}
+		for i, e := range exprs {
+			r.values[i] = e.eval(row)
+		}
+		for _, term := range q.orderBy {
+			if term.column < 0 {
+				r.keys = append(r.keys, term.expr.eval(row))
+			}
+		}
+		out = append(out, r)
+		return true
+	})
+	if err == nil {
+		err = scanErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if aggregated {
+		// Without GROUP BY an aggregate query always returns one row
+		if len(groups) == 0 && len(q.groupBy) == 0 {
+			groups = []string{""}
+		}
+		for _, key := range groups {
+			q.group = key
+			row := groupRows[key]
+			r := outRow{values: make([]sqlValue, len(exprs))}
+			for i, e := range exprs {
+				r.values[i] = e.eval(row)
+			}
+			out = append(out, r)
+		}
+	}
+
+	if q.distinct {
+		seen := make(map[string]bool)
+		unique := out[:0]
+		for _, r := range out {
+			var key strings.Builder
+			for _, v := range r.values {
+				fmt.Fprintf(&key, "%d:%s\x00", sqlTypeRank(v), sqlText(v))
+			}
+			if !seen[key.String()] {
+				seen[key.String()] = true
+				unique = append(unique, r)
+			}
+		}
+		out = unique
+	}
+
+	if len(q.orderBy) > 0 {
+		sort.SliceStable(out, func(a, b int) bool {
+			k := 0
+			for _, term := range q.orderBy {
+				var va, vb sqlValue
+				if term.column >= 0 {
+					va, vb = out[a].values[term.column], out[b].values[term.column]
+				} else {
+					va, vb = out[a].keys[k], out[b].keys[k]
+					k++
+				}
+				if cmp := sqlCompare(va, vb); cmp != 0 {
+					return (cmp < 0) != term.desc
+				}
+			}
+			return false
+		})
+	}
+
+	if collectAll {
+		start := min64(q.offset, int64(len(out)))
+		end := int64(len(out))
+		if q.limit >= 0 {
+			end = min64(end, start+q.limit)
+		}
+		if end-start > int64(maxRows) {
+			end = start + int64(maxRows)
+			res.truncated = true
+		}
+		out = out[start:end]
+	}
+
+	res.rows = make([][]sqlValue, len(out))
+	for i, r := range out {
+		res.rows[i] = r.values
+	}
+	return res, nil
+}
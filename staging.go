@@ -0,0 +1,464 @@
+package main
+
+// Module: staging.go
+// Purpose: Staging, unstaging and discarding changes from changes mode (Ctrl+G)
+// Responsibilities:
+// - Index and worktree status of each changed file
+// - Whole-file stage/unstage/discard
+// - Hunk selection in the diff preview, applied with git apply (--cached) on generated patches
+// - Discards go to trash first so they can be undone
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitPatch is one file's diff split into its header and hunks
+type gitPatch struct {
+	header []string   // diff --git, index, --- and +++ lines
+	hunks  [][]string // Each starts with its @@ line
+}
+
+// hunkSelection is the hunk picked in the changes mode diff with [ and ]
+type hunkSelection struct {
+	path   string
+	root   string
+	rel    string
+	staged bool // Hunks of the staged diff (HEAD → index) instead of the unstaged one (index → worktree)
+	patch  *gitPatch
+	cursor int
+}
+
+// gitStatusWord names one side of a porcelain status code (index or worktree)
+func gitStatusWord(c byte) string {
+	switch c {
+	case 'M':
+		return "modified"
+	case 'A':
+		return "added"
+	case 'D':
+		return "deleted"
+	case 'R':
+		return "renamed"
+	case 'C':
+		return "copied"
+	case 'T':
+		return "type"
+	case 'U':
+		return "conflict"
+	case '?':
+		return "untracked"
+	case '!':
+		return "ignored"
+	}
+	return "—"
+}
+
+// changeStatusColumns returns the staged (index) and unstaged (worktree) status of a changed file
+func changeStatusColumns(file fileItem) (staged, unstaged string) {
+	code := extractGitStatusCode(file.name)
+	if len(code) != 2 {
+		return "—", "—"
+	}
+	if code == "??" {
+		return "—", "untracked"
+	}
+	return gitStatusWord(code[0]), gitStatusWord(code[1])
+}
+
+// parseGitPatch splits `git diff` output for one file into header and hunks
+func parseGitPatch(diff string) *gitPatch {
+	p := &gitPatch{}
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			p.hunks = append(p.hunks, []string{line})
+		case len(p.hunks) > 0:
+			p.hunks[len(p.hunks)-1] = append(p.hunks[len(p.hunks)-1], line)
+		case line != "":
+			p.header = append(p.header, line)
+		}
+	}
+	return p
+}
+
+// hunkPatch returns a patch applying only hunk i
+func (p *gitPatch) hunkPatch(i int) string {
+	lines := append(append([]string{}, p.header...), p.hunks[i]...)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// runGit runs git in root, feeding stdin when given; errors carry git's message
+func runGit(root, stdin string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", gitErrorText(err)
+	}
+	return string(out), nil
+}
+
+// changedFileTarget returns the selected changed file with its git root and path relative to it
+func (m *model) changedFileTarget() (file *fileItem, root, rel string, ok bool) {
+	file = m.getCurrentFile()
+	if file == nil || file.isDir || extractGitStatusCode(file.name) == "" {
+		m.setStatusMessage("Select a changed file", true)
+		return nil, "", "", false
+	}
+	root = m.resolveGitRoot()
+	rel, err := filepath.Rel(root, file.path)
+	if root == "" || err != nil {
+		m.setStatusMessage("Not inside a git repository", true)
+		return nil, "", "", false
+	}
+	return file, root, filepath.ToSlash(rel), true
+}
+
+// refreshChanges rescans git status after staging, keeping the cursor in range
+// and reloading the selected hunks if any
+func (m *model) refreshChanges() {
+	if changed, err := m.getChangedFiles(); err == nil {
+		m.changedFiles = changed
+		m.agentFileMap = buildAgentFileMap(changed, m.agentSessions)
+	}
+	m.invalidateGitStatus()
+	m.loadFiles()
+	if m.cursor >= len(m.changedFiles) {
+		m.cursor = max(0, len(m.changedFiles)-1)
+	}
+	m.reloadHunkSelection()
+	m.preview.cacheValid = false
+}
+
+// reloadHunkSelection re-reads the selected hunks after the index or the file changed;
+// the selection ends when its side has no hunks left
+func (m *model) reloadHunkSelection() {
+	sel := m.activeHunkSelection()
+	if sel == nil {
+		m.hunkSel = nil
+		return
+	}
+	if err := m.loadHunkSelection(sel.staged); err != nil || len(m.hunkSel.patch.hunks) == 0 {
+		m.hunkSel = nil
+	}
+}
+
+// stageFile stages the selected file's changes (including deletions); with a hunk selected,
+// only that hunk
+func (m *model) stageFile() {
+	if sel := m.activeHunkSelection(); sel != nil {
+		m.applyHunk(false)
+		return
+	}
+	_, root, rel, ok := m.changedFileTarget()
+	if !ok {
+		return
+	}
+	if _, err := runGit(root, "", "add", "-A", "--", rel); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Stage failed: %v", err), true)
+		return
+	}
+	m.refreshChanges()
+	m.setStatusMessage("✓ Staged "+rel, false)
+}
+
+// unstageFile removes the selected file's changes from the index; with a hunk selected,
+// only that hunk
+func (m *model) unstageFile() {
+	if sel := m.activeHunkSelection(); sel != nil {
+		m.applyHunk(true)
+		return
+	}
+	_, root, rel, ok := m.changedFileTarget()
+	if !ok {
+		return
+	}
+	if _, err := runGit(root, "", "restore", "--staged", "--", rel); err != nil {
+		// No commits yet: there's no HEAD to restore from
+		if _, rmErr := runGit(root, "", "rm", "--cached", "-q", "--", rel); rmErr != nil {
+			m.setStatusMessage(fmt.Sprintf("Unstage failed: %v", err), true)
+			return
+		}
+	}
+	m.refreshChanges()
+	m.setStatusMessage("✓ Unstaged "+rel, false)
+}
+
+// confirmDiscard asks before discarding the selected file's (or hunk's) unstaged changes
+func (m *model) confirmDiscard() {
+	if sel := m.activeHunkSelection(); sel != nil {
+		if sel.staged {
+			m.setStatusMessage("Staged hunks aren't discarded - press u to unstage it first", true)
+			return
+		}
+		m.dialog = dialogModel{
+			dialogType: dialogConfirm,
+			title:      "Discard Hunk",
+			message:    fmt.Sprintf("Discard hunk %d/%d of '%s'?\nThe current file is moved to trash first.", sel.cursor+1, len(sel.patch.hunks), sel.rel),
+		}
+		m.showDialog = true
+		return
+	}
+	file, _, rel, ok := m.changedFileTarget()
+	if !ok {
+		return
+	}
+	code := extractGitStatusCode(file.name)
+	message := fmt.Sprintf("Discard unstaged changes to '%s'?\nThe current file is moved to trash first.", rel)
+	switch {
+	case code == "??":
+		message = fmt.Sprintf("Discard untracked file '%s'?\nIt is moved to trash.", rel)
+	case code[1] == ' ':
+		m.setStatusMessage("No unstaged changes - press u to unstage first", true)
+		return
+	}
+	m.dialog = dialogModel{
+		dialogType: dialogConfirm,
+		title:      "Discard Changes",
+		message:    message,
+	}
+	m.showDialog = true
+}
+
+// discardFile drops the selected file's unstaged changes: the file goes to trash
+// and the index version is checked out again (untracked files just go to trash)
+func (m *model) discardFile() {
+	file, root, rel, ok := m.changedFileTarget()
+	if !ok {
+		return
+	}
+	if _, err := os.Lstat(file.path); err == nil {
+		if err := m.trashPath(file.path); err != nil {
+			m.setStatusMessage(fmt.Sprintf("Cannot move %s to trash: %v", rel, err), true)
+			return
+		}
+	}
+	if extractGitStatusCode(file.name) != "??" {
+		if _, err := runGit(root, "", "checkout", "--", rel); err != nil {
+			m.setStatusMessage(fmt.Sprintf("Discard failed: %v (the file is in trash)", err), true)
+			m.refreshChanges()
+			return
+		}
+	}
+	m.refreshChanges()
+	m.setStatusMessage(fmt.Sprintf("✓ Discarded changes to %s (previous version is in trash)", rel), false)
+}
+
+// activeHunkSelection returns the hunk selection if it belongs to the selected file
+func (m model) activeHunkSelection() *hunkSelection {
+	sel := m.hunkSel
+	if sel == nil || !m.showChangesOnly || !m.showDiffPreview {
+		return nil
+	}
+	if file := m.getCurrentFile(); file == nil || file.path != sel.path {
+		return nil
+	}
+	return sel
+}
+
+// loadHunkSelection loads the staged or unstaged diff of the selected file into m.hunkSel,
+// keeping the hunk cursor when the same file's diff is reloaded
+func (m *model) loadHunkSelection(staged bool) error {
+	file, root, rel, ok := m.changedFileTarget()
+	if !ok {
+		return fmt.Errorf("no changed file selected")
+	}
+	args := []string{"diff", "--no-color", "--no-ext-diff"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	out, err := runGit(root, "", append(args, "--", rel)...)
+	if err != nil {
+		return err
+	}
+	sel := &hunkSelection{path: file.path, root: root, rel: rel, staged: staged, patch: parseGitPatch(out)}
+	if old := m.hunkSel; old != nil && old.path == sel.path && old.staged == staged {
+		sel.cursor = min(old.cursor, max(0, len(sel.patch.hunks)-1))
+	}
+	m.hunkSel = sel
+	return nil
+}
+
+// moveHunkSelection selects the next (delta > 0) or previous hunk of the selected file's diff.
+// The first press picks the unstaged hunks, or the staged ones when nothing is unstaged.
+func (m *model) moveHunkSelection(delta int) {
+	sel := m.activeHunkSelection()
+	if sel == nil {
+		file, _, _, ok := m.changedFileTarget()
+		if !ok {
+			return
+		}
+		code := extractGitStatusCode(file.name)
+		if code == "??" {
+			m.setStatusMessage("Untracked file: press s to stage it whole", false)
+			return
+		}
+		m.hunkSel = nil
+		if err := m.loadHunkSelection(code[1] == ' '); err != nil {
+			m.setStatusMessage(fmt.Sprintf("Cannot load the diff: %v", err), true)
+			return
+		}
+		sel = m.hunkSel
+		delta = 0
+	} else {
+		sel.cursor += delta
+	}
+	if len(sel.patch.hunks) == 0 {
+		m.hunkSel = nil
+		m.setStatusMessage("No hunks to select", false)
+		return
+	}
+	sel.cursor = max(0, min(sel.cursor, len(sel.patch.hunks)-1))
+	m.scrollToSelectedHunk()
+	m.setStatusMessage(m.hunkSelectionStatus(), false)
+}
+
+// toggleHunkSide switches the hunk selection between the unstaged and staged diff
+func (m *model) toggleHunkSide() {
+	sel := m.activeHunkSelection()
+	if sel == nil {
+		return
+	}
+	if err := m.loadHunkSelection(!sel.staged); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Cannot load the diff: %v", err), true)
+		return
+	}
+	if len(m.hunkSel.patch.hunks) == 0 {
+		side := "unstaged"
+		if m.hunkSel.staged {
+			side = "staged"
+		}
+		m.hunkSel = sel // Stay on the side that has hunks
+		m.setStatusMessage("No "+side+" changes in "+sel.rel, false)
+		return
+	}
+	m.scrollToSelectedHunk()
+	m.setStatusMessage(m.hunkSelectionStatus(), false)
+}
+
+// clearHunkSelection goes back to whole-file staging and the combined diff
+func (m *model) clearHunkSelection() {
+	m.hunkSel = nil
+	m.preview.scrollPos = 0
+	m.setStatusMessage("Hunk selection cleared", false)
+}
+
+// applyHunk stages the selected unstaged hunk, or unstages the selected staged hunk
+func (m *model) applyHunk(unstage bool) {
+	sel := m.hunkSel
+	switch {
+	case unstage && !sel.staged:
+		m.setStatusMessage("This hunk isn't staged - press s to stage it (i: show staged hunks)", true)
+		return
+	case !unstage && sel.staged:
+		m.setStatusMessage("This hunk is already staged - press u to unstage it (i: show unstaged hunks)", true)
+		return
+	}
+	args := []string{"apply", "--cached"}
+	if unstage {
+		args = append(args, "-R")
+	}
+	if _, err := runGit(sel.root, sel.patch.hunkPatch(sel.cursor), append(args, "-")...); err != nil {
+		m.setStatusMessage(fmt.Sprintf("git apply failed: %v", err), true)
+		return
+	}
+	n, total := sel.cursor+1, len(sel.patch.hunks)
+	m.refreshChanges()
+	if unstage {
+		m.setStatusMessage(fmt.Sprintf("✓ Unstaged hunk %d/%d of %s", n, total, sel.rel), false)
+	} else {
+		m.setStatusMessage(fmt.Sprintf("✓ Staged hunk %d/%d of %s", n, total, sel.rel), false)
+	}
+	if m.hunkSel != nil {
+		m.scrollToSelectedHunk()
+	}
+}
+
+// discardHunk reverts the selected unstaged hunk in the working tree.
+// The file is moved to trash first and written back, so the previous version can be restored.
+func (m *model) discardHunk() {
+	sel := m.activeHunkSelection()
+	if sel == nil || sel.staged {
+		return
+	}
+	patch := sel.patch.hunkPatch(sel.cursor)
+	if _, err := runGit(sel.root, patch, "apply", "-R", "--check", "-"); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Cannot discard the hunk: %v", err), true)
+		return
+	}
+	info, err := os.Stat(sel.path)
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Cannot discard the hunk: %v", err), true)
+		return
+	}
+	content, err := os.ReadFile(sel.path)
+	if err == nil {
+		err = m.trashPath(sel.path)
+	}
+	if err == nil {
+		err = os.WriteFile(sel.path, content, info.Mode().Perm())
+	}
+	if err == nil {
+		_, err = runGit(sel.root, patch, "apply", "-R", "-")
+	}
+	n := sel.cursor + 1
+	m.refreshChanges()
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Discard failed: %v", err), true)
+		return
+	}
+	m.setStatusMessage(fmt.Sprintf("✓ Discarded hunk %d of %s (previous version is in trash)", n, sel.rel), false)
+	if m.hunkSel != nil {
+		m.scrollToSelectedHunk()
+	}
+}
+
+// hunkSelectionStatus describes the selected hunk for the status bar
+func (m model) hunkSelectionStatus() string {
+	sel := m.hunkSel
+	if sel.staged {
+		return fmt.Sprintf("Staged hunk %d/%d - u: unstage | i: unstaged hunks | Esc: whole file", sel.cursor+1, len(sel.patch.hunks))
+	}
+	return fmt.Sprintf("Unstaged hunk %d/%d - s: stage | X: discard | i: staged hunks | Esc: whole file", sel.cursor+1, len(sel.patch.hunks))
+}
+
+// hunkDisplayLines lays out the selection's diff with the selected hunk's header marked;
+// start is the display line where the selected hunk begins
+func (m model) hunkDisplayLines() (lines []string, start int) {
+	sel := m.hunkSel
+	lines = m.diffDisplayLines(sel.patch.header)
+	for i, hunk := range sel.patch.hunks {
+		display := m.diffDisplayLines(hunk)
+		if i == sel.cursor {
+			start = len(lines)
+			plain, _ := ansiPlainText(display[0])
+			display[0] = selectedStyle.Render("▶ " + plain)
+		}
+		lines = append(lines, display...)
+	}
+	return lines, start
+}
+
+// scrollToSelectedHunk scrolls the diff preview so the selected hunk starts near the top
+func (m *model) scrollToSelectedHunk() {
+	_, start := m.hunkDisplayLines()
+	m.preview.scrollPos = max(0, start-1)
+	m.preview.cacheValid = false
+}
+
+// renderHunkSelection renders the staged or unstaged diff with the selected hunk marked
+func (m model) renderHunkSelection(maxVisible int) string {
+	sel := m.hunkSel
+	lines, _ := m.hunkDisplayLines()
+	side := "unstaged"
+	if sel.staged {
+		side = "staged"
+	}
+	return m.renderDiffDisplayLines(lines, maxVisible, fmt.Sprintf("%s hunk %d/%d", side, sel.cursor+1, len(sel.patch.hunks)))
+}
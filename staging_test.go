@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGitPatch(t *testing.T) {
+	diff := "diff --git a/f.txt b/f.txt\nindex 1111111..2222222 100644\n--- a/f.txt\n+++ b/f.txt\n" +
+		"@@ -1,2 +1,2 @@\n-one\n+ONE\n two\n@@ -9,2 +9,2 @@\n nine\n-ten\n+TEN\n"
+	p := parseGitPatch(diff)
+	if len(p.header) != 4 || len(p.hunks) != 2 {
+		t.Fatalf("Expected 4 header lines and 2 hunks, got %d and %d", len(p.header), len(p.hunks))
+	}
+	want := "diff --git a/f.txt b/f.txt\nindex 1111111..2222222 100644\n--- a/f.txt\n+++ b/f.txt\n@@ -9,2 +9,2 @@\n nine\n-ten\n+TEN\n"
+	if got := p.hunkPatch(1); got != want {
+		t.Errorf("Expected the second hunk's patch\n%s\ngot\n%s", want, got)
+	}
+
+	staged, unstaged := changeStatusColumns(fileItem{name: "[MD] f.txt"})
+	if staged != "modified" || unstaged != "deleted" {
+		t.Errorf("Expected modified/deleted, got %s/%s", staged, unstaged)
+	}
+	if staged, unstaged := changeStatusColumns(fileItem{name: "[??] new.txt"}); staged != "—" || unstaged != "untracked" {
+		t.Errorf("Expected an untracked file, got %s/%s", staged, unstaged)
+	}
+}
+
+// changesModel returns a model in changes mode for the repo at dir
+func changesModel(t *testing.T, dir string) model {
+	t.Helper()
+	m := model{height: 30, width: 120, viewMode: viewDualPane, currentPath: dir, showChangesOnly: true, showDiffPreview: true}
+	changed, err := m.getChangedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.changedFiles = changed
+	m.loadFiles()
+	return m
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return string(out)
+}
+
+func TestStageHunks(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	dir := initTestRepo(t, "f.txt", strings.Join(lines, "\n")+"\n")
+	lines[1], lines[17] = "first edit", "second edit"
+	path := filepath.Join(dir, "f.txt")
+	os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	t.Setenv("HOME", t.TempDir()) // Trash goes here
+
+	m := changesModel(t, dir)
+	m.moveHunkSelection(1)
+	sel := m.activeHunkSelection()
+	if sel == nil || sel.staged || len(sel.patch.hunks) != 2 {
+		t.Fatalf("Expected 2 unstaged hunks selected, got %+v", sel)
+	}
+
+	// Stage only the first hunk
+	m.stageFile()
+	cached := gitOutput(t, dir, "diff", "--cached")
+	if !strings.Contains(cached, "+first edit") || strings.Contains(cached, "second edit") {
+		t.Errorf("Expected only the first hunk staged:\n%s", cached)
+	}
+	if staged, unstaged := changeStatusColumns(*m.getCurrentFile()); staged != "modified" || unstaged != "modified" {
+		t.Errorf("Expected staged and unstaged changes, got %s/%s", staged, unstaged)
+	}
+	if sel := m.activeHunkSelection(); sel == nil || len(sel.patch.hunks) != 1 {
+		t.Fatalf("Expected the remaining unstaged hunk selected, got %+v", sel)
+	}
+
+	// Discard the remaining unstaged hunk: the file goes to trash first
+	m.discardHunk()
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "second edit") || !strings.Contains(string(data), "first edit") {
+		t.Errorf("Expected the second edit discarded, got:\n%s", data)
+	}
+	if items, err := getTrashItems(); err != nil || len(items) != 1 {
+		t.Errorf("Expected the previous version in trash, got %d (%v)", len(items), err)
+	}
+
+	// Unstage the staged hunk again
+	m.moveHunkSelection(1)
+	if sel := m.activeHunkSelection(); sel == nil || !sel.staged {
+		t.Fatalf("Expected the staged hunk selected when nothing is unstaged, got %+v", sel)
+	}
+	m.unstageFile()
+	if cached := gitOutput(t, dir, "diff", "--cached"); cached != "" {
+		t.Errorf("Expected nothing staged:\n%s", cached)
+	}
+	if staged, unstaged := changeStatusColumns(*m.getCurrentFile()); staged != "—" || unstaged != "modified" {
+		t.Errorf("Expected only unstaged changes, got %s/%s", staged, unstaged)
+	}
+}
+
+func TestStageFiles(t *testing.T) {
+	dir := initTestRepo(t, "f.txt", "one\n")
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte("two\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0644)
+	t.Setenv("HOME", t.TempDir())
+
+	m := changesModel(t, dir)
+	if len(m.changedFiles) != 2 {
+		t.Fatalf("Expected 2 changed files, got %d", len(m.changedFiles))
+	}
+	m.cursor = 0 // f.txt
+	m.stageFile()
+	if got := gitOutput(t, dir, "status", "--porcelain"); !strings.Contains(got, "M  f.txt") {
+		t.Errorf("Expected f.txt staged:\n%s", got)
+	}
+	m.unstageFile()
+	if got := gitOutput(t, dir, "status", "--porcelain"); !strings.Contains(got, " M f.txt") {
+		t.Errorf("Expected f.txt unstaged:\n%s", got)
+	}
+
+	// Discarding restores the committed content; the edit is in trash
+	m.discardFile()
+	if data, _ := os.ReadFile(filepath.Join(dir, "f.txt")); string(data) != "one\n" {
+		t.Errorf("Expected f.txt restored, got %q", data)
+	}
+	m.cursor = 0 // new.txt is all that's left
+	m.discardFile()
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected the untracked file moved away, got %v", err)
+	}
+	if items, err := getTrashItems(); err != nil || len(items) != 2 {
+		t.Errorf("Expected both discards in trash, got %d (%v)", len(items), err)
+	}
+	if len(m.changedFiles) != 0 {
+		t.Errorf("Expected no changes left, got %d", len(m.changedFiles))
+	}
+}
+
+func TestChangesDetailSortHeader(t *testing.T) {
+	dir := initTestRepo(t, "a.txt", "a\n")
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("changed\n"), 0644)
+	m := changesModel(t, dir)
+	m.displayMode = modeDetail
+	m.sortAsc = true
+	for sortBy, want := range map[string]string{"name": "Name ↑", "size": "Name (size ↑)", "modified": "Name (date ↑)"} {
+		m.sortBy = sortBy
+		if view := m.renderDetailView(10); !strings.Contains(view, want) {
+			t.Errorf("Expected %q in the header sorting by %s:\n%s", want, sortBy, view)
+		}
+	}
+}
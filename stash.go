@@ -0,0 +1,553 @@
+package main
+
+// Module: stash.go
+// Purpose: Stash browser (S, Git → Stashes) and stashing files from changes mode
+// Responsibilities:
+// - Listing stashes with message, branch and age in the full-screen preview
+// - Showing a stash's diff (including untracked files)
+// - Apply, pop and drop (after confirmation)
+// - Marking changed files with z and stashing them with Z
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const stashRefWidth = 10 // "stash@{12}"
+
+// stashEntry is one stash
+type stashEntry struct {
+	ref     string // stash@{n}
+	branch  string // Branch the stash was made on
+	message string
+	time    time.Time
+}
+
+// stashState is the stash browser for a repository
+type stashState struct {
+	root       string
+	entries    []stashEntry
+	err        error
+	cursor     int
+	confirm    bool     // Waiting for y to drop the selected stash
+	returnMode viewMode // View to go back to on close
+
+	view *stashView // Diff opened from the list
+}
+
+// stashView is the diff of one stash
+type stashView struct {
+	entry       *stashEntry
+	loading     bool
+	lines       []string
+	err         error
+	savedScroll int
+}
+
+// stashViewMsg delivers a stash's diff
+type stashViewMsg struct {
+	view  *stashView
+	lines []string
+	err   error
+}
+
+// parseStashList parses `git stash list` output in the stashListFormat
+func parseStashList(out string) []stashEntry {
+	var entries []stashEntry
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x1f", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		e := stashEntry{ref: fields[0], message: fields[2]}
+		if unix, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			e.time = time.Unix(unix, 0)
+		}
+		// The reflog subject is "WIP on main: 1234abc Subject" or "On main: message"
+		for _, prefix := range []string{"WIP on ", "On "} {
+			if rest, ok := strings.CutPrefix(e.message, prefix); ok {
+				if branch, message, ok := strings.Cut(rest, ": "); ok {
+					e.branch, e.message = branch, message
+				}
+				break
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// stashListFormat is the git stash list format read by parseStashList
+const stashListFormat = "--format=%gd%x1f%ct%x1f%gs"
+
+// listStashes returns the repository's stashes, newest first
+func listStashes(root string) ([]stashEntry, error) {
+	out, err := runGit(root, "", "stash", "list", stashListFormat)
+	if err != nil {
+		return nil, err
+	}
+	return parseStashList(out), nil
+}
+
+// stashViewCmd loads a stash's diff stat and patch, untracked files included when git supports it
+func stashViewCmd(root string, v *stashView) tea.Cmd {
+	ref := v.entry.ref
+	return func() tea.Msg {
+		out, err := runGit(root, "", "stash", "show", "--include-untracked", "--stat", "--patch", "--no-color", ref)
+		if err != nil {
+			// git before 2.32 has no --include-untracked for stash show
+			out, err = runGit(root, "", "stash", "show", "--stat", "--patch", "--no-color", ref)
+		}
+		if err != nil {
+			return stashViewMsg{view: v, err: err}
+		}
+		return stashViewMsg{view: v, lines: strings.Split(strings.TrimRight(out, "\n"), "\n")}
+	}
+}
+
+// openStashBrowser lists the repository's stashes (the selected one in git repos mode) in the full-screen preview
+func (m *model) openStashBrowser() {
+	root := m.selectedRepoRoot()
+	if root == "" {
+		m.setStatusMessage("Not inside a git repository", true)
+		return
+	}
+	entries, err := listStashes(root)
+	if err != nil {
+		m.setStatusMessage(fmt.Sprintf("Stashes failed: %v", err), true)
+		return
+	}
+	m.stash = &stashState{root: root, entries: entries, returnMode: m.viewMode}
+	m.preview.scrollPos = 0
+	m.viewMode = viewFullPreview
+	m.searchMode = false
+	m.calculateLayout()
+	if len(entries) == 0 {
+		m.setStatusMessage("No stashes - press z on changed files and Z to stash them", false)
+	} else {
+		m.setStatusMessage(fmt.Sprintf("%s | Enter: diff, a: apply, p: pop, x: drop", stashCount(len(entries))), false)
+	}
+}
+
+// closeStashBrowser goes back to the view the browser was opened from
+func (m *model) closeStashBrowser() {
+	m.viewMode = m.stash.returnMode
+	m.stash = nil
+	m.preview.scrollPos = 0
+	m.calculateLayout()
+	m.populatePreviewCache()
+}
+
+// reloadStashes re-reads the list after a change, keeping the cursor in range
+func (m *model) reloadStashes() {
+	s := m.stash
+	s.entries, s.err = listStashes(s.root)
+	s.cursor = max(0, min(s.cursor, len(s.entries)-1))
+	s.view = nil
+	m.preview.scrollPos = 0
+	m.moveStashCursor(0)
+}
+
+// refreshAfterStash updates the file list and changes after the worktree changed
+func (m *model) refreshAfterStash() {
+	m.refreshGitRepoEntry(m.stash.root, nil)
+	m.invalidateGitStatus()
+	if m.showChangesOnly {
+		m.refreshChanges()
+	} else {
+		m.loadFiles()
+		m.preview.cacheValid = false
+	}
+}
+
+// applyStashViewMsg shows a loaded stash diff unless another one was opened meanwhile
+func (m *model) applyStashViewMsg(msg stashViewMsg) {
+	s := m.stash
+	if s == nil || s.view != msg.view {
+		return
+	}
+	s.view.loading = false
+	s.view.lines, s.view.err = msg.lines, msg.err
+}
+
+// selected returns the open stash, or the one under the cursor
+func (s *stashState) selected() *stashEntry {
+	if s.view != nil {
+		return s.view.entry
+	}
+	if s.cursor < len(s.entries) {
+		return &s.entries[s.cursor]
+	}
+	return nil
+}
+
+// openStashView shows the selected stash's diff
+func (m *model) openStashView() tea.Cmd {
+	s := m.stash
+	e := s.selected()
+	if e == nil {
+		return nil
+	}
+	s.view = &stashView{entry: e, loading: true, savedScroll: m.preview.scrollPos}
+	m.preview.scrollPos = 0
+	return stashViewCmd(s.root, s.view)
+}
+
+// closeStashView returns to the stash list
+func (m *model) closeStashView() {
+	s := m.stash
+	m.preview.scrollPos = s.view.savedScroll
+	s.view = nil
+}
+
+// moveStashCursor moves the selected stash and scrolls to keep it visible
+func (m *model) moveStashCursor(delta int) {
+	s := m.stash
+	s.cursor = max(0, min(s.cursor+delta, len(s.entries)-1))
+	visible := max(m.getPreviewVisibleLines(), 1)
+	if s.cursor < m.preview.scrollPos {
+		m.preview.scrollPos = s.cursor
+	} else if s.cursor >= m.preview.scrollPos+visible {
+		m.preview.scrollPos = s.cursor - visible + 1
+	}
+}
+
+// applyStash applies the selected stash to the worktree; pop also drops it when it applied cleanly
+func (m *model) applyStash(pop bool) tea.Cmd {
+	s := m.stash
+	e := s.selected()
+	if e == nil {
+		return nil
+	}
+	ref := e.ref
+	verb, done := "apply", "Applied"
+	if pop {
+		verb, done = "pop", "Popped"
+	}
+	// --index restores what was staged, but refuses when the index can't take it
+	_, err := runGit(s.root, "", "stash", verb, "--index", ref)
+	if err != nil && strings.Contains(err.Error(), "--index") {
+		_, err = runGit(s.root, "", "stash", verb, ref)
+	}
+	m.refreshAfterStash()
+	if err != nil {
+		m.reloadStashes()
+		m.setStatusMessage(fmt.Sprintf("Stash %s failed: %v", verb, err), true)
+		return statusTimeoutCmd()
+	}
+	m.reloadStashes()
+	m.setStatusMessage(fmt.Sprintf("✓ %s %s", done, ref), false)
+	return statusTimeoutCmd()
+}
+
+// dropStash deletes the selected stash
+func (m *model) dropStash() tea.Cmd {
+	s := m.stash
+	e := s.selected()
+	if e == nil {
+		return nil
+	}
+	ref := e.ref
+	if _, err := runGit(s.root, "", "stash", "drop", "-q", ref); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Drop failed: %v", err), true)
+		return statusTimeoutCmd()
+	}
+	m.reloadStashes()
+	m.setStatusMessage("✓ Dropped "+ref, false)
+	return statusTimeoutCmd()
+}
+
+// toggleStashMark marks or unmarks the selected changed file for stashing and moves down
+func (m *model) toggleStashMark() {
+	file := m.getCurrentFile()
+	if file == nil || file.isDir || extractGitStatusCode(file.name) == "" {
+		m.setStatusMessage("Select a changed file", true)
+		return
+	}
+	if m.stashMarked[file.path] {
+		delete(m.stashMarked, file.path)
+	} else {
+		if m.stashMarked == nil {
+			m.stashMarked = make(map[string]bool)
+		}
+		m.stashMarked[file.path] = true
+	}
+	if m.cursor < len(m.getFilteredFiles())-1 {
+		m.cursor++
+	}
+	m.setStatusMessage(fmt.Sprintf("%s marked for stashing (Z: stash)", pluralize(len(m.stashMarked), "file")), false)
+}
+
+// stashTargets returns the files Z stashes: the marked ones, or the selected one
+func (m *model) stashTargets() []fileItem {
+	var targets []fileItem
+	for _, file := range m.changedFiles {
+		if m.stashMarked[file.path] {
+			targets = append(targets, file)
+		}
+	}
+	if len(targets) == 0 {
+		if file := m.getCurrentFile(); file != nil && !file.isDir && extractGitStatusCode(file.name) != "" {
+			targets = append(targets, *file)
+		}
+	}
+	return targets
+}
+
+// promptStashFiles asks for a message before stashing the marked (or selected) files
+func (m *model) promptStashFiles() {
+	targets := m.stashTargets()
+	if len(targets) == 0 {
+		m.setStatusMessage("Select a changed file", true)
+		return
+	}
+	m.dialog = dialogModel{
+		dialogType: dialogInput,
+		title:      "Stash Files",
+		message:    fmt.Sprintf("Stash %s. Message (optional):", pluralize(len(targets), "file")),
+	}
+	m.showDialog = true
+}
+
+// stashFiles stashes the marked (or selected) files' changes, untracked ones included
+func (m *model) stashFiles(message string) {
+	targets := m.stashTargets()
+	root := m.resolveGitRoot()
+	if len(targets) == 0 || root == "" {
+		m.setStatusMessage("Nothing to stash", true)
+		return
+	}
+	args := []string{"stash", "push", "-q"}
+	paths := make([]string, 0, len(targets))
+	untracked := false
+	for _, file := range targets {
+		rel, err := filepath.Rel(root, file.path)
+		if err != nil {
+			continue
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		if extractGitStatusCode(file.name) == "??" {
+			untracked = true
+		}
+	}
+	if untracked {
+		args = append(args, "--include-untracked")
+	}
+	if message = strings.TrimSpace(message); message != "" {
+		args = append(args, "-m", message)
+	}
+	args = append(append(args, "--"), paths...)
+	if _, err := runGit(root, "", args...); err != nil {
+		m.setStatusMessage(fmt.Sprintf("Stash failed: %v", err), true)
+		return
+	}
+	m.stashMarked = nil
+	m.refreshChanges()
+	m.setStatusMessage(fmt.Sprintf("✓ Stashed %s as stash@{0} (S: stashes)", pluralize(len(paths), "file")), false)
+}
+
+// handleStashKey handles the stash browser's keys; false lets quit, help and search through
+func (m *model) handleStashKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	s := m.stash
+	if s == nil {
+		return false, nil
+	}
+	key := msg.String()
+
+	if s.confirm {
+		s.confirm = false
+		if key == "y" || key == "Y" {
+			return true, m.dropStash()
+		}
+		m.setStatusMessage("Drop cancelled", false)
+		return true, statusTimeoutCmd()
+	}
+
+	switch key {
+	case "q", "ctrl+c", "f10", "f1", "ctrl+f", "/", "m", "M":
+		return false, nil
+	case "S":
+		m.closeStashBrowser()
+		return true, tea.ClearScreen
+	case "a":
+		return true, m.applyStash(false)
+	case "p":
+		return true, m.applyStash(true)
+	case "x", "delete":
+		if s.selected() != nil {
+			s.confirm = true
+			m.setStatusMessage(m.stashStatusText(), false)
+		}
+		return true, nil
+	}
+
+	if s.view != nil {
+		visible := m.getPreviewVisibleLines()
+		maxScroll := max(m.stashLineCount()-visible, 0)
+		switch key {
+		case "esc", "backspace", "enter":
+			m.closeStashView()
+		case "up", "k":
+			m.preview.scrollPos = max(m.preview.scrollPos-1, 0)
+		case "down", "j":
+			m.preview.scrollPos = min(m.preview.scrollPos+1, maxScroll)
+		case "pageup", "pgup":
+			m.preview.scrollPos = max(m.preview.scrollPos-visible, 0)
+		case "pagedown", "pgdn", "pgdown", " ":
+			m.preview.scrollPos = min(m.preview.scrollPos+visible, maxScroll)
+		case "home", "g":
+			m.preview.scrollPos = 0
+		case "end", "G":
+			m.preview.scrollPos = maxScroll
+		}
+		return true, nil
+	}
+
+	switch key {
+	case "up", "k":
+		m.moveStashCursor(-1)
+	case "down", "j":
+		m.moveStashCursor(1)
+	case "pageup", "pgup":
+		m.moveStashCursor(-m.getPreviewVisibleLines())
+	case "pagedown", "pgdn", "pgdown":
+		m.moveStashCursor(m.getPreviewVisibleLines())
+	case "home", "g":
+		m.moveStashCursor(-len(s.entries))
+	case "end", "G":
+		m.moveStashCursor(len(s.entries))
+	case "enter":
+		return true, m.openStashView()
+	case "esc":
+		m.closeStashBrowser()
+		return true, tea.ClearScreen
+	}
+	return true, nil
+}
+
+// stashCount formats a number of stashes
+func stashCount(n int) string {
+	if n == 1 {
+		return "1 stash"
+	}
+	return fmt.Sprintf("%d stashes", n)
+}
+
+// stashRow formats a stash for the list: ref, age, branch and message
+func stashRow(e *stashEntry) string {
+	return fmt.Sprintf("%s  %s %s %s",
+		padToWidth(e.ref, stashRefWidth),
+		padToWidth(formatLastCommitTime(e.time), blameDateWidth),
+		padToWidth(truncateToWidth(e.branch, blameAuthorWidth), blameAuthorWidth),
+		e.message)
+}
+
+// stashLineCount returns the number of displayed lines (open diff or stash list)
+func (m model) stashLineCount() int {
+	s := m.stash
+	if v := s.view; v != nil {
+		if v.loading || v.err != nil {
+			return 1
+		}
+		return len(m.diffDisplayLines(v.lines))
+	}
+	return max(len(s.entries), 1)
+}
+
+// stashDisplayLines returns the displayed text for preview search
+func (m model) stashDisplayLines() []string {
+	s := m.stash
+	if v := s.view; v != nil {
+		return m.diffDisplayLines(v.lines)
+	}
+	lines := make([]string, len(s.entries))
+	for i := range s.entries {
+		lines[i] = stashRow(&s.entries[i])
+	}
+	return lines
+}
+
+// renderStashPreview renders the stash list, or the diff opened from it
+func (m model) renderStashPreview(maxVisible int) string {
+	st := m.stash
+	subtle := lipgloss.NewStyle().Foreground(uiSubtleText()).Italic(true)
+	message := func(text string) string {
+		return subtle.Render(text) + strings.Repeat("\n\033[0m", max(maxVisible-1, 0))
+	}
+
+	if v := st.view; v != nil {
+		switch {
+		case v.loading:
+			return message("Loading " + v.entry.ref + "...")
+		case v.err != nil:
+			return message(fmt.Sprintf("Cannot show %s: %v", v.entry.ref, v.err))
+		}
+		return m.renderDiffLines(v.lines, maxVisible, v.entry.ref)
+	}
+
+	switch {
+	case st.err != nil:
+		return message(fmt.Sprintf("Cannot list stashes: %v", st.err))
+	case len(st.entries) == 0:
+		return message("No stashes in " + filepath.Base(st.root))
+	}
+
+	var s strings.Builder
+	totalLines := len(st.entries)
+	start := max(0, min(m.preview.scrollPos, totalLines-maxVisible))
+	width := m.previewBoxWidth() - 2
+
+	cursorStyle := lipgloss.NewStyle().
+		Foreground(currentTheme.SelectionFg.adaptiveColor()).
+		Background(currentTheme.SelectionBg.adaptiveColor())
+	refStyle := lipgloss.NewStyle().Foreground(blameAgeColors[0])
+
+	linesRendered := 0
+	for i := start; i < totalLines && linesRendered < maxVisible; i++ {
+		row := truncateToWidth(stashRow(&st.entries[i]), width)
+		if i == st.cursor {
+			row = cursorStyle.Render(padToWidth(row, width))
+		} else if width > stashRefWidth {
+			// The ref column is ASCII and fits, so it is exactly stashRefWidth bytes
+			row = refStyle.Render(row[:stashRefWidth]) + m.highlightSearchLine(row[stashRefWidth:], i)
+		} else {
+			row = refStyle.Render(row)
+		}
+		if linesRendered > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(m.renderScrollbar(i-start, maxVisible, totalLines) + " " + row + "\033[0m")
+		linesRendered++
+	}
+	for linesRendered < maxVisible {
+		s.WriteString("\n\033[0m")
+		linesRendered++
+	}
+	return s.String()
+}
+
+// stashStatusText describes the selection for info lines
+func (m model) stashStatusText() string {
+	s := m.stash
+	switch {
+	case s.confirm:
+		e := s.selected()
+		return fmt.Sprintf("Stashes | Drop %s (%s)? y/n", e.ref, e.message)
+	case s.view != nil:
+		return fmt.Sprintf("Stashes | %s on %s: %s", s.view.entry.ref, s.view.entry.branch, s.view.entry.message)
+	case len(s.entries) == 0:
+		return "Stashes | none"
+	}
+	return fmt.Sprintf("Stashes | %d of %s", s.cursor+1, stashCount(len(s.entries)))
+}
+
+// stashTitle is the full-screen preview title while the browser is open
+func (m model) stashTitle() string {
+	return "Stashes: " + filepath.Base(m.stash.root)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseStashList(t *testing.T) {
+	out := "stash@{0}\x1f1700000000\x1fOn feature: Half done\n" +
+		"stash@{1}\x1f1690000000\x1fWIP on main: 1234abc Subject: with colon\n" +
+		"stash@{2}\x1f1680000000\x1fcustom reflog message\n"
+	entries := parseStashList(out)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 stashes, got %+v", entries)
+	}
+	if e := entries[0]; e.ref != "stash@{0}" || e.branch != "feature" || e.message != "Half done" || e.time.Unix() != 1700000000 {
+		t.Errorf("Unexpected stash with a message: %+v", e)
+	}
+	if e := entries[1]; e.branch != "main" || e.message != "1234abc Subject: with colon" {
+		t.Errorf("Unexpected WIP stash: %+v", e)
+	}
+	if e := entries[2]; e.branch != "" || e.message != "custom reflog message" {
+		t.Errorf("Expected an unrecognized subject kept whole, got %+v", e)
+	}
+	if stashCount(1) != "1 stash" || stashCount(2) != "2 stashes" {
+		t.Errorf("Unexpected stash counts %q, %q", stashCount(1), stashCount(2))
+	}
+}
+
+func TestStashListNarrow(t *testing.T) {
+	entries := []stashEntry{
+		{ref: "stash@{0}", branch: "main", message: "First", time: time.Now()},
+		{ref: "stash@{1}", branch: "main", message: "Second", time: time.Now()},
+	}
+	// Preview widths around the ref column, down to nothing
+	for width := 0; width <= 20; width++ {
+		m := model{height: 20, width: width + 6, viewMode: viewFullPreview, stash: &stashState{entries: entries}}
+		if out := m.renderStashPreview(5); width > 12 && !strings.Contains(out, "stash@{1}") {
+			t.Errorf("Expected the second ref at width %d:\n%s", width, out)
+		}
+	}
+}
+
+// selectChangedFile moves the file list's cursor to the changed file name
+func selectChangedFile(t *testing.T, m *model, name string) {
+	t.Helper()
+	for i, file := range m.getFilteredFiles() {
+		if strings.HasSuffix(file.name, " "+name) {
+			m.cursor = i
+			return
+		}
+	}
+	t.Fatalf("No changed file %s", name)
+}
+
+func TestStashBrowser(t *testing.T) {
+	dir := initTestRepo(t, "f.txt", "one\n")
+	commitIdentity(t)
+	os.WriteFile(filepath.Join(dir, "f.txt"), []byte("two\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "keep.txt"), []byte("keep\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0644)
+	gitOutput(t, dir, "add", "keep.txt")
+	branch := getGitBranch(dir)
+
+	// Mark f.txt and the untracked new.txt, then stash them with a message
+	m := changesModel(t, dir)
+	for _, name := range []string{"f.txt", "new.txt"} {
+		selectChangedFile(t, &m, name)
+		m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	}
+	if len(m.stashMarked) != 2 {
+		t.Fatalf("Expected 2 marked files, got %v", m.stashMarked)
+	}
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	if !m.showDialog || m.dialog.title != "Stash Files" {
+		t.Fatal("Expected Z to ask for a stash message")
+	}
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Half done")}, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.stashMarked != nil || len(m.changedFiles) != 1 {
+		t.Fatalf("Expected only keep.txt left changed, got %d changes (%s)", len(m.changedFiles), m.statusMessage)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); !os.IsNotExist(err) {
+		t.Error("Expected the untracked file stashed away")
+	}
+
+	// The browser lists the stash and shows its diff
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("S")})
+	if m.stash == nil || m.viewMode != viewFullPreview || len(m.stash.entries) != 1 {
+		t.Fatalf("Expected S to open the stash browser with one stash, got %+v", m.stash)
+	}
+	if e := m.stash.entries[0]; e.branch != branch || e.message != "Half done" {
+		t.Errorf("Unexpected stash entry %+v", e)
+	}
+	if out := plainLines(m.stashDisplayLines()); !strings.Contains(out, "Half done") || !strings.Contains(out, branch) {
+		t.Errorf("Expected the message and branch in the list:\n%s", out)
+	}
+	cmd := m.openStashView()
+	newM, _ := m.Update(cmd())
+	m = newM.(model)
+	diff := strings.Join(m.stash.view.lines, "\n")
+	if !strings.Contains(diff, "+two") || !strings.Contains(diff, "new.txt") {
+		t.Errorf("Expected the stash diff with the untracked file, got\n%s", diff)
+	}
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.stash == nil || m.stash.view != nil {
+		t.Fatal("Expected Esc to go back to the stash list")
+	}
+
+	// Apply keeps the stash; drop needs a confirmation
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if got, _ := os.ReadFile(filepath.Join(dir, "f.txt")); string(got) != "two\n" || len(m.stash.entries) != 1 {
+		t.Fatalf("Expected the stash applied and kept, got %q (%s)", got, m.statusMessage)
+	}
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if len(m.stash.entries) != 1 {
+		t.Fatal("Expected the drop cancelled")
+	}
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if len(m.stash.entries) != 0 || gitOutput(t, dir, "stash", "list") != "" {
+		t.Fatal("Expected the stash dropped")
+	}
+
+	// Pop restores the files and removes the stash
+	gitOutput(t, dir, "stash", "push", "-q", "-m", "Again", "--", "f.txt")
+	m.reloadStashes()
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if got, _ := os.ReadFile(filepath.Join(dir, "f.txt")); string(got) != "two\n" || len(m.stash.entries) != 0 {
+		t.Errorf("Expected the stash popped, got %q (%s)", got, m.statusMessage)
+	}
+
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.stash != nil || m.viewMode != viewDualPane {
+		t.Errorf("Expected Esc to close the browser, got view mode %d", m.viewMode)
+	}
+}
@@ -41,8 +41,10 @@ var (
 	obsidianVaultStyle lipgloss.Style
 
 	// Diff preview styles (git diff coloring in changes mode)
-	diffAddedStyle      lipgloss.Style // Green for added lines (+)
-	diffRemovedStyle    lipgloss.Style // Red for removed lines (-)
-	diffHunkHeaderStyle lipgloss.Style // Cyan for @@ hunk headers
-	diffMetaStyle       lipgloss.Style // Dim for diff/index/---/+++ headers
+	diffAddedStyle       lipgloss.Style // Green for added lines (+)
+	diffRemovedStyle     lipgloss.Style // Red for removed lines (-)
+	diffHunkHeaderStyle  lipgloss.Style // Cyan for @@ hunk headers
+	diffMetaStyle        lipgloss.Style // Dim for diff/index/---/+++ headers
+	diffAddedWordStyle   lipgloss.Style // Changed words within an added line
+	diffRemovedWordStyle lipgloss.Style // Changed words within a removed line
 )
@@ -0,0 +1,8 @@
+package main
+
+import "golang.org/x/sys/unix"
+
+// flushTerminalInput discards unread terminal input (tcflush(fd, TCIFLUSH))
+func flushTerminalInput(fd int) error {
+	return unix.IoctlSetInt(fd, unix.TCFLSH, unix.TCIFLUSH)
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !windows
+
+package main
+
+import "golang.org/x/sys/unix"
+
+// fread selects the input queue for TIOCFLUSH (FREAD from <sys/fcntl.h>)
+const fread = 0x1
+
+// flushTerminalInput discards unread terminal input (tcflush(fd, TCIFLUSH))
+func flushTerminalInput(fd int) error {
+	return unix.IoctlSetPointerInt(fd, unix.TIOCFLUSH, fread)
+}
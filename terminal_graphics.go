@@ -10,6 +10,8 @@ package main
 import (
 	"fmt"
 	"image"
+	"image/color/palette"
+	"image/draw"
 	_ "image/gif"
 	_ "image/jpeg"
 	_ "image/png"
@@ -51,6 +53,11 @@ func detectTerminalProtocol() TerminalProtocol {
 		}
 	}
 
+	// Terminal probed at startup (see terminal_probe.go)
+	if probedTerminal != nil {
+		return probedTerminal.protocol
+	}
+
 	// Check for Kitty terminal
 	if strings.Contains(term, "kitty") || os.Getenv("KITTY_WINDOW_ID") != "" {
 		return ProtocolKitty
@@ -77,18 +84,34 @@ func detectTerminalProtocol() TerminalProtocol {
 		return ProtocolITerm2
 	}
 
-	// Check for Sixel support (xterm, mlterm, foot, wezterm)
-	// Note: Sixel detection via terminal query would be better, but requires async handling
-	if strings.Contains(term, "xterm") || strings.Contains(term, "mlterm") ||
-		strings.Contains(term, "foot") || strings.Contains(term, "sixel") {
-		// For now, prefer no sixel since we have better protocols
-		// Can enable if Kitty/iTerm2 detection fails
-		return ProtocolNone
-	}
+	// Sixel support can't be told from TERM (most xterm builds lack it) - the startup
+	// probe asks the terminal instead; without an answer, stay with the text fallback
 
 	return ProtocolNone
 }
 
+// terminalSupportsTrueColor reports whether the terminal accepts 24-bit color escapes
+// Used by: imageview.go (block image rendering falls back to the 256-color palette)
+func terminalSupportsTrueColor() bool {
+	colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+	if colorTerm == "truecolor" || colorTerm == "24bit" {
+		return true
+	}
+	term := os.Getenv("TERM")
+	if strings.Contains(term, "truecolor") || strings.Contains(term, "24bit") || strings.Contains(term, "direct") {
+		return true
+	}
+	// Terminals known to support truecolor that don't always set COLORTERM (e.g. over SSH)
+	if os.Getenv("WT_SESSION") != "" || os.Getenv("KITTY_WINDOW_ID") != "" {
+		return true
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "Hyper", "ghostty":
+		return true
+	}
+	return false
+}
+
 // isWezTermInPath checks if WezTerm is available in the PATH
 // This is useful for WSL where WezTerm is installed on Windows
 func isWezTermInPath() bool {
@@ -133,6 +156,13 @@ func renderImageWithProtocol(imagePath string, maxWidth, maxHeight int) (string,
 	if err != nil {
 		return "", false
 	}
+	return encodeImageWithProtocol(img, maxWidth, maxHeight)
+}
+
+// encodeImageWithProtocol encodes an already decoded image for the detected protocol
+// Used by: renderImageWithProtocol, imageanim.go (animation frames)
+func encodeImageWithProtocol(img image.Image, maxWidth, maxHeight int) (string, bool) {
+	protocol := detectTerminalProtocol()
 
 	// Scale image to fit dimensions while maintaining aspect ratio
 	scaledImg := scaleImage(img, maxWidth, maxHeight)
@@ -144,26 +174,39 @@ func renderImageWithProtocol(imagePath string, maxWidth, maxHeight int) (string,
 		if err != nil {
 			return "", false
 		}
-		return encoded, true
+		return wrapGraphics(encoded), true
 
 	case ProtocolITerm2:
 		encoded, err := encodeITerm2Image(scaledImg)
 		if err != nil {
 			return "", false
 		}
-		return encoded, true
+		return wrapGraphics(encoded), true
 
 	case ProtocolSixel:
-		encoded, err := encodeSixelImage(scaledImg)
+		// Sixel images are drawn at their pixel size: scale to the cell area instead
+		cellWidth, cellHeight := terminalCellSize()
+		sixelImg := scaleImage(img, maxWidth*cellWidth, maxHeight*cellHeight/2)
+		encoded, err := encodeSixelImage(sixelImg)
 		if err != nil {
 			return "", false
 		}
-		return encoded, true
+		// Blank lines below keep the text after the image from being drawn over it
+		rows := (sixelImg.Bounds().Dy() + cellHeight - 1) / cellHeight
+		return wrapGraphics(encoded) + strings.Repeat("\n", max(0, rows-1)), true
 	}
 
 	return "", false
 }
 
+// terminalCellSize returns the cell size in pixels (reported by the terminal, or a typical 10×20)
+func terminalCellSize() (int, int) {
+	if probedTerminal != nil && probedTerminal.cellWidth > 0 && probedTerminal.cellHeight > 0 {
+		return probedTerminal.cellWidth, probedTerminal.cellHeight
+	}
+	return 10, 20
+}
+
 // loadImageFile loads an image from the filesystem
 func loadImageFile(path string) (image.Image, error) {
 	file, err := os.Open(path)
@@ -244,16 +287,10 @@ func encodeITerm2Image(img image.Image) (string, error) {
 
 // encodeSixelImage encodes an image using the Sixel protocol
 func encodeSixelImage(img image.Image) (string, error) {
-	// Convert to paletted image for Sixel
+	// Sixel is paletted: dither to the 256-color Plan 9 palette
 	bounds := img.Bounds()
-	paletted := image.NewPaletted(bounds, nil)
-
-	// Copy image to paletted format
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			paletted.Set(x, y, img.At(x, y))
-		}
-	}
+	paletted := image.NewPaletted(bounds, palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, bounds, img, bounds.Min)
 
 	var buf strings.Builder
 	err := rasterm.SixelWriteImage(&buf, paletted)
@@ -269,20 +306,25 @@ func encodeSixelImage(img image.Image) (string, error) {
 // (to clear the previous image) and during terminal cleanup on exit.
 // Sequence: ESC _ G a=d ESC \
 func clearKittyGraphics() string {
-	return "\033_Ga=d\033\\"
+	return wrapGraphics("\033_Ga=d\033\\")
 }
 
 // getProtocolName returns a human-readable name for the detected protocol
 func getProtocolName() string {
 	protocol := detectTerminalProtocol()
+	var name string
 	switch protocol {
 	case ProtocolKitty:
-		return "Kitty"
+		name = "Kitty"
 	case ProtocolITerm2:
-		return "iTerm2"
+		name = "iTerm2"
 	case ProtocolSixel:
-		return "Sixel"
+		name = "Sixel"
 	default:
 		return "None"
 	}
+	if probedTerminal != nil && probedTerminal.tmux && os.Getenv("TFE_TERMINAL_PROTOCOL") == "" {
+		name += " (tmux)"
+	}
+	return name
 }
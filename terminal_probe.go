@@ -0,0 +1,206 @@
+package main
+
+// Module: terminal_probe.go
+// Purpose: Asking the terminal which graphics protocols it supports
+// Responsibilities:
+// - Sending DA1 (sixel), Kitty graphics and XTVERSION queries at startup and parsing the replies
+// - Looking through tmux to the outer terminal (client info and DCS passthrough)
+// - Choosing the best protocol and wrapping graphics output for tmux
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	terminalProbeTimeout = 2 * time.Second        // Terminals that never answer DA1 give up here (slow SSH links answer late)
+	tmuxProbeGrace       = 150 * time.Millisecond // Replies through tmux passthrough can trail tmux's own
+)
+
+// Terminal queries
+const (
+	queryKittyGraphics = "\033_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\033\\" // 1×1 RGB query, answered "OK" if supported
+	queryXTVersion     = "\033[>0q"                                   // Terminal name and version
+	queryCellSize      = "\033[16t"                                   // Cell size in pixels
+	queryDA1           = "\033[c"                                     // Primary device attributes (answered by every terminal)
+)
+
+var (
+	kittyReplyRe     = regexp.MustCompile(`\x1b_Gi=31;([^\x1b]*)\x1b\\`)
+	xtversionReplyRe = regexp.MustCompile(`\x1bP>\|([^\x1b]*)\x1b\\`)
+	cellSizeReplyRe  = regexp.MustCompile(`\x1b\[6;(\d+);(\d+)t`)
+	da1ReplyRe       = regexp.MustCompile(`\x1b\[\?([\d;]*)c`)
+)
+
+// terminalCapabilities is what the startup probe learned about the terminal
+type terminalCapabilities struct {
+	name       string // XTVERSION reply (or tmux's view of the outer terminal), e.g. "WezTerm 20240203"
+	kitty      bool   // Kitty graphics protocol
+	iterm2     bool   // iTerm2 inline images
+	sixel      bool   // Sixel graphics (the outer terminal when inside tmux)
+	cellWidth  int    // Cell size in pixels (0 = unknown)
+	cellHeight int
+
+	// tmux: graphics for the outer terminal must be wrapped in DCS passthrough
+	tmux        bool
+	passthrough bool // allow-passthrough is on (or tmux is older than 3.3, where it always is)
+	tmuxSixel   bool // tmux itself draws sixel images (3.4+ built with sixel)
+
+	protocol TerminalProtocol // Chosen protocol
+	wrap     bool             // Output needs tmux passthrough
+}
+
+// probedTerminal holds the startup probe result (nil = not probed, use environment heuristics)
+var probedTerminal *terminalCapabilities
+
+// probeTerminalGraphics queries the terminal once at startup, before the TUI takes over the input.
+// Skipped when TFE_TERMINAL_PROTOCOL is set or the terminal can't be queried.
+func probeTerminalGraphics() {
+	if os.Getenv("TFE_TERMINAL_PROTOCOL") != "" {
+		return
+	}
+	caps := &terminalCapabilities{}
+	if os.Getenv("TMUX") != "" {
+		caps.tmux = true
+		caps.readTmuxClient()
+	}
+
+	query := caps.buildProbeQuery()
+	var da1At time.Time
+	reply, err := queryTerminal(query, terminalProbeTimeout, func(reply []byte) bool {
+		if !da1ReplyRe.Match(reply) {
+			return false
+		}
+		// DA1 is sent last, so every other reply from the terminal itself arrived before it
+		if !caps.tmux || !caps.passthrough || caps.kitty || kittyReplyRe.Match(reply) {
+			return true
+		}
+		if da1At.IsZero() {
+			da1At = time.Now()
+		}
+		return time.Since(da1At) > tmuxProbeGrace
+	})
+	if err != nil || !da1ReplyRe.Match(reply) {
+		return // No answer: keep the environment heuristics
+	}
+	caps.parseProbeReply(reply)
+	caps.choose(isWSL())
+	probedTerminal = caps
+}
+
+// buildProbeQuery returns the queries to send. Inside tmux, DA1 is answered by tmux itself
+// while the Kitty query has to pass through to the outer terminal.
+func (c *terminalCapabilities) buildProbeQuery() string {
+	if !c.tmux {
+		return queryKittyGraphics + queryXTVersion + queryCellSize + queryDA1
+	}
+	query := queryCellSize
+	if c.passthrough && !c.kitty {
+		query = tmuxPassthrough(queryKittyGraphics) + query
+	}
+	return query + queryDA1
+}
+
+// parseProbeReply reads the terminal's replies to the probe queries
+func (c *terminalCapabilities) parseProbeReply(reply []byte) {
+	if m := kittyReplyRe.FindSubmatch(reply); m != nil && string(m[1]) == "OK" {
+		c.kitty = true
+	}
+	if m := xtversionReplyRe.FindSubmatch(reply); m != nil && !c.tmux {
+		c.name = string(m[1])
+	}
+	if m := cellSizeReplyRe.FindSubmatch(reply); m != nil {
+		c.cellHeight, _ = strconv.Atoi(string(m[1]))
+		c.cellWidth, _ = strconv.Atoi(string(m[2]))
+	}
+	if m := da1ReplyRe.FindSubmatch(reply); m != nil {
+		// The first parameter is the terminal class (62 = VT220...); 4 after it means sixel
+		params := strings.Split(string(m[1]), ";")
+		for _, p := range params[min(1, len(params)):] {
+			if p == "4" {
+				if c.tmux {
+					c.tmuxSixel = true
+				} else {
+					c.sixel = true
+				}
+			}
+		}
+	}
+	c.applyTerminalName()
+}
+
+// applyTerminalName fills in support that a terminal has but doesn't report through queries
+func (c *terminalCapabilities) applyTerminalName() {
+	name := strings.ToLower(c.name)
+	switch {
+	case strings.HasPrefix(name, "iterm2"):
+		c.iterm2 = true
+	case strings.HasPrefix(name, "wezterm"):
+		c.iterm2 = true
+		c.kitty = true
+	case strings.HasPrefix(name, "kitty"), strings.HasPrefix(name, "ghostty"):
+		c.kitty = true
+	}
+}
+
+// choose picks the best available protocol: Kitty, then iTerm2, then sixel
+func (c *terminalCapabilities) choose(wsl bool) {
+	// Kitty graphics don't work in WezTerm on Windows (WSL) - its iTerm2 protocol does
+	kitty := c.kitty && !(wsl && strings.HasPrefix(strings.ToLower(c.name), "wezterm"))
+	outer := !c.tmux || c.passthrough
+
+	switch {
+	case kitty && outer:
+		c.protocol, c.wrap = ProtocolKitty, c.tmux
+	case c.iterm2 && outer:
+		c.protocol, c.wrap = ProtocolITerm2, c.tmux
+	case c.tmuxSixel:
+		c.protocol = ProtocolSixel // tmux keeps track of the image itself
+	case c.sixel && outer:
+		c.protocol, c.wrap = ProtocolSixel, c.tmux
+	default:
+		c.protocol = ProtocolNone
+	}
+}
+
+// readTmuxClient asks tmux about the outer terminal and whether passthrough is allowed
+func (c *terminalCapabilities) readTmuxClient() {
+	out, err := exec.Command("tmux", "display-message", "-p",
+		"#{client_termname}\t#{client_termtype}\t#{client_termfeatures}\t#{allow-passthrough}").Output()
+	if err != nil {
+		return
+	}
+	fields := strings.Split(strings.TrimRight(string(out), "\n"), "\t")
+	for len(fields) < 4 {
+		fields = append(fields, "")
+	}
+	termName, termType, features, passthrough := fields[0], fields[1], fields[2], fields[3]
+
+	// client_termtype (3.3+) is tmux's own XTVERSION/DA reply from the outer terminal
+	c.name = termType
+	if c.name == "" && strings.Contains(termName, "kitty") {
+		c.name = "kitty"
+	}
+	c.sixel = strings.Contains(","+features+",", ",sixel,")
+	// allow-passthrough was added in 3.3; before that passthrough was always allowed
+	c.passthrough = passthrough != "off"
+	c.applyTerminalName()
+}
+
+// tmuxPassthrough wraps an escape sequence so tmux forwards it to the outer terminal
+func tmuxPassthrough(seq string) string {
+	return "\033Ptmux;" + strings.ReplaceAll(seq, "\033", "\033\033") + "\033\\"
+}
+
+// wrapGraphics prepares graphics protocol output for the terminal (tmux passthrough if needed)
+// Used by: terminal_graphics.go (rendered images, clearing Kitty images)
+func wrapGraphics(seq string) string {
+	if probedTerminal != nil && probedTerminal.wrap {
+		return tmuxPassthrough(seq)
+	}
+	return seq
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// TestParseProbeReply tests reading the replies to the startup queries and choosing a protocol
+func TestParseProbeReply(t *testing.T) {
+	tests := []struct {
+		name  string
+		caps  terminalCapabilities
+		reply string
+		wsl   bool
+		want  TerminalProtocol
+		wrap  bool
+	}{
+		{"kitty", terminalCapabilities{}, "\x1b_Gi=31;OK\x1b\\\x1bP>|kitty(0.35.2)\x1b\\\x1b[?62;22c", false, ProtocolKitty, false},
+		{"kitty query refused", terminalCapabilities{}, "\x1b_Gi=31;ENOTSUPPORTED\x1b\\\x1b[?62;22c", false, ProtocolNone, false},
+		{"sixel", terminalCapabilities{}, "\x1bP>|foot(1.16.2)\x1b\\\x1b[6;17;8t\x1b[?62;4;22c", false, ProtocolSixel, false},
+		{"vt132 is not sixel", terminalCapabilities{}, "\x1b[?4;6c", false, ProtocolNone, false},
+		{"iterm2", terminalCapabilities{}, "\x1bP>|iTerm2 3.5.0\x1b\\\x1b[?62;4c", false, ProtocolITerm2, false},
+		{"wezterm on wsl", terminalCapabilities{}, "\x1b_Gi=31;OK\x1b\\\x1bP>|WezTerm 20240203\x1b\\\x1b[?65;4c", true, ProtocolITerm2, false},
+		{"tmux with sixel", terminalCapabilities{tmux: true}, "\x1b[?1;2;4c", false, ProtocolSixel, false},
+		{"tmux passthrough to kitty", terminalCapabilities{tmux: true, passthrough: true}, "\x1b[?1;2c\x1b_Gi=31;OK\x1b\\", false, ProtocolKitty, true},
+		{"tmux passthrough to sixel", terminalCapabilities{tmux: true, passthrough: true, sixel: true}, "\x1b[?1;2c", false, ProtocolSixel, true},
+		{"tmux without passthrough", terminalCapabilities{tmux: true, name: "kitty"}, "\x1b[?1;2c", false, ProtocolNone, false},
+	}
+	for _, tt := range tests {
+		c := tt.caps
+		c.applyTerminalName()
+		c.parseProbeReply([]byte(tt.reply))
+		c.choose(tt.wsl)
+		if c.protocol != tt.want || c.wrap != tt.wrap {
+			t.Errorf("%s: protocol = %d (wrap %v), want %d (wrap %v)", tt.name, c.protocol, c.wrap, tt.want, tt.wrap)
+		}
+	}
+
+	c := terminalCapabilities{}
+	c.parseProbeReply([]byte("\x1b[6;17;8t\x1b[?62c"))
+	if c.cellWidth != 8 || c.cellHeight != 17 {
+		t.Errorf("Cell size = %dx%d, want 8x17", c.cellWidth, c.cellHeight)
+	}
+}
+
+// TestProbeQueryAndPassthrough tests the probe queries and tmux wrapping
+func TestProbeQueryAndPassthrough(t *testing.T) {
+	q := (&terminalCapabilities{}).buildProbeQuery()
+	if !strings.HasSuffix(q, queryDA1) || !strings.Contains(q, queryKittyGraphics) || !strings.Contains(q, queryXTVersion) {
+		t.Errorf("Unexpected query %q", q)
+	}
+	q = (&terminalCapabilities{tmux: true, passthrough: true}).buildProbeQuery()
+	if !strings.HasPrefix(q, "\x1bPtmux;\x1b\x1b_G") || !strings.HasSuffix(q, queryDA1) {
+		t.Errorf("Expected the Kitty query wrapped for tmux, got %q", q)
+	}
+
+	if got := tmuxPassthrough("\x1b_Ga=d\x1b\\"); got != "\x1bPtmux;\x1b\x1b_Ga=d\x1b\x1b\\\x1b\\" {
+		t.Errorf("tmuxPassthrough = %q", got)
+	}
+
+	defer func(saved *terminalCapabilities) { probedTerminal = saved }(probedTerminal)
+	t.Setenv("TFE_TERMINAL_PROTOCOL", "")
+	probedTerminal = &terminalCapabilities{protocol: ProtocolKitty, wrap: true, tmux: true}
+	if detectTerminalProtocol() != ProtocolKitty || !strings.HasPrefix(clearKittyGraphics(), "\x1bPtmux;") {
+		t.Error("Expected the probed protocol with tmux passthrough")
+	}
+	if getProtocolName() != "Kitty (tmux)" {
+		t.Errorf("getProtocolName = %q", getProtocolName())
+	}
+	t.Setenv("TFE_TERMINAL_PROTOCOL", "none")
+	if detectTerminalProtocol() != ProtocolNone {
+		t.Error("Expected TFE_TERMINAL_PROTOCOL to override the probe")
+	}
+}
+
+// TestEncodeSixelImage tests that sixel output carries the image colors
+func TestEncodeSixelImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.NRGBA{255, 0, 0, 255})
+		}
+	}
+	out, err := encodeSixelImage(img)
+	if err != nil {
+		t.Fatalf("encodeSixelImage failed: %v", err)
+	}
+	if !strings.HasPrefix(out, "\x1bP") || !strings.Contains(out, ";2;100;0;0") {
+		t.Errorf("Expected a sixel image with a red palette entry, got %q", out)
+	}
+}
@@ -0,0 +1,59 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// queryTerminal writes query to the controlling terminal and collects its replies until
+// done reports they are complete or the timeout passes. The terminal is in raw mode meanwhile
+// so the replies aren't echoed. Whatever is still queued afterwards (replies trailing the
+// DA1 sentinel, e.g. through tmux) is flushed, so Bubble Tea never reads them as keystrokes.
+func queryTerminal(query string, timeout time.Duration, done func(reply []byte) bool) ([]byte, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+
+	fd := int(tty.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	defer term.Restore(fd, state)
+
+	if _, err := tty.WriteString(query); err != nil {
+		return nil, err
+	}
+
+	var reply []byte
+	buf := make([]byte, 1024)
+	deadline := time.Now().Add(timeout)
+	for !done(reply) {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, int(remaining.Milliseconds())+1)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil || n == 0 {
+			break
+		}
+		n, err = tty.Read(buf)
+		if err != nil {
+			break
+		}
+		reply = append(reply, buf[:n]...)
+	}
+	flushTerminalInput(fd)
+	return reply, nil
+}
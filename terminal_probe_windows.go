@@ -0,0 +1,13 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+// queryTerminal is not supported on Windows consoles - detection uses the environment instead
+func queryTerminal(query string, timeout time.Duration, done func(reply []byte) bool) ([]byte, error) {
+	return nil, errors.New("terminal queries are not supported on Windows")
+}
@@ -84,10 +84,68 @@ func wrapLine(line string, width int) []string {
 
 // getWrappedLineCount calculates the total number of wrapped lines for the current preview
 func (m model) getWrappedLineCount() int {
+	// Stash browser: one line per stash, or the diff opened from it
+	if m.stash != nil {
+		return m.stashLineCount()
+	}
+	// Commit log: one line per loaded commit, or the commit / file diff opened from it
+	if m.gitLog != nil {
+		return m.gitLogLineCount()
+	}
+
 	if !m.preview.loaded {
 		return 0
 	}
 
+	// History browser: one line per commit, or the diff / file version opened from it
+	if m.preview.history != nil {
+		return m.historyLineCount()
+	}
+
+	// Hex view: one line per row of bytes
+	if m.preview.hex != nil {
+		return m.preview.hex.rows()
+	}
+
+	// Blame overlay: file lines wrapped next to the blame columns, or the opened commit
+	if m.preview.blame != nil {
+		return m.blameLineCount()
+	}
+
+	// Comparison with another file: diff lines as laid out (unified or side by side)
+	if m.preview.compare != nil {
+		return m.compareLineCount()
+	}
+
+	// Table view: header + separator (+ stats line) + one line per visible row
+	if t := m.preview.table; t != nil {
+		lines := t.viewCount() + 2
+		if t.showStats {
+			lines++
+		}
+		return lines
+	}
+
+	// SQLite browser: schema lines, or header + separator + rows
+	if m.preview.db != nil {
+		return m.dbBrowserLineCount()
+	}
+
+	// Image preview: sized to fit the pane (line count of the last render)
+	if m.preview.image != nil {
+		return len(m.preview.image.cacheLines)
+	}
+
+	// Tree view: one line per visible node
+	if m.dataTreeActive() {
+		return len(m.preview.tree.rows)
+	}
+
+	// Pager: lines are not wrapped, so the count is the (indexed) file line count
+	if m.preview.pager != nil {
+		return m.preview.pager.lineCount()
+	}
+
 	// JSONL files: compute rendered line count at current width
 	if m.preview.isJSONL && len(m.preview.cachedJSONLMessages) > 0 {
 		var boxContentWidth int
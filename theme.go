@@ -287,4 +287,12 @@ func initStyles() {
 	diffMetaStyle = lipgloss.NewStyle().
 		Foreground(currentTheme.DiffMeta.adaptiveColor()).
 		Italic(true)
+
+	diffAddedWordStyle = lipgloss.NewStyle().
+		Foreground(currentTheme.DiffAdded.adaptiveColor()).
+		Reverse(true)
+
+	diffRemovedWordStyle = lipgloss.NewStyle().
+		Foreground(currentTheme.DiffRemoved.adaptiveColor()).
+		Reverse(true)
 }
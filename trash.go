@@ -8,6 +8,9 @@ package main
 // - Restoring files from trash
 // - Emptying trash (permanent deletion)
 // - Listing trash contents
+// - Enforcing the retention policy (max age, max total size)
+// - Serializing metadata updates across TFE instances (file lock + atomic writes)
+// - Rebuilding the metadata index from the trash directory after a crash
 
 import (
 	"encoding/json"
@@ -17,8 +20,11 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"syscall"
 	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 // trashItem represents a deleted item in the trash
@@ -88,19 +94,78 @@ func loadTrashMetadata() ([]trashItem, error) {
 	return items, nil
 }
 
-// saveTrashMetadata saves the trash metadata to disk
+// saveTrashMetadata atomically saves the trash metadata to disk.
+// The JSON is written to a temp file in the same directory and renamed over
+// trash.json, so a crash mid-write can never leave a truncated index behind.
+// Read-modify-write callers must hold the trash lock (see withTrashLock).
 func saveTrashMetadata(items []trashItem) error {
 	metadataPath, err := getTrashMetadataPath()
 	if err != nil {
 		return err
 	}
 
+	if items == nil {
+		items = []trashItem{} // Write [] rather than null
+	}
 	data, err := json.MarshalIndent(items, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(metadataPath, data, 0644)
+	tmp, err := os.CreateTemp(filepath.Dir(metadataPath), "trash.json.tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, metadataPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// withTrashLock runs fn while holding an exclusive lock on ~/.config/tfe/trash.lock.
+// The lock is shared by every TFE process (e.g. several instances in tmux splits),
+// so each read-modify-write of trash.json sees the previous writer's result.
+func withTrashLock(fn func() error) error {
+	metadataPath, err := getTrashMetadataPath()
+	if err != nil {
+		return err
+	}
+
+	lockPath := filepath.Join(filepath.Dir(metadataPath), "trash.lock")
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open trash lock: %w", err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("failed to acquire trash lock: %w", err)
+	}
+	defer unlockFile(f)
+
+	return fn()
 }
 
 // moveToTrash moves a file or directory to the trash
@@ -111,6 +176,40 @@ func moveToTrash(path string) error {
 		return fmt.Errorf("failed to stat file: %w", err)
 	}
 
+	// Record the full size up front (info.Size() is meaningless for directories)
+	size := info.Size()
+	if info.IsDir() {
+		size = dirSize(path)
+	}
+
+	// Name selection, the move itself and the metadata update all happen under
+	// the lock so two instances can't pick the same trashed name or lose entries
+	return withTrashLock(func() error {
+		return moveToTrashLocked(path, info, size)
+	})
+}
+
+// trashWithPolicy moves path to the trash and enforces the retention policy now
+// that the trash has grown. Every trash path in the UI goes through here.
+// Only older items are evicted: the item just trashed is never deleted, even
+// when it alone is larger than the quota.
+func trashWithPolicy(path string, p trashPolicy) error {
+	started := time.Now()
+	if err := moveToTrash(path); err != nil {
+		return err
+	}
+	// Best-effort: the item is already safely trashed, so eviction errors are not fatal
+	applyTrashPolicy(p, started)
+	return nil
+}
+
+// trashPath moves path to the trash under the configured policy
+func (m *model) trashPath(path string) error {
+	return trashWithPolicy(path, m.config.trashPolicy())
+}
+
+// moveToTrashLocked performs the move and metadata update (caller holds the trash lock)
+func moveToTrashLocked(path string, info os.FileInfo, size int64) error {
 	// Get trash directory
 	trashDir, err := getTrashDir()
 	if err != nil {
@@ -129,36 +228,19 @@ func moveToTrash(path string) error {
 		if _, err := os.Stat(trashedPath); os.IsNotExist(err) {
 			break
 		}
-		trashedName = fmt.Sprintf("%s_%s_%d", timestamp, originalName, counter)
+		// Counter goes before the extension so previews still detect the file type
+		ext := filepath.Ext(originalName)
+		if ext == originalName {
+			ext = "" // Dotfile like ".env"
+		}
+		trashedName = fmt.Sprintf("%s_%s_%d%s", timestamp, strings.TrimSuffix(originalName, ext), counter, ext)
 		trashedPath = filepath.Join(trashDir, trashedName)
 		counter++
 	}
 
 	// Move the file/directory to trash
-	// Try rename first (fast, atomic)
-	err = os.Rename(path, trashedPath)
-	if err != nil {
-		// Check if this is a cross-device error (different mount points)
-		if errors.Is(err, syscall.EXDEV) {
-			// Fallback to copy+delete for cross-device moves
-			// This happens when moving between different filesystems
-			// (e.g., /tmp → ~/.config/tfe/trash on different partitions)
-			err = copyRecursive(path, trashedPath)
-			if err != nil {
-				return fmt.Errorf("failed to copy to trash: %w", err)
-			}
-
-			// Only delete original after successful copy
-			err = os.RemoveAll(path)
-			if err != nil {
-				// Try to clean up the copy since we couldn't delete the original
-				os.RemoveAll(trashedPath)
-				return fmt.Errorf("failed to delete original after copy: %w", err)
-			}
-		} else {
-			// Some other error (permissions, etc.)
-			return fmt.Errorf("failed to move to trash: %w", err)
-		}
+	if err := movePath(path, trashedPath); err != nil {
+		return fmt.Errorf("failed to move to trash: %w", err)
 	}
 
 	// Load existing trash metadata
@@ -176,7 +258,7 @@ func moveToTrash(path string) error {
 		DeletedAt:    time.Now(),
 		OriginalName: originalName,
 		IsDir:        info.IsDir(),
-		Size:         info.Size(),
+		Size:         size,
 	}
 	items = append(items, newItem)
 
@@ -190,57 +272,171 @@ func moveToTrash(path string) error {
 	return nil
 }
 
+// movePath moves a file or directory from src to dst.
+// Tries rename first (fast, atomic) and falls back to copy+delete when src and
+// dst are on different filesystems (e.g., /tmp → ~/.config/tfe/trash).
+func movePath(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+
+	// Some other error (permissions, etc.)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	if err := copyRecursive(src, dst); err != nil {
+		return fmt.Errorf("failed to copy: %w", err)
+	}
+
+	// Only delete original after successful copy
+	if err := os.RemoveAll(src); err != nil {
+		// Try to clean up the copy since we couldn't delete the original
+		os.RemoveAll(dst)
+		return fmt.Errorf("failed to delete original after copy: %w", err)
+	}
+	return nil
+}
+
+// errRestoreConflict is returned when the restore destination is already taken
+var errRestoreConflict = errors.New("file already exists at destination")
+
 // restoreFromTrash restores a file from trash to its original location
 func restoreFromTrash(trashedPath string) error {
-	// Load trash metadata
+	_, err := restoreTrashPath(trashedPath, "", false)
+	return err
+}
+
+// restoreTrashPath restores path to dest. path is either a trashed item or a file
+// or folder inside a trashed directory (partial restore). An empty dest means the
+// original location. When dest is taken, keepBoth restores under a " (1)" style
+// suffixed name instead of failing with errRestoreConflict.
+// Returns the path the item was restored to.
+func restoreTrashPath(path, dest string, keepBoth bool) (string, error) {
+	var restoredTo string
+	err := withTrashLock(func() error {
+		var err error
+		restoredTo, err = restoreTrashPathLocked(path, dest, keepBoth)
+		return err
+	})
+	return restoredTo, err
+}
+
+// restoreTrashPathLocked performs the restore and metadata update (caller holds the trash lock)
+func restoreTrashPathLocked(path, dest string, keepBoth bool) (string, error) {
 	items, err := loadTrashMetadata()
 	if err != nil {
-		return fmt.Errorf("failed to load trash metadata: %w", err)
+		return "", fmt.Errorf("failed to load trash metadata: %w", err)
 	}
 
-	// Find the item in metadata
-	itemIndex := -1
-	var item trashItem
-	for i, it := range items {
-		if it.TrashedPath == trashedPath {
-			itemIndex = i
-			item = it
-			break
-		}
+	itemIndex, rel := findTrashOwner(items, path)
+	if itemIndex == -1 {
+		return "", fmt.Errorf("item not found in trash metadata")
 	}
+	item := items[itemIndex]
 
-	if itemIndex == -1 {
-		return fmt.Errorf("item not found in trash metadata")
+	if dest == "" {
+		dest = filepath.Join(item.OriginalPath, rel)
 	}
 
-	// Check if original path still exists
-	if _, err := os.Stat(item.OriginalPath); err == nil {
-		return fmt.Errorf("cannot restore: file already exists at original location")
+	if _, err := os.Lstat(dest); err == nil {
+		if !keepBoth {
+			return "", fmt.Errorf("cannot restore: %w", errRestoreConflict)
+		}
+		dest = uniqueRestorePath(dest)
 	}
 
 	// Ensure parent directory exists
-	parentDir := filepath.Dir(item.OriginalPath)
-	if err := os.MkdirAll(parentDir, 0755); err != nil {
-		return fmt.Errorf("failed to create parent directory: %w", err)
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("failed to create parent directory: %w", err)
 	}
 
-	// Restore the file
-	if err := os.Rename(trashedPath, item.OriginalPath); err != nil {
-		return fmt.Errorf("failed to restore file: %w", err)
+	// Size of a partial restore, measured before it leaves the trash
+	var movedSize int64
+	if rel != "" {
+		if info, err := os.Lstat(path); err == nil {
+			movedSize = info.Size()
+			if info.IsDir() {
+				movedSize = dirSize(path)
+			}
+		}
+	}
+
+	if err := movePath(path, dest); err != nil {
+		return "", fmt.Errorf("failed to restore file: %w", err)
+	}
+
+	if rel == "" {
+		// Whole item restored - remove from metadata
+		items = append(items[:itemIndex], items[itemIndex+1:]...)
+	} else {
+		// Partial restore - the trashed directory stays, minus what was taken out
+		items[itemIndex].Size -= movedSize
+		if items[itemIndex].Size < 0 {
+			items[itemIndex].Size = 0
+		}
 	}
 
-	// Remove from metadata
-	items = append(items[:itemIndex], items[itemIndex+1:]...)
 	if err := saveTrashMetadata(items); err != nil {
-		// File is already restored, just log the metadata error
-		return fmt.Errorf("file restored but failed to update metadata: %w", err)
+		// File is already restored, just report the metadata error
+		return dest, fmt.Errorf("file restored but failed to update metadata: %w", err)
 	}
 
-	return nil
+	return dest, nil
+}
+
+// findTrashOwner returns the index of the trash item that contains path, plus
+// path relative to that item's TrashedPath ("" when path is the item itself).
+// Returns -1 if path isn't part of any trashed item.
+func findTrashOwner(items []trashItem, path string) (int, string) {
+	for i, item := range items {
+		if path == item.TrashedPath {
+			return i, ""
+		}
+		if item.IsDir && strings.HasPrefix(path, item.TrashedPath+string(filepath.Separator)) {
+			return i, strings.TrimPrefix(path, item.TrashedPath+string(filepath.Separator))
+		}
+	}
+	return -1, ""
+}
+
+// trashOriginalPath returns the location path had before it was trashed.
+// Works for trashed items and for files inside trashed directories.
+func trashOriginalPath(items []trashItem, path string) (string, bool) {
+	index, rel := findTrashOwner(items, path)
+	if index == -1 {
+		return "", false
+	}
+	return filepath.Join(items[index].OriginalPath, rel), true
+}
+
+// uniqueRestorePath returns a free "name (N).ext" variant of dest (keep-both on conflict)
+func uniqueRestorePath(dest string) string {
+	dir := filepath.Dir(dest)
+	base := filepath.Base(dest)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		// Dotfile like ".env" - treat the whole name as the stem
+		stem, ext = base, ""
+	}
+
+	for n := 1; ; n++ {
+		candidate := filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, n, ext))
+		if _, err := os.Lstat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
 }
 
 // emptyTrash permanently deletes all items in the trash
 func emptyTrash() error {
+	return withTrashLock(emptyTrashLocked)
+}
+
+// emptyTrashLocked deletes every trashed item (caller holds the trash lock)
+func emptyTrashLocked() error {
 	// Load trash metadata
 	items, err := loadTrashMetadata()
 	if err != nil {
@@ -363,13 +559,24 @@ func copyFile(src, dst string, info os.FileInfo) error {
 
 // cleanupOldTrash removes items from trash older than the specified duration
 func cleanupOldTrash(olderThan time.Duration) (int, error) {
+	var removed int
+	err := withTrashLock(func() error {
+		var err error
+		removed, err = cleanupOldTrashLocked(olderThan)
+		return err
+	})
+	return removed, err
+}
+
+// cleanupOldTrashLocked removes expired items (caller holds the trash lock)
+func cleanupOldTrashLocked(olderThan time.Duration) (int, error) {
 	items, err := loadTrashMetadata()
 	if err != nil {
 		return 0, fmt.Errorf("failed to load trash metadata: %w", err)
 	}
 
 	cutoffTime := time.Now().Add(-olderThan)
-	var keptItems []trashItem
+	keptItems := []trashItem{}
 	removedCount := 0
 
 	for _, item := range items {
@@ -383,6 +590,11 @@ func cleanupOldTrash(olderThan time.Duration) (int, error) {
 		}
 	}
 
+	// Nothing expired - skip rewriting metadata
+	if removedCount == 0 {
+		return 0, nil
+	}
+
 	// Save updated metadata
 	if err := saveTrashMetadata(keptItems); err != nil {
 		return removedCount, fmt.Errorf("removed %d items but failed to update metadata: %w", removedCount, err)
@@ -391,6 +603,252 @@ func cleanupOldTrash(olderThan time.Duration) (int, error) {
 	return removedCount, nil
 }
 
+// evictTrashToSize permanently deletes the oldest trash items until the total
+// size is at or below maxSize. Items deleted at or after keepSince are never
+// evicted (zero = no exception). Returns the number of items removed and bytes freed.
+func evictTrashToSize(maxSize int64, keepSince time.Time) (int, int64, error) {
+	var removed int
+	var freed int64
+	err := withTrashLock(func() error {
+		var err error
+		removed, freed, err = evictTrashToSizeLocked(maxSize, keepSince)
+		return err
+	})
+	return removed, freed, err
+}
+
+// evictTrashToSizeLocked evicts oldest items over the quota (caller holds the trash lock)
+func evictTrashToSizeLocked(maxSize int64, keepSince time.Time) (int, int64, error) {
+	items, err := loadTrashMetadata()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load trash metadata: %w", err)
+	}
+
+	var totalSize int64
+	for _, item := range items {
+		totalSize += item.Size
+	}
+	if totalSize <= maxSize {
+		return 0, 0, nil
+	}
+
+	// Oldest first
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.Before(items[j].DeletedAt)
+	})
+
+	removedCount := 0
+	var freed int64
+	for removedCount < len(items) && totalSize > maxSize {
+		item := items[removedCount]
+		if !keepSince.IsZero() && !item.DeletedAt.Before(keepSince) {
+			break // Only newer (protected) items are left
+		}
+		os.RemoveAll(item.TrashedPath)
+		totalSize -= item.Size
+		freed += item.Size
+		removedCount++
+	}
+
+	if err := saveTrashMetadata(items[removedCount:]); err != nil {
+		return removedCount, freed, fmt.Errorf("removed %d items but failed to update metadata: %w", removedCount, err)
+	}
+
+	return removedCount, freed, nil
+}
+
+// repairTrashMetadata rebuilds trash.json from the contents of the trash directory.
+// Entries whose trashed file no longer exists are dropped, and files in the trash
+// directory without an entry (e.g. after a crash between the move and the metadata
+// write) are re-indexed from their "20060102_150405_name" trashed names. Their
+// original location is unknown, so recovered items restore to the home directory.
+// An unreadable trash.json is kept as trash.json.corrupt before being replaced.
+// Returns the number of entries recovered and dropped.
+func repairTrashMetadata() (int, int, error) {
+	recovered, dropped := 0, 0
+	err := withTrashLock(func() error {
+		trashDir, err := getTrashDir()
+		if err != nil {
+			return fmt.Errorf("failed to get trash directory: %w", err)
+		}
+		metadataPath, err := getTrashMetadataPath()
+		if err != nil {
+			return err
+		}
+
+		// Leftover temp files from an interrupted saveTrashMetadata
+		if stale, err := filepath.Glob(metadataPath + ".tmp-*"); err == nil {
+			for _, tmp := range stale {
+				os.Remove(tmp)
+			}
+		}
+
+		items, err := loadTrashMetadata()
+		if err != nil {
+			// Corrupt index: keep a copy for inspection and rebuild from scratch
+			os.Rename(metadataPath, metadataPath+".corrupt")
+			items = nil
+		}
+
+		// Keep entries that still point at something in the trash
+		known := make(map[string]bool, len(items))
+		kept := []trashItem{}
+		for _, item := range items {
+			if _, err := os.Lstat(item.TrashedPath); err != nil || known[item.TrashedPath] {
+				dropped++
+				continue
+			}
+			known[item.TrashedPath] = true
+			kept = append(kept, item)
+		}
+
+		// Re-index orphans found in the trash directory
+		entries, err := os.ReadDir(trashDir)
+		if err != nil {
+			return fmt.Errorf("failed to read trash directory: %w", err)
+		}
+		home, _ := os.UserHomeDir()
+		for _, entry := range entries {
+			trashedPath := filepath.Join(trashDir, entry.Name())
+			if known[trashedPath] {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			originalName, deletedAt := parseTrashedName(entry.Name())
+			if deletedAt.IsZero() {
+				deletedAt = info.ModTime()
+			}
+			size := info.Size()
+			if info.IsDir() {
+				size = dirSize(trashedPath)
+			}
+
+			kept = append(kept, trashItem{
+				OriginalPath: filepath.Join(home, originalName),
+				TrashedPath:  trashedPath,
+				DeletedAt:    deletedAt,
+				OriginalName: originalName,
+				IsDir:        info.IsDir(),
+				Size:         size,
+			})
+			recovered++
+		}
+
+		return saveTrashMetadata(kept)
+	})
+	return recovered, dropped, err
+}
+
+// parseTrashedName splits a trashed name ("20060102_150405_name") into the
+// original name and deletion time. Unrecognized names are returned unchanged
+// with a zero time.
+func parseTrashedName(name string) (string, time.Time) {
+	const layout = "20060102_150405"
+	if len(name) <= len(layout)+1 || name[len(layout)] != '_' {
+		return name, time.Time{}
+	}
+	deletedAt, err := time.ParseInLocation(layout, name[:len(layout)], time.Local)
+	if err != nil {
+		return name, time.Time{}
+	}
+	return name[len(layout)+1:], deletedAt
+}
+
+// trashPolicy holds the trash retention limits (zero values disable a limit)
+type trashPolicy struct {
+	MaxAge      time.Duration // Evict items deleted longer ago than this
+	MaxSize     int64         // Evict oldest items while total trash size exceeds this (bytes)
+	ConfirmSize int64         // Ask before trashing a single item larger than this (bytes)
+}
+
+// trashPolicy builds the retention policy from the config values
+func (c Config) trashPolicy() trashPolicy {
+	const mb = 1024 * 1024
+	return trashPolicy{
+		MaxAge:      time.Duration(c.TrashMaxAgeDays) * 24 * time.Hour,
+		MaxSize:     int64(c.TrashMaxSizeMB) * mb,
+		ConfirmSize: int64(c.TrashConfirmSizeMB) * mb,
+	}
+}
+
+// needsConfirm reports whether trashing an item of size asks first: it is above
+// the confirm size, or larger than the whole quota (every older item gets evicted)
+func (p trashPolicy) needsConfirm(size int64) bool {
+	return (p.ConfirmSize > 0 && size > p.ConfirmSize) || (p.MaxSize > 0 && size > p.MaxSize)
+}
+
+// String returns a short human-readable summary (e.g. "keep 30d, max 2.0 GB")
+func (p trashPolicy) String() string {
+	var parts []string
+	if p.MaxAge > 0 {
+		parts = append(parts, fmt.Sprintf("keep %dd", int(p.MaxAge.Hours()/24)))
+	}
+	if p.MaxSize > 0 {
+		parts = append(parts, "max "+formatFileSize(p.MaxSize))
+	}
+	if len(parts) == 0 {
+		return "no limits"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// applyTrashPolicy evicts expired items, then the oldest items until the trash
+// fits within the size quota, sparing items deleted at or after keepSince (zero = none).
+// Returns the total number of items removed.
+func applyTrashPolicy(p trashPolicy, keepSince time.Time) (int, error) {
+	removed := 0
+	if p.MaxAge > 0 {
+		n, err := cleanupOldTrash(p.MaxAge)
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+	if p.MaxSize > 0 {
+		n, _, err := evictTrashToSize(p.MaxSize, keepSince)
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// trashCleanupMsg is sent when a background trash policy run completes
+type trashCleanupMsg struct {
+	removed int
+	err     error
+}
+
+// trashCleanupCmd applies the trash retention policy in the background (used at startup)
+func trashCleanupCmd(p trashPolicy) tea.Cmd {
+	return func() tea.Msg {
+		removed, err := applyTrashPolicy(p, time.Time{})
+		return trashCleanupMsg{removed: removed, err: err}
+	}
+}
+
+// dirSize returns the total size of all regular files under path
+func dirSize(path string) int64 {
+	var total int64
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
+
 // convertTrashItemsToFileItems converts trash items to fileItems for display
 func convertTrashItemsToFileItems(items []trashItem) []fileItem {
 	fileItems := make([]fileItem, 0, len(items))
@@ -432,32 +890,48 @@ func getTrashItemByPath(trashItems []trashItem, trashedPath string) (trashItem,
 
 // permanentlyDelete permanently deletes a single item from trash
 func permanentlyDeleteFromTrash(trashedPath string) error {
+	return withTrashLock(func() error {
+		return permanentlyDeleteFromTrashLocked(trashedPath)
+	})
+}
+
+// permanentlyDeleteFromTrashLocked deletes one trashed item (caller holds the trash lock)
+func permanentlyDeleteFromTrashLocked(trashedPath string) error {
 	// Load trash metadata
 	items, err := loadTrashMetadata()
 	if err != nil {
 		return fmt.Errorf("failed to load trash metadata: %w", err)
 	}
 
-	// Find the item in metadata
-	itemIndex := -1
-	for i, item := range items {
-		if item.TrashedPath == trashedPath {
-			itemIndex = i
-			break
-		}
-	}
-
+	// Find the item in metadata (or the trashed directory containing it)
+	itemIndex, rel := findTrashOwner(items, trashedPath)
 	if itemIndex == -1 {
 		return fmt.Errorf("item not found in trash metadata")
 	}
 
+	var removedSize int64
+	if rel != "" {
+		removedSize = dirSize(trashedPath)
+		if info, err := os.Lstat(trashedPath); err == nil && !info.IsDir() {
+			removedSize = info.Size()
+		}
+	}
+
 	// Permanently delete the file/directory
 	if err := os.RemoveAll(trashedPath); err != nil {
 		return fmt.Errorf("failed to delete: %w", err)
 	}
 
-	// Remove from metadata
-	items = append(items[:itemIndex], items[itemIndex+1:]...)
+	if rel == "" {
+		// Remove from metadata
+		items = append(items[:itemIndex], items[itemIndex+1:]...)
+	} else {
+		// Deleted something inside a trashed directory - just shrink it
+		items[itemIndex].Size -= removedSize
+		if items[itemIndex].Size < 0 {
+			items[itemIndex].Size = 0
+		}
+	}
 	if err := saveTrashMetadata(items); err != nil {
 		return fmt.Errorf("deleted but failed to update metadata: %w", err)
 	}
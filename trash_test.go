@@ -2,10 +2,16 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 // setupTestTrash creates a temporary trash directory for testing
@@ -392,6 +398,160 @@ func TestRestoreFromTrash_Conflict(t *testing.T) {
 	}
 }
 
+// TestRestoreTrashPath_KeepBoth tests keep-both suffixing on a restore conflict
+func TestRestoreTrashPath_KeepBoth(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	testFile := filepath.Join(tmpHome, "notes.txt")
+	createTestFile(t, testFile, "original")
+	if err := moveToTrash(testFile); err != nil {
+		t.Fatalf("moveToTrash failed: %v", err)
+	}
+	createTestFile(t, testFile, "new file")
+
+	items, _ := loadTrashMetadata()
+	trashedPath := items[0].TrashedPath
+
+	_, err := restoreTrashPath(trashedPath, "", false)
+	if !errors.Is(err, errRestoreConflict) {
+		t.Fatalf("Expected errRestoreConflict, got %v", err)
+	}
+
+	restoredTo, err := restoreTrashPath(trashedPath, "", true)
+	if err != nil {
+		t.Fatalf("restoreTrashPath keep-both failed: %v", err)
+	}
+	expected := filepath.Join(tmpHome, "notes (1).txt")
+	if restoredTo != expected {
+		t.Errorf("Expected restore to %s, got %s", expected, restoredTo)
+	}
+	if content, _ := os.ReadFile(expected); string(content) != "original" {
+		t.Errorf("Restored content mismatch: got %q", string(content))
+	}
+	if content, _ := os.ReadFile(testFile); string(content) != "new file" {
+		t.Error("Existing file was overwritten")
+	}
+}
+
+// TestRestoreTrashPath_CustomDest tests "Restore As" / "Restore To" destinations
+func TestRestoreTrashPath_CustomDest(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	testFile := filepath.Join(tmpHome, "report.md")
+	createTestFile(t, testFile, "# report")
+	if err := moveToTrash(testFile); err != nil {
+		t.Fatalf("moveToTrash failed: %v", err)
+	}
+	items, _ := loadTrashMetadata()
+
+	dest := filepath.Join(tmpHome, "archive", "2024", "old-report.md")
+	restoredTo, err := restoreTrashPath(items[0].TrashedPath, dest, false)
+	if err != nil {
+		t.Fatalf("restoreTrashPath failed: %v", err)
+	}
+	if restoredTo != dest {
+		t.Errorf("Expected restore to %s, got %s", dest, restoredTo)
+	}
+	if _, err := os.Stat(dest); err != nil {
+		t.Errorf("Restored file missing: %v", err)
+	}
+	if _, err := os.Stat(testFile); !os.IsNotExist(err) {
+		t.Error("File should not be restored to its original location")
+	}
+	if items, _ := loadTrashMetadata(); len(items) != 0 {
+		t.Errorf("Expected empty trash after restore, got %d items", len(items))
+	}
+}
+
+// TestRestoreTrashPath_Partial tests restoring one file out of a trashed directory
+func TestRestoreTrashPath_Partial(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	testDir := filepath.Join(tmpHome, "project")
+	createTestFile(t, filepath.Join(testDir, "keep.txt"), "keep me")
+	createTestFile(t, filepath.Join(testDir, "src", "main.go"), "package main")
+	if err := moveToTrash(testDir); err != nil {
+		t.Fatalf("moveToTrash failed: %v", err)
+	}
+
+	items, _ := loadTrashMetadata()
+	sizeBefore := items[0].Size
+	nested := filepath.Join(items[0].TrashedPath, "src", "main.go")
+
+	if original, ok := trashOriginalPath(items, nested); !ok || original != filepath.Join(testDir, "src", "main.go") {
+		t.Errorf("trashOriginalPath = %q, %v", original, ok)
+	}
+
+	restoredTo, err := restoreTrashPath(nested, "", false)
+	if err != nil {
+		t.Fatalf("partial restore failed: %v", err)
+	}
+	if restoredTo != filepath.Join(testDir, "src", "main.go") {
+		t.Errorf("Unexpected restore path: %s", restoredTo)
+	}
+	if content, _ := os.ReadFile(restoredTo); string(content) != "package main" {
+		t.Errorf("Restored content mismatch: got %q", string(content))
+	}
+
+	// The trashed directory stays, minus the restored file
+	items, _ = loadTrashMetadata()
+	if len(items) != 1 {
+		t.Fatalf("Expected trashed directory to remain, got %d items", len(items))
+	}
+	if items[0].Size != sizeBefore-int64(len("package main")) {
+		t.Errorf("Expected size %d, got %d", sizeBefore-int64(len("package main")), items[0].Size)
+	}
+	if _, err := os.Stat(filepath.Join(items[0].TrashedPath, "keep.txt")); err != nil {
+		t.Errorf("Sibling file should still be in trash: %v", err)
+	}
+}
+
+// TestFindTrashOwner tests mapping nested paths back to their trashed item
+func TestFindTrashOwner(t *testing.T) {
+	items := []trashItem{
+		{TrashedPath: "/trash/20240101_120000_file.txt"},
+		{TrashedPath: "/trash/20240101_120000_dir", IsDir: true},
+	}
+
+	tests := []struct {
+		path      string
+		wantIndex int
+		wantRel   string
+	}{
+		{"/trash/20240101_120000_file.txt", 0, ""},
+		{"/trash/20240101_120000_dir", 1, ""},
+		{"/trash/20240101_120000_dir/a/b.txt", 1, filepath.Join("a", "b.txt")},
+		{"/trash/20240101_120000_dirty", -1, ""},
+		{"/trash/20240101_120000_file.txt/x", -1, ""},
+	}
+
+	for _, tt := range tests {
+		index, rel := findTrashOwner(items, filepath.FromSlash(tt.path))
+		if index != tt.wantIndex || rel != tt.wantRel {
+			t.Errorf("findTrashOwner(%q) = %d, %q; want %d, %q", tt.path, index, rel, tt.wantIndex, tt.wantRel)
+		}
+	}
+}
+
+// TestUniqueRestorePath tests keep-both name generation
+func TestUniqueRestorePath(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	createTestFile(t, filepath.Join(tmpDir, "photo.jpg"), "x")
+	createTestFile(t, filepath.Join(tmpDir, "photo (1).jpg"), "x")
+	createTestFile(t, filepath.Join(tmpDir, ".env"), "x")
+
+	if got := uniqueRestorePath(filepath.Join(tmpDir, "photo.jpg")); got != filepath.Join(tmpDir, "photo (2).jpg") {
+		t.Errorf("Expected photo (2).jpg, got %s", filepath.Base(got))
+	}
+	if got := uniqueRestorePath(filepath.Join(tmpDir, ".env")); got != filepath.Join(tmpDir, ".env (1)") {
+		t.Errorf("Expected .env (1), got %s", filepath.Base(got))
+	}
+}
+
 // TestEmptyTrash tests permanently deleting all trash
 func TestEmptyTrash(t *testing.T) {
 	tmpHome, cleanup := setupTestTrash(t)
@@ -584,6 +744,461 @@ func TestCleanupOldTrash(t *testing.T) {
 	}
 }
 
+// TestEvictTrashToSize tests that the oldest items are evicted until the quota fits
+func TestEvictTrashToSize(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	trashDir, _ := getTrashDir()
+	now := time.Now()
+
+	items := []trashItem{
+		{
+			OriginalPath: filepath.Join(tmpHome, "newest.txt"),
+			TrashedPath:  filepath.Join(trashDir, "newest.txt"),
+			DeletedAt:    now,
+			OriginalName: "newest.txt",
+			Size:         100,
+		},
+		{
+			OriginalPath: filepath.Join(tmpHome, "oldest.txt"),
+			TrashedPath:  filepath.Join(trashDir, "oldest.txt"),
+			DeletedAt:    now.Add(-3 * time.Hour),
+			OriginalName: "oldest.txt",
+			Size:         100,
+		},
+		{
+			OriginalPath: filepath.Join(tmpHome, "middle.txt"),
+			TrashedPath:  filepath.Join(trashDir, "middle.txt"),
+			DeletedAt:    now.Add(-1 * time.Hour),
+			OriginalName: "middle.txt",
+			Size:         100,
+		},
+	}
+
+	for _, item := range items {
+		createTestFile(t, item.TrashedPath, "content")
+	}
+	if err := saveTrashMetadata(items); err != nil {
+		t.Fatalf("saveTrashMetadata failed: %v", err)
+	}
+
+	// 300 bytes in trash, quota of 150 -> evict two oldest
+	count, freed, err := evictTrashToSize(150, time.Time{})
+	if err != nil {
+		t.Fatalf("evictTrashToSize failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected to remove 2 items, removed %d", count)
+	}
+	if freed != 200 {
+		t.Errorf("Expected to free 200 bytes, freed %d", freed)
+	}
+
+	remaining, err := loadTrashMetadata()
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].OriginalName != "newest.txt" {
+		t.Fatalf("Expected only newest.txt to remain, got %+v", remaining)
+	}
+
+	if _, err := os.Stat(filepath.Join(trashDir, "oldest.txt")); !os.IsNotExist(err) {
+		t.Error("Evicted file still exists in trash")
+	}
+
+	// Already within quota: nothing to do
+	count, _, err = evictTrashToSize(150, time.Time{})
+	if err != nil || count != 0 {
+		t.Errorf("Expected no eviction within quota, got count=%d err=%v", count, err)
+	}
+}
+
+// TestApplyTrashPolicy tests combined age and size eviction
+func TestApplyTrashPolicy(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	trashDir, _ := getTrashDir()
+	now := time.Now()
+
+	items := []trashItem{
+		{
+			OriginalPath: filepath.Join(tmpHome, "expired.txt"),
+			TrashedPath:  filepath.Join(trashDir, "expired.txt"),
+			DeletedAt:    now.Add(-10 * 24 * time.Hour),
+			OriginalName: "expired.txt",
+			Size:         10,
+		},
+		{
+			OriginalPath: filepath.Join(tmpHome, "big.bin"),
+			TrashedPath:  filepath.Join(trashDir, "big.bin"),
+			DeletedAt:    now.Add(-1 * time.Hour),
+			OriginalName: "big.bin",
+			Size:         1000,
+		},
+		{
+			OriginalPath: filepath.Join(tmpHome, "recent.txt"),
+			TrashedPath:  filepath.Join(trashDir, "recent.txt"),
+			DeletedAt:    now,
+			OriginalName: "recent.txt",
+			Size:         10,
+		},
+	}
+	for _, item := range items {
+		createTestFile(t, item.TrashedPath, "content")
+	}
+	if err := saveTrashMetadata(items); err != nil {
+		t.Fatalf("saveTrashMetadata failed: %v", err)
+	}
+
+	policy := trashPolicy{MaxAge: 7 * 24 * time.Hour, MaxSize: 500}
+	removed, err := applyTrashPolicy(policy, time.Time{})
+	if err != nil {
+		t.Fatalf("applyTrashPolicy failed: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected to remove 2 items, removed %d", removed)
+	}
+
+	remaining, _ := loadTrashMetadata()
+	if len(remaining) != 1 || remaining[0].OriginalName != "recent.txt" {
+		t.Errorf("Expected only recent.txt to remain, got %+v", remaining)
+	}
+
+	// Zero policy disables all limits
+	if removed, err := applyTrashPolicy(trashPolicy{}, time.Time{}); err != nil || removed != 0 {
+		t.Errorf("Expected no-op for empty policy, got removed=%d err=%v", removed, err)
+	}
+}
+
+// TestTrashPath_AppliesPolicy tests that every UI trash path enforces the retention policy
+func TestTrashPath_AppliesPolicy(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	trashDir, _ := getTrashDir()
+	expired := trashItem{
+		OriginalPath: filepath.Join(tmpHome, "expired.txt"),
+		TrashedPath:  filepath.Join(trashDir, "expired.txt"),
+		DeletedAt:    time.Now().Add(-10 * 24 * time.Hour),
+		OriginalName: "expired.txt",
+		Size:         10,
+	}
+	createTestFile(t, expired.TrashedPath, "content")
+	if err := saveTrashMetadata([]trashItem{expired}); err != nil {
+		t.Fatalf("saveTrashMetadata failed: %v", err)
+	}
+
+	path := filepath.Join(tmpHome, "work", "file.txt")
+	createTestFile(t, path, "content")
+	m := model{}
+	m.config.TrashMaxAgeDays = 7
+	if err := m.trashPath(path); err != nil {
+		t.Fatalf("trashPath failed: %v", err)
+	}
+
+	remaining, _ := loadTrashMetadata()
+	if len(remaining) != 1 || remaining[0].OriginalName != "file.txt" {
+		t.Errorf("Expected the expired item evicted and file.txt kept, got %+v", remaining)
+	}
+}
+
+// TestTrashPath_KeepsItemOverQuota tests that an item larger than the quota evicts
+// the older items but is never deleted itself, and that trashing it asks first
+func TestTrashPath_KeepsItemOverQuota(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	trashDir, _ := getTrashDir()
+	older := trashItem{
+		OriginalPath: filepath.Join(tmpHome, "older.txt"),
+		TrashedPath:  filepath.Join(trashDir, "older.txt"),
+		DeletedAt:    time.Now().Add(-time.Hour),
+		OriginalName: "older.txt",
+		Size:         10,
+	}
+	createTestFile(t, older.TrashedPath, "content")
+	if err := saveTrashMetadata([]trashItem{older}); err != nil {
+		t.Fatalf("saveTrashMetadata failed: %v", err)
+	}
+
+	path := filepath.Join(tmpHome, "work", "huge.bin")
+	createTestFile(t, path, strings.Repeat("x", 2*1024*1024))
+	m := model{}
+	m.config.TrashMaxSizeMB = 1
+	file := fileItem{name: "huge.bin", path: path, size: 2 * 1024 * 1024}
+	if d := m.trashDeleteDialog(file, file.size, dialogModel{title: "Move to Trash"}); d.title != "Large Item" ||
+		!strings.Contains(d.message, "every other item") {
+		t.Errorf("Expected a confirmation for an item over the quota, got %q: %s", d.title, d.message)
+	}
+
+	if err := m.trashPath(path); err != nil {
+		t.Fatalf("trashPath failed: %v", err)
+	}
+	remaining, _ := loadTrashMetadata()
+	if len(remaining) != 1 || remaining[0].OriginalName != "huge.bin" {
+		t.Fatalf("Expected older.txt evicted and huge.bin kept, got %+v", remaining)
+	}
+	if _, err := os.Stat(remaining[0].TrashedPath); err != nil {
+		t.Errorf("Expected huge.bin still in the trash: %v", err)
+	}
+}
+
+// TestTrashDeleteDialog_Background tests that large folders are measured and
+// trashed off the UI goroutine, with the confirmation in between
+func TestTrashDeleteDialog_Background(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	work := filepath.Join(tmpHome, "work")
+	createTestFile(t, filepath.Join(work, "big", "blob.bin"), strings.Repeat("x", 2*1024*1024))
+	m := model{height: 30, width: 120, viewMode: viewSinglePane, currentPath: work}
+	m.config.TrashConfirmSizeMB = 1
+	m.loadFiles()
+	m.cursor = 1 // Past ".."
+	file := *m.getCurrentFile()
+	if file.name != "big" {
+		t.Fatalf("Expected the cursor on big, got %s", file.name)
+	}
+
+	cmd := m.openTrashDeleteDialog(file, dialogModel{dialogType: dialogConfirm, title: "Move to Trash"})
+	if cmd == nil || m.showDialog {
+		t.Fatal("Expected the folder measured in the background before the dialog opens")
+	}
+	newM, _ := m.Update(cmd())
+	m = newM.(model)
+	if !m.showDialog || m.dialog.title != "Large Item" {
+		t.Fatalf("Expected the large item dialog, got %q", m.dialog.title)
+	}
+
+	newM, cmd = m.handleKeyEvent(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = newM.(model)
+	if _, err := os.Stat(file.path); err != nil {
+		t.Fatal("Expected the move to wait for the command")
+	}
+	for _, c := range cmd().(tea.BatchMsg) {
+		if msg, ok := c().(trashedMsg); ok {
+			newM, _ = m.Update(msg)
+			m = newM.(model)
+		}
+	}
+	if _, err := os.Stat(file.path); !os.IsNotExist(err) || m.statusMessage != "Moved to trash: big" {
+		t.Errorf("Expected big in trash, got status %q", m.statusMessage)
+	}
+	if items, _ := loadTrashMetadata(); len(items) != 1 || items[0].Size != 2*1024*1024 {
+		t.Errorf("Expected the folder's size recorded, got %+v", items)
+	}
+}
+
+// TestTrashPolicyFromConfig tests config conversion and the summary string
+func TestTrashPolicyFromConfig(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.TrashMaxAgeDays = 30
+	cfg.TrashMaxSizeMB = 2048
+	cfg.TrashConfirmSizeMB = 0
+
+	policy := cfg.trashPolicy()
+	if policy.MaxAge != 30*24*time.Hour {
+		t.Errorf("Expected MaxAge 30d, got %v", policy.MaxAge)
+	}
+	if policy.MaxSize != 2048*1024*1024 {
+		t.Errorf("Expected MaxSize 2 GB, got %d", policy.MaxSize)
+	}
+	if policy.ConfirmSize != 0 {
+		t.Errorf("Expected ConfirmSize disabled, got %d", policy.ConfirmSize)
+	}
+	if got := policy.String(); !contains(got, "keep 30d") {
+		t.Errorf("Expected policy summary to mention age, got %q", got)
+	}
+	if got := (trashPolicy{}).String(); got != "no limits" {
+		t.Errorf("Expected \"no limits\", got %q", got)
+	}
+}
+
+// TestMoveToTrash_DirectorySize tests that directory sizes are recorded recursively
+func TestMoveToTrash_DirectorySize(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	testDir := filepath.Join(tmpHome, "sized")
+	createTestFile(t, filepath.Join(testDir, "a.txt"), "12345")
+	createTestFile(t, filepath.Join(testDir, "nested", "b.txt"), "1234567890")
+
+	if err := moveToTrash(testDir); err != nil {
+		t.Fatalf("moveToTrash failed: %v", err)
+	}
+
+	items, err := loadTrashMetadata()
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	if len(items) != 1 || items[0].Size != 15 {
+		t.Errorf("Expected directory size 15, got %+v", items)
+	}
+}
+
+// TestSaveTrashMetadata_Atomic tests that saves leave no temp files and never write null
+func TestSaveTrashMetadata_Atomic(t *testing.T) {
+	_, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	if err := saveTrashMetadata(nil); err != nil {
+		t.Fatalf("saveTrashMetadata failed: %v", err)
+	}
+
+	metadataPath, _ := getTrashMetadataPath()
+	data, err := os.ReadFile(metadataPath)
+	if err != nil {
+		t.Fatalf("Failed to read metadata: %v", err)
+	}
+	if string(data) != "[]" {
+		t.Errorf("Expected empty JSON array, got %q", string(data))
+	}
+
+	leftovers, _ := filepath.Glob(metadataPath + ".tmp-*")
+	if len(leftovers) != 0 {
+		t.Errorf("Expected no temp files, found %v", leftovers)
+	}
+}
+
+// TestMoveToTrash_Concurrent tests that concurrent trash operations don't lose entries
+func TestMoveToTrash_Concurrent(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	const n = 20
+	for i := 0; i < n; i++ {
+		createTestFile(t, filepath.Join(tmpHome, "files", fmt.Sprintf("f%d.txt", i)), "content")
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := moveToTrash(filepath.Join(tmpHome, "files", fmt.Sprintf("f%d.txt", i))); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("moveToTrash failed: %v", err)
+	}
+
+	items, err := loadTrashMetadata()
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	if len(items) != n {
+		t.Errorf("Expected %d metadata entries, got %d", n, len(items))
+	}
+}
+
+// TestRepairTrashMetadata tests rebuilding the index from the trash directory
+func TestRepairTrashMetadata(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	// One properly trashed file
+	kept := filepath.Join(tmpHome, "kept.txt")
+	createTestFile(t, kept, "content")
+	if err := moveToTrash(kept); err != nil {
+		t.Fatalf("moveToTrash failed: %v", err)
+	}
+
+	// An orphan in the trash dir (crash before metadata write) and a stale entry
+	trashDir, _ := getTrashDir()
+	createTestFile(t, filepath.Join(trashDir, "20240102_030405_orphan.txt"), "12345")
+	items, _ := loadTrashMetadata()
+	items = append(items, trashItem{
+		OriginalPath: filepath.Join(tmpHome, "gone.txt"),
+		TrashedPath:  filepath.Join(trashDir, "gone.txt"),
+		OriginalName: "gone.txt",
+	})
+	if err := saveTrashMetadata(items); err != nil {
+		t.Fatalf("saveTrashMetadata failed: %v", err)
+	}
+
+	recovered, dropped, err := repairTrashMetadata()
+	if err != nil {
+		t.Fatalf("repairTrashMetadata failed: %v", err)
+	}
+	if recovered != 1 || dropped != 1 {
+		t.Errorf("Expected 1 recovered and 1 dropped, got %d and %d", recovered, dropped)
+	}
+
+	items, _ = loadTrashMetadata()
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 entries after repair, got %d", len(items))
+	}
+	orphan, found := getTrashItemByPath(items, filepath.Join(trashDir, "20240102_030405_orphan.txt"))
+	if !found {
+		t.Fatal("Orphan was not re-indexed")
+	}
+	if orphan.OriginalName != "orphan.txt" || orphan.Size != 5 {
+		t.Errorf("Unexpected recovered item: %+v", orphan)
+	}
+	if orphan.DeletedAt.Year() != 2024 {
+		t.Errorf("Expected deletion time parsed from name, got %v", orphan.DeletedAt)
+	}
+}
+
+// TestRepairTrashMetadata_Corrupt tests recovery from an unreadable trash.json
+func TestRepairTrashMetadata_Corrupt(t *testing.T) {
+	_, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	trashDir, _ := getTrashDir()
+	createTestFile(t, filepath.Join(trashDir, "20240102_030405_a.txt"), "a")
+
+	metadataPath, _ := getTrashMetadataPath()
+	if err := os.WriteFile(metadataPath, []byte(`[{"original_path": `), 0644); err != nil {
+		t.Fatalf("Failed to write corrupt metadata: %v", err)
+	}
+	if _, err := loadTrashMetadata(); err == nil {
+		t.Fatal("Expected corrupt metadata to fail loading")
+	}
+
+	recovered, _, err := repairTrashMetadata()
+	if err != nil {
+		t.Fatalf("repairTrashMetadata failed: %v", err)
+	}
+	if recovered != 1 {
+		t.Errorf("Expected 1 recovered item, got %d", recovered)
+	}
+	if _, err := os.Stat(metadataPath + ".corrupt"); err != nil {
+		t.Error("Expected corrupt metadata to be preserved as trash.json.corrupt")
+	}
+}
+
+// TestParseTrashedName tests splitting trashed names into name and timestamp
+func TestParseTrashedName(t *testing.T) {
+	tests := []struct {
+		input    string
+		wantName string
+		wantZero bool
+	}{
+		{"20240102_030405_notes.md", "notes.md", false},
+		{"20240102_030405_with_underscores.txt", "with_underscores.txt", false},
+		{"random-file.txt", "random-file.txt", true},
+		{"20240102_030405", "20240102_030405", true},
+	}
+
+	for _, tt := range tests {
+		name, deletedAt := parseTrashedName(tt.input)
+		if name != tt.wantName {
+			t.Errorf("parseTrashedName(%q) name = %q, want %q", tt.input, name, tt.wantName)
+		}
+		if deletedAt.IsZero() != tt.wantZero {
+			t.Errorf("parseTrashedName(%q) zero time = %v, want %v", tt.input, deletedAt.IsZero(), tt.wantZero)
+		}
+	}
+}
+
 // TestPermanentlyDeleteFromTrash tests deleting a single item permanently
 func TestPermanentlyDeleteFromTrash(t *testing.T) {
 	tmpHome, cleanup := setupTestTrash(t)
@@ -631,6 +1246,35 @@ func TestPermanentlyDeleteFromTrash(t *testing.T) {
 	}
 }
 
+// TestPermanentlyDeleteFromTrash_Nested tests deleting one file inside a trashed directory
+func TestPermanentlyDeleteFromTrash_Nested(t *testing.T) {
+	tmpHome, cleanup := setupTestTrash(t)
+	defer cleanup()
+
+	testDir := filepath.Join(tmpHome, "logs")
+	createTestFile(t, filepath.Join(testDir, "a.log"), "aaaa")
+	createTestFile(t, filepath.Join(testDir, "b.log"), "bb")
+	if err := moveToTrash(testDir); err != nil {
+		t.Fatalf("moveToTrash failed: %v", err)
+	}
+	items, _ := loadTrashMetadata()
+
+	if err := permanentlyDeleteFromTrash(filepath.Join(items[0].TrashedPath, "a.log")); err != nil {
+		t.Fatalf("permanentlyDeleteFromTrash failed: %v", err)
+	}
+
+	items, _ = loadTrashMetadata()
+	if len(items) != 1 {
+		t.Fatalf("Expected trashed directory to remain, got %d items", len(items))
+	}
+	if items[0].Size != 2 {
+		t.Errorf("Expected remaining size 2, got %d", items[0].Size)
+	}
+	if _, err := os.Stat(filepath.Join(items[0].TrashedPath, "a.log")); !os.IsNotExist(err) {
+		t.Error("Nested file should be deleted")
+	}
+}
+
 // TestConvertTrashItemsToFileItems tests conversion for display
 func TestConvertTrashItemsToFileItems(t *testing.T) {
 	tmpHome, cleanup := setupTestTrash(t)
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -145,14 +146,47 @@ type previewModel struct {
 	cachedJSONLMessages []jsonlMessage // Parsed JSONL messages (expensive JSON parsing done once)
 	cachedJSONLIsTailed bool           // Whether the file was tail-read
 	jsonlFullLoad       bool           // Whether to load full file instead of tail
+	// Streaming pager (large files - see pager.go)
+	pager      *pagerState // Non-nil when the file is paged from disk instead of loaded
+	gotoActive bool        // Whether the pager's jump prompt (":") is open
+	gotoInput  string      // Jump prompt input (line, percentage or $)
+	// Hex view (see hexview.go) - overlays the regular preview when non-nil
+	hex *hexViewState
+	// Tree view for JSON/YAML/TOML (see datatree.go) - nil when the file didn't parse
+	tree *dataTreeState
+	// Table view for CSV/TSV (see csvtable.go)
+	table *csvTableState
+	// SQLite browser (see dbbrowser.go)
+	db *dbBrowserState
+	// Block-character image preview when the terminal has no graphics protocol (see imageview.go)
+	image *imagePreviewState
+	// Outline, links and the open outline/link panel for markdown (see markdownnav.go)
+	markdown *markdownNavState
+	// GIF playback, for both the graphics protocol and block-character previews (see imageanim.go)
+	anim *imageAnimation
+	// Follow mode: appends to the file are shown as they happen (see follow.go)
+	follow *followState
+	// Summary shown when the selected item is a directory (see dirsummary.go)
+	dir *dirSummaryState
+	// Git blame overlay and the commit opened from it (see blame.go)
+	blame *blameState
+	// Git history of the file, with diffs and old versions (see history.go)
+	history *historyState
+	// Comparison of two files shown instead of the file (see compare.go)
+	compare *compareState
 	// Prompt template (for prompt files)
 	isPrompt       bool            // Whether the file is a prompt template
 	promptTemplate *promptTemplate // Parsed prompt template
 	// Search within preview
 	searchActive  bool   // Whether search mode is active in preview
 	searchQuery   string // Current search query
-	searchMatches []int  // Line numbers with matches
+	searchMatches []int  // Displayed line of each match (a line appears once per occurrence)
 	currentMatch  int    // Index in searchMatches array
+	// Occurrence within its line for each entry of searchMatches, the compiled query
+	// and why it didn't compile (see previewsearch.go)
+	searchOccurrence []int
+	searchRe         *regexp.Regexp
+	searchErr        string
 }
 
 // promptTemplate represents a parsed prompt with metadata and template
@@ -286,6 +320,9 @@ type model struct {
 	rightWidth     int     // Width of right pane in dual-pane mode
 	lockedTopRatio float64 // Vertical split: locked ratio of top pane height (0 = not set)
 	focusedPane    paneType // Which pane has focus in dual-pane mode
+	terminalBlurred bool    // The terminal window lost focus (focus reporting) - pauses animations
+	markdownBack    []markdownLocation // Documents/positions before followed markdown links (Backspace)
+	previewSearchOpts previewSearchOptions // Regex / case-sensitive / whole-word preview search (Alt+R/C/W)
 	panelsLocked   bool     // When true, panel widths don't change with focus (disables accordion)
 	// Glamour renderer cache (avoid recreating on every render)
 	glamourRenderer      interface{} // *glamour.TermRenderer
@@ -322,6 +359,14 @@ type model struct {
 	showChangesOnly       bool              // Filter to show only git-changed/untracked files
 	changedFiles          []fileItem        // Cached list of changed files from git status
 	showDiffPreview       bool              // When true, show git diff in preview instead of file content (default in changes mode)
+	diffSideBySide        bool              // Diffs in old/new columns when the pane is wide enough (see diffview.go)
+	hunkSel               *hunkSelection    // Hunk picked with [/] in the changes mode diff for staging (see staging.go)
+	commit                *commitComposer   // Commit dialog opened with c in changes mode (see commit.go)
+	branches              *branchManager    // Branches dialog opened with B (see branches.go)
+	stash                 *stashState       // Stash browser opened with S, shown in the full preview (see stash.go)
+	gitLog                *gitLogState      // Commit log opened with L, shown in the full preview (see gitlog.go)
+	stashMarked           map[string]bool   // Changed files marked with z for stashing with Z
+	gitStatus             gitStatusState    // Cached git status of the current repository for row markers (see gitstatus.go)
 	agentSessions         []AgentSession    // Cached agent sessions (populated on changes mode entry)
 	agentFileMap          map[string]string // File path -> agent label (built from agentSessions + changedFiles)
 	changesRestoreDisplay displayMode       // Display mode to restore when exiting changes mode
@@ -334,6 +379,7 @@ type model struct {
 	// Trash/Recycle bin system
 	showTrashOnly     bool        // Filter to show trash contents
 	trashItems        []trashItem // Cached trash items when viewing trash
+	trashTotalSize    int64       // Cached total trash size (getTrashSize) for the F12 status bar
 	trashRestorePath  string      // Path to restore when exiting trash view
 	// Prompt inline editing (fillable variables)
 	promptEditMode         bool              // Whether prompt edit mode is active (Tab to activate)
@@ -343,6 +389,11 @@ type model struct {
 	filePickerRestorePath  string            // Path to restore preview after file picker
 	filePickerRestorePrompts bool            // Whether to restore prompts filter after file picker
 	filePickerCopySource   string            // Source path when picking copy destination (context menu)
+	filePickerRestoreSource string           // Trashed path when picking a restore destination (context menu)
+	filePickerCompareSource string           // File to compare with the one picked (context menu, see compare.go)
+	compareMarked          string            // File marked with = to compare with the next one
+	pendingRestorePath     string            // Trashed path awaiting the "Restore Conflict" keep-both answer
+	pendingRestoreDest     string            // Destination for that retry ("" = original location)
 	// Tree view expansion
 	expandedDirs map[string]bool // Path -> expanded state
 	treeItems    []treeItem       // Cached tree items for tree view
@@ -401,7 +452,7 @@ type model struct {
 	// Unified configuration (loaded from ~/.config/tfe/config.toml)
 	config Config
 	// Settings panel state (Ctrl+,)
-	settingsCategory int // Active category tab (0=General, 1=Appearance, 2=File Watcher)
+	settingsCategory int // Active category tab (0=General, 1=Appearance, 2=File Watcher, 3=Trash)
 	settingsCursor   int // Selected setting within category
 	settingsEditing  bool   // Whether currently editing a string field
 	settingsInput    string // Buffer for string input editing
@@ -466,6 +517,8 @@ const (
 	dialogConfirm  // Yes/No confirmation (F8 delete)
 	dialogMessage  // Status messages (success/error)
 	dialogSettings // Settings panel (Ctrl+,)
+	dialogCommit   // Commit composer (c in changes mode)
+	dialogBranches // Branch manager (B)
 )
 
 // dialogModel holds dialog state
@@ -476,6 +529,7 @@ type dialogModel struct {
 	input      string // For text input dialogs
 	confirmed  bool   // User confirmed action
 	isError    bool   // For message dialogs (red vs green)
+	hint       string // Custom key hint for confirm dialogs (empty = "[Y]es / [N]o / [Esc]")
 }
 
 // MenuItem represents a single menu item
@@ -32,11 +32,19 @@ func (m model) Init() tea.Cmd {
 		checkForUpdates(),  // Check for new releases on GitHub
 	}
 
+	// Apply the trash retention policy (max age / size quota) in the background
+	cmds = append(cmds, trashCleanupCmd(m.config.trashPolicy()))
+
 	// Start file watcher for the initial directory
 	if watchCmd := m.startWatcher(m.currentPath); watchCmd != nil {
 		cmds = append(cmds, watchCmd)
 	}
 
+	// Standalone preview started with --follow
+	if m.preview.follow != nil {
+		cmds = append(cmds, m.preview.follow.wait())
+	}
+
 	// Start agent session polling if auto-watch is enabled (TFE_AUTO_CHANGES=1)
 	if m.agentAutoWatch {
 		// Seed initial agent session state so we don't trigger on startup
@@ -211,11 +219,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.WindowSizeMsg:
 		// Handle window resize
+		followAtEnd := m.preview.follow != nil && m.followAtEnd() // Follow mode stays at the end
 		m.height = msg.Height
 		m.width = msg.Width
 
 		m.calculateLayout()      // Recalculate pane layout on resize
 		m.populatePreviewCache() // Repopulate cache with new width
+		if followAtEnd {
+			m.followScrollToEnd()
+		}
 
 		// Reset horizontal scroll on window resize
 		m.detailScrollX = 0
@@ -235,8 +247,35 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Keep indexing the streaming pager's file (in the background) and
+		// the table's rows in small steps
+		indexCmd := m.advancePagerIndex()
+		m.advanceCSVIndex()
+		m.advanceDirSummary()
+
+		// Start (or resume) GIF playback once the preview has focus, and
+		// start adding up the size of a previewed folder
+		if cmd := tea.Batch(indexCmd, m.imageAnimationCmd(), m.dirSummaryCmd(), m.gitStatusCmd()); cmd != nil {
+			return m, tea.Batch(tickCmd(), cmd)
+		}
 		return m, tickCmd() // Continue animation
 
+	case imageFrameMsg:
+		return m, m.handleImageFrame(msg)
+
+	case followMsg:
+		// Followed file changed: show what was appended (see follow.go)
+		return m, m.handleFollowMsg(msg)
+
+	case tea.FocusMsg:
+		m.terminalBlurred = false
+		return m, nil
+
+	case tea.BlurMsg:
+		// Terminal window lost focus: pause animations until it's back
+		m.terminalBlurred = true
+		return m, nil
+
 	case footerTickMsg:
 		// Animate footer scrolling if active
 		if m.footerScrolling {
@@ -438,6 +477,115 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			tea.EnableMouseCellMotion,
 		)
 
+	case trashSizeMsg:
+		// Folder measured for the delete confirmation
+		m.applyTrashSizeMsg(msg)
+		return m, nil
+
+	case trashedMsg:
+		// Background move to trash finished
+		m.applyTrashedMsg(msg)
+		return m, statusTimeoutCmd()
+
+	case trashCleanupMsg:
+		// Startup trash retention run finished
+		if msg.err != nil {
+			m.setStatusMessage(fmt.Sprintf("Trash cleanup failed: %v", msg.err), true)
+		} else if msg.removed > 0 {
+			m.setStatusMessage(fmt.Sprintf("🧹 Trash cleanup: removed %d old items (%s)", msg.removed, m.config.trashPolicy()), false)
+			if m.showTrashOnly {
+				m.loadFiles()
+			}
+		}
+		return m, nil
+
+	case hexSearchMsg:
+		// Streaming byte-pattern search in the hex view finished
+		m.applyHexSearchResult(msg)
+		return m, nil
+
+	case csvTableMsg:
+		// Table sort, filter or stats scan finished
+		return m, m.applyCSVTableMsg(msg)
+
+	case dbBrowserMsg:
+		// SQLite table opened or query finished
+		return m, m.applyDBBrowserMsg(msg)
+
+	case blameMsg:
+		// git blame for the previewed file finished
+		return m, m.applyBlameMsg(msg)
+
+	case blameCommitMsg:
+		// Commit opened from the blame overlay loaded
+		m.applyBlameCommitMsg(msg)
+		return m, nil
+
+	case historyMsg:
+		// git log for the file's history browser finished
+		return m, m.applyHistoryMsg(msg)
+
+	case commitMessageMsg:
+		// Commit message command finished
+		m.applyCommitMessageMsg(msg)
+		return m, nil
+
+	case commitEditedMsg:
+		// External editor closed the commit message
+		return m, m.applyCommitEditedMsg(msg)
+
+	case commitDoneMsg:
+		// git commit exited
+		return m, m.applyCommitDoneMsg(msg)
+
+	case gitLogPageMsg:
+		// Next page of the commit log loaded
+		return m, m.applyGitLogPageMsg(msg)
+
+	case gitLogCommitMsg:
+		// Commit opened from the log loaded
+		m.applyGitLogCommitMsg(msg)
+		return m, nil
+
+	case gitLogFileMsg:
+		// File diff opened from a commit in the log loaded
+		m.applyGitLogFileMsg(msg)
+		return m, nil
+
+	case stashViewMsg:
+		// Diff opened from the stash browser loaded
+		m.applyStashViewMsg(msg)
+		return m, nil
+
+	case historyViewMsg:
+		// Diff or file version opened from the history browser loaded
+		m.applyHistoryViewMsg(msg)
+		return m, nil
+
+	case dirSizeMsg:
+		// Background size walk of the previewed folder finished
+		m.applyDirSizeMsg(msg)
+		return m, nil
+
+	case dirGitMsg:
+		// Git status of the previewed repository folder
+		m.applyDirGitMsg(msg)
+		return m, nil
+
+	case gitStatusMsg:
+		// Background git status for the row markers finished
+		m.applyGitStatusMsg(msg)
+		return m, nil
+
+	case pagerIndexMsg:
+		// Background indexing step of the streaming pager finished
+		return m, m.applyPagerIndexMsg(msg)
+
+	case pagerSearchMsg:
+		// Streaming search through a large file finished
+		m.applyPagerSearchResult(msg)
+		return m, nil
+
 	case agentCheckTickMsg:
 		// Periodic poll: detect agent session completions
 		if m.agentAutoWatch {
@@ -450,6 +598,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case fileChangedMsg:
 		// File system change detected by fsnotify watcher
 		// Refresh the file list to reflect changes (new/deleted/modified files)
+		m.invalidateGitStatus()
 		m.loadFiles()
 
 		// Auto-refresh git changes list when in changes mode
@@ -473,11 +622,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 				m.changedFiles = changed
+				m.reloadHunkSelection() // The file or the index changed under the selected hunk
 			}
 		}
 
 		// If preview is active, refresh it too (file content may have changed)
-		if m.preview.loaded && m.preview.filePath != "" {
+		// Followed files are updated incrementally by follow mode instead
+		if m.preview.loaded && m.preview.filePath != "" && m.preview.follow == nil {
 			// Check if the changed file is the one being previewed
 			if msg.path == m.preview.filePath || msg.op&fsnotify.Create != 0 || msg.op&fsnotify.Remove != 0 {
 				m.loadPreview(m.preview.filePath)
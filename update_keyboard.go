@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -164,6 +165,11 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Handle pager jump prompt input
+	if m.viewMode == viewFullPreview && m.preview.gotoActive {
+		return m.handlePagerGotoKey(msg)
+	}
+
 	// Handle preview search mode input
 	if m.viewMode == viewFullPreview && m.preview.searchActive {
 		switch msg.String() {
@@ -175,20 +181,43 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.preview.currentMatch = -1
 			return m, nil
 
-		case "enter", "n":
-			// Find next match
+		case "enter", "down", "ctrl+n":
+			// Find next match (hex view / pager: stream forward through the file)
+			if m.preview.hex != nil {
+				return m, m.hexSearchNext()
+			}
+			if m.preview.pager != nil {
+				return m, m.pagerSearchNext()
+			}
 			m.findNextSearchMatch()
 			return m, nil
 
-		case "shift+n":
+		case "up", "ctrl+p":
 			// Find previous match
+			if m.preview.pager != nil || m.preview.hex != nil {
+				m.setStatusMessage("🔍 Large-file search only runs forward (Enter/↓: next match)", false)
+				return m, nil
+			}
 			m.findPreviousSearchMatch()
 			return m, nil
 
+		case "alt+r":
+			m.toggleSearchOption("regex")
+			return m, nil
+
+		case "alt+c":
+			m.toggleSearchOption("case")
+			return m, nil
+
+		case "alt+w":
+			m.toggleSearchOption("word")
+			return m, nil
+
 		case "backspace":
 			// Delete last character from search query
 			if len(m.preview.searchQuery) > 0 {
-				m.preview.searchQuery = m.preview.searchQuery[:len(m.preview.searchQuery)-1]
+				_, size := utf8.DecodeLastRuneInString(m.preview.searchQuery)
+				m.preview.searchQuery = m.preview.searchQuery[:len(m.preview.searchQuery)-size]
 				m.performPreviewSearch()
 			}
 			return m, nil
@@ -215,6 +244,23 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.filePickerMode = false
 			m.filePickerCopySource = "" // Reset copy mode
 
+			if m.filePickerCompareSource != "" {
+				m.filePickerCompareSource = ""
+				m.loadFiles()
+				m.setStatusMessage("Compare cancelled", false)
+				return m, nil
+			}
+
+			// Restore-to picker was opened from trash view - go back there
+			if m.filePickerRestoreSource != "" {
+				m.filePickerRestoreSource = ""
+				m.showTrashOnly = true
+				m.cursor = 0
+				m.loadFiles()
+				m.setStatusMessage("Restore cancelled", false)
+				return m, nil
+			}
+
 			// Only restore preview mode if we came from edit mode (prompts)
 			// If we came from context menu copy, just return to normal view
 			if m.filePickerRestorePath != "" {
@@ -238,6 +284,34 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			// Get current file (handles tree mode correctly)
 			selectedFile := m.getCurrentFile()
 			if selectedFile != nil {
+				// Check if we're in restore mode (context menu "Restore To...")
+				if m.filePickerRestoreSource != "" {
+					destDir := selectedFile.path
+					if !selectedFile.isDir {
+						destDir = filepath.Dir(destDir)
+					}
+
+					sourcePath := m.filePickerRestoreSource
+					name := filepath.Base(sourcePath)
+					if original, ok := trashOriginalPath(m.trashItems, sourcePath); ok {
+						name = filepath.Base(original) // Drop the trash timestamp prefix
+					}
+
+					m.filePickerMode = false
+					m.filePickerRestoreSource = ""
+					m.restoreTrashedItem(sourcePath, filepath.Join(destDir, name), true)
+					m.loadFiles() // Show the restored item in the destination
+					return m, nil
+				}
+
+				// Compare picker (context menu "Compare with..."): folders are entered, a file is compared
+				if m.filePickerCompareSource != "" && !selectedFile.isDir {
+					source := m.filePickerCompareSource
+					m.filePickerMode = false
+					m.filePickerCompareSource = ""
+					return m, m.openCompare(source, selectedFile.path)
+				}
+
 				// Check if we're in copy mode (context menu copy operation)
 				if m.filePickerCopySource != "" {
 					// Copy mode: selecting destination
@@ -531,6 +605,53 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	// Handle preview mode keys
 	if m.viewMode == viewFullPreview {
+		// Stash browser and commit log cover the whole preview while open
+		if handled, cmd := m.handleStashKey(msg); handled {
+			return m, cmd
+		}
+		if handled, cmd := m.handleGitLogKey(msg); handled {
+			return m, cmd
+		}
+		// Git history browser covers the preview while open
+		if handled, cmd := m.handleHistoryKey(msg); handled {
+			return m, cmd
+		}
+
+		// Comparison with another file: hunk navigation and layout
+		if m.handleCompareKey(msg) {
+			return m, nil
+		}
+
+		// JSON/YAML/TOML tree view navigation
+		if handled := m.handleDataTreeKey(msg); handled {
+			return m, nil
+		}
+
+		// CSV/TSV table: horizontal scroll, column selection, sort, filter and stats
+		if handled, cmd := m.handleCSVTableKey(msg); handled {
+			return m, cmd
+		}
+
+		// SQLite browser: schema list, row grid and SQL prompt
+		if handled, cmd := m.handleDBBrowserKey(msg); handled {
+			return m, cmd
+		}
+
+		// Block-character image preview
+		if handled := m.handleImagePreviewKey(msg); handled {
+			return m, nil
+		}
+
+		// Git blame overlay: line selection and the commit view
+		if handled, cmd := m.handleBlameKey(msg); handled {
+			return m, cmd
+		}
+
+		// Markdown outline, links and back stack
+		if handled, cmd := m.handleMarkdownNavKey(msg); handled {
+			return m, cmd
+		}
+
 		// Normal preview mode keyboard handling
 		switch msg.String() {
 		case "f10", "ctrl+c":
@@ -712,6 +833,9 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				info, _ := os.Stat(m.preview.filePath)
 				if info != nil {
 					m.loadJSONLPreview(m.preview.filePath, info.Size())
+					if m.preview.follow != nil {
+						m.syncFollow() // Continue following from the end of the full read
+					}
 					m.setStatusMessage(fmt.Sprintf("Loaded full transcript (%d messages)", len(m.preview.cachedJSONLMessages)), false)
 				}
 				return m, statusTimeoutCmd()
@@ -803,6 +927,56 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
+		case ":":
+			// Pager / hex view: jump to line, offset, percentage or end
+			if m.preview.pager != nil || m.preview.hex != nil {
+				m.preview.gotoActive = true
+				m.preview.gotoInput = ""
+			}
+			return m, nil
+
+		case "x", "X":
+			// Toggle the built-in hex view
+			m.toggleHexView()
+			return m, nil
+
+		case "w":
+			// Follow mode: show lines appended to the file as they arrive (like tail -f)
+			return m, m.toggleFollow()
+
+		case "b":
+			// Git blame overlay: commit, author and date next to each line
+			return m, m.toggleBlame()
+
+		case "L":
+			// Git history of the file: commits with diffs, old versions and restore
+			return m, m.toggleFileHistory()
+
+		case "D":
+			// Side-by-side / unified layout of the changes mode diff
+			if m.showChangesOnly && m.showDiffPreview {
+				m.toggleDiffSideBySide()
+				return m, nil
+			}
+
+		case "=":
+			// Mark this file for comparison, or compare it with the marked one
+			return m, m.markOrCompare(m.preview.filePath)
+
+		case "home", "g":
+			// Scroll to top
+			m.preview.scrollPos = 0
+
+		case "end", "G":
+			// Scroll to bottom (pager: waits for indexing to reach the end)
+			if m.preview.pager != nil && m.preview.hex == nil {
+				m.pagerJumpTo(pagerJump{line: -1, percent: -1, end: true})
+				return m, nil
+			}
+			totalLines := m.getWrappedLineCount()
+			visibleLines := m.getPreviewVisibleLines()
+			m.preview.scrollPos = max(0, totalLines-visibleLines)
+
 		case "f1":
 			// F1: Show hotkeys reference from preview mode
 			// First check if it exists in current directory
@@ -942,6 +1116,28 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 							}
 						}
 					}
+				} else if m.dialog.title == "Stash Files" {
+					// Stash the marked (or selected) changed files with the typed message
+					message := m.dialog.input
+					m.showDialog = false
+					m.dialog = dialogModel{}
+					m.stashFiles(message)
+					return m, tea.ClearScreen
+				} else if m.dialog.title == "Restore As" {
+					// Restore from trash under a new name next to the original location
+					newName := m.dialog.input
+					original, ok := trashOriginalPath(m.trashItems, m.contextMenuFile.path)
+					if newName == "" || !ok {
+						m.setStatusMessage("Restore cancelled", false)
+					} else if strings.Contains(newName, "/") {
+						m.setStatusMessage("Error: Filename cannot contain '/'", true)
+					} else {
+						m.showDialog = false
+						m.dialog = dialogModel{}
+						// May open the "Restore Conflict" dialog if the name is taken
+						m.restoreTrashedItem(m.contextMenuFile.path, filepath.Join(filepath.Dir(original), newName), false)
+						return m, tea.ClearScreen
+					}
 				} else if m.dialog.title == "Rename" {
 					// Handle rename
 					newName := m.dialog.input
@@ -1012,8 +1208,38 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.dialog = dialogModel{}
 				return m, tea.ClearScreen
 
+			case "p", "P":
+				// Permanent delete (only offered by the "Large Item" dialog)
+				if m.dialog.title != "Large Item" {
+					return m, nil
+				}
+				target := m.contextMenuFile
+				if target == nil {
+					target = m.getCurrentFile()
+				}
+				if target != nil {
+					if err := m.permanentDeleteFileOrDir(target.path, target.isDir); err != nil {
+						m.setStatusMessage(fmt.Sprintf("Error: %s", err), true)
+					} else {
+						m.setStatusMessage(fmt.Sprintf("Permanently deleted: %s", target.name), false)
+						m.loadFiles()
+						if m.cursor >= len(m.files) {
+							m.cursor = len(m.files) - 1
+							if m.cursor < 0 {
+								m.cursor = 0
+							}
+						}
+					}
+				}
+				m.contextMenuFile = nil
+				m.contextMenuOpen = false
+				m.showDialog = false
+				m.dialog = dialogModel{}
+				return m, tea.ClearScreen
+
 			case "y", "Y":
 				// Confirm action
+				var trashCmd tea.Cmd // Background move to trash (see trashFileCmd)
 				if m.dialog.title == "Permanently Delete" {
 					// Permanently delete item from trash
 					if m.contextMenuFile != nil {
@@ -1026,6 +1252,10 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						m.contextMenuFile = nil
 						m.contextMenuOpen = false
 					}
+				} else if m.dialog.title == "Discard Changes" {
+					m.discardFile()
+				} else if m.dialog.title == "Discard Hunk" {
+					m.discardHunk()
 				} else if m.dialog.title == "Empty Trash" {
 					// Empty entire trash
 					if err := emptyTrash(); err != nil {
@@ -1034,15 +1264,21 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 						m.setStatusMessage("Trash emptied successfully", false)
 						m.loadFiles() // Refresh trash view
 					}
+				} else if m.dialog.title == "Large Item" {
+					// Oversized item confirmed for trash anyway
+					target := m.contextMenuFile
+					if target == nil {
+						target = m.getCurrentFile()
+					}
+					if target != nil {
+						trashCmd = m.trashFileCmd(*target, fmt.Sprintf("Moved to trash: %s", target.name))
+					}
+					m.contextMenuFile = nil
+					m.contextMenuOpen = false
 				} else if m.dialog.title == "Move to Trash" {
 					// Move item to trash (from context menu)
 					if m.contextMenuFile != nil {
-						if err := m.deleteFileOrDir(m.contextMenuFile.path, m.contextMenuFile.isDir); err != nil {
-							m.setStatusMessage(fmt.Sprintf("Error: %s", err), true)
-						} else {
-							m.setStatusMessage(fmt.Sprintf("Moved to trash: %s", m.contextMenuFile.name), false)
-							m.loadFiles()
-						}
+						trashCmd = m.trashFileCmd(*m.contextMenuFile, fmt.Sprintf("Moved to trash: %s", m.contextMenuFile.name))
 						m.contextMenuFile = nil
 						m.contextMenuOpen = false
 					}
@@ -1050,45 +1286,26 @@ func (m model) handleKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					// Handle F8 deletion
 					if m.contextMenuFile != nil {
 						// Delete from context menu
-						if err := m.deleteFileOrDir(m.contextMenuFile.path, m.contextMenuFile.isDir); err != nil {
-							m.setStatusMessage(fmt.Sprintf("Error: %s", err), true)
-						} else {
-							itemType := "file"
-							if m.contextMenuFile.isDir {
-								itemType = "directory"
-							}
-							m.setStatusMessage(fmt.Sprintf("Deleted %s: %s", itemType, m.contextMenuFile.name), false)
-							m.loadFiles()
-							// Adjust cursor if needed
-							if m.cursor >= len(m.files) {
-								m.cursor = len(m.files) - 1
-								if m.cursor < 0 {
-									m.cursor = 0
-								}
-							}
+						itemType := "file"
+						if m.contextMenuFile.isDir {
+							itemType = "directory"
 						}
+						trashCmd = m.trashFileCmd(*m.contextMenuFile, fmt.Sprintf("Deleted %s: %s", itemType, m.contextMenuFile.name))
 						m.contextMenuFile = nil
 						m.contextMenuOpen = false
 					} else if currentFile := m.getCurrentFile(); currentFile != nil {
 						// Delete from F8 key
-						if err := m.deleteFileOrDir(currentFile.path, currentFile.isDir); err != nil {
-							m.setStatusMessage(fmt.Sprintf("Error: %s", err), true)
-						} else {
-							itemType := "file"
-							if currentFile.isDir {
-								itemType = "directory"
-							}
-							m.setStatusMessage(fmt.Sprintf("Deleted %s: %s", itemType, currentFile.name), false)
-							m.loadFiles()
-							// Adjust cursor if needed
-							if m.cursor >= len(m.files) {
-								m.cursor = len(m.files) - 1
-								if m.cursor < 0 {
-									m.cursor = 0
-								}
-							}
+						itemType := "file"
+						if currentFile.isDir {
+							itemType = "directory"
 						}
+						trashCmd = m.trashFileCmd(*currentFile, fmt.Sprintf("Deleted %s: %s", itemType, currentFile.name))
 					}
+				} else if m.dialog.title == "Restore Conflict" {
+					// Keep both: restore under a " (N)" suffixed name
+					m.restoreTrashedItem(m.pendingRestorePath, m.pendingRestoreDest, true)
+					m.pendingRestorePath = ""
+					m.pendingRestoreDest = ""
 				} else if m.dialog.title == "Pull & Rebuild TFE" {
 					// Find TFE repository
 					tfeRepoPath := findTFERepository()
@@ -1171,12 +1388,18 @@ rm -f "$0"
 				}
 				m.showDialog = false
 				m.dialog = dialogModel{}
-				return m, tea.ClearScreen
+				return m, tea.Batch(trashCmd, tea.ClearScreen)
 			}
 			return m, nil
 
 		case dialogSettings:
 			return m.handleSettingsKeyEvent(msg)
+
+		case dialogCommit:
+			return m.handleCommitKey(msg)
+
+		case dialogBranches:
+			return m.handleBranchKey(msg)
 		}
 	}
 
@@ -1530,6 +1753,11 @@ rm -f "$0"
 		}
 	}
 
+	// Comparison shown in the dual-pane preview: hunk navigation from either pane
+	if m.viewMode == viewDualPane && !m.commandFocused && m.handleCompareKey(msg) {
+		return m, nil
+	}
+
 	// Regular file browser keys
 	switch msg.String() {
 	case "ctrl+p":
@@ -1586,9 +1814,12 @@ rm -f "$0"
 
 	case "esc":
 		// Context-aware ESC behavior:
-		// 1. Exit dual-pane mode if active
-		// 2. Otherwise, go to parent directory (Windows-style back navigation)
-		if m.viewMode == viewDualPane {
+		// 1. Clear the changes mode hunk selection
+		// 2. Exit dual-pane mode if active
+		// 3. Otherwise, go to parent directory (Windows-style back navigation)
+		if m.activeHunkSelection() != nil {
+			m.clearHunkSelection()
+		} else if m.viewMode == viewDualPane {
 			m.viewMode = viewSinglePane
 			m.calculateLayout()
 			m.populatePreviewCache() // Refresh cache with new width
@@ -1626,7 +1857,7 @@ rm -f "$0"
 				if m.cursor > 0 {
 					m.cursor--
 					// Update preview if file selected
-					if currentFile := m.getCurrentFile(); currentFile != nil && !currentFile.isDir {
+					if currentFile := m.getCurrentFile(); currentFile != nil && currentFile.name != ".." {
 						m.loadPreview(currentFile.path)
 						m.populatePreviewCache() // Populate cache with dual-pane width
 					}
@@ -1674,7 +1905,7 @@ rm -f "$0"
 				if m.cursor < maxCursor {
 					m.cursor++
 					// Update preview if file selected
-					if currentFile := m.getCurrentFile(); currentFile != nil && !currentFile.isDir {
+					if currentFile := m.getCurrentFile(); currentFile != nil && currentFile.name != ".." {
 						m.loadPreview(currentFile.path)
 						m.populatePreviewCache() // Populate cache with dual-pane width
 					}
@@ -1869,7 +2100,7 @@ rm -f "$0"
 			m.focusedPane = leftPane
 			m.calculateLayout()
 			// Load preview of current file
-			if currentFile := m.getCurrentFile(); currentFile != nil && !currentFile.isDir {
+			if currentFile := m.getCurrentFile(); currentFile != nil && currentFile.name != ".." {
 				m.loadPreview(currentFile.path)
 				m.populatePreviewCache() // Populate cache with dual-pane width
 			}
@@ -1887,7 +2118,7 @@ rm -f "$0"
 			m.focusedPane = leftPane
 			m.calculateLayout()
 			// Load preview of current file
-			if currentFile := m.getCurrentFile(); currentFile != nil && !currentFile.isDir {
+			if currentFile := m.getCurrentFile(); currentFile != nil && currentFile.name != ".." {
 				m.loadPreview(currentFile.path)
 				m.populatePreviewCache() // Populate cache with dual-pane width
 			}
@@ -1934,7 +2165,7 @@ rm -f "$0"
 					m.cursor = 0
 				}
 				// Update preview if file selected
-				if currentFile := m.getCurrentFile(); currentFile != nil && !currentFile.isDir {
+				if currentFile := m.getCurrentFile(); currentFile != nil && currentFile.name != ".." {
 					m.loadPreview(currentFile.path)
 					m.populatePreviewCache() // Populate cache with dual-pane width
 				}
@@ -1973,7 +2204,7 @@ rm -f "$0"
 					m.cursor = 0
 				}
 				// Update preview if file selected
-				if currentFile := m.getCurrentFile(); currentFile != nil && !currentFile.isDir {
+				if currentFile := m.getCurrentFile(); currentFile != nil && currentFile.name != ".." {
 					m.loadPreview(currentFile.path)
 					m.populatePreviewCache() // Populate cache with dual-pane width
 				}
@@ -2445,6 +2676,107 @@ rm -f "$0"
 			return m, nil
 		}
 
+	case "D":
+		// 'D': Side-by-side / unified diff layout (only in changes mode)
+		if m.showChangesOnly && !m.commandFocused {
+			m.toggleDiffSideBySide()
+			return m, nil
+		}
+
+	case "s":
+		// 's': Stage the selected file, or the selected hunk (only in changes mode)
+		if m.showChangesOnly && !m.commandFocused {
+			m.stageFile()
+			return m, nil
+		}
+
+	case "u":
+		// 'u': Unstage the selected file, or the selected hunk (only in changes mode)
+		if m.showChangesOnly && !m.commandFocused {
+			m.unstageFile()
+			return m, nil
+		}
+
+	case "X":
+		// 'X': Discard unstaged changes of the selected file or hunk, after confirmation (only in changes mode)
+		if m.showChangesOnly && !m.commandFocused {
+			m.confirmDiscard()
+			return m, tea.ClearScreen
+		}
+
+	case "c":
+		// 'c': Commit the staged changes (only in changes mode)
+		if m.showChangesOnly && !m.commandFocused {
+			m.openCommitComposer()
+			return m, tea.ClearScreen
+		}
+
+	case "S":
+		// 'S': Stash browser for the current repository (full-screen preview)
+		if !m.commandFocused {
+			m.openStashBrowser()
+			return m, tea.ClearScreen
+		}
+
+	case "L":
+		// 'L': Commit log of the current repository (full-screen preview; L in the preview is the file's history)
+		if !m.commandFocused {
+			cmd := m.openGitLog()
+			return m, tea.Batch(cmd, tea.ClearScreen)
+		}
+
+	case "z":
+		// 'z': Mark/unmark the selected file for stashing (only in changes mode)
+		if m.showChangesOnly && !m.commandFocused {
+			m.toggleStashMark()
+			return m, nil
+		}
+
+	case "Z":
+		// 'Z': Stash the marked files, or the selected one, after asking for a message (only in changes mode)
+		if m.showChangesOnly && !m.commandFocused {
+			m.promptStashFiles()
+			return m, tea.ClearScreen
+		}
+
+	case "B":
+		// 'B': Branch manager for the current repository (the selected one in git repos mode)
+		if !m.commandFocused {
+			m.openBranchManager(m.selectedRepoRoot())
+			return m, tea.ClearScreen
+		}
+
+	case "[", "]":
+		// '[' / ']': Select the previous/next hunk of the diff for staging (only in changes mode)
+		if m.showChangesOnly && m.showDiffPreview && !m.commandFocused {
+			if msg.String() == "[" {
+				m.moveHunkSelection(-1)
+			} else {
+				m.moveHunkSelection(1)
+			}
+			return m, nil
+		}
+
+	case "i":
+		// 'i': Switch the hunk selection between unstaged and staged hunks (only in changes mode)
+		if m.showChangesOnly && !m.commandFocused && m.activeHunkSelection() != nil {
+			m.toggleHunkSide()
+			return m, nil
+		}
+
+	case "=":
+		// '=': Mark a file, then compare the next one with it (the preview's file when it's focused)
+		if !m.commandFocused {
+			path := m.preview.filePath
+			if m.viewMode != viewDualPane || m.focusedPane == leftPane {
+				path = ""
+				if currentFile := m.getCurrentFile(); currentFile != nil && !currentFile.isDir {
+					path = currentFile.path
+				}
+			}
+			return m, m.markOrCompare(path)
+		}
+
 	case "y":
 		// 'y': In changes mode, copy current file's diff to clipboard as markdown
 		if m.showChangesOnly && !m.commandFocused {
@@ -2612,13 +2944,12 @@ rm -f "$0"
 		if currentFile.isDir {
 			fileType = "directory"
 		}
-		m.dialog = dialogModel{
+		cmd := m.openTrashDeleteDialog(*currentFile, dialogModel{
 			dialogType: dialogConfirm,
 			title:      "Delete " + fileType,
 			message:    fmt.Sprintf("Delete '%s'?\nThis cannot be undone.", currentFile.name),
-		}
-		m.showDialog = true
-		return m, tea.ClearScreen
+		})
+		return m, tea.Batch(cmd, tea.ClearScreen)
 
 	case "ctrl+,":
 		// Ctrl+,: Open settings panel
@@ -2643,7 +2974,12 @@ rm -f "$0"
 // handlePreviewOnlyKeyEvent handles keyboard input in standalone preview mode
 // Only minimal keybindings are supported: quit, scroll, and search
 func (m model) handlePreviewOnlyKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Handle search mode input first
+	// Handle pager jump prompt input first
+	if m.preview.gotoActive {
+		return m.handlePagerGotoKey(msg)
+	}
+
+	// Handle search mode input
 	if m.preview.searchActive {
 		switch msg.String() {
 		case "esc":
@@ -2653,12 +2989,40 @@ func (m model) handlePreviewOnlyKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.preview.currentMatch = -1
 			return m, nil
 		case "enter":
-			// Accept search and exit search input mode
+			// Accept search and exit search input mode (hex view / pager: start the streaming search)
 			m.preview.searchActive = false
+			if m.preview.hex != nil {
+				return m, m.hexSearchNext()
+			}
+			if m.preview.pager != nil {
+				return m, m.pagerSearchNext()
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.preview.hex != nil {
+				return m, m.hexSearchNext()
+			}
+			if m.preview.pager != nil {
+				return m, m.pagerSearchNext()
+			}
+			m.findNextSearchMatch()
+			return m, nil
+		case "up", "ctrl+p":
+			m.findPreviousSearchMatch()
+			return m, nil
+		case "alt+r":
+			m.toggleSearchOption("regex")
+			return m, nil
+		case "alt+c":
+			m.toggleSearchOption("case")
+			return m, nil
+		case "alt+w":
+			m.toggleSearchOption("word")
 			return m, nil
 		case "backspace":
 			if len(m.preview.searchQuery) > 0 {
-				m.preview.searchQuery = m.preview.searchQuery[:len(m.preview.searchQuery)-1]
+				_, size := utf8.DecodeLastRuneInString(m.preview.searchQuery)
+				m.preview.searchQuery = m.preview.searchQuery[:len(m.preview.searchQuery)-size]
 				m.performPreviewSearch()
 			}
 			return m, nil
@@ -2672,6 +3036,36 @@ func (m model) handlePreviewOnlyKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if handled, cmd := m.handleHistoryKey(msg); handled {
+		return m, cmd
+	}
+
+	// JSON/YAML/TOML tree view navigation
+	if handled := m.handleDataTreeKey(msg); handled {
+		return m, nil
+	}
+
+	// CSV/TSV table: horizontal scroll, column selection, sort, filter and stats
+	if handled, cmd := m.handleCSVTableKey(msg); handled {
+		return m, cmd
+	}
+
+	// SQLite browser: schema list, row grid and SQL prompt
+	if handled, cmd := m.handleDBBrowserKey(msg); handled {
+		return m, cmd
+	}
+
+	// Block-character image preview
+	if handled := m.handleImagePreviewKey(msg); handled {
+		return m, nil
+	}
+	if handled, cmd := m.handleBlameKey(msg); handled {
+		return m, cmd
+	}
+	if handled, cmd := m.handleMarkdownNavKey(msg); handled {
+		return m, cmd
+	}
+
 	switch msg.String() {
 	case "q", "esc", "ctrl+c":
 		return m, tea.Quit
@@ -2718,7 +3112,11 @@ func (m model) handlePreviewOnlyKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.preview.scrollPos = 0
 
 	case "end", "G":
-		// Scroll to bottom
+		// Scroll to bottom (pager: waits for indexing to reach the end)
+		if m.preview.pager != nil && m.preview.hex == nil {
+			m.pagerJumpTo(pagerJump{line: -1, percent: -1, end: true})
+			return m, nil
+		}
 		totalLines := m.getWrappedLineCount()
 		visibleLines := m.getPreviewVisibleLines()
 		maxScroll := totalLines - visibleLines
@@ -2727,6 +3125,29 @@ func (m model) handlePreviewOnlyKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.preview.scrollPos = maxScroll
 
+	case ":":
+		// Pager / hex view: jump to line, offset, percentage or end
+		if m.preview.pager != nil || m.preview.hex != nil {
+			m.preview.gotoActive = true
+			m.preview.gotoInput = ""
+		}
+
+	case "x", "X":
+		// Toggle the built-in hex view
+		m.toggleHexView()
+
+	case "w":
+		// Follow mode: show lines appended to the file as they arrive (like tail -f)
+		return m, m.toggleFollow()
+
+	case "b":
+		// Git blame overlay: commit, author and date next to each line
+		return m, m.toggleBlame()
+
+	case "L":
+		// Git history of the file: commits with diffs, old versions and restore
+		return m, m.toggleFileHistory()
+
 	case "ctrl+f", "/":
 		// Activate search mode in preview
 		if !m.preview.searchActive {
@@ -2738,21 +3159,454 @@ func (m model) handlePreviewOnlyKeyEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "n":
 		// Next search match
+		if m.preview.hex != nil {
+			return m, m.hexSearchNext()
+		}
+		if m.preview.pager != nil {
+			return m, m.pagerSearchNext()
+		}
 		if len(m.preview.searchMatches) > 0 {
-			m.preview.currentMatch = (m.preview.currentMatch + 1) % len(m.preview.searchMatches)
-			m.preview.scrollPos = m.preview.searchMatches[m.preview.currentMatch]
+			m.findNextSearchMatch()
 		}
 
 	case "N":
 		// Previous search match
 		if len(m.preview.searchMatches) > 0 {
-			m.preview.currentMatch--
-			if m.preview.currentMatch < 0 {
-				m.preview.currentMatch = len(m.preview.searchMatches) - 1
-			}
-			m.preview.scrollPos = m.preview.searchMatches[m.preview.currentMatch]
+			m.findPreviousSearchMatch()
 		}
 	}
 
 	return m, nil
 }
+
+// handlePagerGotoKey handles input for the pager / hex view jump prompt (":")
+// Pager: a line number, a percentage ("50%") or "$" for the end of the file.
+// Hex view: a byte offset ("0x1f00" or "4096"), a percentage or "$".
+// Tree view: the prompt holds a jq-like filter (opened with ".").
+// Table view: the prompt holds a row filter (opened with ".").
+// SQLite browser: the prompt holds a SQL query (opened with ".").
+func (m model) handlePagerGotoKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.preview.gotoActive = false
+		m.preview.gotoInput = ""
+
+	case "enter":
+		m.preview.gotoActive = false
+		if m.preview.db != nil {
+			cmd := m.dbQueryCmd(m.preview.gotoInput)
+			m.preview.gotoInput = ""
+			return m, cmd
+		} else if m.preview.table != nil {
+			cmd := m.csvFilterCmd(m.preview.gotoInput)
+			m.preview.gotoInput = ""
+			return m, cmd
+		} else if m.dataTreeActive() {
+			m.applyDataTreeFilter(m.preview.gotoInput)
+		} else if m.preview.hex != nil {
+			if offset, err := parseHexJump(m.preview.gotoInput, m.preview.hex.size); err != nil {
+				m.setStatusMessage(err.Error(), true)
+			} else {
+				m.hexJumpTo(offset)
+			}
+		} else if jump, err := parsePagerJump(m.preview.gotoInput); err != nil {
+			m.setStatusMessage(err.Error(), true)
+		} else {
+			m.pagerJumpTo(jump)
+		}
+		m.preview.gotoInput = ""
+
+	case "backspace":
+		if len(m.preview.gotoInput) > 0 {
+			m.preview.gotoInput = m.preview.gotoInput[:len(m.preview.gotoInput)-1]
+		}
+
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			m.preview.gotoInput += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+// handleDataTreeKey handles tree view keys (full-screen and standalone preview).
+// Returns false for keys the tree doesn't use, so the regular preview handles them.
+func (m *model) handleDataTreeKey(msg tea.KeyMsg) bool {
+	t := m.preview.tree
+	if t == nil || m.preview.hex != nil {
+		return false
+	}
+	if msg.String() == "t" {
+		m.toggleDataTreeText()
+		return true
+	}
+	if t.textMode {
+		return false
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		m.dataTreeMoveCursor(-1)
+	case "down", "j":
+		m.dataTreeMoveCursor(1)
+	case "pageup", "pgup":
+		m.dataTreeMoveCursor(-m.getPreviewVisibleLines())
+	case "pagedown", "pgdn", "pgdown":
+		m.dataTreeMoveCursor(m.getPreviewVisibleLines())
+	case "home", "g":
+		m.dataTreeMoveCursor(-len(t.rows))
+	case "end", "G":
+		m.dataTreeMoveCursor(len(t.rows))
+	case "left", "h":
+		m.dataTreeCollapseOrParent()
+	case "right", "l":
+		m.dataTreeToggle(false)
+	case "enter", " ":
+		m.dataTreeToggle(!t.current().collapsed)
+	case "+":
+		m.dataTreeSetAll(false)
+	case "-":
+		m.dataTreeSetAll(true)
+	case "y":
+		m.copyDataTreePath()
+	case "Y":
+		m.copyDataTreeValue()
+	case ".", "|":
+		// Filter prompt, pre-filled with the active filter
+		m.preview.gotoActive = true
+		m.preview.gotoInput = "."
+		if t.filter != "" {
+			m.preview.gotoInput = t.filter
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// handleCSVTableKey handles table view keys (full-screen and standalone preview).
+// Returns false for keys the table doesn't use, so the regular preview handles them.
+func (m *model) handleCSVTableKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	t := m.preview.table
+	if t == nil || m.preview.hex != nil {
+		return false, nil
+	}
+
+	switch msg.String() {
+	case "left", "h":
+		m.csvScrollBy(-csvScrollStep)
+	case "right", "l":
+		m.csvScrollBy(csvScrollStep)
+	case "[", "shift+tab":
+		return true, m.csvSelectColumn(-1)
+	case "]", "tab":
+		return true, m.csvSelectColumn(1)
+	case "s":
+		return true, m.csvSortNext()
+	case ".":
+		// Row filter prompt, pre-filled with the active filter
+		m.preview.gotoActive = true
+		m.preview.gotoInput = t.filter
+	case "i":
+		t.showStats = !t.showStats
+		if t.showStats {
+			return true, m.csvStatsCmd()
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// handleDBBrowserKey handles SQLite browser keys (full-screen and standalone preview).
+// Returns false for keys the browser doesn't use, so the regular preview handles them.
+func (m *model) handleDBBrowserKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	b := m.preview.db
+	if b == nil || m.preview.hex != nil {
+		return false, nil
+	}
+	if msg.String() == "." {
+		m.dbOpenQueryPrompt()
+		return true, nil
+	}
+
+	if b.grid != nil {
+		switch msg.String() {
+		case "left", "h":
+			m.dbScrollBy(-dbScrollStep)
+		case "right", "l":
+			m.dbScrollBy(dbScrollStep)
+		case "backspace":
+			m.dbBack()
+		default:
+			return false, nil
+		}
+		return true, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		m.dbMoveCursor(-1)
+	case "down", "j":
+		m.dbMoveCursor(1)
+	case "pageup", "pgup":
+		m.dbMoveCursor(-10)
+	case "pagedown", "pgdn", "pgdown":
+		m.dbMoveCursor(10)
+	case "home", "g":
+		m.dbMoveCursor(-len(b.db.schema))
+	case "end", "G":
+		m.dbMoveCursor(len(b.db.schema))
+	case "enter", "right", "l":
+		return true, m.dbOpenObjectCmd()
+	case "y":
+		m.copyDBSchema()
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// handleImagePreviewKey handles image preview keys: block modes and GIF playback (full-screen and standalone preview).
+// Returns false for keys the image preview doesn't use.
+func (m *model) handleImagePreviewKey(msg tea.KeyMsg) bool {
+	if m.preview.hex != nil {
+		return false
+	}
+	if m.preview.anim != nil {
+		// Animated GIF playback (graphics protocol or block characters)
+		switch msg.String() {
+		case "p", " ":
+			m.toggleImageAnimation()
+			return true
+		case "[":
+			m.stepImageFrame(-1)
+			return true
+		case "]":
+			m.stepImageFrame(1)
+			return true
+		}
+	}
+	if m.preview.image == nil {
+		return false
+	}
+	switch msg.String() {
+	case "b":
+		m.toggleImageBlockMode()
+		return true
+	}
+	return false
+}
+
+// handleMarkdownNavKey handles markdown navigation keys (full-screen and standalone preview):
+// the outline/link panel while it's open, otherwise o/l to open it, [/] between headings and Backspace to go back.
+func (m *model) handleMarkdownNavKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	nav := m.preview.markdown
+	if nav == nil || m.preview.hex != nil || m.preview.blame != nil {
+		return false, nil
+	}
+
+	if nav.panel != mdPanelNone {
+		switch msg.String() {
+		case "esc":
+			nav.panel = mdPanelNone
+		case "enter":
+			return true, m.selectMarkdownPanelItem()
+		case "tab":
+			if nav.panel == mdPanelOutline {
+				m.openMarkdownPanel(mdPanelLinks)
+			} else {
+				m.openMarkdownPanel(mdPanelOutline)
+			}
+		case "up", "ctrl+p":
+			m.moveMarkdownPanelCursor(-1)
+		case "down", "ctrl+n":
+			m.moveMarkdownPanelCursor(1)
+		case "pageup", "pgup":
+			m.moveMarkdownPanelCursor(-m.getPreviewVisibleLines())
+		case "pagedown", "pgdn", "pgdown":
+			m.moveMarkdownPanelCursor(m.getPreviewVisibleLines())
+		case "home":
+			m.moveMarkdownPanelCursor(-len(nav.items))
+		case "end":
+			m.moveMarkdownPanelCursor(len(nav.items))
+		case "backspace":
+			if nav.filter != "" {
+				nav.filter = string([]rune(nav.filter)[:len([]rune(nav.filter))-1])
+				nav.applyFilter()
+				m.moveMarkdownPanelCursor(0)
+			}
+		default:
+			// Type to filter
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				nav.filter += string(msg.Runes)
+				nav.cursor = 0
+				nav.offset = 0
+				nav.applyFilter()
+			}
+		}
+		return true, nil // The panel is modal
+	}
+
+	switch msg.String() {
+	case "o":
+		m.openMarkdownPanel(mdPanelOutline)
+	case "l":
+		m.openMarkdownPanel(mdPanelLinks)
+	case "]":
+		m.jumpMarkdownHeading(1)
+	case "[":
+		m.jumpMarkdownHeading(-1)
+	case "backspace":
+		return m.markdownGoBack(), nil
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// handleBlameKey handles the blame overlay's keys (full-screen and standalone preview):
+// j/k and paging move the selected line, Enter opens its commit, Esc/Backspace goes back.
+func (m *model) handleBlameKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	b := m.preview.blame
+	if b == nil || m.preview.hex != nil {
+		return false, nil
+	}
+
+	if b.detail != nil {
+		// Commit view scrolls like a regular preview
+		switch msg.String() {
+		case "esc", "backspace", "enter":
+			m.closeBlameCommit()
+			return true, nil
+		}
+		return false, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		m.moveBlameCursor(-1)
+	case "down", "j":
+		m.moveBlameCursor(1)
+	case "pageup", "pgup":
+		m.moveBlameCursor(-m.getPreviewVisibleLines())
+	case "pagedown", "pgdn", "pgdown":
+		m.moveBlameCursor(m.getPreviewVisibleLines())
+	case "home", "g":
+		m.moveBlameCursor(-len(m.preview.content))
+	case "end", "G":
+		m.moveBlameCursor(len(m.preview.content))
+	case "enter":
+		return true, m.openBlameCommit()
+	case "esc":
+		return true, m.toggleBlame()
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// handleHistoryKey handles the file history browser's keys (full-screen and standalone preview):
+// j/k move through commits, Enter opens a diff, v the file at that commit, R restores it.
+// The browser covers the preview, so keys of the preview underneath are not passed on.
+func (m *model) handleHistoryKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	h := m.preview.history
+	if h == nil {
+		return false, nil
+	}
+	key := msg.String()
+
+	if h.confirm {
+		h.confirm = false
+		if key == "y" || key == "Y" {
+			return true, m.restoreHistoryVersion()
+		}
+		m.setStatusMessage("Restore cancelled", false)
+		return true, statusTimeoutCmd()
+	}
+
+	switch key {
+	case "q", "ctrl+c", "f10", "f1", "ctrl+f", "/", "m", "M":
+		// Quit, help, search and mouse mode work as usual
+		return false, nil
+	case "L":
+		return true, m.toggleFileHistory()
+	case "R":
+		if h.selected() != nil {
+			h.confirm = true
+			m.setStatusMessage(m.historyStatusText(), false)
+		}
+		return true, nil
+	}
+
+	if v := h.view; v != nil {
+		switch key {
+		case "esc", "backspace":
+			m.closeHistoryView()
+		case "enter":
+			if v.file {
+				return true, m.openHistoryView(false)
+			}
+			m.closeHistoryView()
+		case "v", "V":
+			return true, m.openHistoryView(!v.file)
+		case "up", "k":
+			m.preview.scrollPos = max(m.preview.scrollPos-1, 0)
+		case "down", "j":
+			m.preview.scrollPos = min(m.preview.scrollPos+1, max(m.historyLineCount()-m.getPreviewVisibleLines(), 0))
+		case "pageup", "pgup":
+			m.preview.scrollPos = max(m.preview.scrollPos-m.getPreviewVisibleLines(), 0)
+		case "pagedown", "pgdn", "pgdown", " ":
+			m.preview.scrollPos = min(m.preview.scrollPos+m.getPreviewVisibleLines(), max(m.historyLineCount()-m.getPreviewVisibleLines(), 0))
+		case "home", "g":
+			m.preview.scrollPos = 0
+		case "end", "G":
+			m.preview.scrollPos = max(m.historyLineCount()-m.getPreviewVisibleLines(), 0)
+		}
+		return true, nil
+	}
+
+	switch key {
+	case "up", "k":
+		m.moveHistoryCursor(-1)
+	case "down", "j":
+		m.moveHistoryCursor(1)
+	case "pageup", "pgup":
+		m.moveHistoryCursor(-m.getPreviewVisibleLines())
+	case "pagedown", "pgdn", "pgdown":
+		m.moveHistoryCursor(m.getPreviewVisibleLines())
+	case "home", "g":
+		m.moveHistoryCursor(-len(h.commits))
+	case "end", "G":
+		m.moveHistoryCursor(len(h.commits))
+	case "enter":
+		return true, m.openHistoryView(false)
+	case "v", "V":
+		return true, m.openHistoryView(true)
+	case "esc":
+		return true, m.toggleFileHistory()
+	}
+	return true, nil
+}
+
+// handleCompareKey handles a shown comparison's keys (full-screen and dual-pane
+// preview): [/] jump between hunks, D switches the layout, = closes it. Other keys scroll it.
+func (m *model) handleCompareKey(msg tea.KeyMsg) bool {
+	if m.preview.compare == nil || m.preview.history != nil || m.preview.hex != nil || m.preview.blame != nil {
+		return false
+	}
+
+	switch msg.String() {
+	case "[":
+		m.jumpCompareHunk(-1)
+	case "]":
+		m.jumpCompareHunk(1)
+	case "D":
+		m.toggleDiffSideBySide()
+	case "=":
+		m.closeCompare()
+	default:
+		return false
+	}
+	return true
+}
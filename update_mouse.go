@@ -195,6 +195,10 @@ func (m model) handleMouseEvent(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
 					if m.filePickerMode {
 						if m.filePickerCopySource != "" {
 							titleText += " [📋 Copy Mode - Select Destination]"
+						} else if m.filePickerRestoreSource != "" {
+							titleText += " [♻ Restore - Select Destination]"
+						} else if m.filePickerCompareSource != "" {
+							titleText += " [⚖ Compare - Select File]"
 						} else {
 							titleText += " [📁 File Picker]"
 						}
@@ -439,7 +443,7 @@ git pull
 							m.detailScrollX = 0
 							m.showDiffPreview = true
 							m.calculateLayout()
-							m.setStatusMessage(fmt.Sprintf("Git changes: %d files (d: toggle diff)", len(changed)), false)
+							m.setStatusMessage(fmt.Sprintf("Git changes: %d files (d: toggle diff, s/u: stage/unstage, [/]: hunks, c: commit, z/Z: stash)", len(changed)), false)
 						}
 					} else {
 						m.exitChangesMode()
@@ -811,8 +815,17 @@ git pull
 				}
 
 				if isDoubleClick {
+					// Compare picker: double-click on a file compares it
+					if m.filePickerMode && m.filePickerCompareSource != "" && !clickedFile.isDir {
+						source := m.filePickerCompareSource
+						m.filePickerMode = false
+						m.filePickerCompareSource = ""
+						return m, m.openCompare(source, clickedFile.path)
+					}
+
 					// In file picker mode, double-click on file should select it
-					if m.filePickerMode && !clickedFile.isDir {
+					// (restore-to picker only picks folders - Enter selects the destination)
+					if m.filePickerMode && m.filePickerRestoreSource == "" && !clickedFile.isDir {
 						// Save edit state before reloading preview (loadPreview resets these)
 						savedEditMode := m.promptEditMode
 						savedFocusedIndex := m.focusedVariableIndex
@@ -909,7 +922,7 @@ git pull
 					m.lastClickTime = now
 
 					// Update preview in dual-pane mode
-					if m.viewMode == viewDualPane && !clickedFile.isDir {
+					if m.viewMode == viewDualPane && clickedFile.name != ".." {
 						m.loadPreview(clickedFile.path)
 						m.populatePreviewCache() // Populate cache with dual-pane width
 					}
@@ -1096,7 +1109,7 @@ git pull
 				m.cursor--
 				// Update preview in dual-pane mode
 				if m.viewMode == viewDualPane {
-					if currentFile := m.getCurrentFile(); currentFile != nil && !currentFile.isDir {
+					if currentFile := m.getCurrentFile(); currentFile != nil && currentFile.name != ".." {
 						m.loadPreview(currentFile.path)
 						m.populatePreviewCache() // Populate cache with dual-pane width
 					}
@@ -1147,7 +1160,7 @@ git pull
 				m.cursor++
 				// Update preview in dual-pane mode
 				if m.viewMode == viewDualPane {
-					if currentFile := m.getCurrentFile(); currentFile != nil && !currentFile.isDir {
+					if currentFile := m.getCurrentFile(); currentFile != nil && currentFile.name != ".." {
 						m.loadPreview(currentFile.path)
 						m.populatePreviewCache() // Populate cache with dual-pane width
 					}
@@ -90,6 +90,10 @@ func (m model) renderSinglePane() string {
 		if m.filePickerMode {
 			if m.filePickerCopySource != "" {
 				titleText += " [📋 Copy Mode - Select Destination]"
+			} else if m.filePickerRestoreSource != "" {
+				titleText += " [♻ Restore - Select Destination]"
+			} else if m.filePickerCompareSource != "" {
+				titleText += " [⚖ Compare - Select File]"
 			} else {
 				titleText += " [📁 File Picker]"
 			}
@@ -372,6 +376,11 @@ func (m model) renderSinglePane() string {
 			favoritesIndicator = " • ⭐ favorites only"
 		}
 
+		trashIndicator := ""
+		if m.showTrashOnly {
+			trashIndicator = fmt.Sprintf(" • 🗑 %s in trash (%s)", formatFileSize(m.trashTotalSize), m.config.trashPolicy())
+		}
+
 		promptsIndicator := ""
 		if m.showPromptsOnly {
 			promptsIndicator = " • 📝 prompts only"
@@ -380,7 +389,9 @@ func (m model) renderSinglePane() string {
 		changesIndicator := ""
 		if m.showChangesOnly {
 			diffMode := "file"
-			if m.showDiffPreview {
+			if m.showDiffPreview && m.diffSideBySide {
+				diffMode = "side-by-side"
+			} else if m.showDiffPreview {
 				diffMode = "diff"
 			}
 			changesIndicator = fmt.Sprintf(" • ⚡ %d changes [%s]", len(m.changedFiles), diffMode)
@@ -397,7 +408,7 @@ func (m model) renderSinglePane() string {
 
 		// Split status into two lines to prevent truncation
 		// Line 1: Counts, indicators, view mode, help
-		statusLine1 := fmt.Sprintf("%s%s%s%s%s%s%s", itemsInfo, hiddenIndicator, favoritesIndicator, promptsIndicator, changesIndicator, viewModeText, helpHint)
+		statusLine1 := fmt.Sprintf("%s%s%s%s%s%s%s%s", itemsInfo, hiddenIndicator, favoritesIndicator, trashIndicator, promptsIndicator, changesIndicator, viewModeText, helpHint)
 		// Use scrolling footer (click to activate) or truncate if too long
 		statusLine1 = m.renderScrollingFooter(statusLine1, m.width-4)
 		s.WriteString(statusStyle.Render(statusLine1))